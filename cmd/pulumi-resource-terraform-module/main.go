@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/pulumi/pulumi/pkg/v3/resource/provider"
@@ -25,6 +26,24 @@ import (
 
 func main() {
 	disableTFLogging()
+	if len(os.Args) > 1 && os.Args[1] == modprovider.ForceUnlockCommandName {
+		if err := modprovider.ForceUnlock(context.Background(), os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			cmdutil.ExitError(err.Error())
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == modprovider.MoveStateCommandName {
+		if err := modprovider.MoveState(os.Args[2:], os.Stdout); err != nil {
+			cmdutil.ExitError(err.Error())
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == modprovider.InventoryCommandName {
+		if err := modprovider.Inventory(os.Args[2:], os.Stdin, os.Stdout); err != nil {
+			cmdutil.ExitError(err.Error())
+		}
+		return
+	}
 	err := provider.Main(modprovider.Name(), modprovider.StartServer)
 	if err != nil {
 		cmdutil.ExitError(err.Error())
@@ -0,0 +1,89 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// warnOnAnyTypes summarizes the inputs and outputs whose types could not be inferred when adding a package. They are
+// typed as Any, or as collections of Any, and the SDKs do not type their values. Properties that the schema overrides
+// already type are not listed, as overriding the types is how users are expected to address the warning.
+func warnOnAnyTypes(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	inferredModule *InferredModuleSchema,
+	override *InferredModuleSchema, // may be nil
+) {
+	var overriddenInputs, overriddenOutputs map[resource.PropertyKey]*schema.PropertySpec
+	if override != nil {
+		overriddenInputs, overriddenOutputs = override.Inputs, override.Outputs
+	}
+	inputs := anyTypedProperties(inferredModule.Inputs, overriddenInputs)
+	outputs := anyTypedProperties(inferredModule.Outputs, overriddenOutputs)
+	if len(inputs) == 0 && len(outputs) == 0 {
+		return
+	}
+
+	var listed []string
+	if len(inputs) > 0 {
+		listed = append(listed, "inputs "+strings.Join(inputs, ", "))
+	}
+	if len(outputs) > 0 {
+		listed = append(listed, "outputs "+strings.Join(outputs, ", "))
+	}
+	logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("The types of module %s could not be inferred and "+
+		"fall back to Any, so the generated SDK does not type their values. Supply their types in the module "+
+		"configuration of `pulumi package add --config` to type them.", strings.Join(listed, " and ")))
+}
+
+// anyTypedProperties lists the names of the properties typed as Any, or as collections of Any, in order, leaving out
+// the overridden ones.
+func anyTypedProperties(
+	properties map[resource.PropertyKey]*schema.PropertySpec,
+	overridden map[resource.PropertyKey]*schema.PropertySpec,
+) []string {
+	var names []string
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		if _, ok := overridden[name]; ok || properties[name] == nil {
+			continue
+		}
+		if isAnyType(properties[name].TypeSpec) {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
+
+func isAnyType(t schema.TypeSpec) bool {
+	switch {
+	case t.Ref == anyType.Ref:
+		return true
+	case t.Items != nil:
+		return isAnyType(*t.Items)
+	case t.AdditionalProperties != nil:
+		return isAnyType(*t.AdditionalProperties)
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestWarnOnAnyTypes(t *testing.T) {
+	ctx := context.Background()
+	inferredModule, err := inferModuleSchemaFromContent("anytypes", loadTestModule(t, "any-types"), nil)
+	require.NoError(t, err)
+
+	logger := &recordingLogger{}
+	warnOnAnyTypes(ctx, logger, inferredModule, nil)
+	require.Len(t, logger.messages, 1)
+	assert.Contains(t, logger.messages[0], "warn: The types of module inputs overrides, settings and "+
+		"outputs decoded, overrides, settings could not be inferred and fall back to Any")
+
+	// Outputs typed by the schema overrides are no longer listed.
+	override := &InferredModuleSchema{
+		Outputs: map[resource.PropertyKey]*schema.PropertySpec{
+			"decoded":  {TypeSpec: mapType(stringType)},
+			"settings": {TypeSpec: stringType},
+		},
+	}
+	logger = &recordingLogger{}
+	warnOnAnyTypes(ctx, logger, inferredModule, override)
+	require.Len(t, logger.messages, 1)
+	assert.Contains(t, logger.messages[0], "module inputs overrides, settings and outputs overrides could not be")
+
+	override.Inputs = map[resource.PropertyKey]*schema.PropertySpec{
+		"overrides": {TypeSpec: mapType(stringType)},
+		"settings":  {TypeSpec: stringType},
+	}
+	override.Outputs["overrides"] = &schema.PropertySpec{TypeSpec: mapType(stringType)}
+	logger = &recordingLogger{}
+	warnOnAnyTypes(ctx, logger, inferredModule, override)
+	assert.Empty(t, logger.messages, "no warning when every type is known")
+}
@@ -0,0 +1,121 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// defaultApplyRetryPatterns match the messages of common transient AWS errors such as throttled API requests.
+var defaultApplyRetryPatterns = []string{
+	`ThrottlingException`,
+	`Throttling: Rate exceeded`,
+	`RequestLimitExceeded`,
+	`TooManyRequestsException`,
+	`SlowDown`,
+}
+
+const (
+	maxApplyRetries     = 3
+	initialApplyBackoff = 10 * time.Second
+)
+
+// applyRetryPolicy decides which failed applies are retried. Applies are retried up to maxRetries times when one of
+// the patterns matches the apply error or one of the error diagnostics, waiting for a backoff that doubles with every
+// retry. The zero value never retries.
+type applyRetryPolicy struct {
+	patterns   []*regexp.Regexp
+	maxRetries int
+	backoff    time.Duration
+}
+
+func newApplyRetryPolicy(patterns []string) (applyRetryPolicy, error) {
+	policy := applyRetryPolicy{maxRetries: maxApplyRetries, backoff: initialApplyBackoff}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return applyRetryPolicy{}, fmt.Errorf("invalid apply retry pattern %q: %w", p, err)
+		}
+		policy.patterns = append(policy.patterns, re)
+	}
+	return policy, nil
+}
+
+// match returns the pattern matching a failed apply, if any.
+func (p applyRetryPolicy) match(err error) (*regexp.Regexp, bool) {
+	messages := []string{err.Error()}
+	var applyErr *tfsandbox.ApplyError
+	if errors.As(err, &applyErr) {
+		messages = append(messages, applyErr.Diagnostics...)
+	}
+	for _, re := range p.patterns {
+		for _, msg := range messages {
+			if re.MatchString(msg) {
+				return re, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// applyWithRetry runs apply, retrying it according to the policy. Retrying is safe because a failed apply records
+// the changes it did make in the state of the working directory, so the next attempt only applies the remainder.
+func applyWithRetry(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	policy applyRetryPolicy,
+	apply func() (*tfsandbox.State, error),
+) (*tfsandbox.State, error) {
+	return retryWithBackoff(ctx, policy.maxRetries, policy.backoff, apply,
+		func(err error, retry int, backoff time.Duration) bool {
+			pattern, ok := policy.match(err)
+			if ok {
+				logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf(
+					"Apply failed with an error matching the retry pattern %q, retrying in %v (retry %d of %d)",
+					pattern, backoff, retry, policy.maxRetries))
+			}
+			return ok
+		})
+}
+
+// retryWithBackoff runs op until it succeeds, up to maxRetries more times. Before every retry shouldRetry decides
+// whether the error of the last attempt is worth retrying, after which retryWithBackoff waits for a backoff that
+// doubles with every retry. The result and error of the last attempt are returned.
+func retryWithBackoff[T any](
+	ctx context.Context,
+	maxRetries int,
+	backoff time.Duration,
+	op func() (T, error),
+	shouldRetry func(err error, retry int, backoff time.Duration) bool,
+) (T, error) {
+	for retry := 1; ; retry++ {
+		result, err := op()
+		if err == nil || retry > maxRetries || !shouldRetry(err, retry, backoff) {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestApplyWithRetry(t *testing.T) {
+	ctx := context.Background()
+	policy, err := newApplyRetryPolicy(defaultApplyRetryPatterns)
+	require.NoError(t, err)
+	policy.backoff = time.Millisecond
+
+	throttled := &tfsandbox.ApplyError{
+		Err: errors.New("exit status 1"),
+		Diagnostics: []string{
+			"Error: creating S3 Bucket: operation error S3: CreateBucket, ThrottlingException: Rate exceeded",
+		},
+	}
+	finalState := &tfsandbox.State{}
+
+	t.Run("transient failure is retried", func(t *testing.T) {
+		logger := &recordingLogger{}
+		attempts := 0
+		state, err := applyWithRetry(ctx, logger, policy, func() (*tfsandbox.State, error) {
+			attempts++
+			if attempts == 1 {
+				return &tfsandbox.State{}, throttled
+			}
+			return finalState, nil
+		})
+		require.NoError(t, err)
+		assert.Same(t, finalState, state)
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, []string{`warn: Apply failed with an error matching the retry pattern "ThrottlingException", ` +
+			`retrying in 1ms (retry 1 of 3)`}, logger.messages)
+	})
+
+	t.Run("retries are bounded", func(t *testing.T) {
+		attempts := 0
+		_, err := applyWithRetry(ctx, &recordingLogger{}, policy, func() (*tfsandbox.State, error) {
+			attempts++
+			return &tfsandbox.State{}, throttled
+		})
+		assert.ErrorIs(t, err, throttled)
+		assert.Equal(t, 1+maxApplyRetries, attempts)
+	})
+
+	t.Run("other failures are not retried", func(t *testing.T) {
+		attempts := 0
+		_, err := applyWithRetry(ctx, &recordingLogger{}, policy, func() (*tfsandbox.State, error) {
+			attempts++
+			return nil, errors.New("AccessDenied")
+		})
+		assert.EqualError(t, err, "AccessDenied")
+		assert.Equal(t, 1, attempts)
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+const (
+	awsProviderName = "aws"
+
+	// The aws provider block listing tags that resources ignore, which keeps tags managed by external systems from
+	// showing up as drift. The camel-cased names are those of the same setting in the Pulumi AWS provider and are
+	// accepted as well.
+	awsIgnoreTags                 = "ignore_tags"
+	awsIgnoreTagsCamel            = "ignoreTags"
+	awsIgnoreTagsKeys             = "keys"
+	awsIgnoreTagsKeyPrefixes      = "key_prefixes"
+	awsIgnoreTagsKeyPrefixesCamel = "keyPrefixes"
+)
+
+// awsIgnoreTagsFailure validates the ignore_tags block of the aws provider configuration, returning the reason it is
+// malformed or an empty string. The block is an object, or a list holding a single object as in the Terraform JSON
+// syntax, with lists of strings for keys and key_prefixes.
+func awsIgnoreTagsFailure(config resource.PropertyMap) string {
+	if config.HasValue(awsIgnoreTags) && config.HasValue(awsIgnoreTagsCamel) {
+		return fmt.Sprintf("provider %q accepts either %s or %s, not both", awsProviderName, awsIgnoreTags,
+			awsIgnoreTagsCamel)
+	}
+	for _, name := range []resource.PropertyKey{awsIgnoreTags, awsIgnoreTagsCamel} {
+		block, ok := config[name]
+		if !ok || block.IsNull() || block.ContainsUnknowns() {
+			continue
+		}
+		block = singleBlockObject(unwrapSecret(block))
+		if !block.IsObject() {
+			return fmt.Sprintf("%s of provider %q must be an object with %s and %s lists, got %v",
+				name, awsProviderName, awsIgnoreTagsKeys, awsIgnoreTagsKeyPrefixes, block.TypeString())
+		}
+		for _, key := range block.ObjectValue().StableKeys() {
+			switch key {
+			case awsIgnoreTagsKeys, awsIgnoreTagsKeyPrefixes, awsIgnoreTagsKeyPrefixesCamel:
+			default:
+				return fmt.Sprintf("%s of provider %q only supports %s and %s, got %q",
+					name, awsProviderName, awsIgnoreTagsKeys, awsIgnoreTagsKeyPrefixes, key)
+			}
+			if !isStringList(unwrapSecret(block.ObjectValue()[key])) {
+				return fmt.Sprintf("%s.%s of provider %q must be a list of strings", name, key, awsProviderName)
+			}
+		}
+	}
+	return ""
+}
+
+// singleBlockObject returns the object of a provider configuration block limited to a single instance, such as
+// ignore_tags, when it is given as a list holding that object.
+func singleBlockObject(block resource.PropertyValue) resource.PropertyValue {
+	if block.IsArray() && len(block.ArrayValue()) == 1 {
+		return block.ArrayValue()[0]
+	}
+	return block
+}
+
+func unwrapSecret(v resource.PropertyValue) resource.PropertyValue {
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	return v
+}
+
+func isStringList(v resource.PropertyValue) bool {
+	if !v.IsArray() {
+		return false
+	}
+	for _, e := range v.ArrayValue() {
+		if e = unwrapSecret(e); !e.IsString() && !e.ContainsUnknowns() {
+			return false
+		}
+	}
+	return true
+}
+
+// fixupProvidersConfigForAWSIgnoreTags writes an ignoreTags setting of the aws provider, named as in the Pulumi AWS
+// provider, as the ignore_tags block expected by the Terraform AWS provider.
+func fixupProvidersConfigForAWSIgnoreTags(config map[string]resource.PropertyMap) map[string]resource.PropertyMap {
+	awsConfig, ok := config[awsProviderName]
+	if !ok || !awsConfig.HasValue(awsIgnoreTagsCamel) || awsConfig.HasValue(awsIgnoreTags) {
+		return config
+	}
+
+	block := singleBlockObject(awsConfig[awsIgnoreTagsCamel])
+	if block.IsObject() {
+		renamed := resource.PropertyMap{}
+		for key, v := range block.ObjectValue() {
+			if key == awsIgnoreTagsKeyPrefixesCamel {
+				key = awsIgnoreTagsKeyPrefixes
+			}
+			renamed[key] = v
+		}
+		block = resource.NewObjectProperty(renamed)
+	}
+
+	fixed := awsConfig.Copy()
+	delete(fixed, awsIgnoreTagsCamel)
+	fixed[awsIgnoreTags] = block
+	config[awsProviderName] = fixed
+	return config
+}
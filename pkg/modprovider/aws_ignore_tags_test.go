@@ -0,0 +1,121 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestAWSIgnoreTagsProviderBlock(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+	}{
+		{
+			name:   "terraform names",
+			config: `{"region":"us-west-2","ignore_tags":{"keys":["CostCenter"],"key_prefixes":["kubernetes.io/"]}}`,
+		},
+		{
+			name:   "pulumi aws names",
+			config: `{"region":"us-west-2","ignoreTags":{"keys":["CostCenter"],"keyPrefixes":["kubernetes.io/"]}}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &server{inferredModuleSchema: &InferredModuleSchema{}}
+			s.providerConfig = resource.PropertyMap{awsKey: resource.NewStringProperty(tc.config)}
+			assert.Empty(t, providersConfigFailures(s.providerConfig))
+
+			workingDir := t.TempDir()
+			err := tfsandbox.CreateTFFile("mod", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+				resource.PropertyMap{}, nil /* outputs */, s.providersConfig(), tfsandbox.CreateTFFileOpts{})
+			require.NoError(t, err)
+
+			contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+			require.NoError(t, err)
+
+			var tfFile struct {
+				Provider map[string]map[string]any `json:"provider"`
+			}
+			require.NoError(t, json.Unmarshal(contents, &tfFile))
+			assert.Equal(t, map[string]any{
+				"region": "us-west-2",
+				"ignore_tags": map[string]any{
+					"keys":         []any{"CostCenter"},
+					"key_prefixes": []any{"kubernetes.io/"},
+				},
+			}, tfFile.Provider[awsKey])
+		})
+	}
+}
+
+func TestCheckConfigMalformedAWSIgnoreTags(t *testing.T) {
+	cases := []struct {
+		name   string
+		config resource.PropertyValue
+		reason string
+	}{
+		{
+			name:   "keys not a list",
+			config: resource.NewStringProperty(`{"ignore_tags":{"keys":"CostCenter"}}`),
+			reason: `ignore_tags.keys of provider "aws" must be a list of strings`,
+		},
+		{
+			name:   "unsupported setting",
+			config: resource.NewStringProperty(`{"ignore_tags":{"key":["CostCenter"]}}`),
+			reason: `ignore_tags of provider "aws" only supports keys and key_prefixes, got "key"`,
+		},
+		{
+			name: "not an object",
+			config: resource.NewObjectProperty(resource.PropertyMap{
+				awsIgnoreTagsCamel: resource.NewStringProperty("CostCenter"),
+			}),
+			reason: `ignoreTags of provider "aws" must be an object with keys and key_prefixes lists, got string`,
+		},
+		{
+			name:   "both names",
+			config: resource.NewStringProperty(`{"ignore_tags":{"keys":["a"]},"ignoreTags":{"keys":["b"]}}`),
+			reason: `provider "aws" accepts either ignore_tags or ignoreTags, not both`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &server{pulumiCliSupportsViews: true}
+			news, err := plugin.MarshalProperties(resource.PropertyMap{awsKey: tc.config}, plugin.MarshalOptions{})
+			require.NoError(t, err)
+
+			resp, err := s.CheckConfig(context.Background(), &pulumirpc.CheckRequest{News: news})
+			require.NoError(t, err)
+			require.Len(t, resp.Failures, 1)
+			assert.Equal(t, awsKey, resp.Failures[0].Property)
+			assert.Equal(t, tc.reason, resp.Failures[0].Reason)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/opentofu/configs"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// childResourceOutput identifies the attribute of a child resource that a module output exposes.
+type childResourceOutput struct {
+	resourceType tfsandbox.TFResourceType
+	resourceName string
+	attribute    string
+	// count and forEach are set for resources expanded with count or for_each, whose attributes are exposed as a
+	// list or as a map keyed by instance key respectively.
+	count   bool
+	forEach bool
+}
+
+// childResourceOutputName names the output exposing an attribute of a child resource after the resource name, its
+// type without the provider prefix and the attribute, such as this_s3_bucket_arn for the arn of aws_s3_bucket.this.
+func childResourceOutputName(resourceType string, resourceName string, attribute string) resource.PropertyKey {
+	typeName := resourceType
+	if _, withoutProvider, ok := strings.Cut(resourceType, "_"); ok {
+		typeName = withoutProvider
+	}
+	name := strings.Join([]string{resourceName, typeName, attribute}, "_")
+	return tfsandbox.PulumiTopLevelKey(strings.ReplaceAll(name, "-", "_"))
+}
+
+// inferChildResourceOutputs adds a module output for every attribute that childOutputs requests of the resources of
+// the given types declared by the module.
+func inferChildResourceOutputs(
+	inferredModule *InferredModuleSchema,
+	module *configs.Module,
+	childOutputs map[string][]string,
+) error {
+	if len(childOutputs) == 0 {
+		return nil
+	}
+	inferredModule.childResourceOutputs = map[resource.PropertyKey]childResourceOutput{}
+
+	declaredTypes := map[string]bool{}
+	for _, addr := range slices.Sorted(maps.Keys(module.ManagedResources)) {
+		r := module.ManagedResources[addr]
+		attributes, ok := childOutputs[r.Type]
+		if !ok {
+			continue
+		}
+		declaredTypes[r.Type] = true
+
+		for _, attribute := range attributes {
+			key := childResourceOutputName(r.Type, r.Name, attribute)
+			if _, exists := inferredModule.Outputs[key]; exists {
+				return fmt.Errorf("the output %q exposing the %s attribute of %s collides with another module output",
+					key, attribute, addr)
+			}
+
+			out := childResourceOutput{
+				resourceType: tfsandbox.TFResourceType(r.Type),
+				resourceName: r.Name,
+				attribute:    attribute,
+				count:        r.Count != nil,
+				forEach:      r.ForEach != nil,
+			}
+			outputType := anyType
+			switch {
+			case out.count:
+				outputType = arrayType(anyType)
+			case out.forEach:
+				outputType = mapType(anyType)
+			}
+			inferredModule.Outputs[key] = &schema.PropertySpec{
+				Description: fmt.Sprintf("The %s attribute of %s.", attribute, addr),
+				TypeSpec:    outputType,
+			}
+			inferredModule.childResourceOutputs[key] = out
+		}
+	}
+
+	for _, resourceType := range slices.Sorted(maps.Keys(childOutputs)) {
+		if !declaredTypes[resourceType] {
+			return fmt.Errorf("childOutputs requests attributes of %s resources, but the module declares none",
+				resourceType)
+		}
+	}
+	return nil
+}
+
+// childResource is an instance of a child resource found in a plan or a state, with its attribute values.
+type childResource struct {
+	moduleAddress string
+	resourceType  tfsandbox.TFResourceType
+	name          string
+	index         any
+	values        resource.PropertyMap
+}
+
+// childResourceOutputsFromState computes the outputs exposing attributes of child resources from the state of the
+// module instance with the given module block name.
+func childResourceOutputsFromState(
+	inferredModule *InferredModuleSchema,
+	moduleName string,
+	state *tfsandbox.State,
+) resource.PropertyMap {
+	if inferredModule == nil || len(inferredModule.childResourceOutputs) == 0 {
+		return nil
+	}
+	var resources []childResource
+	state.VisitResourceStates(func(rs *tfsandbox.ResourceState) {
+		resources = append(resources, childResource{
+			moduleAddress: rs.ModuleAddress(),
+			resourceType:  rs.Type(),
+			name:          rs.Name(),
+			index:         rs.Index(),
+			values:        rs.AttributeValues(),
+		})
+	})
+	return childResourceOutputs(inferredModule, moduleName, resources, resource.NewNullProperty())
+}
+
+// childResourceOutputsFromPlan computes the outputs exposing attributes of child resources from the planned values of
+// the module instance with the given module block name. Attributes that are only known after the apply are unknown.
+func childResourceOutputsFromPlan(
+	inferredModule *InferredModuleSchema,
+	moduleName string,
+	plan *tfsandbox.Plan,
+) resource.PropertyMap {
+	if inferredModule == nil || len(inferredModule.childResourceOutputs) == 0 {
+		return nil
+	}
+	var resources []childResource
+	plan.VisitResourcePlans(func(rp *tfsandbox.ResourcePlan) {
+		values, ok := rp.PlannedValues()
+		if !ok {
+			// the resource is planned to be removed
+			return
+		}
+		resources = append(resources, childResource{
+			moduleAddress: rp.ModuleAddress(),
+			resourceType:  rp.Type(),
+			name:          rp.Name(),
+			index:         rp.Index(),
+			values:        values,
+		})
+	})
+	return childResourceOutputs(inferredModule, moduleName, resources,
+		resource.MakeComputed(resource.NewStringProperty("")))
+}
+
+func childResourceOutputs(
+	inferredModule *InferredModuleSchema,
+	moduleName string,
+	resources []childResource,
+	missing resource.PropertyValue,
+) resource.PropertyMap {
+	// Only the resources declared by the module itself are exposed, not those of its nested modules.
+	moduleAddress := "module." + moduleName
+
+	outputs := resource.PropertyMap{}
+	for key, out := range inferredModule.childResourceOutputs {
+		value := resource.NewNullProperty()
+		counted := map[int]resource.PropertyValue{}
+		keyed := resource.PropertyMap{}
+		for _, r := range resources {
+			if r.moduleAddress != moduleAddress || r.resourceType != out.resourceType || r.name != out.resourceName {
+				continue
+			}
+			v, ok := r.values[resource.PropertyKey(out.attribute)]
+			if !ok {
+				v = missing
+			}
+			switch {
+			case out.forEach:
+				if k, ok := r.index.(string); ok {
+					keyed[resource.PropertyKey(k)] = v
+				}
+			case out.count:
+				if i, ok := instanceNumber(r.index); ok {
+					counted[i] = v
+				}
+			default:
+				value = v
+			}
+		}
+
+		switch {
+		case out.forEach:
+			value = resource.NewObjectProperty(keyed)
+		case out.count:
+			values := []resource.PropertyValue{}
+			for _, i := range slices.Sorted(maps.Keys(counted)) {
+				values = append(values, counted[i])
+			}
+			value = resource.NewArrayProperty(values)
+		}
+		outputs[key] = value
+	}
+	return outputs
+}
+
+// instanceNumber reads the instance key of a resource expanded with count, which is decoded from JSON.
+func instanceNumber(index any) (int, bool) {
+	switch i := index.(type) {
+	case int:
+		return i, true
+	case float64:
+		return int(i), true
+	case json.Number:
+		n, err := i.Int64()
+		return int(n), err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestChildResourceOutputs(t *testing.T) {
+	config := &ModuleConfig{ChildOutputs: map[string][]string{"aws_s3_bucket": {"arn"}}}
+	inferredModule, err := inferModuleSchemaFromContent("buckets", loadTestModule(t, "child-outputs"), config)
+	require.NoError(t, err)
+
+	assert.Equal(t, anyType, inferredModule.Outputs["this_s3_bucket_arn"].TypeSpec)
+	assert.Equal(t, "The arn attribute of aws_s3_bucket.this.", inferredModule.Outputs["this_s3_bucket_arn"].Description)
+	assert.Equal(t, arrayType(anyType), inferredModule.Outputs["logs_s3_bucket_arn"].TypeSpec)
+	assert.Equal(t, mapType(anyType), inferredModule.Outputs["replica_s3_bucket_arn"].TypeSpec)
+	assert.Contains(t, inferredModule.Outputs, resource.PropertyKey("bucket_id"))
+
+	bucket := func(address, name string, index any, arn string) *tfjson.StateResource {
+		return &tfjson.StateResource{
+			Address:         "module.mymod." + address,
+			Mode:            tfjson.ManagedResourceMode,
+			Type:            "aws_s3_bucket",
+			Name:            name,
+			Index:           index,
+			AttributeValues: map[string]any{"arn": arn},
+		}
+	}
+
+	t.Run("state", func(t *testing.T) {
+		state, err := tfsandbox.NewState(&tfjson.State{
+			Values: &tfjson.StateValues{
+				RootModule: &tfjson.StateModule{
+					ChildModules: []*tfjson.StateModule{{
+						Address: "module.mymod",
+						Resources: []*tfjson.StateResource{
+							bucket("aws_s3_bucket.this", "this", nil, "arn:aws:s3:::main"),
+							bucket("aws_s3_bucket.logs[1]", "logs", json.Number("1"), "arn:aws:s3:::logs-1"),
+							bucket("aws_s3_bucket.logs[0]", "logs", json.Number("0"), "arn:aws:s3:::logs-0"),
+							bucket(`aws_s3_bucket.replica["eu"]`, "replica", "eu", "arn:aws:s3:::eu"),
+						},
+						ChildModules: []*tfjson.StateModule{{
+							Address: "module.mymod.module.nested",
+							Resources: []*tfjson.StateResource{
+								bucket("module.nested.aws_s3_bucket.this", "this", nil, "arn:aws:s3:::nested"),
+							},
+						}},
+					}},
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, resource.PropertyMap{
+			"this_s3_bucket_arn": resource.NewStringProperty("arn:aws:s3:::main"),
+			"logs_s3_bucket_arn": resource.NewArrayProperty([]resource.PropertyValue{
+				resource.NewStringProperty("arn:aws:s3:::logs-0"),
+				resource.NewStringProperty("arn:aws:s3:::logs-1"),
+			}),
+			"replica_s3_bucket_arn": resource.NewObjectProperty(resource.PropertyMap{
+				"eu": resource.NewStringProperty("arn:aws:s3:::eu"),
+			}),
+		}, childResourceOutputsFromState(inferredModule, "mymod", state))
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+			PlannedValues: &tfjson.StateValues{
+				RootModule: &tfjson.StateModule{
+					ChildModules: []*tfjson.StateModule{{
+						Address: "module.mymod",
+						Resources: []*tfjson.StateResource{{
+							Address:         "module.mymod.aws_s3_bucket.this",
+							Mode:            tfjson.ManagedResourceMode,
+							Type:            "aws_s3_bucket",
+							Name:            "this",
+							AttributeValues: map[string]any{"bucket": "main"},
+						}},
+					}},
+				},
+			},
+			ResourceChanges: []*tfjson.ResourceChange{{
+				Address:       "module.mymod.aws_s3_bucket.this",
+				ModuleAddress: "module.mymod",
+				Mode:          tfjson.ManagedResourceMode,
+				Type:          "aws_s3_bucket",
+				Name:          "this",
+				Change: &tfjson.Change{
+					Actions:      tfjson.Actions{tfjson.ActionCreate},
+					After:        map[string]any{"bucket": "main"},
+					AfterUnknown: map[string]any{"arn": true},
+				},
+			}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, resource.PropertyMap{
+			"this_s3_bucket_arn":    resource.MakeComputed(resource.NewStringProperty("")),
+			"logs_s3_bucket_arn":    resource.NewArrayProperty([]resource.PropertyValue{}),
+			"replica_s3_bucket_arn": resource.NewObjectProperty(resource.PropertyMap{}),
+		}, childResourceOutputsFromPlan(inferredModule, "mymod", plan))
+	})
+}
+
+func TestChildResourceOutputsAreNotModuleOutputs(t *testing.T) {
+	config := &ModuleConfig{ChildOutputs: map[string][]string{"aws_s3_bucket": {"arn"}}}
+	inferredModule, err := inferModuleSchemaFromContent("buckets", loadTestModule(t, "child-outputs"), config)
+	require.NoError(t, err)
+	require.Contains(t, inferredModule.Outputs, resource.PropertyKey("this_s3_bucket_arn"))
+
+	// the attributes are read from the state, the module declares no such outputs
+	assert.Equal(t, []string{"bucket_id", "internal_output_is_secret_bucket_id"},
+		renderedTFOutputs(t, "child-outputs", inferredModule))
+}
+
+func TestChildResourceOutputsErrors(t *testing.T) {
+	module := loadTestModule(t, "child-outputs")
+
+	_, err := inferModuleSchemaFromContent("buckets", module, &ModuleConfig{
+		ChildOutputs: map[string][]string{"aws_s3_object": {"etag"}},
+	})
+	assert.EqualError(t, err, "childOutputs requests attributes of aws_s3_object resources, but the module declares none")
+
+	_, err = inferModuleSchemaFromContent("buckets", module, &ModuleConfig{
+		ChildOutputs: map[string][]string{"aws_s3_bucket": {"id"}},
+		Renames:      &ModuleRenames{Outputs: map[string]string{"bucket_id": "this_s3_bucket_id"}},
+	})
+	assert.ErrorContains(t, err, `the output "this_s3_bucket_id" exposing the id attribute of aws_s3_bucket.this collides`)
+}
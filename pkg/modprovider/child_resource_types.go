@@ -0,0 +1,337 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// childResourceTypesPolicy selects the type tokens of the views of child resources.
+type childResourceTypesPolicy string
+
+const (
+	// childResourceTypesTerraform types views after the Terraform type of their resource in the tf module of the
+	// package, as in vpc:tf:aws_s3_bucket. This is the default.
+	childResourceTypesTerraform childResourceTypesPolicy = "terraform"
+	// childResourceTypesPulumi types the views of resources that have a known counterpart in a Pulumi provider with the
+	// token of that resource, as in aws:s3/bucket:Bucket, so that policy packs written for the Pulumi provider apply
+	// to them. Other views keep their Terraform types.
+	childResourceTypesPulumi childResourceTypesPolicy = "pulumi"
+)
+
+func parseChildResourceTypesPolicy(s string) (childResourceTypesPolicy, error) {
+	switch p := childResourceTypesPolicy(s); p {
+	case "":
+		return childResourceTypesTerraform, nil
+	case childResourceTypesTerraform, childResourceTypesPulumi:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", childResourceTypesVariableName,
+			childResourceTypesTerraform, childResourceTypesPulumi, s)
+	}
+}
+
+// pulumiViewTypes types the views of child resources with the tokens of the Pulumi resources corresponding to their
+// Terraform type when the childResourceTypes provider option is "pulumi". The Terraform type is read from the outputs
+// of the views (see [childResourceMetadata]), and data sources keep their types as they map to Pulumi functions rather
+// than resources. The properties of the views typed as Pulumi resources are named as in Pulumi providers too, see
+// [pulumiPropertyName].
+//
+// The old states of the views keep the types they were published with, following the policy recorded by the previous
+// operation (see [recordChildResourceTypes]). Changing the types of views changes their URNs, so once the option
+// changes, the views of existing module instances are replaced: the view of the previous type is deleted and one of
+// the new type created.
+func pulumiViewTypes(
+	steps []*pulumirpc.ViewStep,
+	published childResourceTypesPolicy,
+	policy childResourceTypesPolicy,
+) []*pulumirpc.ViewStep {
+	if published != childResourceTypesPulumi && policy != childResourceTypesPulumi {
+		return steps
+	}
+	typed := make([]*pulumirpc.ViewStep, 0, len(steps))
+	for _, step := range steps {
+		typed = append(typed, pulumiViewStepTypes(step, published, policy)...)
+	}
+	return typed
+}
+
+func pulumiViewStepTypes(
+	step *pulumirpc.ViewStep,
+	published childResourceTypesPolicy,
+	policy childResourceTypesPolicy,
+) []*pulumirpc.ViewStep {
+	// unchanged views share their old and new state, which may be typed differently
+	if step.Old != nil && step.Old == step.New {
+		step.New = proto.Clone(step.Old).(*pulumirpc.ViewStepState)
+	}
+
+	retyped := false
+	if step.Old != nil {
+		publishedType, _ := pulumiViewType(step.Old, published)
+		newType, _ := pulumiViewType(step.Old, policy)
+		retyped = publishedType != newType
+	}
+	oldMapped := pulumiViewStateType(step.Old, published)
+	newMapped := pulumiViewStateType(step.New, policy)
+	if step.New != nil {
+		step.Type = step.New.Type
+	} else if step.Old != nil {
+		step.Type = step.Old.Type
+	}
+	if newMapped || (step.New == nil && oldMapped) {
+		pulumiDiffPropertyNames(step)
+	}
+	if !retyped {
+		return []*pulumirpc.ViewStep{step}
+	}
+
+	switch {
+	case step.Op == pulumirpc.ViewStep_REPLACE:
+		return nil
+	case step.New == nil:
+		// the view of the previous type is deleted, as the replacement has a different type
+		step.Op = pulumirpc.ViewStep_DELETE
+		return []*pulumirpc.ViewStep{step}
+	case step.Op == pulumirpc.ViewStep_CREATE_REPLACEMENT:
+		step.Op, step.Old = pulumirpc.ViewStep_CREATE, nil
+		return []*pulumirpc.ViewStep{step}
+	}
+	deleted := &pulumirpc.ViewStep{
+		Status: pulumirpc.ViewStep_OK,
+		Op:     pulumirpc.ViewStep_DELETE,
+		Type:   step.Old.Type,
+		Name:   step.Name,
+		Old:    step.Old,
+	}
+	step.Op, step.Old = pulumirpc.ViewStep_CREATE, nil
+	step.Keys, step.Diffs, step.DetailedDiff, step.HasDetailedDiff = nil, nil, nil, false
+	return []*pulumirpc.ViewStep{deleted, step}
+}
+
+// pulumiViewType returns the type token of a view state under the policy, and whether it is the token of a Pulumi
+// resource.
+func pulumiViewType(state *pulumirpc.ViewStepState, policy childResourceTypesPolicy) (string, bool) {
+	fields := state.GetOutputs().GetFields()
+	if policy != childResourceTypesPulumi || fields["mode"].GetStringValue() == dataSourceMode {
+		return state.GetType(), false
+	}
+	if token, ok := pulumiResourceType(TFResourceType(fields["type"].GetStringValue())); ok {
+		return token, true
+	}
+	return state.GetType(), false
+}
+
+// pulumiViewStateType types a view state under the policy, renaming its inputs when it is typed as a Pulumi resource.
+// It reports whether it was.
+func pulumiViewStateType(state *pulumirpc.ViewStepState, policy childResourceTypesPolicy) bool {
+	if state == nil {
+		return false
+	}
+	token, ok := pulumiViewType(state, policy)
+	if ok {
+		state.Type = token
+		state.Inputs = pulumiPropertyNames(state.Inputs)
+	}
+	return ok
+}
+
+// publishedChildResourceTypes reads the policy by which the previous operation on a module instance typed its views
+// from its outputs, see recordChildResourceTypes.
+func publishedChildResourceTypes(outputs resource.PropertyMap) childResourceTypesPolicy {
+	recorded, ok := outputs[moduleResourceChildResourceTypesPropName]
+	if ok && recorded.IsString() && childResourceTypesPolicy(recorded.StringValue()) == childResourceTypesPulumi {
+		return childResourceTypesPulumi
+	}
+	return childResourceTypesTerraform
+}
+
+// recordChildResourceTypes records the policy by which the views of a module instance are typed in its outputs, so
+// that the next operation refers to the views by the types they were published with. Nothing is recorded for the
+// default policy.
+func recordChildResourceTypes(outputs resource.PropertyMap, policy childResourceTypesPolicy) {
+	if outputs == nil || policy != childResourceTypesPulumi {
+		return
+	}
+	outputs[moduleResourceChildResourceTypesPropName] = resource.NewStringProperty(string(policy))
+}
+
+// pulumiPropertyName camel-cases the name of a Terraform attribute, so that tags_all becomes tagsAll as in Pulumi
+// providers.
+func pulumiPropertyName(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts[1:] {
+		if part != "" {
+			parts[i+1] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// pulumiPropertyNames renames the top-level properties of the inputs of a view, see [pulumiPropertyName]. Nested
+// properties keep their names: without the schema of the provider, the keys of map attributes such as tags, which
+// Pulumi providers keep as they are, cannot be told apart from the names of nested attributes.
+func pulumiPropertyNames(inputs *structpb.Struct) *structpb.Struct {
+	if inputs == nil {
+		return nil
+	}
+	renamed := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(inputs.Fields))}
+	for name, value := range inputs.Fields {
+		renamed.Fields[pulumiPropertyName(name)] = value
+	}
+	return renamed
+}
+
+// pulumiDiffPropertyNames renames the properties that the diff of a view step refers to like its inputs, see
+// [pulumiPropertyNames].
+func pulumiDiffPropertyNames(step *pulumirpc.ViewStep) {
+	for i, key := range step.Keys {
+		step.Keys[i] = pulumiPropertyName(key)
+	}
+	for i, key := range step.Diffs {
+		step.Diffs[i] = pulumiPropertyName(key)
+	}
+	if len(step.DetailedDiff) == 0 {
+		return
+	}
+	detailedDiff := make(map[string]*pulumirpc.PropertyDiff, len(step.DetailedDiff))
+	for path, diff := range step.DetailedDiff {
+		if parsed, err := resource.ParsePropertyPath(path); err == nil && len(parsed) > 0 {
+			if name, ok := parsed[0].(string); ok {
+				parsed[0] = pulumiPropertyName(name)
+				path = parsed.String()
+			}
+		}
+		detailedDiff[path] = diff
+	}
+	step.DetailedDiff = detailedDiff
+}
+
+// pulumiResourceType returns the token of the Pulumi resource managing the same kind of cloud resource as the
+// Terraform resource type. Only the types of the aws provider are known, with the tokens of version 7 of the Pulumi
+// AWS provider.
+func pulumiResourceType(tfType TFResourceType) (string, bool) {
+	name, ok := strings.CutPrefix(string(tfType), "aws_")
+	if !ok {
+		return "", false
+	}
+	if token, ok := awsResourceTypes[name]; ok {
+		return token, true
+	}
+	for prefix, module := range awsModules {
+		rest, ok := strings.CutPrefix(name, prefix+"_")
+		if !ok || rest == "" {
+			continue
+		}
+		typeName := formatPascalCaseTypeName(rest)
+		return fmt.Sprintf("aws:%s/%s%s:%s", module, strings.ToLower(typeName[:1]), typeName[1:], typeName), true
+	}
+	return "", false
+}
+
+// awsModules maps the service prefixes of aws resource types, such as s3 in aws_s3_bucket, to the modules of the
+// Pulumi AWS provider whose resources are named after the rest of the type, as in aws:s3/bucket:Bucket. Services
+// whose resources are named irregularly are left out, see [awsResourceTypes].
+var awsModules = map[string]string{
+	"acm":            "acm",
+	"apigatewayv2":   "apigatewayv2",
+	"api_gateway":    "apigateway",
+	"athena":         "athena",
+	"autoscaling":    "autoscaling",
+	"backup":         "backup",
+	"cloudformation": "cloudformation",
+	"cloudfront":     "cloudfront",
+	"cloudwatch":     "cloudwatch",
+	"codebuild":      "codebuild",
+	"codepipeline":   "codepipeline",
+	"cognito":        "cognito",
+	"db":             "rds",
+	"dynamodb":       "dynamodb",
+	"ecr":            "ecr",
+	"ecs":            "ecs",
+	"efs":            "efs",
+	"eks":            "eks",
+	"elasticache":    "elasticache",
+	"glue":           "glue",
+	"guardduty":      "guardduty",
+	"iam":            "iam",
+	"kinesis":        "kinesis",
+	"kms":            "kms",
+	"lambda":         "lambda",
+	"lb":             "lb",
+	"msk":            "msk",
+	"organizations":  "organizations",
+	"rds":            "rds",
+	"redshift":       "redshift",
+	"route53":        "route53",
+	"s3":             "s3",
+	"sagemaker":      "sagemaker",
+	"secretsmanager": "secretsmanager",
+	"ses":            "ses",
+	"sfn":            "sfn",
+	"sns":            "sns",
+	"sqs":            "sqs",
+	"ssm":            "ssm",
+	"wafv2":          "wafv2",
+}
+
+// awsResourceTypes are the tokens of aws resource types not following [awsModules], keyed by the type without the aws_
+// prefix. They cover the resources of the most used modules, such as the EC2 networking of VPC modules.
+var awsResourceTypes = map[string]string{
+	"alb":                             "aws:alb/loadBalancer:LoadBalancer",
+	"cloudtrail":                      "aws:cloudtrail/trail:Trail",
+	"customer_gateway":                "aws:ec2/customerGateway:CustomerGateway",
+	"default_network_acl":             "aws:ec2/defaultNetworkAcl:DefaultNetworkAcl",
+	"default_route_table":             "aws:ec2/defaultRouteTable:DefaultRouteTable",
+	"default_security_group":          "aws:ec2/defaultSecurityGroup:DefaultSecurityGroup",
+	"default_vpc":                     "aws:ec2/defaultVpc:DefaultVpc",
+	"ebs_volume":                      "aws:ebs/volume:Volume",
+	"egress_only_internet_gateway":    "aws:ec2/egressOnlyInternetGateway:EgressOnlyInternetGateway",
+	"eip":                             "aws:ec2/eip:Eip",
+	"elb":                             "aws:elb/loadBalancer:LoadBalancer",
+	"flow_log":                        "aws:ec2/flowLog:FlowLog",
+	"iam_openid_connect_provider":     "aws:iam/openIdConnectProvider:OpenIdConnectProvider",
+	"instance":                        "aws:ec2/instance:Instance",
+	"internet_gateway":                "aws:ec2/internetGateway:InternetGateway",
+	"key_pair":                        "aws:ec2/keyPair:KeyPair",
+	"launch_template":                 "aws:ec2/launchTemplate:LaunchTemplate",
+	"lb":                              "aws:lb/loadBalancer:LoadBalancer",
+	"nat_gateway":                     "aws:ec2/natGateway:NatGateway",
+	"network_acl":                     "aws:ec2/networkAcl:NetworkAcl",
+	"network_acl_rule":                "aws:ec2/networkAclRule:NetworkAclRule",
+	"network_interface":               "aws:ec2/networkInterface:NetworkInterface",
+	"route":                           "aws:ec2/route:Route",
+	"route_table":                     "aws:ec2/routeTable:RouteTable",
+	"route_table_association":         "aws:ec2/routeTableAssociation:RouteTableAssociation",
+	"security_group":                  "aws:ec2/securityGroup:SecurityGroup",
+	"security_group_rule":             "aws:ec2/securityGroupRule:SecurityGroupRule",
+	"subnet":                          "aws:ec2/subnet:Subnet",
+	"vpc":                             "aws:ec2/vpc:Vpc",
+	"vpc_dhcp_options":                "aws:ec2/vpcDhcpOptions:VpcDhcpOptions",
+	"vpc_dhcp_options_association":    "aws:ec2/vpcDhcpOptionsAssociation:VpcDhcpOptionsAssociation",
+	"vpc_endpoint":                    "aws:ec2/vpcEndpoint:VpcEndpoint",
+	"vpc_ipv4_cidr_block_association": "aws:ec2/vpcIpv4CidrBlockAssociation:VpcIpv4CidrBlockAssociation",
+	"vpc_peering_connection":          "aws:ec2/vpcPeeringConnection:VpcPeeringConnection",
+	"vpc_security_group_egress_rule":  "aws:vpc/securityGroupEgressRule:SecurityGroupEgressRule",
+	"vpc_security_group_ingress_rule": "aws:vpc/securityGroupIngressRule:SecurityGroupIngressRule",
+	"vpn_gateway":                     "aws:ec2/vpnGateway:VpnGateway",
+}
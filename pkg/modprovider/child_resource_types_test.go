@@ -0,0 +1,245 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+func TestParseChildResourceTypesPolicy(t *testing.T) {
+	for s, expected := range map[string]childResourceTypesPolicy{
+		"":          childResourceTypesTerraform,
+		"terraform": childResourceTypesTerraform,
+		"pulumi":    childResourceTypesPulumi,
+	} {
+		policy, err := parseChildResourceTypesPolicy(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+
+	_, err := parseChildResourceTypesPolicy("aws")
+	assert.EqualError(t, err, `provider option "childResourceTypes" must be one of "terraform" or "pulumi", got "aws"`)
+}
+
+func TestPulumiResourceType(t *testing.T) {
+	for tfType, expected := range map[TFResourceType]string{
+		"aws_s3_bucket":                       "aws:s3/bucket:Bucket",
+		"aws_s3_bucket_public_access_block":   "aws:s3/bucketPublicAccessBlock:BucketPublicAccessBlock",
+		"aws_iam_role_policy_attachment":      "aws:iam/rolePolicyAttachment:RolePolicyAttachment",
+		"aws_cloudwatch_log_group":            "aws:cloudwatch/logGroup:LogGroup",
+		"aws_db_subnet_group":                 "aws:rds/subnetGroup:SubnetGroup",
+		"aws_api_gateway_rest_api":            "aws:apigateway/restApi:RestApi",
+		"aws_vpc":                             "aws:ec2/vpc:Vpc",
+		"aws_subnet":                          "aws:ec2/subnet:Subnet",
+		"aws_vpc_security_group_ingress_rule": "aws:vpc/securityGroupIngressRule:SecurityGroupIngressRule",
+		"aws_iam_openid_connect_provider":     "aws:iam/openIdConnectProvider:OpenIdConnectProvider",
+	} {
+		token, ok := pulumiResourceType(tfType)
+		assert.True(t, ok, tfType)
+		assert.Equal(t, expected, token, tfType)
+	}
+
+	for _, tfType := range []TFResourceType{"random_integer", "azurerm_resource_group", "aws_unknownservice_thing"} {
+		_, ok := pulumiResourceType(tfType)
+		assert.False(t, ok, tfType)
+	}
+}
+
+func TestPulumiViewTypes(t *testing.T) {
+	// the states are typed in place, so every subtest gets its own
+	steps := func() []*pulumirpc.ViewStep {
+		bucket := viewStepState("vpc", "module.m.aws_s3_bucket.logs", "aws_s3_bucket",
+			"registry.terraform.io/hashicorp/aws", resource.PropertyMap{"bucket": resource.NewStringProperty("logs")})
+		pet := viewStepState("vpc", "module.m.random_pet.name", "random_pet", "registry.terraform.io/hashicorp/random",
+			resource.PropertyMap{})
+		ami := dataSourceViewState("vpc", "module.m.data.aws_ami.ubuntu", "aws_ami",
+			"registry.terraform.io/hashicorp/aws", resource.PropertyMap{})
+		return []*pulumirpc.ViewStep{
+			{Name: bucket.Name, Type: bucket.Type, Op: pulumirpc.ViewStep_UPDATE, Old: bucket, New: bucket},
+			{Name: pet.Name, Type: pet.Type, Op: pulumirpc.ViewStep_CREATE, New: pet},
+			{Name: ami.Name, Type: ami.Type, Op: pulumirpc.ViewStep_READ, New: ami},
+		}
+	}
+
+	t.Run("terraform", func(t *testing.T) {
+		typed := pulumiViewTypes(steps(), childResourceTypesTerraform, childResourceTypesTerraform)
+		assert.Equal(t, "vpc:tf:aws_s3_bucket", typed[0].Type)
+	})
+
+	t.Run("pulumi", func(t *testing.T) {
+		typed := pulumiViewTypes(steps(), childResourceTypesPulumi, childResourceTypesPulumi)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[0].Type)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[0].Old.Type)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[0].New.Type)
+		// the Terraform type remains in the outputs of the view
+		assert.Equal(t, "aws_s3_bucket", typed[0].New.Outputs.Fields["type"].GetStringValue())
+
+		assert.Equal(t, "vpc:tf:random_pet", typed[1].Type)
+		assert.Equal(t, "vpc:tf:aws_ami", typed[2].Type)
+	})
+
+	t.Run("enabling the option", func(t *testing.T) {
+		typed := pulumiViewTypes(steps(), childResourceTypesTerraform, childResourceTypesPulumi)
+		require.Len(t, typed, 4)
+
+		// the view published with the Terraform type is replaced by one with the Pulumi type
+		assert.Equal(t, pulumirpc.ViewStep_DELETE, typed[0].Op)
+		assert.Equal(t, "vpc:tf:aws_s3_bucket", typed[0].Type)
+		assert.Equal(t, "vpc:tf:aws_s3_bucket", typed[0].Old.Type)
+		assert.Nil(t, typed[0].New)
+		assert.Equal(t, pulumirpc.ViewStep_CREATE, typed[1].Op)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[1].Type)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[1].New.Type)
+		assert.Nil(t, typed[1].Old)
+
+		assert.Equal(t, "vpc:tf:random_pet", typed[2].Type)
+		assert.Equal(t, "vpc:tf:aws_ami", typed[3].Type)
+	})
+
+	t.Run("disabling the option", func(t *testing.T) {
+		unchanged := viewStepState("vpc", "module.m.aws_s3_bucket.logs", "aws_s3_bucket", "", resource.PropertyMap{})
+		typed := pulumiViewTypes([]*pulumirpc.ViewStep{
+			{Name: unchanged.Name, Type: unchanged.Type, Op: pulumirpc.ViewStep_SAME, Old: unchanged, New: unchanged},
+		}, childResourceTypesPulumi, childResourceTypesTerraform)
+		require.Len(t, typed, 2)
+		assert.Equal(t, pulumirpc.ViewStep_DELETE, typed[0].Op)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[0].Type)
+		assert.Equal(t, pulumirpc.ViewStep_CREATE, typed[1].Op)
+		assert.Equal(t, "vpc:tf:aws_s3_bucket", typed[1].Type)
+	})
+
+	t.Run("replacements", func(t *testing.T) {
+		old := viewStepState("vpc", "module.m.aws_s3_bucket.logs", "aws_s3_bucket", "", resource.PropertyMap{})
+		replacement := viewStepState("vpc", "module.m.aws_s3_bucket.logs", "aws_s3_bucket", "", resource.PropertyMap{})
+		typed := pulumiViewTypes([]*pulumirpc.ViewStep{
+			{Name: old.Name, Type: old.Type, Op: pulumirpc.ViewStep_CREATE_REPLACEMENT, Old: old, New: replacement},
+			{Name: old.Name, Type: old.Type, Op: pulumirpc.ViewStep_REPLACE, Old: old, New: replacement},
+			{Name: old.Name, Type: old.Type, Op: pulumirpc.ViewStep_DELETE_REPLACED, Old: old},
+		}, childResourceTypesTerraform, childResourceTypesPulumi)
+		require.Len(t, typed, 2)
+		assert.Equal(t, pulumirpc.ViewStep_CREATE, typed[0].Op)
+		assert.Equal(t, "aws:s3/bucket:Bucket", typed[0].Type)
+		assert.Equal(t, pulumirpc.ViewStep_DELETE, typed[1].Op)
+		assert.Equal(t, "vpc:tf:aws_s3_bucket", typed[1].Type)
+	})
+
+	t.Run("recorded policy", func(t *testing.T) {
+		outputs := resource.PropertyMap{}
+		assert.Equal(t, childResourceTypesTerraform, publishedChildResourceTypes(outputs))
+		recordChildResourceTypes(outputs, childResourceTypesTerraform)
+		assert.Empty(t, outputs)
+		recordChildResourceTypes(outputs, childResourceTypesPulumi)
+		assert.Equal(t, childResourceTypesPulumi, publishedChildResourceTypes(outputs))
+	})
+
+	t.Run("property names", func(t *testing.T) {
+		tagged := viewStepState("vpc", "module.m.aws_s3_bucket.logs", "aws_s3_bucket", "", resource.PropertyMap{
+			"force_destroy": resource.NewBoolProperty(true),
+			"tags_all": resource.NewObjectProperty(resource.PropertyMap{
+				"cost_center": resource.NewStringProperty("ops"),
+			}),
+		})
+		thing := viewStepState("vpc", "module.m.random_pet.name", "random_pet", "", resource.PropertyMap{
+			"keepers_all": resource.NewObjectProperty(resource.PropertyMap{}),
+		})
+		typed := pulumiViewTypes([]*pulumirpc.ViewStep{
+			{
+				Name: tagged.Name, Type: tagged.Type, Op: pulumirpc.ViewStep_UPDATE, Old: tagged, New: tagged,
+				Keys: []string{"force_destroy"}, Diffs: []string{"tags_all"},
+				DetailedDiff:    map[string]*pulumirpc.PropertyDiff{"tags_all.cost_center": {}},
+				HasDetailedDiff: true,
+			},
+			{Name: thing.Name, Type: thing.Type, Op: pulumirpc.ViewStep_CREATE, New: thing},
+		}, childResourceTypesPulumi, childResourceTypesPulumi)
+
+		assert.ElementsMatch(t, []string{"forceDestroy", "tagsAll"}, slices.Collect(maps.Keys(typed[0].New.Inputs.Fields)))
+		assert.Equal(t, "ops", typed[0].New.Inputs.Fields["tagsAll"].GetStructValue().Fields["cost_center"].
+			GetStringValue(), "the keys of maps are kept")
+		assert.Equal(t, []string{"forceDestroy"}, typed[0].Keys)
+		assert.Equal(t, []string{"tagsAll"}, typed[0].Diffs)
+		assert.Contains(t, typed[0].DetailedDiff, "tagsAll.cost_center")
+		assert.ElementsMatch(t, []string{"keepers_all"}, slices.Collect(maps.Keys(typed[1].New.Inputs.Fields)),
+			"views keeping their Terraform type keep the Terraform names")
+	})
+}
+
+func TestPublishedViewsHavePulumiTypes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "child_resource_types", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	recorder := &recordingStatusClient{}
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+	h.statusPool = recordingStatusPool{recorder}
+	t.Cleanup(func() {
+		os.RemoveAll(tfsandboxWorkdir(t, stub, modURN))
+	})
+
+	olds, err := plugin.MarshalProperties(resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(
+			`{"version":4,"terraform_version":"1.9.0","serial":1,"lineage":"l","outputs":{},"resources":[]}`)),
+		moduleResourceLockPropName: resource.NewStringProperty(""),
+	}, h.marshalOpts())
+	require.NoError(t, err)
+	news, err := plugin.MarshalProperties(resource.PropertyMap{}, h.marshalOpts())
+	require.NoError(t, err)
+
+	resp, err := h.Update(ctx, &pulumirpc.UpdateRequest{
+		Urn:                 string(modURN),
+		Olds:                olds,
+		News:                news,
+		Preview:             true,
+		ResourceStatusToken: "token",
+	}, TFModuleSource(src), "", map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple",
+		moduleOptions{executor: stub, childResourceTypes: childResourceTypesPulumi})
+	require.NoError(t, err)
+	outputs, err := plugin.UnmarshalProperties(resp.GetProperties(), h.marshalOpts())
+	require.NoError(t, err)
+	assert.Equal(t, childResourceTypesPulumi, publishedChildResourceTypes(outputs),
+		"the next operation should refer to the views by their Pulumi types")
+
+	var published []*pulumirpc.ViewStep
+	for _, request := range recorder.requests {
+		published = append(published, request.Steps...)
+	}
+	require.Len(t, published, 1)
+	step := published[0]
+	assert.Equal(t, pulumirpc.ViewStep_CREATE, step.Op)
+	assert.Equal(t, "aws:s3/bucket:Bucket", step.Type)
+	assert.Equal(t, "aws:s3/bucket:Bucket", step.New.Type)
+	assert.ElementsMatch(t, []string{"bucket", "forceDestroy", "tagsAll"},
+		slices.Collect(maps.Keys(step.New.Inputs.Fields)))
+}
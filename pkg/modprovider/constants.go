@@ -4,4 +4,124 @@ const (
 	defaultComponentTypeName          = "Module"
 	moduleExecutorVariableName        = "executor"
 	moduleExecutorEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_EXECUTOR"
+
+	skipUnchangedPlansVariableName        = "skipUnchangedPlans"
+	skipUnchangedPlansEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_SKIP_UNCHANGED_PLANS"
+
+	providerMetaVariableName = "providerMeta"
+
+	extraTerraformFilesVariableName = "extraTerraformFiles"
+
+	moduleDependsOnVariableName        = "moduleDependsOn"
+	moduleDependsOnEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_DEPENDS_ON"
+
+	pluginCacheDirVariableName        = "pluginCacheDir"
+	pluginCacheDirEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_PLUGIN_CACHE_DIR"
+
+	initParallelismVariableName        = "initParallelism"
+	initParallelismEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_INIT_PARALLELISM"
+
+	escEnvironmentVariableName        = "escEnvironment"
+	escEnvironmentEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_ESC_ENVIRONMENT"
+
+	costEstimateCommandVariableName        = "costEstimateCommand"
+	costEstimateCommandEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_COST_ESTIMATE_COMMAND"
+
+	readinessCommandVariableName        = "readinessCommand"
+	readinessCommandEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_READINESS_COMMAND"
+
+	readinessTimeoutVariableName        = "readinessTimeout"
+	readinessTimeoutEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_READINESS_TIMEOUT"
+
+	applyRetryPatternsVariableName        = "applyRetryPatterns"
+	applyRetryPatternsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_APPLY_RETRY_PATTERNS"
+
+	stateSizeWarningLimitVariableName        = "stateSizeWarningLimit"
+	stateSizeWarningLimitEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_STATE_SIZE_WARNING_LIMIT"
+
+	stateSizeLimitVariableName        = "stateSizeLimit"
+	stateSizeLimitEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_STATE_SIZE_LIMIT"
+
+	readOnlyVariableName        = "readOnly"
+	readOnlyEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_READ_ONLY"
+
+	workdirCleanupVariableName        = "workdirCleanup"
+	workdirCleanupEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_WORKDIR_CLEANUP"
+
+	providerEndpointsVariableName        = "providerEndpoints"
+	providerEndpointsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_PROVIDER_ENDPOINTS"
+
+	gitFullCloneVariableName        = "gitFullClone"
+	gitFullCloneEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_GIT_FULL_CLONE"
+
+	invalidModuleStateVariableName        = "invalidModuleState"
+	invalidModuleStateEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_INVALID_MODULE_STATE"
+
+	failOnDriftVariableName        = "failOnDrift"
+	failOnDriftEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_FAIL_ON_DRIFT"
+
+	refreshOnlyVariableName        = "refreshOnly"
+	refreshOnlyEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_REFRESH_ONLY"
+
+	destroyTargetsVariableName        = "destroyTargets"
+	destroyTargetsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_DESTROY_TARGETS"
+
+	moduleNamingVariableName        = "moduleNaming"
+	moduleNamingEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_NAMING"
+
+	pulumiDefaultTagsVariableName        = "pulumiDefaultTags"
+	pulumiDefaultTagsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_PULUMI_DEFAULT_TAGS"
+
+	secretsAuditVariableName        = "secretsAudit"
+	secretsAuditEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_SECRETS_AUDIT"
+
+	taintedResourcesVariableName        = "taintedResources"
+	taintedResourcesEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_TAINTED_RESOURCES"
+
+	viewStepsBatchSizeVariableName        = "viewStepsBatchSize"
+	viewStepsBatchSizeEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_VIEW_STEPS_BATCH_SIZE"
+
+	providerChecksumsVariableName        = "providerChecksums"
+	providerChecksumsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_PROVIDER_CHECKSUMS"
+
+	dataSourceViewsVariableName        = "dataSourceViews"
+	dataSourceViewsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_DATA_SOURCE_VIEWS"
+
+	moduleRefVariableName        = "moduleRef"
+	moduleRefEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_REF"
+
+	moduleCacheDirVariableName        = "moduleCacheDir"
+	moduleCacheDirEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_MODULE_CACHE_DIR"
+
+	interactiveAuthVariableName        = "interactiveAuth"
+	interactiveAuthEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_INTERACTIVE_AUTH"
+
+	childResourceDiffsVariableName        = "childResourceDiffs"
+	childResourceDiffsEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_CHILD_RESOURCE_DIFFS"
+
+	filesystemSandboxVariableName        = "filesystemSandbox"
+	filesystemSandboxEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_FILESYSTEM_SANDBOX"
+
+	unconfiguredProvidersVariableName        = "unconfiguredProviders"
+	unconfiguredProvidersEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_UNCONFIGURED_PROVIDERS"
+
+	stateBackupDirVariableName        = "stateBackupDir"
+	stateBackupDirEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_STATE_BACKUP_DIR"
+
+	childResourceTypesVariableName        = "childResourceTypes"
+	childResourceTypesEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_CHILD_RESOURCE_TYPES"
+
+	refreshModeVariableName        = "refreshMode"
+	refreshModeEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_REFRESH_MODE"
+
+	// schemaInferenceTimeoutEnvironmentVariable bounds how long `pulumi package add` may spend inferring the schema
+	// of a module, as a duration such as 10m. There is no limit by default.
+	schemaInferenceTimeoutEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_SCHEMA_INFERENCE_TIMEOUT"
+
+	// registryRetriesEnvironmentVariable is how many times to retry listing the versions of a registry module when
+	// `pulumi package add` resolves its latest version, see defaultRegistryRetries.
+	registryRetriesEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_REGISTRY_RETRIES"
+
+	registryTokensVariableName        = "registryTokens"
+	registryTokensEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_REGISTRY_TOKENS"
 )
@@ -0,0 +1,70 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// estimateCost runs the cost estimation hook configured with the costEstimateCommand provider option.
+//
+// The hook receives the plan of the module instance in the Terraform JSON plan format on stdin and is expected to
+// print a human-readable estimate to stdout. A non-zero exit code is treated as a failure and its stderr is reported.
+// The command is split on whitespace and run directly rather than through a shell.
+func estimateCost(ctx context.Context, command string, plan *tfsandbox.Plan) (string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return "", errors.New("cost estimation command is empty")
+	}
+
+	planJSON, err := json.Marshal(plan.RawPlan())
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize plan: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(planJSON)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cost estimation command %q failed: %w: %s", command, err,
+			strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// reportCostEstimate surfaces the cost estimate for a planned module instance as an informational message. Cost
+// estimation is advisory, so failures are reported as warnings rather than failing the preview.
+func reportCostEstimate(ctx context.Context, logger tfsandbox.Logger, command string, plan *tfsandbox.Plan) {
+	estimate, err := estimateCost(ctx, command, plan)
+	if err != nil {
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Cost estimation failed: %v", err))
+		return
+	}
+	if estimate == "" {
+		return
+	}
+	logger.Log(ctx, tfsandbox.Info, "Cost estimate:\n"+estimate)
+}
@@ -0,0 +1,147 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/provider"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestReportCostEstimate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub cost estimation command is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "cost_estimate", "stub.sh"))
+	require.NoError(t, err)
+
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		FormatVersion: "1.2",
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+	})
+	require.NoError(t, err)
+
+	t.Run("estimate is reported", func(t *testing.T) {
+		logger := &recordingLogger{}
+		reportCostEstimate(ctx, logger, stub, plan)
+		assert.Equal(t, []string{"info: Cost estimate:\nMonthly cost: $42.00"}, logger.messages)
+	})
+
+	t.Run("failures are warnings", func(t *testing.T) {
+		logger := &recordingLogger{}
+		reportCostEstimate(ctx, logger, "false", plan)
+		require.Len(t, logger.messages, 1)
+		assert.Contains(t, logger.messages[0], `warn: Cost estimation failed: cost estimation command "false" failed`)
+	})
+}
+
+func TestCostEstimateInPreviewOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor and cost estimation command are shell scripts")
+	}
+
+	ctx := context.Background()
+	executor, err := filepath.Abs(filepath.Join("testdata", "child_resource_types", "stub.sh"))
+	require.NoError(t, err)
+	costStub, err := filepath.Abs(filepath.Join("testdata", "cost_estimate", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	engine := &recordingEngine{}
+	h := newModuleHandler(engine.hostClient(t), newTestAuxProviderServer(t))
+	h.statusPool = recordingStatusPool{&recordingStatusClient{}}
+	t.Cleanup(func() {
+		os.RemoveAll(tfsandboxWorkdir(t, executor, modURN))
+	})
+
+	news, err := plugin.MarshalProperties(resource.PropertyMap{}, h.marshalOpts())
+	require.NoError(t, err)
+	_, err = h.Create(ctx, &pulumirpc.CreateRequest{
+		Urn:                 string(modURN),
+		Properties:          news,
+		Preview:             true,
+		ResourceStatusToken: "token",
+	}, TFModuleSource(src), "", map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple",
+		moduleOptions{executor: executor, costEstimateCommand: costStub})
+	require.NoError(t, err)
+
+	assert.Contains(t, engine.logs(), &pulumirpc.LogRequest{
+		Severity: pulumirpc.LogSeverity_INFO,
+		Urn:      string(modURN),
+		Message:  "Cost estimate:\nMonthly cost: $42.00",
+	}, "the estimate is shown in the preview of the module")
+}
+
+// recordingEngine is an engine recording the messages logged by the provider.
+type recordingEngine struct {
+	pulumirpc.UnimplementedEngineServer
+
+	mu       sync.Mutex
+	messages []*pulumirpc.LogRequest
+}
+
+func (e *recordingEngine) Log(_ context.Context, req *pulumirpc.LogRequest) (*emptypb.Empty, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.messages = append(e.messages, req)
+	return &emptypb.Empty{}, nil
+}
+
+func (e *recordingEngine) logs() []*pulumirpc.LogRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	logs := make([]*pulumirpc.LogRequest, len(e.messages))
+	for i, message := range e.messages {
+		logs[i] = &pulumirpc.LogRequest{Severity: message.Severity, Urn: message.Urn, Message: message.Message}
+	}
+	return logs
+}
+
+// hostClient serves the engine and connects a host client to it for the duration of the test.
+func (e *recordingEngine) hostClient(t *testing.T) *provider.HostClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pulumirpc.RegisterEngineServer(server, e)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	hc, err := provider.NewHostClient(listener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = hc.Close() })
+	return hc
+}
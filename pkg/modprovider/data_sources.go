@@ -0,0 +1,129 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// dataSourceMode is the mode recorded in the outputs of data source views, following the mode Terraform records for
+// them in its state, to tell them apart from the views of the resources a module manages.
+const dataSourceMode = "data"
+
+// viewStepsForDataSources reports the data sources read by a module instance as views when the dataSourceViews
+// provider option is set, so that what a module queries can be audited. The data sources are not managed by the
+// module, so their views are read steps, as for resources read with `get` functions in Pulumi programs.
+//
+// The engine requires the previous view of a data source to update it, and the Terraform state does not tell which
+// data sources were reported, as the option may have been set since. The reported data sources are therefore recorded
+// in the outputs of the module instance with recordDataSources and passed back as published. Views of data sources
+// that are no longer read, or of every data source when the option is unset, are discarded.
+//
+// Visit is nil when no data sources are read, as when destroying the module instance.
+func viewStepsForDataSources(
+	packageName packageName,
+	enabled bool,
+	published map[ResourceAddress]TFResourceType,
+	visit func(func(*tfsandbox.ResourceState)),
+) ([]*pulumirpc.ViewStep, map[ResourceAddress]TFResourceType) {
+	var steps []*pulumirpc.ViewStep
+	read := map[ResourceAddress]TFResourceType{}
+
+	if enabled && visit != nil {
+		visit(func(ds *tfsandbox.ResourceState) {
+			addr, tfType := ds.Address(), ds.Type()
+			state := dataSourceViewState(packageName, addr, tfType, ds.ProviderName(), ds.AttributeValues())
+			step := &pulumirpc.ViewStep{
+				Status: pulumirpc.ViewStep_OK,
+				Name:   state.Name,
+				Type:   state.Type,
+				Op:     pulumirpc.ViewStep_READ,
+				New:    state,
+			}
+			if _, ok := published[addr]; ok {
+				step.Old = state
+			}
+			steps = append(steps, step)
+			read[addr] = tfType
+		})
+	}
+
+	for _, addr := range slices.Sorted(maps.Keys(published)) {
+		if _, ok := read[addr]; ok {
+			continue
+		}
+		state := dataSourceViewState(packageName, addr, published[addr], "", nil)
+		steps = append(steps, &pulumirpc.ViewStep{
+			Status: pulumirpc.ViewStep_OK,
+			Name:   state.Name,
+			Type:   state.Type,
+			Op:     pulumirpc.ViewStep_READ_DISCARD,
+			Old:    state,
+		})
+	}
+
+	return steps, read
+}
+
+// dataSourceViewState describes a data source read by a module instance like a child resource (see viewStepState),
+// with its mode recorded in the outputs of the view.
+func dataSourceViewState(
+	packageName packageName,
+	addr ResourceAddress,
+	tfType TFResourceType,
+	providerName string,
+	values resource.PropertyMap,
+) *pulumirpc.ViewStepState {
+	state := viewStepState(packageName, addr, tfType, providerName, values)
+	metadata := childResourceMetadata(addr, tfType, providerName, values)
+	metadata["mode"] = resource.NewStringProperty(dataSourceMode)
+	state.Outputs = viewStruct(metadata)
+	return state
+}
+
+// publishedDataSources reads the data sources reported as views by the previous operation on a module instance, keyed
+// by address with their Terraform type, from its outputs.
+func publishedDataSources(outputs resource.PropertyMap) map[ResourceAddress]TFResourceType {
+	published := map[ResourceAddress]TFResourceType{}
+	recorded, ok := outputs[moduleResourceDataSourcesPropName]
+	if !ok || !recorded.IsObject() {
+		return published
+	}
+	for addr, tfType := range recorded.ObjectValue() {
+		if tfType.IsString() {
+			published[ResourceAddress(addr)] = TFResourceType(tfType.StringValue())
+		}
+	}
+	return published
+}
+
+// recordDataSources records the data sources reported as views in the outputs of a module instance, see
+// viewStepsForDataSources. Nothing is recorded when no data sources are reported.
+func recordDataSources(outputs resource.PropertyMap, read map[ResourceAddress]TFResourceType) {
+	if len(read) == 0 {
+		return
+	}
+	recorded := resource.PropertyMap{}
+	for addr, tfType := range read {
+		recorded[resource.PropertyKey(addr)] = resource.NewStringProperty(string(tfType))
+	}
+	outputs[moduleResourceDataSourcesPropName] = resource.NewObjectProperty(recorded)
+}
@@ -0,0 +1,106 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestDataSourcesAreReportedAsReadOnlyViews(t *testing.T) {
+	const addr = "module.m.data.aws_caller_identity.current"
+	const providerName = "registry.opentofu.org/hashicorp/aws"
+
+	state, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{
+					{
+						Address:         "module.m.aws_s3_bucket.this",
+						Mode:            tfjson.ManagedResourceMode,
+						Type:            "aws_s3_bucket",
+						Name:            "this",
+						ProviderName:    providerName,
+						AttributeValues: map[string]any{"id": "my-bucket"},
+					},
+					{
+						Address:         addr,
+						Mode:            tfjson.DataResourceMode,
+						Type:            "aws_caller_identity",
+						Name:            "current",
+						ProviderName:    providerName,
+						AttributeValues: map[string]any{"id": "123456789012", "account_id": "123456789012"},
+					},
+				},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	var managed []tfsandbox.ResourceAddress
+	state.VisitResourceStates(func(rs *tfsandbox.ResourceState) { managed = append(managed, rs.Address()) })
+	assert.Equal(t, []tfsandbox.ResourceAddress{"module.m.aws_s3_bucket.this"}, managed,
+		"data sources are not child resources of the module")
+
+	steps, read := viewStepsForDataSources("testmod", false, nil, state.VisitDataSources)
+	assert.Empty(t, steps, "data sources are only reported with the dataSourceViews option")
+	assert.Empty(t, read)
+
+	steps, read = viewStepsForDataSources("testmod", true, nil, state.VisitDataSources)
+	require.Len(t, steps, 1)
+	step := steps[0]
+	assert.Equal(t, pulumirpc.ViewStep_READ, step.Op)
+	assert.Equal(t, childResourceName(addr), step.Name)
+	assert.Equal(t, "testmod:tf:aws_caller_identity", step.Type)
+	assert.Nil(t, step.Old, "the data source was not reported before")
+	require.NotNil(t, step.New)
+	assert.Equal(t, map[string]any{
+		"address":  addr,
+		"id":       "123456789012",
+		"mode":     "data",
+		"provider": providerName,
+		"type":     "aws_caller_identity",
+	}, step.New.Outputs.AsMap())
+	assert.Equal(t, "123456789012", step.New.Inputs.AsMap()["account_id"])
+
+	// The reported data sources are recorded so that later operations update their views.
+	outputs := resource.PropertyMap{}
+	recordDataSources(outputs, read)
+	published := publishedDataSources(outputs)
+	assert.Equal(t, map[tfsandbox.ResourceAddress]tfsandbox.TFResourceType{addr: "aws_caller_identity"}, published)
+
+	steps, _ = viewStepsForDataSources("testmod", true, published, state.VisitDataSources)
+	require.Len(t, steps, 1)
+	assert.Equal(t, pulumirpc.ViewStep_READ, steps[0].Op)
+	assert.Equal(t, steps[0].New, steps[0].Old)
+
+	// Unsetting the option, or destroying the module instance, discards the views.
+	steps, read = viewStepsForDataSources("testmod", false, published, state.VisitDataSources)
+	assert.Empty(t, read)
+	require.Len(t, steps, 1)
+	assert.Equal(t, pulumirpc.ViewStep_READ_DISCARD, steps[0].Op)
+	require.NotNil(t, steps[0].Old)
+	assert.Equal(t, childResourceName(addr), steps[0].Old.Name)
+	assert.Nil(t, steps[0].New)
+}
@@ -0,0 +1,78 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"maps"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+const (
+	// The aws provider block holding tags applied to every resource the provider manages that supports tags.
+	awsDefaultTags     = "default_tags"
+	awsDefaultTagsTags = "tags"
+
+	pulumiStackTag   = "pulumi:stack"
+	pulumiProjectTag = "pulumi:project"
+)
+
+// withPulumiDefaultTags tags the resources of a module instance with the Pulumi stack and project it belongs to, as
+// read from its URN, through the default_tags block of the aws provider. Tags set in the default_tags block of the
+// provider configuration take precedence. Modules that do not require the aws provider are left as they are, since
+// configuring the provider would make Terraform install it.
+func withPulumiDefaultTags(
+	config map[string]resource.PropertyMap,
+	inferredModule *InferredModuleSchema,
+	moduleURN urn.URN,
+) map[string]resource.PropertyMap {
+	if inferredModule == nil {
+		return config
+	}
+	if _, requiresAWS := inferredModule.ProvidersConfig.Variables[awsProviderName]; !requiresAWS {
+		return config
+	}
+
+	tags := resource.PropertyMap{
+		pulumiStackTag:   resource.NewStringProperty(moduleURN.Stack().String()),
+		pulumiProjectTag: resource.NewStringProperty(moduleURN.Project().String()),
+	}
+
+	awsConfig := config[awsProviderName].Copy()
+	configured := awsConfig[awsDefaultTags]
+	secret := configured.IsSecret()
+	// the block may also be given as a list holding a single object, as in the Terraform JSON syntax
+	if block := singleBlockObject(unwrapSecret(configured)); block.IsObject() {
+		if configuredTags := unwrapSecret(block.ObjectValue()[awsDefaultTagsTags]); configuredTags.IsObject() {
+			maps.Copy(tags, configuredTags.ObjectValue())
+		}
+	}
+
+	block := resource.NewObjectProperty(resource.PropertyMap{
+		awsDefaultTagsTags: resource.NewObjectProperty(tags),
+	})
+	if secret {
+		block = resource.MakeSecret(block)
+	}
+	awsConfig[awsDefaultTags] = block
+
+	withTags := maps.Clone(config)
+	if withTags == nil {
+		withTags = map[string]resource.PropertyMap{}
+	}
+	withTags[awsProviderName] = awsConfig
+	return withTags
+}
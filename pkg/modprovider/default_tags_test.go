@@ -0,0 +1,71 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestPulumiDefaultTagsReachChildResources(t *testing.T) {
+	moduleURN := urn.New("dev", "networking", "", "vpc:index:Module", "main")
+	inferredModule := &InferredModuleSchema{
+		ProvidersConfig: schema.ConfigSpec{Variables: map[string]schema.PropertySpec{awsKey: {}}},
+	}
+
+	providersConfig := withPulumiDefaultTags(cleanProvidersConfig(resource.PropertyMap{
+		awsKey: resource.NewStringProperty(`{"region":"us-west-2","default_tags":{"tags":{"team":"platform",` +
+			`"pulumi:project":"overridden"}}}`),
+	}), inferredModule, moduleURN)
+
+	workingDir := t.TempDir()
+	err := tfsandbox.CreateTFFile("main", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+		resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Provider map[string]map[string]any `json:"provider"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+
+	awsProvider := tfFile.Provider[awsKey]
+	require.NotNil(t, awsProvider, "expected an aws provider block in %s", contents)
+	assert.Equal(t, "us-west-2", awsProvider["region"])
+	assert.Equal(t, map[string]any{
+		"tags": map[string]any{
+			"pulumi:stack":   "dev",
+			"pulumi:project": "overridden",
+			"team":           "platform",
+		},
+	}, awsProvider["default_tags"])
+
+	// Modules that do not use the aws provider do not get it configured.
+	untagged := withPulumiDefaultTags(nil, &InferredModuleSchema{}, moduleURN)
+	assert.Empty(t, untagged)
+}
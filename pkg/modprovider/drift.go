@@ -0,0 +1,69 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// driftError fails refreshes, previews and updates that detect drift when the provider is configured with
+// failOnDrift, so that they fail when the infrastructure no longer matches the Pulumi state (see checkDrift).
+func driftError(urn urn.URN, refreshPlan *tfsandbox.Plan) error {
+	drifted := driftedResources(refreshPlan)
+	if len(drifted) == 0 {
+		return nil
+	}
+	return fmt.Errorf("drift detected in module %s, the following resources changed outside of Pulumi: %s. "+
+		"Set %s to false to reconcile the drift", urn.Name(), strings.Join(drifted, ", "), failOnDriftVariableName)
+}
+
+// checkDrift fails previews and updates of module instances whose resources drifted when the provider is configured
+// with failOnDrift. They plan without refreshing, since the engine only refreshes beforehand with --refresh, so the
+// drift is looked for with a refresh-only plan of its own. Refresh-only updates accept the drift instead.
+func checkDrift(
+	ctx context.Context,
+	tf *tfsandbox.ModuleRuntime,
+	logger tfsandbox.Logger,
+	urn urn.URN,
+	opts moduleOptions,
+) error {
+	if !opts.failOnDrift || opts.refreshOnly {
+		return nil
+	}
+	plan, err := tf.PlanRefreshOnly(ctx, logger)
+	if err != nil {
+		return fmt.Errorf("error planning refresh to detect drift: %w", err)
+	}
+	return driftError(urn, plan)
+}
+
+// driftedResources lists the sorted addresses of the resources that a refresh plan found changed outside of Pulumi.
+func driftedResources(refreshPlan *tfsandbox.Plan) []string {
+	var drifted []string
+	refreshPlan.VisitResourcePlans(func(rp *tfsandbox.ResourcePlan) {
+		if rp.Drift() && rp.ChangeKind() != tfsandbox.NoOp {
+			drifted = append(drifted, string(rp.Address()))
+		}
+	})
+	slices.Sort(drifted)
+	return drifted
+}
@@ -0,0 +1,132 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestDriftError(t *testing.T) {
+	modURN := urn.URN("urn:pulumi:test::prog::bucketmod:index:Module::mybucketmod")
+
+	refreshPlan := func(drift ...*tfjson.ResourceChange) *tfsandbox.Plan {
+		plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+			PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+			ResourceDrift: drift,
+		})
+		require.NoError(t, err)
+		return plan
+	}
+	change := func(address string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    "aws_s3_bucket",
+			Change:  &tfjson.Change{Actions: actions},
+		}
+	}
+
+	t.Run("no drift", func(t *testing.T) {
+		assert.NoError(t, driftError(modURN, refreshPlan()))
+	})
+
+	t.Run("tags changed outside of Pulumi", func(t *testing.T) {
+		err := driftError(modURN, refreshPlan(
+			change("module.mybucketmod.aws_s3_bucket.logs", tfjson.ActionDelete),
+			change("module.mybucketmod.aws_s3_bucket.data", tfjson.ActionUpdate),
+		))
+		assert.EqualError(t, err, "drift detected in module mybucketmod, the following resources changed outside "+
+			"of Pulumi: module.mybucketmod.aws_s3_bucket.data, module.mybucketmod.aws_s3_bucket.logs. "+
+			"Set failOnDrift to false to reconcile the drift")
+	})
+}
+
+func TestFailOnDriftInPreviewsAndUpdates(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "drift", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+	t.Cleanup(func() {
+		os.RemoveAll(tfsandboxWorkdir(t, stub, modURN))
+	})
+	const wantErr = "drift detected in module m, the following resources changed outside of Pulumi: " +
+		"module.m.terraform_data.example. Set failOnDrift to false to reconcile the drift"
+
+	oldOutputs := resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(
+			`{"version":4,"terraform_version":"1.9.0","serial":1,"lineage":"l","outputs":{},"resources":[]}`)),
+		moduleResourceLockPropName: resource.NewStringProperty(""),
+		moduleResourceProvidersConfigPropName: resource.MakeSecret(resource.NewStringProperty(
+			providersConfigDigest(map[string]resource.PropertyMap{}, moduleOptions{}))),
+	}
+	olds, err := plugin.MarshalProperties(oldOutputs, h.marshalOpts())
+	require.NoError(t, err)
+
+	diff := func(opts moduleOptions) (*pulumirpc.DiffResponse, error) {
+		return h.Diff(ctx, &pulumirpc.DiffRequest{Urn: string(modURN), Olds: olds}, TFModuleSource(src), "",
+			map[string]resource.PropertyMap{}, &InferredModuleSchema{}, opts)
+	}
+
+	t.Run("diff", func(t *testing.T) {
+		resp, err := diff(moduleOptions{executor: stub})
+		require.NoError(t, err)
+		assert.Equal(t, pulumirpc.DiffResponse_DIFF_NONE, resp.GetChanges())
+
+		_, err = diff(moduleOptions{executor: stub, failOnDrift: true})
+		assert.EqualError(t, err, wantErr)
+
+		// unchanged module instances are not trusted to be in sync when drift must fail the preview
+		_, err = diff(moduleOptions{executor: stub, failOnDrift: true, skipUnchangedPlans: true})
+		assert.EqualError(t, err, wantErr)
+	})
+
+	for _, preview := range []bool{true, false} {
+		name := "update"
+		if preview {
+			name = "preview"
+		}
+		t.Run(name, func(t *testing.T) {
+			_, _, err := h.applyModuleOperation(ctx, modURN, resource.PropertyMap{}, oldOutputs,
+				TFModuleSource(src), "", map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple",
+				preview, moduleOptions{executor: stub, failOnDrift: true})
+			assert.EqualError(t, err, wantErr)
+			assert.NoFileExists(t, filepath.Join(tfsandboxWorkdir(t, stub, modURN), "mutations.log"),
+				"nothing is applied")
+		})
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pulumi/esc"
+	escclient "github.com/pulumi/esc/cmd/esc/cli/client"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// defaultCloudURL is the Pulumi Cloud API used when no login selects another one, as in the Pulumi CLI.
+const defaultCloudURL = "https://api.pulumi.com"
+
+// escProvidersProperty is the top-level key of an ESC environment's values holding Terraform provider configurations,
+// keyed by provider name:
+//
+//	values:
+//	  terraformProviders:
+//	    aws:
+//	      region: us-west-2
+const escProvidersProperty = "terraformProviders"
+
+// escEnvironmentRef identifies an ESC environment as <org>/<project>/<environment>.
+type escEnvironmentRef struct {
+	org, project, env string
+}
+
+func parseESCEnvironmentRef(ref string) (escEnvironmentRef, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return escEnvironmentRef{}, fmt.Errorf("ESC environment %q must be of the form <org>/<project>/<environment>",
+			ref)
+	}
+	return escEnvironmentRef{org: parts[0], project: parts[1], env: parts[2]}, nil
+}
+
+func (r escEnvironmentRef) String() string {
+	return r.org + "/" + r.project + "/" + r.env
+}
+
+// escEnvironmentOpener opens an ESC environment and returns its evaluated values.
+type escEnvironmentOpener func(ctx context.Context, ref escEnvironmentRef) (map[string]esc.Value, error)
+
+// escEnvironmentCache keeps the values of the ESC environments opened by a provider instance, so that configuring it
+// again does not open another session of the same environment. The zero value is ready to use.
+type escEnvironmentCache struct {
+	mu     sync.Mutex
+	opened map[escEnvironmentRef]map[string]esc.Value
+}
+
+// opener returns an escEnvironmentOpener opening every environment with open only once.
+func (c *escEnvironmentCache) opener(open escEnvironmentOpener) escEnvironmentOpener {
+	return func(ctx context.Context, ref escEnvironmentRef) (map[string]esc.Value, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if values, ok := c.opened[ref]; ok {
+			return values, nil
+		}
+		values, err := open(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		if c.opened == nil {
+			c.opened = map[escEnvironmentRef]map[string]esc.Value{}
+		}
+		c.opened[ref] = values
+		return values, nil
+	}
+}
+
+// escCredentials returns the Pulumi Cloud API URL and access token to open ESC environments with. PULUMI_API and
+// PULUMI_ACCESS_TOKEN, as set for the provider by the Pulumi CLI or in CI, take precedence over the current account
+// stored by `pulumi login`.
+func escCredentials() (apiURL, token string, err error) {
+	apiURL, token = os.Getenv("PULUMI_API"), os.Getenv("PULUMI_ACCESS_TOKEN")
+	if apiURL == "" || token == "" {
+		creds, err := workspace.GetStoredCredentials()
+		if err != nil {
+			return "", "", fmt.Errorf("error reading the Pulumi Cloud credentials: %w", err)
+		}
+		if apiURL == "" {
+			apiURL = creds.Current
+		}
+		if apiURL == "" {
+			apiURL = defaultCloudURL
+		}
+		if token == "" {
+			account, err := workspace.GetAccount(apiURL)
+			if err != nil {
+				return "", "", fmt.Errorf("error reading the Pulumi Cloud credentials: %w", err)
+			}
+			token = account.AccessToken
+		}
+	}
+	if !strings.HasPrefix(apiURL, "https://") && !strings.HasPrefix(apiURL, "http://") {
+		return "", "", fmt.Errorf("reading provider configuration from ESC requires a Pulumi Cloud login, "+
+			"the current backend is %s", apiURL)
+	}
+	if token == "" {
+		return "", "", fmt.Errorf("reading provider configuration from ESC requires a Pulumi Cloud login, "+
+			"run `pulumi login %s` or set PULUMI_ACCESS_TOKEN", apiURL)
+	}
+	return apiURL, token, nil
+}
+
+// openESCEnvironment opens an ESC environment using the Pulumi Cloud credentials of the current login, see
+// escCredentials.
+func openESCEnvironment(ctx context.Context, ref escEnvironmentRef) (map[string]esc.Value, error) {
+	apiURL, token, err := escCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	client := escclient.New("pulumi-terraform-module/"+Version(), apiURL, token, false /* insecure */)
+	sessionID, diags, err := client.OpenEnvironment(ctx, ref.org, ref.project, ref.env, "", 2*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if len(diags) > 0 {
+		messages := make([]string, 0, len(diags))
+		for _, d := range diags {
+			messages = append(messages, d.Summary)
+		}
+		return nil, errors.New(strings.Join(messages, "; "))
+	}
+
+	env, err := client.GetOpenEnvironmentWithProject(ctx, ref.org, ref.project, ref.env, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return env.Properties, nil
+}
+
+// loadESCProvidersConfig reads the Terraform provider configurations stored in an ESC environment.
+func loadESCProvidersConfig(
+	ctx context.Context,
+	ref string,
+	open escEnvironmentOpener,
+) (map[string]resource.PropertyMap, error) {
+	envRef, err := parseESCEnvironmentRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := open(ctx, envRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ESC environment %s: %w", envRef, err)
+	}
+
+	providers, ok := values[escProvidersProperty]
+	if !ok {
+		return nil, nil
+	}
+	providersMap, ok := providers.Value.(map[string]esc.Value)
+	if !ok {
+		return nil, fmt.Errorf("ESC environment %s: %q must be an object", envRef, escProvidersProperty)
+	}
+
+	providersConfig := make(map[string]resource.PropertyMap, len(providersMap))
+	for providerName, config := range providersMap {
+		pv := escValueToPropertyValue(config)
+		for pv.IsSecret() {
+			// Secrets are tracked on the individual settings.
+			pv = pv.SecretValue().Element
+		}
+		if !pv.IsObject() {
+			return nil, fmt.Errorf("ESC environment %s: configuration for provider %q must be an object",
+				envRef, providerName)
+		}
+		providersConfig[providerName] = pv.ObjectValue()
+	}
+	return providersConfig, nil
+}
+
+func escValueToPropertyValue(v esc.Value) resource.PropertyValue {
+	var pv resource.PropertyValue
+	switch value := v.Value.(type) {
+	case bool:
+		pv = resource.NewBoolProperty(value)
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			pv = resource.NewStringProperty(value.String())
+		} else {
+			pv = resource.NewNumberProperty(f)
+		}
+	case string:
+		pv = resource.NewStringProperty(value)
+	case []esc.Value:
+		elements := make([]resource.PropertyValue, len(value))
+		for i, e := range value {
+			elements[i] = escValueToPropertyValue(e)
+		}
+		pv = resource.NewArrayProperty(elements)
+	case map[string]esc.Value:
+		properties := make(resource.PropertyMap, len(value))
+		for k, e := range value {
+			properties[resource.PropertyKey(k)] = escValueToPropertyValue(e)
+		}
+		pv = resource.NewObjectProperty(properties)
+	default:
+		pv = resource.NewNullProperty()
+	}
+
+	if v.Secret {
+		return resource.MakeSecret(pv)
+	}
+	return pv
+}
+
+// mergeProvidersConfig layers the provider configurations from the program over the ones from ESC, so that settings
+// passed explicitly in the program take precedence.
+func mergeProvidersConfig(
+	fromESC map[string]resource.PropertyMap,
+	fromProgram map[string]resource.PropertyMap,
+) map[string]resource.PropertyMap {
+	if len(fromESC) == 0 {
+		return fromProgram
+	}
+	merged := make(map[string]resource.PropertyMap, len(fromESC)+len(fromProgram))
+	for providerName, config := range fromESC {
+		merged[providerName] = config.Copy()
+	}
+	for providerName, config := range fromProgram {
+		if base, ok := merged[providerName]; ok {
+			for k, v := range config {
+				base[k] = v
+			}
+			continue
+		}
+		merged[providerName] = config
+	}
+	return merged
+}
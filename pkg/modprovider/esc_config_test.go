@@ -0,0 +1,166 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/esc"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestESCProvidersConfigIsApplied(t *testing.T) {
+	ctx := context.Background()
+
+	var opened escEnvironmentRef
+	open := func(_ context.Context, ref escEnvironmentRef) (map[string]esc.Value, error) {
+		opened = ref
+		return map[string]esc.Value{
+			escProvidersProperty: esc.NewValue(map[string]esc.Value{
+				awsKey: esc.NewValue(map[string]esc.Value{
+					"region":     esc.NewValue("us-west-2"),
+					"access_key": esc.NewSecret("AKIA..."),
+					"profile":    esc.NewValue("esc-profile"),
+				}),
+			}),
+		}, nil
+	}
+
+	fromESC, err := loadESCProvidersConfig(ctx, "acme/networking/prod", open)
+	require.NoError(t, err)
+	assert.Equal(t, escEnvironmentRef{org: "acme", project: "networking", env: "prod"}, opened)
+
+	// The program overrides the profile; everything else comes from ESC.
+	providersConfig := mergeProvidersConfig(fromESC, cleanProvidersConfig(resource.PropertyMap{
+		awsKey: resource.NewStringProperty(`{"profile":"program-profile"}`),
+	}))
+
+	workingDir := t.TempDir()
+	err = tfsandbox.CreateTFFile("mod", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+		resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Provider map[string]map[string]any `json:"provider"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+
+	awsProvider := tfFile.Provider[awsKey]
+	require.NotNil(t, awsProvider, "expected an aws provider block in %s", contents)
+	assert.Equal(t, "us-west-2", awsProvider["region"])
+	assert.Equal(t, "program-profile", awsProvider["profile"])
+	assert.Equal(t, "${sensitive(local.local1)}", awsProvider["access_key"], "secrets from ESC stay sensitive")
+}
+
+func TestLoadESCProvidersConfigErrors(t *testing.T) {
+	ctx := context.Background()
+	open := func(context.Context, escEnvironmentRef) (map[string]esc.Value, error) {
+		return map[string]esc.Value{
+			escProvidersProperty: esc.NewValue(map[string]esc.Value{
+				awsKey: esc.NewValue("us-west-2"),
+			}),
+		}, nil
+	}
+
+	_, err := loadESCProvidersConfig(ctx, "networking/prod", open)
+	assert.ErrorContains(t, err, "must be of the form <org>/<project>/<environment>")
+
+	_, err = loadESCProvidersConfig(ctx, "acme/networking/prod", open)
+	assert.ErrorContains(t, err, `configuration for provider "aws" must be an object`)
+}
+
+func TestESCCredentials(t *testing.T) {
+	login := func(t *testing.T, creds string) {
+		dir := t.TempDir()
+		t.Setenv(workspace.PulumiCredentialsPathEnvVar, dir)
+		if creds != "" {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "credentials.json"), []byte(creds), 0o600))
+		}
+	}
+
+	t.Run("stored login", func(t *testing.T) {
+		t.Setenv("PULUMI_API", "")
+		t.Setenv("PULUMI_ACCESS_TOKEN", "")
+		login(t, `{"current":"https://api.example.com","accessTokens":{"https://api.example.com":"pul-stored"}}`)
+
+		apiURL, token, err := escCredentials()
+		require.NoError(t, err)
+		assert.Equal(t, "https://api.example.com", apiURL)
+		assert.Equal(t, "pul-stored", token)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("PULUMI_API", "")
+		t.Setenv("PULUMI_ACCESS_TOKEN", "pul-env")
+		login(t, "")
+
+		apiURL, token, err := escCredentials()
+		require.NoError(t, err)
+		assert.Equal(t, defaultCloudURL, apiURL)
+		assert.Equal(t, "pul-env", token)
+	})
+
+	t.Run("self-managed backend", func(t *testing.T) {
+		t.Setenv("PULUMI_API", "")
+		t.Setenv("PULUMI_ACCESS_TOKEN", "")
+		login(t, `{"current":"file://~"}`)
+
+		_, _, err := escCredentials()
+		assert.EqualError(t, err, "reading provider configuration from ESC requires a Pulumi Cloud login, "+
+			"the current backend is file://~")
+	})
+
+	t.Run("no login", func(t *testing.T) {
+		t.Setenv("PULUMI_API", "")
+		t.Setenv("PULUMI_ACCESS_TOKEN", "")
+		login(t, "")
+
+		_, _, err := escCredentials()
+		assert.EqualError(t, err, "reading provider configuration from ESC requires a Pulumi Cloud login, "+
+			"run `pulumi login https://api.pulumi.com` or set PULUMI_ACCESS_TOKEN")
+	})
+}
+
+func TestESCEnvironmentsAreOpenedOnce(t *testing.T) {
+	ctx := context.Background()
+	opens := map[escEnvironmentRef]int{}
+	open := func(_ context.Context, ref escEnvironmentRef) (map[string]esc.Value, error) {
+		opens[ref]++
+		return map[string]esc.Value{}, nil
+	}
+
+	var cache escEnvironmentCache
+	for _, ref := range []string{"acme/networking/prod", "acme/networking/prod", "acme/networking/dev"} {
+		_, err := loadESCProvidersConfig(ctx, ref, cache.opener(open))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[escEnvironmentRef]int{
+		{org: "acme", project: "networking", env: "prod"}: 1,
+		{org: "acme", project: "networking", env: "dev"}:  1,
+	}, opens)
+}
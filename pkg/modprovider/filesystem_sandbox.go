@@ -0,0 +1,45 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+)
+
+// filesystemSandboxPolicy selects how much of the host a module instance may reach, limiting what untrusted modules
+// can read.
+type filesystemSandboxPolicy string
+
+const (
+	// filesystemSandboxOff runs modules like Terraform does, with the environment of the provider. This is the
+	// default.
+	filesystemSandboxOff filesystemSandboxPolicy = "off"
+	// filesystemSandboxRestricted runs modules with a restricted environment whose home directory is the working
+	// directory, and rejects modules referring to files outside of their working directory, see
+	// [tfsandbox.ModuleRuntime.CheckRestrictedPaths].
+	filesystemSandboxRestricted filesystemSandboxPolicy = "restricted"
+)
+
+func parseFilesystemSandboxPolicy(s string) (filesystemSandboxPolicy, error) {
+	switch p := filesystemSandboxPolicy(s); p {
+	case "":
+		return filesystemSandboxOff, nil
+	case filesystemSandboxOff, filesystemSandboxRestricted:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", filesystemSandboxVariableName,
+			filesystemSandboxOff, filesystemSandboxRestricted, s)
+	}
+}
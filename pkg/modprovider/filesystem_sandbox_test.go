@@ -0,0 +1,35 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilesystemSandboxPolicy(t *testing.T) {
+	policy, err := parseFilesystemSandboxPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, filesystemSandboxOff, policy)
+
+	policy, err = parseFilesystemSandboxPolicy("restricted")
+	require.NoError(t, err)
+	assert.Equal(t, filesystemSandboxRestricted, policy)
+
+	_, err = parseFilesystemSandboxPolicy("strict")
+	assert.EqualError(t, err, `provider option "filesystemSandbox" must be one of "off" or "restricted", got "strict"`)
+}
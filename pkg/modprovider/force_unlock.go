@@ -0,0 +1,85 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// ForceUnlockCommandName is the provider subcommand that forcibly unlocks the state of a module instance.
+const ForceUnlockCommandName = "force-unlock"
+
+// ForceUnlock implements the force-unlock subcommand of the provider binary:
+//
+//	pulumi-resource-terraform-module force-unlock --urn <module-urn> --lock-id <lock-id> [--executor <executor>] [--yes]
+//
+// This is the equivalent of `terraform force-unlock` for the working directory of the module instance and is only
+// relevant when the module state is kept in a backend that supports locking. Unless --yes is passed the user has to
+// confirm the operation by typing "yes".
+func ForceUnlock(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet(ForceUnlockCommandName, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	moduleURN := flags.String("urn", "", "URN of the module instance whose state is locked")
+	lockID := flags.String("lock-id", "", "ID of the lock to remove, as reported by the failed operation")
+	executor := flags.String("executor", os.Getenv(moduleExecutorEnvironmentVariable),
+		"executor the module instance is run with, as in the executor provider option")
+	yes := flags.Bool("yes", false, "skip the interactive confirmation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *moduleURN == "" || *lockID == "" {
+		return errors.New("both --urn and --lock-id are required")
+	}
+	modURN, err := urn.Parse(*moduleURN)
+	if err != nil {
+		return fmt.Errorf("invalid module URN: %w", err)
+	}
+
+	if !*yes {
+		fmt.Fprintf(stdout, "Force-unlocking the state of %s while another operation holds the lock may corrupt it.\n"+
+			"Only 'yes' will be accepted to confirm: ", modURN)
+		answer, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if strings.TrimSpace(answer) != "yes" {
+			return errors.New("force-unlock cancelled")
+		}
+	}
+
+	workdir := tfsandbox.ModuleInstanceWorkdir(*executor, modURN)
+	tf, err := tfsandbox.PickModuleRuntime(ctx, tfsandbox.DiscardLogger, workdir, nil /* auxServer */, *executor)
+	if err != nil {
+		return err
+	}
+	if err := tf.ForceUnlock(ctx, *lockID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "The state of %s has been unlocked.\n", modURN)
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForceUnlockRequiresConfirmation(t *testing.T) {
+	ctx := context.Background()
+	args := []string{
+		"--urn", "urn:pulumi:test::prog::vpc:index:Module::my-vpc",
+		"--lock-id", "abc-123",
+		"--executor", "/does/not/exist",
+	}
+
+	var stdout bytes.Buffer
+	err := ForceUnlock(ctx, args, strings.NewReader("no\n"), &stdout)
+	assert.EqualError(t, err, "force-unlock cancelled")
+	assert.Contains(t, stdout.String(), "Only 'yes' will be accepted to confirm")
+
+	err = ForceUnlock(ctx, args[:2], strings.NewReader("yes\n"), &stdout)
+	assert.EqualError(t, err, "both --urn and --lock-id are required")
+}
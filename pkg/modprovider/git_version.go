@@ -0,0 +1,91 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// Schemes deriving the package version of git sources that do not reference a version, set with the
+// gitVersionScheme module configuration.
+const (
+	// gitVersionSchemeNone uses defaultPackageVersion and runs whatever the default branch holds at the time.
+	gitVersionSchemeNone = "none"
+	// gitVersionSchemeCommit pins the source to the commit the default branch points to when adding the package.
+	gitVersionSchemeCommit = "commit"
+)
+
+// gitCommitResolver returns the commit the HEAD of a remote git repository points to.
+type gitCommitResolver func(ctx context.Context, remote string) (string, error)
+
+// lsRemoteHead resolves the HEAD of a remote git repository with git ls-remote, which does not clone it.
+func lsRemoteHead(ctx context.Context, remote string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", remote, "HEAD") //nolint:gosec
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git ls-remote %s failed: %w: %s", remote, err, strings.TrimSpace(stderr.String()))
+	}
+	commit, _, _ := strings.Cut(stdout.String(), "\t")
+	if commit == "" {
+		return "", fmt.Errorf("git ls-remote %s did not report a HEAD commit", remote)
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// pinGitSourceCommit applies the gitVersionScheme module configuration to git sources that reference neither a
+// version nor a commit. With the commit scheme, the source is pinned to the commit its default branch points to, so
+// that the generated package always runs the module it was generated from.
+func pinGitSourceCommit(
+	ctx context.Context,
+	args ParameterizeArgs,
+	resolve gitCommitResolver,
+) (ParameterizeArgs, error) {
+	scheme := args.Config.gitVersionScheme()
+	if scheme != gitVersionSchemeNone && scheme != gitVersionSchemeCommit {
+		return ParameterizeArgs{}, fmt.Errorf("invalid gitVersionScheme module configuration %q, expected %q or %q",
+			scheme, gitVersionSchemeNone, gitVersionSchemeCommit)
+	}
+
+	source := args.TFModuleSource
+	if _, hasRef := source.ReferencedVersionInURL(); scheme == gitVersionSchemeNone || !source.IsGit() || hasRef {
+		return args, nil
+	}
+
+	commit, err := resolve(ctx, source.GitRemote())
+	if err != nil {
+		return ParameterizeArgs{}, fmt.Errorf("failed to resolve the commit of module %s: %w", source, err)
+	}
+	args.TFModuleSource = source.WithRef(commit)
+	return args, nil
+}
+
+// commitPackageVersion derives the package version of a source pinned to a commit from the commit hash, as a
+// pre-release of defaultPackageVersion in the style of git describe, for example 0.0.1-g51d462976d84.
+func commitPackageVersion(source tfsandbox.TFModuleSource) (packageVersion, bool) {
+	commit, ok := source.ReferencedCommit()
+	if !ok {
+		return "", false
+	}
+	return packageVersion(fmt.Sprintf("%s-g%s", defaultPackageVersion, strings.ToLower(commit[:min(len(commit), 12)]))),
+		true
+}
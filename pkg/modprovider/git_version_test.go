@@ -0,0 +1,86 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitSourcesArePinnedToTheirResolvedCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	ctx := context.Background()
+
+	repo := t.TempDir()
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"},
+			args...)...)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "git %v: %s", args, out)
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "-m", "initial")
+	head := git("rev-parse", "HEAD")
+
+	args := ParameterizeArgs{
+		TFModuleSource: TFModuleSource("git::file://" + repo + "//modules/vpc"),
+		PackageName:    "vpc",
+		Config:         &ModuleConfig{GitVersionScheme: gitVersionSchemeCommit},
+	}
+	pinned, err := pinGitSourceCommit(ctx, args, lsRemoteHead)
+	require.NoError(t, err)
+	assert.Equal(t, TFModuleSource("git::file://"+repo+"//modules/vpc?ref="+head), pinned.TFModuleSource)
+
+	version := inferPackageVersion(pinned.TFModuleSource, pinned.TFModuleVersion, gitVersionSchemeCommit)
+	assert.Equal(t, packageVersion("0.0.1-g"+head[:12]), version)
+
+	// sources pinned by hand keep the default version unless the commit scheme is selected
+	assert.Equal(t, defaultPackageVersion,
+		inferPackageVersion(pinned.TFModuleSource, pinned.TFModuleVersion, gitVersionSchemeNone))
+
+	// new commits do not change the package generated from the pinned source
+	git("commit", "--quiet", "--allow-empty", "-m", "second")
+	pinnedAgain, err := pinGitSourceCommit(ctx, pinned, lsRemoteHead)
+	require.NoError(t, err)
+	assert.Equal(t, pinned, pinnedAgain)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		args := args
+		args.Config = nil
+		unpinned, err := pinGitSourceCommit(ctx, args, func(context.Context, string) (string, error) {
+			return "", errors.New("unexpected resolution")
+		})
+		require.NoError(t, err)
+		assert.Equal(t, args, unpinned)
+		assert.Equal(t, defaultPackageVersion, inferPackageVersion(unpinned.TFModuleSource, unpinned.TFModuleVersion,
+			unpinned.Config.gitVersionScheme()))
+	})
+
+	t.Run("invalid scheme", func(t *testing.T) {
+		args := args
+		args.Config = &ModuleConfig{GitVersionScheme: "tag"}
+		_, err := pinGitSourceCommit(ctx, args, lsRemoteHead)
+		assert.ErrorContains(t, err, `invalid gitVersionScheme module configuration "tag"`)
+	})
+}
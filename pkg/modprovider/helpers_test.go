@@ -2,18 +2,25 @@ package modprovider
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
 	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
 	"github.com/pulumi/pulumi-terraform-module/pkg/auxprovider"
 	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
 	"github.com/pulumi/pulumi-terraform-module/pkg/tofuresolver"
 )
 
 type testLogger struct {
-	t *testing.T
+	t testing.TB
 }
 
 func (l *testLogger) Log(_ context.Context, level tfsandbox.LogLevel, msg string) {
@@ -24,10 +31,23 @@ func (l *testLogger) LogStatus(_ context.Context, level tfsandbox.LogLevel, msg
 	l.t.Log(string(level) + ": " + msg)
 }
 
-func newTestLogger(t *testing.T) tfsandbox.Logger {
+func newTestLogger(t testing.TB) tfsandbox.Logger {
 	return &testLogger{t: t}
 }
 
+// recordingLogger keeps log messages for assertions, prefixed with their level.
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Log(_ context.Context, level tfsandbox.LogLevel, msg string) {
+	l.messages = append(l.messages, string(level)+": "+msg)
+}
+
+func (l *recordingLogger) LogStatus(_ context.Context, level tfsandbox.LogLevel, msg string) {
+	l.messages = append(l.messages, string(level)+": "+msg)
+}
+
 //nolint:unused
 func newTestTofu(t *testing.T) *tfsandbox.ModuleRuntime {
 	srv := newTestAuxProviderServer(t)
@@ -42,7 +62,7 @@ func newTestTofu(t *testing.T) *tfsandbox.ModuleRuntime {
 	return tofu
 }
 
-func newTestAuxProviderServer(t *testing.T) *auxprovider.Server {
+func newTestAuxProviderServer(t testing.TB) *auxprovider.Server {
 	srv, err := auxprovider.Serve()
 	require.NoError(t, err)
 	t.Cleanup(func() {
@@ -51,3 +71,30 @@ func newTestAuxProviderServer(t *testing.T) *auxprovider.Server {
 	})
 	return srv
 }
+
+// tfsandboxWorkdir locates the working directory of a module instance.
+func tfsandboxWorkdir(t testing.TB, executor string, modURN urn.URN) string {
+	tf, err := tfsandbox.NewRuntimeFromExecutable(context.Background(), tfsandbox.DiscardLogger,
+		tfsandbox.ModuleInstanceWorkdir(executor, modURN), nil, executor)
+	require.NoError(t, err)
+	return tf.WorkingDir()
+}
+
+// renderedTFOutputs generates the TF file of an instance of a test module and returns the sorted names of its
+// outputs.
+func renderedTFOutputs(t *testing.T, module string, inferredModule *InferredModuleSchema) []string {
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", module))
+	require.NoError(t, err)
+	workingDir := t.TempDir()
+	err = tfsandbox.CreateTFFile("mod", tfsandbox.TFModuleSource(src), "", workingDir, resource.PropertyMap{},
+		tfOutputSpecs(inferredModule), nil /* providerConfig */, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+	var tfFile struct {
+		Output map[string]any `json:"output"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+	return slices.Sorted(maps.Keys(tfFile.Output))
+}
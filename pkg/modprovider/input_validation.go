@@ -0,0 +1,213 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/opentofu/configs"
+	"github.com/pulumi/opentofu/lang"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+// inputValidations are the validation rules of the variables of a module, which Check evaluates against the inputs of
+// module instances (see [inputValidationFailures]). Terraform only evaluates them when planning, and rules relating
+// several variables, such as a cidr required when create_vpc is true, are not expressed in the schema at all.
+type inputValidations struct {
+	// baseDir is the directory of the module, used by the functions reading files.
+	baseDir   string
+	variables map[string]*configs.Variable
+	// keys are the Pulumi keys of the module inputs indexed by the Terraform variable name.
+	keys  map[string]resource.PropertyKey
+	rules []inputValidationRule
+}
+
+type inputValidationRule struct {
+	variable string
+	rule     *configs.CheckRule
+}
+
+func newInputValidations(module *configs.Module, keys map[string]resource.PropertyKey) *inputValidations {
+	validations := &inputValidations{
+		baseDir:   module.SourceDir,
+		variables: module.Variables,
+		keys:      keys,
+	}
+	for _, name := range slices.Sorted(maps.Keys(module.Variables)) {
+		for _, rule := range module.Variables[name].Validations {
+			validations.rules = append(validations.rules, inputValidationRule{variable: name, rule: rule})
+		}
+	}
+	if len(validations.rules) == 0 {
+		return nil
+	}
+	return validations
+}
+
+// inputValidationFailures evaluates the validation rules of the module variables against the inputs of a module
+// instance, reporting the failures on every input the failing condition refers to. Rules referring to inputs that are
+// not known yet, or that cannot be evaluated outside of Terraform, are left for Terraform to check while planning.
+func inputValidationFailures(
+	inferredModule *InferredModuleSchema,
+	inputs resource.PropertyMap,
+) []*pulumirpc.CheckFailure {
+	if inferredModule == nil || inferredModule.inputValidations == nil {
+		return nil
+	}
+	v := inferredModule.inputValidations
+
+	values := map[string]cty.Value{}
+	secret := map[string]bool{}
+	for name, variable := range v.variables {
+		input, ok := inputs[v.keys[name]]
+		secret[name] = variable.Sensitive || (ok && input.ContainsSecrets())
+		values[name] = variableValue(variable, input, ok)
+	}
+
+	scope := &lang.Scope{BaseDir: v.baseDir, PureOnly: true}
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(values)},
+		Functions: scope.Functions(),
+	}
+
+	var failures []*pulumirpc.CheckFailure
+	for _, r := range v.rules {
+		result, diags := r.rule.Condition.Value(evalCtx)
+		if diags.HasErrors() || !result.IsWhollyKnown() || result.IsNull() {
+			continue
+		}
+		result, err := convert.Convert(result, cty.Bool)
+		if err != nil || result.True() {
+			continue
+		}
+
+		reason := validationErrorMessage(r, evalCtx, secret)
+		for _, name := range referencedVariables(r.rule.Condition, r.variable) {
+			key, ok := v.keys[name]
+			if !ok {
+				continue
+			}
+			failures = append(failures, &pulumirpc.CheckFailure{Property: string(key), Reason: reason})
+		}
+	}
+	return failures
+}
+
+// validationErrorMessage evaluates the error message of a failed rule. Like Terraform, it does not show messages
+// that refer to sensitive values.
+func validationErrorMessage(r inputValidationRule, evalCtx *hcl.EvalContext, secret map[string]bool) string {
+	fallback := "Invalid value for variable " + r.variable + ": the validation rule failed."
+	for _, name := range referencedVariables(r.rule.ErrorMessage, r.variable) {
+		if secret[name] {
+			return fallback + " The error message refers to sensitive values and is not shown."
+		}
+	}
+	message, diags := r.rule.ErrorMessage.Value(evalCtx)
+	if diags.HasErrors() || !message.IsWhollyKnown() || message.IsNull() {
+		return fallback
+	}
+	message, err := convert.Convert(message, cty.String)
+	if err != nil {
+		return fallback
+	}
+	return message.AsString()
+}
+
+// referencedVariables lists the variables an expression of a validation rule of the given variable refers to, starting
+// with that variable.
+func referencedVariables(expr hcl.Expression, variable string) []string {
+	names := []string{variable}
+	for _, traversal := range expr.Variables() {
+		if traversal.RootName() != "var" || len(traversal) < 2 {
+			continue
+		}
+		if attr, ok := traversal[1].(hcl.TraverseAttr); ok && !slices.Contains(names, attr.Name) {
+			names = append(names, attr.Name)
+		}
+	}
+	return names
+}
+
+// variableValue is the value a module variable takes for an input, falling back to its default when the input is not
+// set or null. Values that cannot be converted to the type of the variable are treated as unknown, leaving the type
+// error to be reported by Terraform.
+func variableValue(variable *configs.Variable, input resource.PropertyValue, set bool) cty.Value {
+	if !set || input.IsNull() {
+		if variable.Default != cty.NilVal {
+			return variable.Default
+		}
+		return cty.NullVal(variable.ConstraintType)
+	}
+	val := ctyValueOf(input)
+	if !val.IsWhollyKnown() {
+		return cty.UnknownVal(variable.ConstraintType)
+	}
+	if variable.TypeDefaults != nil {
+		val = variable.TypeDefaults.Apply(val)
+	}
+	converted, err := convert.Convert(val, variable.ConstraintType)
+	if err != nil {
+		return cty.UnknownVal(variable.ConstraintType)
+	}
+	return converted
+}
+
+// ctyValueOf converts an input to a dynamically typed cty value. Unknown values and resource references are unknown.
+func ctyValueOf(v resource.PropertyValue) cty.Value {
+	switch {
+	case v.IsSecret():
+		return ctyValueOf(v.SecretValue().Element)
+	case v.IsOutput():
+		if !v.OutputValue().Known {
+			return cty.DynamicVal
+		}
+		return ctyValueOf(v.OutputValue().Element)
+	case v.IsComputed(), v.IsResourceReference():
+		return cty.DynamicVal
+	case v.IsNull():
+		return cty.NullVal(cty.DynamicPseudoType)
+	case v.IsBool():
+		return cty.BoolVal(v.BoolValue())
+	case v.IsNumber():
+		return cty.NumberFloatVal(v.NumberValue())
+	case v.IsString():
+		return cty.StringVal(v.StringValue())
+	case v.IsArray():
+		if len(v.ArrayValue()) == 0 {
+			return cty.EmptyTupleVal
+		}
+		elements := make([]cty.Value, len(v.ArrayValue()))
+		for i, element := range v.ArrayValue() {
+			elements[i] = ctyValueOf(element)
+		}
+		return cty.TupleVal(elements)
+	case v.IsObject():
+		if len(v.ObjectValue()) == 0 {
+			return cty.EmptyObjectVal
+		}
+		attributes := map[string]cty.Value{}
+		for k, element := range v.ObjectValue() {
+			attributes[string(k)] = ctyValueOf(element)
+		}
+		return cty.ObjectVal(attributes)
+	default:
+		return cty.DynamicVal
+	}
+}
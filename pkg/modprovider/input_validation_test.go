@@ -0,0 +1,102 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+func TestCheckEvaluatesCrossInputValidation(t *testing.T) {
+	h := &moduleHandler{}
+	inferredModule, err := inferModuleSchemaFromContent("vpc", loadTestModule(t, "cross-validation"), nil)
+	require.NoError(t, err)
+
+	check := func(t *testing.T, inputs resource.PropertyMap) []*pulumirpc.CheckFailure {
+		news, err := plugin.MarshalProperties(inputs, h.marshalOpts())
+		require.NoError(t, err)
+		resp, err := h.Check(context.Background(), &pulumirpc.CheckRequest{
+			Urn:  "urn:pulumi:test::test::vpc:index:Module::vpc",
+			News: news,
+		}, inferredModule, nil, moduleOptions{})
+		require.NoError(t, err)
+		return resp.GetFailures()
+	}
+	failure := func(property, reason string) *pulumirpc.CheckFailure {
+		return &pulumirpc.CheckFailure{Property: property, Reason: reason}
+	}
+
+	t.Run("satisfied", func(t *testing.T) {
+		assert.Empty(t, check(t, resource.PropertyMap{}))
+		assert.Empty(t, check(t, resource.PropertyMap{
+			"create_vpc": resource.NewBoolProperty(true),
+			"cidr":       resource.NewStringProperty("10.0.0.0/16"),
+		}))
+	})
+
+	t.Run("violated", func(t *testing.T) {
+		// the rule of cidr refers to create_vpc, so the failure is reported on both
+		assert.Equal(t, []*pulumirpc.CheckFailure{
+			failure("cidr", "cidr is required when create_vpc is true."),
+			failure("create_vpc", "cidr is required when create_vpc is true."),
+		}, check(t, resource.PropertyMap{
+			"create_vpc": resource.NewBoolProperty(true),
+		}))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		assert.Empty(t, check(t, resource.PropertyMap{
+			"create_vpc": resource.MakeComputed(resource.NewBoolProperty(false)),
+		}))
+	})
+
+	t.Run("functions", func(t *testing.T) {
+		assert.Equal(t, []*pulumirpc.CheckFailure{
+			failure("cidr", "cidr must be a valid CIDR block, got 10.0.0.0."),
+		}, check(t, resource.PropertyMap{
+			"cidr": resource.NewStringProperty("10.0.0.0"),
+		}))
+	})
+
+	t.Run("sensitive", func(t *testing.T) {
+		assert.Equal(t, []*pulumirpc.CheckFailure{
+			failure("password", "Invalid value for variable password: the validation rule failed. The error "+
+				"message refers to sensitive values and is not shown."),
+		}, check(t, resource.PropertyMap{
+			"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		}))
+	})
+}
+
+func TestCheckWithoutValidationRules(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), nil)
+	require.NoError(t, err)
+	assert.Nil(t, inferredModule.inputValidations)
+
+	resp, err := (&moduleHandler{}).Check(context.Background(), &pulumirpc.CheckRequest{
+		Urn:  "urn:pulumi:test::test::simple:index:Module::simple",
+		News: &structpb.Struct{},
+	}, inferredModule, nil, moduleOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetFailures())
+}
@@ -0,0 +1,203 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// interactiveAuthPolicy selects how module operations that need interactive provider authentication are handled,
+// such as AWS SSO sessions that expired or Azure device code logins. Terraform runs without a terminal, so these flows
+// either fail with errors that do not say what to do or wait for a login that never comes.
+type interactiveAuthPolicy string
+
+const (
+	// interactiveAuthFail stops the operation as soon as interactive authentication is detected and fails it with
+	// instructions to authenticate before running Pulumi. Applies and destroys are not stopped, see
+	// changingInfrastructure. This is the default.
+	interactiveAuthFail interactiveAuthPolicy = "fail"
+	// interactiveAuthAllow lets the operation continue, so that device code logins printed in the logs can be
+	// completed from a browser. Failures still explain how to authenticate beforehand.
+	interactiveAuthAllow interactiveAuthPolicy = "allow"
+)
+
+func parseInteractiveAuthPolicy(s string) (interactiveAuthPolicy, error) {
+	switch p := interactiveAuthPolicy(s); p {
+	case "":
+		return interactiveAuthFail, nil
+	case interactiveAuthFail, interactiveAuthAllow:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", interactiveAuthVariableName,
+			interactiveAuthFail, interactiveAuthAllow, s)
+	}
+}
+
+// interactiveAuthFlow describes the messages by which an authentication flow that needs a user is recognized, and the
+// command that authenticates ahead of time.
+type interactiveAuthFlow struct {
+	name     string
+	patterns []string
+	login    string
+}
+
+var interactiveAuthFlows = []interactiveAuthFlow{
+	{
+		name: "AWS SSO",
+		patterns: []string{
+			"refresh cached SSO token failed",
+			"SSO session has expired",
+			"SSO session associated with this profile has expired",
+			"SSOProviderInvalidToken",
+		},
+		login: "aws sso login",
+	},
+	{
+		name: "Azure",
+		patterns: []string{
+			"microsoft.com/devicelogin",
+			"Please run 'az login'",
+			"please run `az login`",
+		},
+		login: "az login",
+	},
+	{
+		name: "Google Cloud",
+		patterns: []string{
+			"Reauthentication is needed",
+			"invalid_rapt",
+		},
+		login: "gcloud auth application-default login",
+	},
+}
+
+// detectInteractiveAuth finds the authentication flow a message of the module run shows to need a user, if any.
+func detectInteractiveAuth(message string) (interactiveAuthFlow, bool) {
+	for _, flow := range interactiveAuthFlows {
+		for _, pattern := range flow.patterns {
+			if strings.Contains(message, pattern) {
+				return flow, true
+			}
+		}
+	}
+	return interactiveAuthFlow{}, false
+}
+
+// interactiveAuthGuidance tells the user how to authenticate before running Pulumi.
+func interactiveAuthGuidance(flow interactiveAuthFlow) string {
+	return fmt.Sprintf("the module requires interactive %s authentication, which is not possible while Pulumi runs "+
+		"Terraform non-interactively; authenticate before running Pulumi, for example with `%s`", flow.name,
+		flow.login)
+}
+
+type interactiveAuthDetectorKey struct{}
+
+// interactiveAuthDetector watches the messages of a module run for interactive authentication. It is carried in the
+// context so that every logger of the operation reports to it, see [resourceLogger.Log].
+type interactiveAuthDetector struct {
+	policy interactiveAuthPolicy
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	detected *interactiveAuthFlow
+	// changing is set once Terraform applies or destroys, after which the operation is no longer stopped.
+	changing bool
+}
+
+// observe records the authentication flow a message needs, if any, and stops the operation when the policy says so.
+func (d *interactiveAuthDetector) observe(message string) (interactiveAuthFlow, bool) {
+	flow, ok := detectInteractiveAuth(message)
+	if !ok {
+		return flow, false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.detected == nil {
+		d.detected = &flow
+		if d.policy == interactiveAuthFail && !d.changing {
+			d.cancel()
+		}
+	}
+	return flow, true
+}
+
+// guardInteractiveAuth watches the module operation run with the returned context for interactive authentication.
+// The returned function explains the error of the operation when authentication was the reason it failed, and must
+// be called once the operation is done.
+func guardInteractiveAuth(
+	ctx context.Context,
+	policy interactiveAuthPolicy,
+) (context.Context, func(error) error) {
+	ctx, cancel := context.WithCancel(ctx)
+	d := &interactiveAuthDetector{policy: policy, cancel: cancel}
+	ctx = context.WithValue(ctx, interactiveAuthDetectorKey{}, d)
+	return ctx, func(err error) error {
+		defer cancel()
+		if err == nil {
+			return nil
+		}
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if d.detected == nil {
+			return err
+		}
+		return fmt.Errorf("%s: %w", interactiveAuthGuidance(*d.detected), err)
+	}
+}
+
+// changingInfrastructure tells the detector of the operation, if any, that Terraform is about to apply or destroy.
+// Stopping Terraform from then on would lose the state of the resources it already changed, since the state is only
+// read back once Terraform is done, so interactive authentication only explains the error of the operation.
+func changingInfrastructure(ctx context.Context) {
+	d, ok := ctx.Value(interactiveAuthDetectorKey{}).(*interactiveAuthDetector)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.changing = true
+}
+
+// inspectInteractiveAuth reports a message of the module run to the detector of the operation, if any, and appends
+// guidance to the error messages that show interactive authentication was needed. It also returns the context to log
+// the message with: the detector may have cancelled the context of the operation, but the messages explaining why
+// must still reach the engine.
+//
+// Only warnings and errors are inspected, which is how Terraform reports the diagnostics of providers. Other messages,
+// such as debug dumps of plans and states, may hold arbitrary values that happen to match a pattern.
+func inspectInteractiveAuth(
+	ctx context.Context,
+	level tfsandbox.LogLevel,
+	message string,
+) (context.Context, string) {
+	d, ok := ctx.Value(interactiveAuthDetectorKey{}).(*interactiveAuthDetector)
+	if !ok {
+		return ctx, message
+	}
+	ctx = context.WithoutCancel(ctx)
+	if level != tfsandbox.Warn && level != tfsandbox.Error {
+		return ctx, message
+	}
+	flow, ok := d.observe(message)
+	if !ok || level != tfsandbox.Error {
+		return ctx, message
+	}
+	return ctx, message + "\n" + interactiveAuthGuidance(flow)
+}
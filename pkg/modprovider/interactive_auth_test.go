@@ -0,0 +1,120 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+const ssoTokenExpiredDiagnostic = "Error: configuring Terraform AWS Provider: failed to refresh cached credentials, " +
+	"refresh cached SSO token failed, unable to refresh SSO token, operation error SSO OIDC: CreateToken"
+
+func TestInteractiveAuthFail(t *testing.T) {
+	ctx, explain := guardInteractiveAuth(context.Background(), interactiveAuthFail)
+	logger := newResourceLogger(nil, "")
+
+	// stands in for a provider that waits for the user to log in until it is stopped
+	cmd := exec.CommandContext(ctx, "sleep", "60")
+	require.NoError(t, cmd.Start())
+
+	logger.Log(ctx, tfsandbox.Error, ssoTokenExpiredDiagnostic)
+	runErr := cmd.Wait()
+	require.Error(t, runErr, "the operation should be stopped")
+
+	err := explain(fmt.Errorf("apply failed: %w", runErr))
+	assert.ErrorContains(t, err, "the module requires interactive AWS SSO authentication, which is not possible "+
+		"while Pulumi runs Terraform non-interactively; authenticate before running Pulumi, for example with "+
+		"`aws sso login`: apply failed")
+	assert.ErrorIs(t, err, runErr)
+}
+
+func TestInteractiveAuthDoesNotStopApplies(t *testing.T) {
+	ctx, explain := guardInteractiveAuth(context.Background(), interactiveAuthFail)
+	logger := newResourceLogger(nil, "")
+
+	changingInfrastructure(ctx)
+	logger.Log(ctx, tfsandbox.Error, ssoTokenExpiredDiagnostic)
+	assert.NoError(t, ctx.Err(), "stopping the apply would lose the state of the resources it changed")
+
+	assert.ErrorContains(t, explain(errors.New("apply failed")), "for example with `aws sso login`: apply failed")
+}
+
+func TestInteractiveAuthAllow(t *testing.T) {
+	ctx, explain := guardInteractiveAuth(context.Background(), interactiveAuthAllow)
+	logger := newResourceLogger(nil, "")
+
+	logger.Log(ctx, tfsandbox.Warn, "Warning: To sign in, use a web browser to open the page "+
+		"https://microsoft.com/devicelogin and enter the code ABC123 to authenticate.")
+	assert.NoError(t, ctx.Err(), "the operation should continue")
+
+	assert.NoError(t, explain(nil))
+	assert.ErrorContains(t, explain(errors.New("plan failed")), "for example with `az login`: plan failed")
+}
+
+func TestInteractiveAuthUndetected(t *testing.T) {
+	ctx, explain := guardInteractiveAuth(context.Background(), interactiveAuthFail)
+	logger := newResourceLogger(nil, "")
+
+	logger.Log(ctx, tfsandbox.Error, "Error: creating S3 Bucket: BucketAlreadyExists")
+	assert.NoError(t, ctx.Err())
+	assert.EqualError(t, explain(errors.New("apply failed")), "apply failed")
+}
+
+func TestInteractiveAuthIgnoresDebugMessages(t *testing.T) {
+	ctx, explain := guardInteractiveAuth(context.Background(), interactiveAuthFail)
+	logger := newResourceLogger(nil, "")
+
+	// plans and states are dumped at the debug level and may hold any string, such as the output of a module
+	logger.Log(ctx, tfsandbox.Debug, `tf.Apply produced the following state: {"outputs":{"hint":"SSO session has `+
+		`expired"}}`)
+	logger.LogStatus(ctx, tfsandbox.Info, "SSO session has expired")
+	assert.NoError(t, ctx.Err())
+	assert.EqualError(t, explain(errors.New("apply failed")), "apply failed")
+}
+
+func TestInspectInteractiveAuth(t *testing.T) {
+	ctx, _ := guardInteractiveAuth(context.Background(), interactiveAuthFail)
+
+	_, message := inspectInteractiveAuth(ctx, tfsandbox.Error, "Error: Reauthentication is needed. Please run "+
+		"`gcloud auth application-default login` to reauthenticate.")
+	assert.Contains(t, message, "for example with `gcloud auth application-default login`")
+
+	logCtx, message := inspectInteractiveAuth(ctx, tfsandbox.Info, "Refreshing state...")
+	assert.Equal(t, "Refreshing state...", message)
+	assert.Error(t, ctx.Err())
+	assert.NoError(t, logCtx.Err(), "messages should still be logged once the operation is stopped")
+}
+
+func TestParseInteractiveAuthPolicy(t *testing.T) {
+	policy, err := parseInteractiveAuthPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, interactiveAuthFail, policy)
+
+	policy, err = parseInteractiveAuthPolicy("allow")
+	require.NoError(t, err)
+	assert.Equal(t, interactiveAuthAllow, policy)
+
+	_, err = parseInteractiveAuthPolicy("prompt")
+	assert.EqualError(t, err, `provider option "interactiveAuth" must be one of "fail" or "allow", got "prompt"`)
+}
@@ -0,0 +1,77 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+// invalidModuleStatePolicy selects how module instances whose Terraform state stored in Pulumi is missing or
+// malformed are handled. This typically results from editing the Pulumi state by hand.
+type invalidModuleStatePolicy string
+
+const (
+	// invalidModuleStateError fails operations on the module instance until the state is repaired. This is the
+	// default.
+	invalidModuleStateError invalidModuleStatePolicy = "error"
+	// invalidModuleStateReset runs the module instance from an empty Terraform state. The resources recorded in the
+	// discarded state are no longer managed and the module creates them anew.
+	invalidModuleStateReset invalidModuleStatePolicy = "reset"
+)
+
+func parseInvalidModuleStatePolicy(s string) (invalidModuleStatePolicy, error) {
+	switch p := invalidModuleStatePolicy(s); p {
+	case "":
+		return invalidModuleStateError, nil
+	case invalidModuleStateError, invalidModuleStateReset:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", invalidModuleStateVariableName,
+			invalidModuleStateError, invalidModuleStateReset, s)
+	}
+}
+
+var errMissingModuleState = fmt.Errorf("the %q property is missing", moduleResourceStatePropName)
+
+// checkModuleState validates the Terraform state of a module instance read from its outputs by getState.
+func checkModuleState(rawState []byte, err error) error {
+	switch {
+	case err != nil:
+		return err
+	case rawState == nil:
+		return errMissingModuleState
+	case !json.Valid(rawState):
+		return fmt.Errorf("the %q property does not hold a Terraform state in the JSON format",
+			moduleResourceStatePropName)
+	default:
+		return nil
+	}
+}
+
+// invalidModuleStateRecoveryError explains how to recover from a module instance state rejected by checkModuleState.
+func invalidModuleStateRecoveryError(urn urn.URN, err error) error {
+	hint := "Restore the state of the stack from a backup with `pulumi stack import`"
+	if errors.Is(err, errMissingModuleState) {
+		hint = "If the module instance was imported or its state edited by hand, restore the state of the stack " +
+			"from a backup with `pulumi stack import`"
+	}
+	return fmt.Errorf("the Terraform state of module %s is invalid: %w. %s, or set the %q provider option to %q "+
+		"to run the module from an empty state, which creates its resources anew",
+		urn.Name(), err, hint, invalidModuleStateVariableName, invalidModuleStateReset)
+}
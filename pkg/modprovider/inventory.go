@@ -0,0 +1,105 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// InventoryCommandName is the provider subcommand that lists the resources managed by the module instances of a stack.
+const InventoryCommandName = "inventory"
+
+// moduleInventoryEntry is a resource managed by the module instance with the given URN.
+type moduleInventoryEntry struct {
+	URN string `json:"urn"`
+	tfsandbox.InventoryEntry
+}
+
+// Inventory implements the inventory subcommand of the provider binary:
+//
+//	pulumi stack export --show-secrets | pulumi-resource-terraform-module inventory [--stack-file <file>]
+//
+// It prints a JSON array describing every resource managed by the module instances of the stack, with the URN of the
+// module instance, the Terraform address and type of the resource and its id and arn attributes when it has them.
+// The stack is read from stdin unless --stack-file is passed. Secrets are needed to read the module states but are
+// not printed.
+func Inventory(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet(InventoryCommandName, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	stackFile := flags.String("stack-file", "", "stack exported with `pulumi stack export --show-secrets`")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var contents []byte
+	var err error
+	if *stackFile != "" {
+		contents, err = os.ReadFile(*stackFile)
+	} else {
+		contents, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	inventory, err := stackInventory(contents)
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(out))
+	return err
+}
+
+func stackInventory(stackFile []byte) ([]moduleInventoryEntry, error) {
+	_, deployment, err := decodeExportedStack(stackFile)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := []moduleInventoryEntry{}
+	resources, _ := deployment["resources"].([]any)
+	for _, r := range resources {
+		res, _ := r.(map[string]any)
+		outputs, _ := res["outputs"].(map[string]any)
+		if _, ok := outputs[moduleResourceStatePropName]; !ok {
+			continue
+		}
+		moduleURN, _ := res["urn"].(string)
+		state, err := exportedModuleState(outputs, urn.URN(moduleURN))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := tfsandbox.Inventory(state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the resources of %s: %w", moduleURN, err)
+		}
+		for _, e := range entries {
+			inventory = append(inventory, moduleInventoryEntry{URN: moduleURN, InventoryEntry: e})
+		}
+	}
+	return inventory, nil
+}
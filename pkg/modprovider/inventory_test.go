@@ -0,0 +1,59 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/sig"
+)
+
+func TestInventoryCommand(t *testing.T) {
+	t.Parallel()
+
+	moduleURN := "urn:pulumi:test::ts-randmod-program::randmod:index:Module::myrandmod"
+	rawState := `{"version":4,"serial":1,"resources":[{"module":"module.myrandmod","mode":"managed",` +
+		`"type":"random_integer","name":"priority","instances":[{"attributes":{"id":"2","result":2}}]}]}`
+	plaintext, err := json.Marshal(rawState)
+	require.NoError(t, err)
+	stack, err := json.Marshal(map[string]any{
+		"version": 3,
+		"deployment": map[string]any{"resources": []any{
+			map[string]any{"urn": "urn:pulumi:test::ts-randmod-program::pulumi:pulumi:Stack::ts-randmod-program-test"},
+			map[string]any{"urn": moduleURN, "outputs": map[string]any{
+				moduleResourceStatePropName: map[string]any{sig.Key: sig.Secret, "plaintext": string(plaintext)},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	var stdout bytes.Buffer
+	require.NoError(t, Inventory(nil, strings.NewReader(string(stack)), &stdout))
+
+	var inventory []map[string]any
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &inventory))
+	assert.Equal(t, []map[string]any{{
+		"urn":     moduleURN,
+		"address": "module.myrandmod.random_integer.priority",
+		"type":    "random_integer",
+		"id":      "2",
+	}}, inventory)
+}
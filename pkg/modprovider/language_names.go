@@ -0,0 +1,85 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// csharpPropertyName returns the name of the C# property generated for a schema property. The C# SDK capitalizes
+// property names, so while they never collide with keywords, they may collide with the names of class members.
+func csharpPropertyName(name string) string {
+	if name == "" {
+		return ""
+	}
+	runes := []rune(name)
+	return string(append([]rune{unicode.ToUpper(runes[0])}, runes[1:]...))
+}
+
+// withCSharpNameOverride records a C# name for a property whose generated name would collide with one of the
+// reserved member names of its class, such as the name of the class itself. The Pulumi and Terraform names of the
+// property are unchanged.
+func withCSharpNameOverride(property schema.PropertySpec, name string, reserved ...string) schema.PropertySpec {
+	csharpName := csharpPropertyName(name)
+	collides := false
+	for _, r := range reserved {
+		collides = collides || strings.EqualFold(csharpName, r)
+	}
+	if !collides {
+		return property
+	}
+
+	info, err := json.Marshal(map[string]string{"name": csharpName + "Value"})
+	contract.AssertNoErrorf(err, "failed to marshal C# property info")
+	language := map[string]schema.RawMessage{}
+	for lang, raw := range property.Language {
+		language[lang] = raw
+	}
+	language["csharp"] = info
+	property.Language = language
+	return property
+}
+
+// withLanguageNameOverrides adds C# names to the properties of the module resource and its supporting types whose
+// generated names would not compile. Properties named after their class, such as a module output named module, and
+// resource outputs named get, which collides with the static Get method of resources, are renamed.
+func withLanguageNameOverrides(
+	inputs, outputs map[string]schema.PropertySpec,
+	supportingTypes map[string]schema.ComplexTypeSpec,
+) {
+	for name, property := range inputs {
+		inputs[name] = withCSharpNameOverride(property, name, defaultComponentTypeName+"Args")
+	}
+	for name, property := range outputs {
+		outputs[name] = withCSharpNameOverride(property, name, defaultComponentTypeName, "Get")
+	}
+	for token, typeSpec := range supportingTypes {
+		if len(typeSpec.Properties) == 0 {
+			continue
+		}
+		typeName := csharpPropertyName(token[strings.LastIndex(token, ":")+1:])
+		properties := make(map[string]schema.PropertySpec, len(typeSpec.Properties))
+		for name, property := range typeSpec.Properties {
+			properties[name] = withCSharpNameOverride(property, name, typeName, typeName+"Args")
+		}
+		typeSpec.Properties = properties
+		supportingTypes[token] = typeSpec
+	}
+}
@@ -109,6 +109,7 @@ func isMissingCredentialsErrorFromAWS(message string) bool {
 }
 
 func (l *resourceLogger) Log(ctx context.Context, level tfsandbox.LogLevel, message string) {
+	ctx, message = inspectInteractiveAuth(ctx, level, message)
 	if l.hc == nil {
 		return
 	}
@@ -126,10 +127,6 @@ func (l *resourceLogger) Log(ctx context.Context, level tfsandbox.LogLevel, mess
 }
 
 func (l *resourceLogger) LogStatus(ctx context.Context, level tfsandbox.LogLevel, message string) {
-	if l.hc == nil {
-		return
-	}
-
 	// warnings and errors should not be Status messages
 	switch level {
 	case tfsandbox.Warn, tfsandbox.Error:
@@ -137,6 +134,11 @@ func (l *resourceLogger) LogStatus(ctx context.Context, level tfsandbox.LogLevel
 		return
 	}
 
+	ctx, message = inspectInteractiveAuth(ctx, level, message)
+	if l.hc == nil {
+		return
+	}
+
 	err := l.hc.LogStatus(ctx, asSeverity(level), l.urn, message)
 	contract.IgnoreError(err)
 }
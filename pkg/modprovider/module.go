@@ -15,13 +15,24 @@
 package modprovider
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/pkg/v3/resource/provider"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
@@ -42,6 +53,14 @@ const (
 	moduleResourceStatePropName   = "__state"
 	moduleResourceLockPropName    = "__lock"
 	moduleResourceVersionPropName = "__moduleVersion"
+	// moduleResourceDataSourcesPropName records the data sources published as views, see recordDataSources.
+	moduleResourceDataSourcesPropName = "__dataSources"
+	// moduleResourceChildResourceTypesPropName records the policy by which the views of child resources are typed, see
+	// recordChildResourceTypes.
+	moduleResourceChildResourceTypesPropName = "__childResourceTypes"
+	// moduleResourceProvidersConfigPropName records a digest of the provider configuration the module instance was
+	// deployed with, see recordProvidersConfig.
+	moduleResourceProvidersConfigPropName = "__providersConfig"
 )
 
 type moduleHandler struct {
@@ -50,6 +69,90 @@ type moduleHandler struct {
 	statusPool        status.Pool
 }
 
+// moduleOptions are the provider-level settings that affect how module instances are run.
+type moduleOptions struct {
+	// executor selects the binary running the module, see [tfsandbox.PickModuleRuntime].
+	executor string
+	// skipUnchangedPlans lets Diff skip planning module instances whose inputs, version and provider configuration are
+	// unchanged.
+	skipUnchangedPlans bool
+	// providerMeta is emitted into the provider_meta blocks of the generated Terraform file.
+	providerMeta map[string]resource.PropertyMap
+	// pluginCacheDir enables a provider plugin cache shared between module instances when set.
+	pluginCacheDir string
+	// initLimiter bounds the number of module instances running init at the same time, see initParallelism.
+	initLimiter *tfsandbox.InitLimiter
+	// moduleCacheDir enables a cache of downloaded modules shared between module instances when set.
+	moduleCacheDir string
+	// costEstimateCommand is a hook to estimate the cost of the planned changes during previews, see estimateCost.
+	costEstimateCommand string
+	// readiness is polled after applying a module instance, see waitUntilReady.
+	readiness readinessCheck
+	// applyRetry selects the failed applies that are retried, see applyWithRetry.
+	applyRetry applyRetryPolicy
+	// stateSizeLimits bound the size of the Terraform state stored in the module outputs, see checkStateSize.
+	stateSizeLimits stateSizeLimits
+	// readOnly refuses operations that would change the infrastructure, see refusedInReadOnlyMode.
+	readOnly bool
+	// workdirCleanup selects when the working directory of a module instance is removed, see cleanupWorkdir.
+	workdirCleanup workdirCleanupPolicy
+	// failOnDrift fails refreshes that detect drift, see driftError.
+	failOnDrift bool
+	// refreshOnly reconciles the state with the infrastructure on updates instead of applying the program, like
+	// `terraform apply -refresh-only`.
+	refreshOnly bool
+	// destroyTargets are addresses of child resources that updates destroy instead of applying the program, see
+	// destroyTargetsOf.
+	destroyTargets []string
+	// extraTerraformFiles are written next to the generated Terraform file, keyed by file name.
+	extraTerraformFiles map[string]string
+	// moduleDependsOn are added to the depends_on meta-argument of the module block.
+	moduleDependsOn []string
+	// registryTokens authenticate to private module registries during init, see injectRegistryToken.
+	registryTokens registryTokens
+	// moduleNaming selects the name of the module block in the generated Terraform file, see moduleInstanceName.
+	moduleNaming moduleNamingStrategy
+	// invalidModuleState selects how module instances with a missing or malformed state are handled, see
+	// checkModuleState.
+	invalidModuleState invalidModuleStatePolicy
+	// gitFullClone disables shallow clones of git sources, see [tfsandbox.TFModuleSource.WithShallowClone].
+	gitFullClone bool
+	// pulumiDefaultTags tags the resources of module instances with their stack and project, see
+	// withPulumiDefaultTags.
+	pulumiDefaultTags bool
+	// secretsAudit logs the paths of the secret inputs and outputs of module instances, see reportSecretsAudit.
+	secretsAudit bool
+	// taintedResources selects how child resources left tainted by a failed apply are handled, see
+	// handleTaintedResources.
+	taintedResources taintedResourcesPolicy
+	// providerChecksums selects how strictly provider packages are verified against the recorded lock file, see
+	// enforceLockedProviders.
+	providerChecksums providerChecksumsPolicy
+	// viewStepsBatchSize bounds the size in bytes of every request publishing view steps, see publishViewSteps.
+	viewStepsBatchSize int
+	// dataSourceViews reports the data sources read by module instances as read-only views, see
+	// viewStepsForDataSources.
+	dataSourceViews bool
+	// childResourceDiffs adds the changed attributes of child resources to their views, see detailViewSteps.
+	childResourceDiffs bool
+	// childResourceTypes selects the type tokens of the views of child resources, see pulumiViewTypes.
+	childResourceTypes childResourceTypesPolicy
+	// filesystemSandbox selects how much of the host module instances may reach, see filesystemSandboxPolicy.
+	filesystemSandbox filesystemSandboxPolicy
+	// interactiveAuth selects how operations needing interactive provider authentication are handled, see
+	// guardInteractiveAuth.
+	interactiveAuth interactiveAuthPolicy
+	// unconfiguredProviders selects how Check handles required providers that are not configured, see
+	// unconfiguredProviderFailures.
+	unconfiguredProviders unconfiguredProvidersPolicy
+	// stateBackupDir receives a copy of the state of module instances before every apply and destroy when set, see
+	// backupModuleState.
+	stateBackupDir string
+	// refreshMode selects whether refreshes read the child resources or only re-evaluate the outputs, see
+	// refreshOutputs.
+	refreshMode refreshMode
+}
+
 func newModuleHandler(hc *provider.HostClient, as *auxprovider.Server) *moduleHandler {
 	return &moduleHandler{
 		hc:                hc,
@@ -62,11 +165,14 @@ func moduleTypeToken(pkgName packageName) tokens.Type {
 	return tokens.Type(fmt.Sprintf("%s:index:%s", pkgName, moduleTypeName))
 }
 
-// Check is generic and does not do anything.
+// Check autonames the module and reports inputs that the module does not declare or that fail its validation rules, as
+// well as required providers that are not configured when the unconfiguredProviders provider option asks for it.
 func (h *moduleHandler) Check(
-	_ context.Context,
+	ctx context.Context,
 	req *pulumirpc.CheckRequest,
 	moduleSchema *InferredModuleSchema,
+	providersConfig map[string]resource.PropertyMap,
+	opts moduleOptions,
 ) (*pulumirpc.CheckResponse, error) {
 	news := make(map[string]*structpb.Value)
 	if req.News != nil && req.News.Fields != nil {
@@ -102,11 +208,42 @@ func (h *moduleHandler) Check(
 		}
 	}
 
+	failures := unknownInputFailures(news, moduleSchema)
+	if moduleSchema.inputValidations != nil {
+		inputs, err := plugin.UnmarshalProperties(&structpb.Struct{Fields: news}, h.marshalOpts())
+		if err != nil {
+			return nil, err
+		}
+		failures = append(failures, inputValidationFailures(moduleSchema, inputs)...)
+	}
+	failures = append(failures, unconfiguredProviderFailures(ctx,
+		newResourceLogger(h.hc, resource.URN(req.GetUrn())), moduleSchema, providersConfig, opts.unconfiguredProviders)...)
+
 	return &pulumirpc.CheckResponse{
-		Inputs: &structpb.Struct{Fields: news},
+		Inputs:   &structpb.Struct{Fields: news},
+		Failures: failures,
 	}, nil
 }
 
+// unknownInputFailures reports inputs that do not correspond to any module variable, such as typos or variables
+// removed from the module, before Terraform rejects them later in the deployment.
+func unknownInputFailures(
+	news map[string]*structpb.Value,
+	moduleSchema *InferredModuleSchema,
+) []*pulumirpc.CheckFailure {
+	var failures []*pulumirpc.CheckFailure
+	for _, key := range slices.Sorted(maps.Keys(news)) {
+		if _, known := moduleSchema.Inputs[resource.PropertyKey(key)]; known {
+			continue
+		}
+		failures = append(failures, &pulumirpc.CheckFailure{
+			Property: key,
+			Reason:   fmt.Sprintf("unknown input %q: the module does not declare a matching variable", key),
+		})
+	}
+	return failures
+}
+
 func (h *moduleHandler) Diff(
 	ctx context.Context,
 	req *pulumirpc.DiffRequest,
@@ -114,9 +251,13 @@ func (h *moduleHandler) Diff(
 	moduleVersion TFModuleVersion,
 	providersConfig map[string]resource.PropertyMap,
 	inferredModule *InferredModuleSchema,
-	executor string,
-) (*pulumirpc.DiffResponse, error) {
+	opts moduleOptions,
+) (_ *pulumirpc.DiffResponse, err error) {
 	urn := urn.URN(req.GetUrn())
+	defer func() { cleanupWorkdir(ctx, newResourceLogger(h.hc, urn), urn, opts, err) }()
+	ctx, explainInteractiveAuth := guardInteractiveAuth(ctx, opts.interactiveAuth)
+	defer func() { err = explainInteractiveAuth(err) }()
+	destroyTargets := opts.destroyTargetsOf(urn)
 
 	oldInputs, err := plugin.UnmarshalProperties(req.GetOldInputs(), h.marshalOpts())
 	if err != nil {
@@ -128,7 +269,15 @@ func (h *moduleHandler) Diff(
 		return nil, err
 	}
 
-	if !oldInputs.DeepEquals(newInputs) {
+	// Sets are unordered, so reordering their elements in the program is not a change.
+	if !normalizeSetInputs(inferredModule, oldInputs).DeepEquals(normalizeSetInputs(inferredModule, newInputs)) {
+		if opts.refreshOnly {
+			// The engine would record the new inputs without them having been applied.
+			return nil, refreshOnlyInputChangesError(urn)
+		}
+		if len(destroyTargets) > 0 {
+			return nil, destroyTargetsInputChangesError(urn)
+		}
 		// Inputs have changed, so we need tell the engine that an update is needed.
 		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_SOME}, nil
 	}
@@ -140,6 +289,13 @@ func (h *moduleHandler) Diff(
 		return nil, fmt.Errorf("failed to unmarshal old outputs: %w", err)
 	}
 
+	if opts.skipUnchangedPlans && !opts.refreshOnly && !opts.failOnDrift && len(destroyTargets) == 0 &&
+		h.deployedWith(oldOutputs, moduleVersion, providersConfig, opts) {
+		// The module instance was deployed with the same inputs, module version and provider configuration; trust
+		// that nothing changed. Drift can only be found with a plan, so failOnDrift takes the slow path.
+		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_NONE}, nil
+	}
+
 	tf, err := h.prepSandbox(
 		ctx,
 		urn,
@@ -149,17 +305,43 @@ func (h *moduleHandler) Diff(
 		moduleSource,
 		moduleVersion,
 		providersConfig,
-		executor,
+		opts,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed preparing sandbox: %w", err)
 	}
 
-	plan, err := tf.PlanNoRefresh(ctx, newResourceLogger(h.hc, urn))
+	if opts.refreshOnly {
+		// Only drift is reconciled, so the module needs an update exactly when its resources drifted.
+		plan, err := tf.PlanRefreshOnly(ctx, newResourceLogger(h.hc, urn))
+		if err != nil {
+			return nil, fmt.Errorf("error performing refresh-only plan during Diff(...) %w", err)
+		}
+		if len(driftedResources(plan)) > 0 {
+			return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_SOME}, nil
+		}
+		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_NONE}, nil
+	}
+
+	if err := checkDrift(ctx, tf, newResourceLogger(h.hc, urn), urn, opts); err != nil {
+		return nil, err
+	}
+
+	plan, err := planUpdate(ctx, tf, newResourceLogger(h.hc, urn), destroyTargets)
 	if err != nil {
 		return nil, fmt.Errorf("error performing plan during Diff(...) %w", err)
 	}
 
+	if planHasChanges(plan) {
+		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_SOME}, nil
+	}
+
+	// the module has not changed, return DIFF_NONE.
+	return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_NONE}, nil
+}
+
+// planHasChanges reports whether a plan changes any resource or output of the module.
+func planHasChanges(plan *tfsandbox.Plan) bool {
 	resourcesChanged := false
 	plan.VisitResourcePlans(func(resource *tfsandbox.ResourcePlan) {
 		if resource.ChangeKind() != tfsandbox.NoOp {
@@ -167,21 +349,16 @@ func (h *moduleHandler) Diff(
 			resourcesChanged = true
 		}
 	})
+	if resourcesChanged {
+		return true
+	}
 
-	outputsChanged := false
 	for _, output := range plan.RawPlan().OutputChanges {
 		if !output.Actions.NoOp() {
-			outputsChanged = true
-			break
+			return true
 		}
 	}
-
-	if resourcesChanged || outputsChanged {
-		return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_SOME}, nil
-	}
-
-	// the module has not changed, return DIFF_NONE.
-	return &pulumirpc.DiffResponse{Changes: pulumirpc.DiffResponse_DIFF_NONE}, nil
+	return false
 }
 
 func (h *moduleHandler) prepSandbox(
@@ -193,11 +370,11 @@ func (h *moduleHandler) prepSandbox(
 	moduleSource TFModuleSource,
 	moduleVersion TFModuleVersion,
 	providersConfig map[string]resource.PropertyMap,
-	executor string,
+	opts moduleOptions,
 ) (*tfsandbox.ModuleRuntime, error) {
 	logger := newResourceLogger(h.hc, urn)
-	wd := tfsandbox.ModuleInstanceWorkdir(executor, urn)
-	tf, err := tfsandbox.PickModuleRuntime(ctx, logger, wd, h.auxProviderServer, executor)
+	wd := tfsandbox.ModuleInstanceWorkdir(opts.executor, urn)
+	tf, err := tfsandbox.PickModuleRuntime(ctx, logger, wd, h.auxProviderServer, opts.executor)
 	if err != nil {
 		return nil, fmt.Errorf("sandbox construction failed: %w", err)
 	}
@@ -207,42 +384,17 @@ func (h *moduleHandler) prepSandbox(
 	// Pulumi name as present in the module URN.
 	// The name chosen here will proliferate into ResourceAddress of every child resource as well,
 	// which will get further reused for Pulumi URNs.
-	tfName := getModuleName(urn)
-
-	hasOutputFieldMapping := inferredModule != nil &&
-		inferredModule.SchemaFieldMappings != nil &&
-		inferredModule.SchemaFieldMappings.OutputFieldMappings != nil
-
-	outputSpecs := []tfsandbox.TFOutputSpec{}
-	for outputName := range inferredModule.Outputs {
-		if hasOutputFieldMapping {
-			mappings := inferredModule.SchemaFieldMappings.OutputFieldMappings
-			if tfName, ok := mappings[outputName]; ok {
-				outputName = tfName
-			}
-		}
+	tfName := moduleInstanceName(urn, opts.moduleNaming)
 
-		outputSpecs = append(outputSpecs, tfsandbox.TFOutputSpec{
-			Name: tfsandbox.DecodePulumiTopLevelKey(outputName),
-		})
-	}
+	outputSpecs := tfOutputSpecs(inferredModule)
 
 	// remap input fields to terraform module inputs
 	// for example if terraform module input was "input-value" but pulumi input was "input_value",
 	// then we need to remap it to "input-value" in the tf file.
-	hasInputFieldMappings := inferredModule != nil &&
-		inferredModule.SchemaFieldMappings != nil &&
-		inferredModule.SchemaFieldMappings.InputFieldMappings != nil
+	moduleInputs = inputsToTerraform(inferredModule, omitNullInputs(inferredModule, moduleInputs))
 
-	if hasInputFieldMappings {
-		mappings := inferredModule.SchemaFieldMappings.InputFieldMappings
-		for pulumiInputName, input := range moduleInputs {
-			if tfName, ok := mappings[pulumiInputName]; ok {
-				// if the input is mapped, use the mapped name
-				moduleInputs[tfName] = input
-				delete(moduleInputs, pulumiInputName)
-			}
-		}
+	if opts.pulumiDefaultTags {
+		providersConfig = withPulumiDefaultTags(providersConfig, inferredModule, urn)
 	}
 
 	// remap some required providers in the TF module. For example,
@@ -265,25 +417,69 @@ func (h *moduleHandler) prepSandbox(
 
 	err = tfsandbox.CreateTFFile(tfName, moduleSource,
 		moduleVersion, tf.WorkingDir(),
-		moduleInputs, outputSpecs, providersConfig)
+		moduleInputs, outputSpecs, providersConfig, tfsandbox.CreateTFFileOpts{
+			ProviderMeta: opts.providerMeta,
+			ExtraFiles:   opts.extraTerraformFiles,
+			DependsOn:    opts.moduleDependsOn,
+			FullGitClone: opts.gitFullClone,
+		})
 	if err != nil {
 		return nil, fmt.Errorf("seed file generation failed: %w", err)
 	}
 
 	var previousVersion tfsandbox.TFModuleVersion
+	// recordedState is set for module instances whose state is pushed to the working directory.
+	recordedState := false
 	if oldOutputs != nil {
-		rawState, rawLockFile, recordedVersion := h.getState(oldOutputs)
-		previousVersion = recordedVersion
-		err = tf.PushStateAndLockFile(ctx, rawState, rawLockFile)
-		if err != nil {
-			return nil, fmt.Errorf("PushStateAndLockFile failed: %w", err)
+		rawState, rawLockFile, recordedVersion, err := h.getState(oldOutputs)
+		err = checkModuleState(rawState, err)
+		switch {
+		case err != nil && opts.invalidModuleState == invalidModuleStateReset:
+			logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Running module %s from an empty Terraform state, which "+
+				"creates its resources anew, since its recorded state is invalid: %v", urn.Name(), err))
+		case err != nil:
+			return nil, invalidModuleStateRecoveryError(urn, err)
+		default:
+			previousVersion = recordedVersion
+			err = tf.PushStateAndLockFile(ctx, rawState, rawLockFile)
+			if err != nil {
+				return nil, fmt.Errorf("PushStateAndLockFile failed: %w", err)
+			}
+			recordedState = true
+		}
+	}
+
+	injectRegistryToken(ctx, logger, opts.registryTokens)
+	tf.LimitInits(opts.initLimiter)
+	if opts.pluginCacheDir != "" {
+		if err := tf.UsePluginCache(opts.pluginCacheDir); err != nil {
+			return nil, err
+		}
+	}
+	if opts.moduleCacheDir != "" {
+		if err := tf.UseModuleCache(opts.moduleCacheDir, tfName, moduleSource, moduleVersion); err != nil {
+			return nil, err
+		}
+	}
+	if opts.filesystemSandbox == filesystemSandboxRestricted {
+		if err := tf.RestrictEnvironment(); err != nil {
+			return nil, err
 		}
 	}
 
-	injectRegistryToken(ctx, logger)
 	// If the module version changed between deployments, rerun init with -upgrade so the lockfile
 	// is refreshed to match the newer constraint set.
-	if needsInitUpgrade(oldOutputs, previousVersion, moduleVersion) {
+	upgrade := needsInitUpgrade(oldOutputs, previousVersion, moduleVersion)
+	// Upgrades change the lock file on purpose, so strict checksum verification only guards the other inits.
+	enforceLockFile := opts.providerChecksums == providerChecksumsStrict && recordedState && !upgrade
+	var lockedBeforeInit []byte
+	if enforceLockFile {
+		lockedBeforeInit, err = readLockFile(tf.WorkingDir())
+		if err != nil {
+			return nil, fmt.Errorf("error reading the dependency lock file: %w", err)
+		}
+	}
+	if upgrade {
 		logger.LogStatus(ctx, tfsandbox.Info, fmt.Sprintf(
 			"Module version changed from %s to %s; re-running init with -upgrade",
 			versionOrUnknown(previousVersion), versionOrUnknown(moduleVersion)))
@@ -292,7 +488,22 @@ func (h *moduleHandler) prepSandbox(
 		err = tf.Init(ctx, logger)
 	}
 	if err != nil {
-		return nil, fmt.Errorf("init failed: %w", err)
+		return nil, fmt.Errorf("init failed: %w", explainProviderConstraints(tf.WorkingDir(), err))
+	}
+	if enforceLockFile {
+		if err := enforceLockedProviders(tf.WorkingDir(), lockedBeforeInit); err != nil {
+			return nil, fmt.Errorf("init failed: %w", err)
+		}
+	}
+	if upgrade {
+		reportModuleUpgradeNotes(ctx, logger, tf.WorkingDir(), tfName, moduleVersion)
+	}
+	warnOnProviderVersionViolations(ctx, logger, tf.WorkingDir())
+	if opts.filesystemSandbox == filesystemSandboxRestricted {
+		if err := tf.CheckRestrictedPaths(moduleSource); err != nil {
+			return nil, fmt.Errorf("the module is not allowed by the %s provider option: %w",
+				filesystemSandboxVariableName, err)
+		}
 	}
 
 	return tf, nil
@@ -310,8 +521,19 @@ func (h *moduleHandler) applyModuleOperation(
 	inferredModule *InferredModuleSchema,
 	packageName packageName,
 	preview bool,
-	executor string,
+	opts moduleOptions,
 ) (resource.PropertyMap, []*pulumirpc.ViewStep, error) {
+	started := time.Now()
+	if opts.refreshOnly && oldOutputs == nil {
+		return nil, nil, fmt.Errorf("cannot create module %s: the provider is configured with %s: true and there "+
+			"is no state to reconcile", urn.Name(), refreshOnlyVariableName)
+	}
+	destroyTargets := opts.destroyTargetsOf(urn)
+	if len(destroyTargets) > 0 && oldOutputs == nil {
+		return nil, nil, fmt.Errorf("cannot create module %s: the provider is configured with %s targeting its "+
+			"resources", urn.Name(), destroyTargetsVariableName)
+	}
+
 	tf, err := h.prepSandbox(
 		ctx,
 		urn,
@@ -321,7 +543,7 @@ func (h *moduleHandler) applyModuleOperation(
 		moduleSource,
 		moduleVersion,
 		providersConfig,
-		executor,
+		opts,
 	)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed preparing sandbox: %w", err)
@@ -329,11 +551,22 @@ func (h *moduleHandler) applyModuleOperation(
 
 	logger := newResourceLogger(h.hc, urn)
 
+	if err := checkDrift(ctx, tf, logger, urn, opts); err != nil {
+		return nil, nil, err
+	}
+
 	// Because of RefreshBeforeUpdate, Pulumi CLI has already refreshed at this point.
 	// so we use plan -refresh=false via tfsandbox.PlanNoRefresh()
 	// Plans are always needed, so this code will run in DryRun and otherwise. In the future we
 	// may be able to reuse the plan from DryRun for the subsequent application.
-	plan, err := tf.PlanNoRefresh(ctx, logger)
+	//
+	// In refresh-only mode the plan consists of the drift alone, which the apply accepts into the state.
+	var plan *tfsandbox.Plan
+	if opts.refreshOnly {
+		plan, err = tf.PlanRefreshOnly(ctx, logger)
+	} else {
+		plan, err = planUpdate(ctx, tf, logger, destroyTargets)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("Plan failed: %w", err)
 	}
@@ -347,15 +580,45 @@ func (h *moduleHandler) applyModuleOperation(
 
 	if preview {
 		views = viewStepsPlan(packageName, plan)
+		if opts.childResourceDiffs {
+			detailViewSteps(plan, views)
+		}
 		moduleOutputs = plan.Outputs()
+		maps.Copy(moduleOutputs,
+			childResourceOutputsFromPlan(inferredModule, moduleInstanceName(urn, opts.moduleNaming), plan))
+		dataSourceViews, read := viewStepsForDataSources(packageName, opts.dataSourceViews,
+			publishedDataSources(oldOutputs), plan.VisitDataSources)
+		views = append(views, dataSourceViews...)
+		recordDataSources(moduleOutputs, read)
+		if opts.costEstimateCommand != "" {
+			reportCostEstimate(ctx, logger, opts.costEstimateCommand, plan)
+		}
+	} else if opts.readOnly {
+		// Report what would have been done, marking every step as not carried out.
+		err := refusedInReadOnlyMode("apply", urn)
+		views = viewStepsPlan(packageName, plan)
+		for _, step := range views {
+			step.Error = err.Error()
+		}
+		return nil, views, err
 	} else {
+		err := h.backupModuleState(ctx, logger, opts.stateBackupDir, urn, oldOutputs, stateBackupApply)
+		if err != nil {
+			return nil, nil, err
+		}
+		changingInfrastructure(ctx)
 		// TODO[pulumi/pulumi-terraform-module#341] reuse the plan
-		tfState, err := tf.Apply(ctx, logger, tfsandbox.RefreshOpts{
-			NoRefresh: true, // we already refreshed before this point
+		tfState, err := applyWithRetry(ctx, logger, opts.applyRetry, func() (*tfsandbox.State, error) {
+			return tf.Apply(ctx, logger, tfsandbox.RefreshOpts{
+				NoRefresh:      !opts.refreshOnly, // we already refreshed before this point
+				RefreshOnly:    opts.refreshOnly,
+				DestroyTargets: destroyTargets,
+			})
 		})
 		if tfState != nil {
 			msg := fmt.Sprintf("tf.Apply produced the following state: %s", tfState.PrettyPrint())
 			logger.Log(ctx, tfsandbox.Debug, msg)
+			logger.Log(ctx, tfsandbox.Debug, "Dependency graph of the module resources:\n"+tfState.DependencyGraph().DOT())
 		}
 
 		// the error is unrecoverable if tf.Apply() returned a nil state also
@@ -366,11 +629,57 @@ func (h *moduleHandler) applyModuleOperation(
 			applyErr = err
 		}
 
-		views = viewStepsAfterApply(packageName, plan, tfState)
+		tfState = handleTaintedResources(ctx, tf, logger, opts.taintedResources, tfState)
+
+		var resourceErrors map[tfsandbox.ResourceAddress][]string
+		var tfApplyErr *tfsandbox.ApplyError
+		if errors.As(applyErr, &tfApplyErr) {
+			resourceErrors = tfApplyErr.ResourceErrors
+		}
+
+		if opts.refreshOnly {
+			views = viewStepsAfterRefresh(packageName, plan, tfState)
+		} else {
+			views = viewStepsAfterApply(packageName, plan, tfState, resourceErrors)
+		}
+		if opts.childResourceDiffs {
+			detailViewSteps(plan, views)
+		}
 		moduleOutputs, err = h.outputs(ctx, tf, tfState, moduleVersion)
 		if err != nil {
 			return nil, nil, err
 		}
+		if oldOutputs != nil && applyErr == nil && !planHasChanges(plan) {
+			h.keepUnchangedState(moduleOutputs, oldOutputs)
+		}
+		// refresh-only and targeted applies leave the other resources as they were configured before
+		recordProvidersConfig(moduleOutputs, oldOutputs, providersConfig, opts,
+			applyErr == nil && !opts.refreshOnly && len(destroyTargets) == 0)
+		maps.Copy(moduleOutputs,
+			childResourceOutputsFromState(inferredModule, moduleInstanceName(urn, opts.moduleNaming), tfState))
+		dataSourceViews, read := viewStepsForDataSources(packageName, opts.dataSourceViews,
+			publishedDataSources(oldOutputs), tfState.VisitDataSources)
+		views = append(views, dataSourceViews...)
+		recordDataSources(moduleOutputs, read)
+		// The changes are applied at this point, so the state is kept even when it is too large.
+		if err := checkStateSize(ctx, logger, moduleOutputs, opts.stateSizeLimits); err != nil && applyErr == nil {
+			applyErr = err
+		}
+		// A module that does not become ready is reported like a partial failure, keeping the applied changes.
+		if applyErr == nil && !opts.refreshOnly {
+			applyErr = waitUntilReady(ctx, logger, opts.readiness, tfState.Outputs())
+		}
+	}
+
+	recordChildResourceTypes(moduleOutputs, opts.childResourceTypes)
+
+	if inferredModule != nil && inferredModule.operationSummary {
+		operation := operationSummaryApply
+		if preview {
+			operation = operationSummaryPlan
+		}
+		moduleOutputs[operationSummaryOutputName] = summarizeOperation(operation, plan, time.Since(started),
+			applyErr).value()
 	}
 
 	if applyErr != nil {
@@ -378,23 +687,266 @@ func (h *moduleHandler) applyModuleOperation(
 		applyErr = h.initializationError(moduleOutputs, applyErr.Error())
 	}
 
-	hasOutputFieldMappings := inferredModule != nil &&
+	moduleOutputs = outputsToPulumi(inferredModule, moduleOutputs)
+	moduleOutputs = typeUnknownOutputs(inferredModule, moduleOutputs)
+	if !preview {
+		warnOnNullNonNilOutputs(ctx, logger, inferredModule, moduleOutputs)
+	}
+	moduleOutputs = omitEmptyOutputs(inferredModule, moduleOutputs)
+	if opts.secretsAudit {
+		reportSecretsAudit(ctx, logger, moduleInputs, moduleOutputs)
+	}
+
+	return moduleOutputs, views, applyErr
+}
+
+// refreshOnlyInputChangesError is the error for changes to the inputs of a module while the refreshOnly provider
+// option is set, as they would be recorded in the Pulumi state without being applied.
+func refreshOnlyInputChangesError(urn urn.URN) error {
+	return fmt.Errorf("the inputs of module %s changed, but the provider is configured with %s: true and does not "+
+		"apply them; revert the changes or unset %s", urn.Name(), refreshOnlyVariableName, refreshOnlyVariableName)
+}
+
+// planUpdate plans the changes an update applies: the program, or the destruction of the targeted resources when the
+// destroyTargets provider option names resources of the module instance.
+func planUpdate(
+	ctx context.Context,
+	tf *tfsandbox.ModuleRuntime,
+	logger tfsandbox.Logger,
+	destroyTargets []string,
+) (*tfsandbox.Plan, error) {
+	if len(destroyTargets) > 0 {
+		return tf.PlanDestroyTargets(ctx, logger, destroyTargets)
+	}
+	return tf.PlanNoRefresh(ctx, logger)
+}
+
+// destroyTargetsOf selects the addresses of the destroyTargets provider option that belong to the module instance.
+// The option applies to every module instance of the provider, and instances it does not target are updated as usual.
+func (o moduleOptions) destroyTargetsOf(urn urn.URN) []string {
+	block := "module." + moduleInstanceName(urn, o.moduleNaming)
+	var targets []string
+	for _, target := range o.destroyTargets {
+		rest, ok := strings.CutPrefix(target, block)
+		if ok && (rest == "" || strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[")) {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// destroyTargetsInputChangesError is the error for changes to the inputs of a module while the destroyTargets
+// provider option targets its resources, as they would be recorded in the Pulumi state without being applied.
+func destroyTargetsInputChangesError(urn urn.URN) error {
+	return fmt.Errorf("the inputs of module %s changed, but the provider is configured with %s targeting its "+
+		"resources and does not apply them; revert the changes or unset %s", urn.Name(), destroyTargetsVariableName,
+		destroyTargetsVariableName)
+}
+
+// refusedInReadOnlyMode is the error for operations that the readOnly provider option forbids.
+func refusedInReadOnlyMode(operation string, urn urn.URN) error {
+	return fmt.Errorf("refusing to %s module %s: the provider is configured with %s: true", operation, urn.Name(),
+		readOnlyVariableName)
+}
+
+// warnOnNullNonNilOutputs reports outputs that evaluated to null even though the schema declares them as non-nil,
+// typically through a nonNilOutputs override. Such outputs are passed through as null values; SDKs that rely on the
+// declared type may not be able to handle them.
+func warnOnNullNonNilOutputs(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	inferredModule *InferredModuleSchema,
+	moduleOutputs resource.PropertyMap,
+) {
+	if inferredModule == nil {
+		return
+	}
+	for _, key := range inferredModule.NonNilOutputs {
+		if v, ok := moduleOutputs[key]; ok && v.IsNull() {
+			logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf(
+				"module output %q is declared as non-nil but the module returned null", key))
+		}
+	}
+}
+
+// omitEmptyOutputs removes the outputs that are null, empty strings or empty collections when the module is configured
+// with omitEmptyOutputs. The meta-properties persisting the module state and the outputs declared as non-nil are kept.
+func omitEmptyOutputs(inferredModule *InferredModuleSchema, moduleOutputs resource.PropertyMap) resource.PropertyMap {
+	if inferredModule == nil || !inferredModule.omitEmptyOutputs {
+		return moduleOutputs
+	}
+	kept := resource.PropertyMap{}
+	for key, v := range moduleOutputs {
+		isMeta := key == moduleResourceStatePropName || key == moduleResourceLockPropName ||
+			key == moduleResourceVersionPropName || key == moduleResourceDataSourcesPropName ||
+			key == moduleResourceChildResourceTypesPropName || key == moduleResourceProvidersConfigPropName
+		if isMeta || slices.Contains(inferredModule.NonNilOutputs, key) || !isEmptyOutput(v) {
+			kept[key] = v
+		}
+	}
+	return kept
+}
+
+func isEmptyOutput(v resource.PropertyValue) bool {
+	if v.IsSecret() {
+		return isEmptyOutput(v.SecretValue().Element)
+	}
+	switch {
+	case v.IsNull():
+		return true
+	case v.IsString():
+		return v.StringValue() == ""
+	case v.IsArray():
+		return len(v.ArrayValue()) == 0
+	case v.IsObject():
+		return len(v.ObjectValue()) == 0
+	}
+	return false
+}
+
+// tfOutputSpecs lists the Terraform outputs of the module that need to be exposed from the generated TF file. The
+// outputs that the provider adds to the module schema, such as operationSummary and the attributes of child resources
+// requested with childOutputs, are not declared by the module.
+func tfOutputSpecs(inferredModule *InferredModuleSchema) []tfsandbox.TFOutputSpec {
+	hasOutputFieldMapping := inferredModule != nil &&
 		inferredModule.SchemaFieldMappings != nil &&
 		inferredModule.SchemaFieldMappings.OutputFieldMappings != nil
 
-	if hasOutputFieldMappings {
-		mappings := inferredModule.SchemaFieldMappings.OutputFieldMappings
-		for tfName, output := range moduleOutputs {
-			for pulumiOutputName, mappedTerraformName := range mappings {
-				if tfName == mappedTerraformName {
-					moduleOutputs[pulumiOutputName] = output
-					delete(moduleOutputs, tfName)
-				}
+	outputSpecs := []tfsandbox.TFOutputSpec{}
+	if inferredModule == nil {
+		return outputSpecs
+	}
+
+	for outputName := range inferredModule.Outputs {
+		if inferredModule.operationSummary && outputName == operationSummaryOutputName {
+			continue
+		}
+		if _, ok := inferredModule.childResourceOutputs[outputName]; ok {
+			continue
+		}
+		if hasOutputFieldMapping {
+			mappings := inferredModule.SchemaFieldMappings.OutputFieldMappings
+			if tfName, ok := mappings[outputName]; ok {
+				outputName = tfName
 			}
 		}
+
+		outputSpecs = append(outputSpecs, tfsandbox.TFOutputSpec{
+			Name: tfsandbox.DecodePulumiTopLevelKey(outputName),
+		})
+	}
+	return outputSpecs
+}
+
+// omitNullInputs removes the inputs set to null when the module is configured with omitNullInputs. Inputs that are not
+// set are never passed to the module, which lets its defaults apply. Terraform however assigns null to nullable
+// variables that are explicitly passed null, overriding their defaults, so by default nulls are passed through. Inputs
+// whose variables default to null are the exception: they are left out when null, as when they are not set.
+func omitNullInputs(inferredModule *InferredModuleSchema, moduleInputs resource.PropertyMap) resource.PropertyMap {
+	if inferredModule == nil || (!inferredModule.omitNullInputs && len(inferredModule.nullDefaultInputs) == 0) {
+		return moduleInputs
+	}
+	kept := resource.PropertyMap{}
+	for k, v := range moduleInputs {
+		if v.IsNull() && (inferredModule.omitNullInputs || slices.Contains(inferredModule.nullDefaultInputs, k)) {
+			continue
+		}
+		kept[k] = v
 	}
+	return kept
+}
 
-	return moduleOutputs, views, applyErr
+// inputsToTerraform renames module inputs from their Pulumi names to the names of the Terraform variables.
+func inputsToTerraform(inferredModule *InferredModuleSchema, moduleInputs resource.PropertyMap) resource.PropertyMap {
+	hasInputFieldMappings := inferredModule != nil &&
+		inferredModule.SchemaFieldMappings != nil &&
+		inferredModule.SchemaFieldMappings.InputFieldMappings != nil
+
+	if !hasInputFieldMappings {
+		return moduleInputs
+	}
+
+	mappings := inferredModule.SchemaFieldMappings.InputFieldMappings
+	remapped := resource.PropertyMap{}
+	for pulumiInputName, input := range moduleInputs {
+		if tfName, ok := mappings[pulumiInputName]; ok {
+			// if the input is mapped, use the mapped name
+			remapped[tfName] = input
+			continue
+		}
+		remapped[pulumiInputName] = input
+	}
+	return remapped
+}
+
+// outputsToPulumi renames module outputs from the names of the Terraform outputs to their Pulumi names.
+func outputsToPulumi(inferredModule *InferredModuleSchema, moduleOutputs resource.PropertyMap) resource.PropertyMap {
+	hasOutputFieldMappings := inferredModule != nil &&
+		inferredModule.SchemaFieldMappings != nil &&
+		inferredModule.SchemaFieldMappings.OutputFieldMappings != nil
+
+	if !hasOutputFieldMappings {
+		return moduleOutputs
+	}
+
+	pulumiNames := map[resource.PropertyKey]resource.PropertyKey{}
+	for pulumiOutputName, mappedTerraformName := range inferredModule.SchemaFieldMappings.OutputFieldMappings {
+		pulumiNames[tfsandbox.PulumiTopLevelKey(string(mappedTerraformName))] = pulumiOutputName
+	}
+
+	remapped := resource.PropertyMap{}
+	for tfName, output := range moduleOutputs {
+		if pulumiOutputName, ok := pulumiNames[tfName]; ok {
+			remapped[pulumiOutputName] = output
+			continue
+		}
+		remapped[tfName] = output
+	}
+	return remapped
+}
+
+// typeUnknownOutputs gives the unknown outputs of a planned module instance the type inferred for them, such that an
+// output computed by a provider during the apply, such as the ID of a resource to be created, is reported as an unknown
+// list, map or object rather than an unknown string. Outputs inferred as Any keep the shape Terraform planned for them.
+func typeUnknownOutputs(inferredModule *InferredModuleSchema, moduleOutputs resource.PropertyMap) resource.PropertyMap {
+	if inferredModule == nil {
+		return moduleOutputs
+	}
+	for key, output := range moduleOutputs {
+		if !output.IsComputed() {
+			continue
+		}
+		spec, ok := inferredModule.Outputs[key]
+		if !ok || spec == nil {
+			continue
+		}
+		if element, ok := zeroValueOfType(spec.TypeSpec); ok {
+			moduleOutputs[key] = resource.MakeComputed(element)
+		}
+	}
+	return moduleOutputs
+}
+
+// zeroValueOfType returns a value of the given schema type to use as the element of a typed unknown, or false for
+// types that do not determine the kind of value, such as Any.
+func zeroValueOfType(t schema.TypeSpec) (resource.PropertyValue, bool) {
+	if strings.HasPrefix(t.Ref, "#/types/") {
+		return resource.NewObjectProperty(resource.PropertyMap{}), true
+	}
+	switch t.Type {
+	case "array":
+		return resource.NewArrayProperty([]resource.PropertyValue{}), true
+	case objectTypeName:
+		return resource.NewObjectProperty(resource.PropertyMap{}), true
+	case "boolean":
+		return resource.NewBoolProperty(false), true
+	case "number", "integer":
+		return resource.NewNumberProperty(0), true
+	case stringTypeName:
+		return resource.NewStringProperty(""), true
+	default:
+		return resource.PropertyValue{}, false
+	}
 }
 
 func (h *moduleHandler) initializationError(outputs resource.PropertyMap, reasons ...string) error {
@@ -419,18 +971,64 @@ func (h *moduleHandler) outputs(
 	tfState *tfsandbox.State,
 	moduleVersion TFModuleVersion,
 ) (resource.PropertyMap, error) {
-	rawState, rawLockFile, err := tf.PullStateAndLockFile(ctx)
+	// The state records the changes Terraform carried out, it is read back even if the operation was cancelled.
+	rawState, rawLockFile, err := tf.PullStateAndLockFile(context.WithoutCancel(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("PullStateAndLockFile failed: %w", err)
 	}
 
+	return moduleOutputsWithState(tfState, rawState, rawLockFile, moduleVersion), nil
+}
+
+// moduleOutputsWithState combines the module outputs with the meta properties that persist the module state.
+//
+// Secrecy is tracked per output: only the outputs that Terraform considers sensitive are marked secret. The raw state
+// is always secret since it may embed sensitive values, but that does not extend to the non-sensitive outputs.
+func moduleOutputsWithState(
+	tfState *tfsandbox.State,
+	rawState []byte,
+	rawLockFile []byte,
+	moduleVersion TFModuleVersion,
+) resource.PropertyMap {
 	moduleOutputs := tfState.Outputs()
 	stateProp := resource.MakeSecret(resource.NewStringProperty(string(rawState)))
 	lockProp := resource.NewStringProperty(string(rawLockFile))
 	moduleOutputs[moduleResourceStatePropName] = stateProp
 	moduleOutputs[moduleResourceLockPropName] = lockProp
 	moduleOutputs[moduleResourceVersionPropName] = resource.NewStringProperty(string(moduleVersion))
-	return moduleOutputs, nil
+	return moduleOutputs
+}
+
+// keepUnchangedState records the previous Terraform state and lock file of a module instance instead of the ones
+// pulled after an apply that changed nothing. Pulling the state serializes it again, so that the bytes may differ,
+// for example in the version of the executor that wrote it, and every no-op update would otherwise rewrite the state.
+func (h *moduleHandler) keepUnchangedState(moduleOutputs, oldOutputs resource.PropertyMap) {
+	oldState, oldLockFile, _, err := h.getState(oldOutputs)
+	if err != nil || oldState == nil {
+		return
+	}
+	newState, newLockFile, _, err := h.getState(moduleOutputs)
+	if err != nil {
+		return
+	}
+	if equivalentStates(oldState, newState) {
+		moduleOutputs[moduleResourceStatePropName] = resource.MakeSecret(resource.NewStringProperty(string(oldState)))
+	}
+	if bytes.Equal(bytes.TrimSpace(oldLockFile), bytes.TrimSpace(newLockFile)) {
+		moduleOutputs[moduleResourceLockPropName] = resource.NewStringProperty(string(oldLockFile))
+	}
+}
+
+// equivalentStates reports whether two raw Terraform states hold the same resources and outputs, disregarding the
+// version of the executor that wrote them.
+func equivalentStates(a, b []byte) bool {
+	var stateA, stateB map[string]any
+	if json.Unmarshal(a, &stateA) != nil || json.Unmarshal(b, &stateB) != nil {
+		return false
+	}
+	delete(stateA, "terraform_version")
+	delete(stateB, "terraform_version")
+	return reflect.DeepEqual(stateA, stateB)
 }
 
 func (h *moduleHandler) Create(
@@ -441,10 +1039,13 @@ func (h *moduleHandler) Create(
 	providersConfig map[string]resource.PropertyMap,
 	inferredModule *InferredModuleSchema,
 	packageName packageName,
-	executor string,
-) (*pulumirpc.CreateResponse, error) {
+	opts moduleOptions,
+) (_ *pulumirpc.CreateResponse, err error) {
 	urn := urn.URN(req.GetUrn())
 	logger := newResourceLogger(h.hc, urn)
+	defer func() { cleanupWorkdir(ctx, logger, urn, opts, err) }()
+	ctx, explainInteractiveAuth := guardInteractiveAuth(ctx, opts.interactiveAuth)
+	defer func() { err = explainInteractiveAuth(err) }()
 
 	statusClient, err := h.statusPool.Acquire(ctx, logger, req.ResourceStatusAddress)
 	if err != nil {
@@ -470,15 +1071,14 @@ func (h *moduleHandler) Create(
 		inferredModule,
 		packageName,
 		req.GetPreview(),
-		executor,
+		opts,
 	)
 
 	// Publish views even if applyErr != nil as is the case of partial failures.
 	if views != nil {
-		_, err = statusClient.PublishViewSteps(ctx, &pulumirpc.PublishViewStepsRequest{
-			Token: req.ResourceStatusToken,
-			Steps: views,
-		})
+		err = publishViewSteps(ctx, statusClient, req.ResourceStatusToken,
+			groupViewSteps(packageName, inferredModule,
+				pulumiViewTypes(views, childResourceTypesTerraform, opts.childResourceTypes)), opts.viewStepsBatchSize)
 		if err != nil {
 			logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error publishing view steps after Create: %v", err))
 			return nil, err
@@ -507,10 +1107,13 @@ func (h *moduleHandler) Update(
 	providersConfig map[string]resource.PropertyMap,
 	inferredModule *InferredModuleSchema,
 	packageName packageName,
-	executor string,
-) (*pulumirpc.UpdateResponse, error) {
+	opts moduleOptions,
+) (_ *pulumirpc.UpdateResponse, err error) {
 	urn := urn.URN(req.GetUrn())
 	logger := newResourceLogger(h.hc, urn)
+	defer func() { cleanupWorkdir(ctx, logger, urn, opts, err) }()
+	ctx, explainInteractiveAuth := guardInteractiveAuth(ctx, opts.interactiveAuth)
+	defer func() { err = explainInteractiveAuth(err) }()
 
 	moduleInputs, err := plugin.UnmarshalProperties(req.GetNews(), h.marshalOpts())
 	if err != nil {
@@ -541,15 +1144,15 @@ func (h *moduleHandler) Update(
 		inferredModule,
 		packageName,
 		req.GetPreview(),
-		executor,
+		opts,
 	)
 
 	// Publish views even if applyErr != nil as is the case of partial failures.
 	if views != nil {
-		_, err = statusClient.PublishViewSteps(ctx, &pulumirpc.PublishViewStepsRequest{
-			Token: req.ResourceStatusToken,
-			Steps: views,
-		})
+		err = publishViewSteps(ctx, statusClient, req.ResourceStatusToken,
+			groupViewSteps(packageName, inferredModule,
+				pulumiViewTypes(views, publishedChildResourceTypes(oldOutputs), opts.childResourceTypes)),
+			opts.viewStepsBatchSize)
 		if err != nil {
 			logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error publishing view steps after Update: %v", err))
 			return nil, err
@@ -578,16 +1181,13 @@ func (h *moduleHandler) Delete(
 	moduleVersion TFModuleVersion,
 	inferredModule *InferredModuleSchema,
 	providersConfig map[string]resource.PropertyMap,
-	executor string,
-) (*emptypb.Empty, error) {
+	opts moduleOptions,
+) (_ *emptypb.Empty, err error) {
 	urn := urn.URN(req.GetUrn())
 	logger := newResourceLogger(h.hc, resource.URN(req.GetUrn()))
-
-	statusClient, err := h.statusPool.Acquire(ctx, logger, req.ResourceStatusAddress)
-	if err != nil {
-		return nil, fmt.Errorf("acquiring status client failed in Delete: %w", err)
-	}
-	defer statusClient.Release()
+	defer func() { cleanupWorkdir(ctx, logger, urn, opts, err) }()
+	ctx, explainInteractiveAuth := guardInteractiveAuth(ctx, opts.interactiveAuth)
+	defer func() { err = explainInteractiveAuth(err) }()
 
 	moduleInputs, err := plugin.UnmarshalProperties(req.GetOldInputs(), h.marshalOpts())
 	if err != nil {
@@ -599,6 +1199,39 @@ func (h *moduleHandler) Delete(
 		return nil, fmt.Errorf("Delete failed to unmarshal old outputs: %s", err)
 	}
 
+	if opts.readOnly {
+		return nil, refusedInReadOnlyMode("destroy", urn)
+	}
+
+	rawState, _, _, stateErr := h.getState(oldOutputs)
+	// The module instance has an empty state, as when it was imported or its state edited by hand.
+	noState := stateErr == nil && rawState == nil
+	if noState {
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Module %s has no Terraform state, there is nothing to destroy",
+			urn.Name()))
+		if len(publishedDataSources(oldOutputs)) == 0 {
+			return &emptypb.Empty{}, nil
+		}
+	}
+
+	statusClient, err := h.statusPool.Acquire(ctx, logger, req.ResourceStatusAddress)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring status client failed in Delete: %w", err)
+	}
+	defer statusClient.Release()
+
+	if noState {
+		// the data source views published by earlier operations are still deleted
+		dataSourceViews, _ := viewStepsForDataSources(packageName, false, publishedDataSources(oldOutputs), nil)
+		err := publishViewSteps(ctx, statusClient, req.ResourceStatusToken,
+			groupViewSteps(packageName, inferredModule, dataSourceViews), opts.viewStepsBatchSize)
+		if err != nil {
+			logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error publishing view steps after delete: %v", err))
+			return &emptypb.Empty{}, err
+		}
+		return &emptypb.Empty{}, nil
+	}
+
 	tf, err := h.prepSandbox(
 		ctx,
 		urn,
@@ -608,7 +1241,7 @@ func (h *moduleHandler) Delete(
 		moduleSource,
 		moduleVersion,
 		providersConfig,
-		executor,
+		opts,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed preparing sandbox: %w", err)
@@ -624,21 +1257,32 @@ func (h *moduleHandler) Delete(
 		return &emptypb.Empty{}, err
 	}
 
+	err = h.backupModuleState(ctx, logger, opts.stateBackupDir, urn, oldOutputs, stateBackupDestroy)
+	if err != nil {
+		return &emptypb.Empty{}, err
+	}
+
+	changingInfrastructure(ctx)
 	destroyErr := tf.Destroy(ctx, logger)
 	if destroyErr != nil {
 		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error running tofu destroy in delete: %v", destroyErr))
 	}
 
-	stateAfterDestroy, err := tf.Show(ctx, logger)
+	// The resources destroyed so far are recorded even if the operation was cancelled meanwhile.
+	stateAfterDestroy, err := tf.Show(context.WithoutCancel(ctx), logger)
 	if err != nil {
 		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error running tofu show after delete: %v", err))
 		return &emptypb.Empty{}, err
 	}
 
-	_, err = statusClient.PublishViewSteps(ctx, &pulumirpc.PublishViewStepsRequest{
-		Token: req.ResourceStatusToken,
-		Steps: viewStepsAfterDestroy(packageName, stateBeforeDestroy, stateAfterDestroy),
-	})
+	viewSteps := viewStepsAfterDestroy(packageName, stateBeforeDestroy, stateAfterDestroy)
+	dataSourceViews, _ := viewStepsForDataSources(packageName, false, publishedDataSources(oldOutputs), nil)
+	viewSteps = append(viewSteps, dataSourceViews...)
+
+	err = publishViewSteps(ctx, statusClient, req.ResourceStatusToken,
+		groupViewSteps(packageName, inferredModule,
+			pulumiViewTypes(viewSteps, publishedChildResourceTypes(oldOutputs), opts.childResourceTypes)),
+		opts.viewStepsBatchSize)
 	if err != nil {
 		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error publishing view steps after delete: %v", err))
 		return &emptypb.Empty{}, err
@@ -656,14 +1300,17 @@ func (h *moduleHandler) Read(
 	moduleVersion TFModuleVersion,
 	inferredModule *InferredModuleSchema,
 	providersConfig map[string]resource.PropertyMap,
-	executor string,
-) (*pulumirpc.ReadResponse, error) {
+	opts moduleOptions,
+) (_ *pulumirpc.ReadResponse, err error) {
 	if req.Inputs == nil {
 		return nil, fmt.Errorf("Read() is currently only supported for pulumi refresh")
 	}
 
 	logger := newResourceLogger(h.hc, resource.URN(req.GetUrn()))
 	urn := urn.URN(req.GetUrn())
+	defer func() { cleanupWorkdir(ctx, logger, urn, opts, err) }()
+	ctx, explainInteractiveAuth := guardInteractiveAuth(ctx, opts.interactiveAuth)
+	defer func() { err = explainInteractiveAuth(err) }()
 
 	statusClient, err := h.statusPool.Acquire(ctx, logger, req.ResourceStatusAddress)
 	if err != nil {
@@ -690,37 +1337,82 @@ func (h *moduleHandler) Read(
 		moduleSource,
 		moduleVersion,
 		providersConfig,
-		executor,
+		opts,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed preparing tofu sandbox: %w", err)
 	}
 
-	plan, err := tf.PlanRefreshOnly(ctx, logger)
-	if err != nil {
-		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error planning refresh: %v", err))
-		return nil, err
+	var plan *tfsandbox.Plan
+	var state *tfsandbox.State
+	// drift can only be detected by a full refresh
+	if opts.refreshMode == refreshModeOutputs && !opts.failOnDrift {
+		plan, state, err = refreshOutputs(ctx, tf, logger)
+		if err != nil {
+			return nil, err
+		}
 	}
+	outputsFromPlan := state != nil
 
-	state, err := tf.Refresh(ctx, logger)
-	if err != nil {
-		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error running refresh: %v", err))
-		return nil, fmt.Errorf("module refresh failed: %w", err)
+	if state == nil {
+		plan, err = tf.PlanRefreshOnly(ctx, logger)
+		if err != nil {
+			logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error planning refresh: %v", err))
+			return nil, err
+		}
+
+		if opts.failOnDrift {
+			if err := driftError(urn, plan); err != nil {
+				return nil, err
+			}
+		}
+
+		state, err = tf.Refresh(ctx, logger)
+		if err != nil {
+			logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error running refresh: %v", err))
+			return nil, fmt.Errorf("module refresh failed: %w", err)
+		}
 	}
 
 	outputs, err := h.outputs(ctx, tf, state, moduleVersion)
 	if err != nil {
 		return nil, err
 	}
+	if outputsFromPlan {
+		withPlannedOutputs(outputs, state, plan)
+	}
+	// refreshes do not apply the provider configuration
+	recordProvidersConfig(outputs, oldOutputs, providersConfig, opts, false)
+	maps.Copy(outputs, childResourceOutputsFromState(inferredModule, moduleInstanceName(urn, opts.moduleNaming), state))
+	// the data sources are read again by the plan refreshing the outputs, while the state holds them as last applied
+	visitDataSources := state.VisitDataSources
+	if outputsFromPlan {
+		visitDataSources = plan.VisitDataSources
+	}
+	dataSourceViews, read := viewStepsForDataSources(packageName, opts.dataSourceViews,
+		publishedDataSources(oldOutputs), visitDataSources)
+	recordDataSources(outputs, read)
+	recordChildResourceTypes(outputs, opts.childResourceTypes)
+	if err := checkStateSize(ctx, logger, outputs, opts.stateSizeLimits); err != nil {
+		return nil, err
+	}
+	outputs = outputsToPulumi(inferredModule, outputs)
+	if summary, ok := oldOutputs[operationSummaryOutputName]; ok && inferredModule != nil &&
+		inferredModule.operationSummary {
+		// refreshes are not summarized, the summary of the last plan or apply stays
+		outputs[operationSummaryOutputName] = summary
+	}
+	warnOnNullNonNilOutputs(ctx, logger, inferredModule, outputs)
+	outputs = omitEmptyOutputs(inferredModule, outputs)
 
-	viewSteps := viewStepsAfterRefresh(packageName, plan, state)
+	viewSteps := append(viewStepsAfterRefresh(packageName, plan, state), dataSourceViews...)
 
 	//q.Q("REFRESH viewSteps", viewSteps)
 
-	_, err = statusClient.PublishViewSteps(ctx, &pulumirpc.PublishViewStepsRequest{
-		Token: req.ResourceStatusToken,
-		Steps: viewSteps,
-	})
+	err = publishViewSteps(ctx, statusClient, req.ResourceStatusToken,
+		groupViewSteps(packageName, inferredModule,
+			pulumiViewTypes(viewSteps, publishedChildResourceTypes(oldOutputs), opts.childResourceTypes)),
+		opts.viewStepsBatchSize)
 	if err != nil {
 		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("error publishing view steps after refresh: %v", err))
 		return nil, err
@@ -747,41 +1439,133 @@ func (h *moduleHandler) Read(
 	}, nil
 }
 
+// deployedWith checks if the module instance state recorded in oldOutputs was produced by moduleVersion with
+// providersConfig and the options of opts that shape the generated Terraform file. Instances deployed before the
+// provider configuration was recorded do not qualify.
+func (h *moduleHandler) deployedWith(
+	oldOutputs resource.PropertyMap,
+	moduleVersion TFModuleVersion,
+	providersConfig map[string]resource.PropertyMap,
+	opts moduleOptions,
+) bool {
+	if _, hasState := oldOutputs[moduleResourceStatePropName]; !hasState {
+		return false
+	}
+	_, _, recordedVersion, err := h.getState(oldOutputs)
+	if err != nil || recordedVersion != moduleVersion {
+		return false
+	}
+	for _, config := range providersConfig {
+		if resource.NewObjectProperty(config).ContainsUnknowns() {
+			return false
+		}
+	}
+	recorded, ok := oldOutputs[moduleResourceProvidersConfigPropName]
+	for recorded.IsSecret() {
+		recorded = recorded.SecretValue().Element
+	}
+	return ok && recorded.IsString() && recorded.StringValue() == providersConfigDigest(providersConfig, opts)
+}
+
+// recordProvidersConfig records the digest of providersConfig and opts in the outputs of a module instance once it
+// was applied with them. Otherwise, as after a failed apply, the digest of the last successful deployment is kept, if
+// any.
+func recordProvidersConfig(
+	outputs resource.PropertyMap,
+	oldOutputs resource.PropertyMap,
+	providersConfig map[string]resource.PropertyMap,
+	opts moduleOptions,
+	applied bool,
+) {
+	if !applied {
+		if recorded, ok := oldOutputs[moduleResourceProvidersConfigPropName]; ok {
+			outputs[moduleResourceProvidersConfigPropName] = recorded
+		}
+		return
+	}
+	// the configuration may hold credentials, which should not be guessable from their digest in the Pulumi state
+	outputs[moduleResourceProvidersConfigPropName] = resource.MakeSecret(
+		resource.NewStringProperty(providersConfigDigest(providersConfig, opts)))
+}
+
+// providersConfigDigest returns a SHA-256 digest of the configuration of the providers of a module instance, secret
+// values included, and of the options that change the generated Terraform file besides the module inputs. Options
+// left unset are not part of the digest, so the digests recorded before they existed still match.
+func providersConfigDigest(providersConfig map[string]resource.PropertyMap, opts moduleOptions) string {
+	config := map[string]any{}
+	for name, c := range providersConfig {
+		config[name] = c.MapRepl(nil, tfsandbox.UnwrapSecrets)
+	}
+	fileOptions := map[string]any{}
+	if opts.pulumiDefaultTags {
+		fileOptions[pulumiDefaultTagsVariableName] = true
+	}
+	if len(opts.providerMeta) > 0 {
+		providerMeta := map[string]any{}
+		for name, meta := range opts.providerMeta {
+			providerMeta[name] = meta.MapRepl(nil, tfsandbox.UnwrapSecrets)
+		}
+		fileOptions[providerMetaVariableName] = providerMeta
+	}
+	if len(opts.extraTerraformFiles) > 0 {
+		fileOptions[extraTerraformFilesVariableName] = opts.extraTerraformFiles
+	}
+	if len(opts.moduleDependsOn) > 0 {
+		fileOptions[moduleDependsOnVariableName] = opts.moduleDependsOn
+	}
+	var digested any = config
+	if len(fileOptions) > 0 {
+		digested = map[string]any{"providers": config, "options": fileOptions}
+	}
+	// maps are marshaled with sorted keys, so the digest does not depend on the order of the configuration
+	contents, err := json.Marshal(digested)
+	contract.AssertNoErrorf(err, "provider configuration must marshal to JSON")
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// getState reads the Terraform state, lock file and module version recorded in the outputs of a module instance. The
+// state is empty when the outputs do not record one. Properties that do not hold strings, as may result from editing
+// the Pulumi state by hand, are reported as errors.
 func (h *moduleHandler) getState(props resource.PropertyMap) (
 	rawState []byte,
 	rawLockFile []byte,
 	moduleVersion tfsandbox.TFModuleVersion,
+	err error,
 ) {
-	state, ok := props[moduleResourceStatePropName]
-	if !ok {
-		return rawState, rawLockFile, moduleVersion // empty
+	stringProp := func(key resource.PropertyKey) (string, bool, error) {
+		v, ok := props[key]
+		if !ok {
+			return "", false, nil
+		}
+		for v.IsSecret() {
+			v = v.SecretValue().Element
+		}
+		if !v.IsString() {
+			return "", false, fmt.Errorf("the %q property must be a string, got %s", key, v.TypeString())
+		}
+		return v.StringValue(), true, nil
 	}
 
-	for state.IsSecret() {
-		state = state.SecretValue().Element
+	state, ok, err := stringProp(moduleResourceStatePropName)
+	if err != nil || !ok {
+		return nil, nil, "", err
 	}
+	rawState = []byte(state)
 
-	contract.Assertf(state.IsString(), "Expected %q to carry a String PropertyValue", moduleResourceStatePropName)
-
-	stateString := state.StringValue()
-	rawState = []byte(stateString)
-	if lock, ok := props[moduleResourceLockPropName]; ok {
-		for lock.IsSecret() {
-			lock = lock.SecretValue().Element
-		}
-		contract.Assertf(lock.IsString(), "Expected %q to carry a String PropertyValue",
-			moduleResourceLockPropName)
-		lockString := lock.StringValue()
-		rawLockFile = []byte(lockString)
+	lock, ok, err := stringProp(moduleResourceLockPropName)
+	if err != nil {
+		return nil, nil, "", err
 	}
-	if version, ok := props[moduleResourceVersionPropName]; ok {
-		for version.IsSecret() {
-			version = version.SecretValue().Element
-		}
-		contract.Assertf(version.IsString(), "Expected %q to carry a String PropertyValue", moduleResourceVersionPropName)
-		moduleVersion = tfsandbox.TFModuleVersion(version.StringValue())
+	if ok {
+		rawLockFile = []byte(lock)
+	}
+
+	version, _, err := stringProp(moduleResourceVersionPropName)
+	if err != nil {
+		return nil, nil, "", err
 	}
-	return rawState, rawLockFile, moduleVersion
+	return rawState, rawLockFile, tfsandbox.TFModuleVersion(version), nil
 }
 
 func versionOrUnknown(v tfsandbox.TFModuleVersion) string {
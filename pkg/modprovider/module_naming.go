@@ -0,0 +1,101 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+// moduleNamingStrategy selects how the name of the Terraform module block of a module instance is derived from the
+// name of the Pulumi resource. The name is part of the address of every child resource in the Terraform state, so it
+// must be stable across operations.
+type moduleNamingStrategy string
+
+const (
+	// moduleNamingResourceName uses the resource name as is. This is the default.
+	moduleNamingResourceName moduleNamingStrategy = "resource-name"
+	// moduleNamingSanitized keeps resource names that are valid Terraform identifiers and otherwise replaces the
+	// invalid characters and truncates the name, adding a hash of the resource name to keep it unique.
+	moduleNamingSanitized moduleNamingStrategy = "sanitized"
+	// moduleNamingHashed uses a hash of the resource name.
+	moduleNamingHashed moduleNamingStrategy = "hashed"
+)
+
+// maxSanitizedModuleNameLength bounds the part of a sanitized module name taken from the resource name. Terraform
+// creates directories named after module blocks, so very long names may exceed filesystem limits.
+const maxSanitizedModuleNameLength = 64
+
+func parseModuleNamingStrategy(s string) (moduleNamingStrategy, error) {
+	switch n := moduleNamingStrategy(s); n {
+	case "":
+		return moduleNamingResourceName, nil
+	case moduleNamingResourceName, moduleNamingSanitized, moduleNamingHashed:
+		return n, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q, %q or %q, got %q", moduleNamingVariableName,
+			moduleNamingResourceName, moduleNamingSanitized, moduleNamingHashed, s)
+	}
+}
+
+// moduleInstanceName returns the name of the Terraform module block for the module instance with the given URN.
+func moduleInstanceName(urn urn.URN, strategy moduleNamingStrategy) string {
+	name := urn.Name()
+	switch strategy {
+	case moduleNamingSanitized:
+		return sanitizeModuleName(name)
+	case moduleNamingHashed:
+		return "module_" + moduleNameHash(name)
+	default:
+		return name
+	}
+}
+
+func sanitizeModuleName(name string) string {
+	if hclsyntax.ValidIdentifier(name) && len(name) <= maxSanitizedModuleNameLength {
+		return name
+	}
+
+	var b strings.Builder
+	for i, r := range name {
+		valid := r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		switch {
+		case i == 0 && (r == '-' || r >= '0' && r <= '9'):
+			b.WriteRune('_')
+			b.WriteRune(r)
+		case valid:
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	sanitized := b.String()
+	if len(sanitized) > maxSanitizedModuleNameLength {
+		sanitized = sanitized[:maxSanitizedModuleNameLength]
+	}
+	// Distinct resource names may sanitize to the same name, which the hash tells apart.
+	return sanitized + "_" + moduleNameHash(name)[:8]
+}
+
+func moduleNameHash(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:8])
+}
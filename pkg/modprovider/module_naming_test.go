@@ -0,0 +1,78 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+func TestModuleInstanceName(t *testing.T) {
+	moduleURN := func(name string) urn.URN {
+		return urn.New("dev", "proj", "", "vpc:index:Module", name)
+	}
+
+	t.Run("resource-name", func(t *testing.T) {
+		assert.Equal(t, "my.bucket", moduleInstanceName(moduleURN("my.bucket"), moduleNamingResourceName))
+	})
+
+	t.Run("valid names are kept when sanitized", func(t *testing.T) {
+		assert.Equal(t, "my-vpc_1", moduleInstanceName(moduleURN("my-vpc_1"), moduleNamingSanitized))
+	})
+
+	problematic := []string{
+		"my.bucket/prod:v1 (eu)",
+		"9lives",
+		"-dash",
+		"ünïcode",
+		"",
+		strings.Repeat("very-long-name-", 20),
+	}
+	for _, strategy := range []moduleNamingStrategy{moduleNamingSanitized, moduleNamingHashed} {
+		t.Run(string(strategy), func(t *testing.T) {
+			seen := map[string]string{}
+			for _, name := range problematic {
+				tfName := moduleInstanceName(moduleURN(name), strategy)
+				assert.True(t, hclsyntax.ValidIdentifier(tfName), "%q is not a valid identifier", tfName)
+				assert.LessOrEqual(t, len(tfName), maxSanitizedModuleNameLength+9)
+				assert.Equal(t, tfName, moduleInstanceName(moduleURN(name), strategy), "names must be stable")
+				require.NotContains(t, seen, tfName, "names must be unique")
+				seen[tfName] = name
+			}
+		})
+	}
+
+	t.Run("sanitized names stay readable and distinct", func(t *testing.T) {
+		dotted := moduleInstanceName(moduleURN("my.bucket/prod:v1 (eu)"), moduleNamingSanitized)
+		assert.Regexp(t, `^my_bucket_prod_v1__eu__[0-9a-f]{8}$`, dotted)
+		underscored := moduleInstanceName(moduleURN("my_bucket_prod_v1__eu_"), moduleNamingSanitized)
+		assert.NotEqual(t, dotted, underscored)
+	})
+}
+
+func TestParseModuleNamingStrategy(t *testing.T) {
+	n, err := parseModuleNamingStrategy("")
+	require.NoError(t, err)
+	assert.Equal(t, moduleNamingResourceName, n)
+
+	_, err = parseModuleNamingStrategy("random")
+	assert.ErrorContains(t, err, `provider option "moduleNaming" must be one of`)
+}
@@ -0,0 +1,74 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+// moduleWithRef overrides the ref of the module the package was generated from with the moduleRef provider option, so
+// that the same package can run a development branch of the module in one stack and a released tag in another.
+//
+// The override stays within the module of the package, as its schema was inferred from it: only the ref of git
+// sources and the version of registry modules change. Versions must keep the major version the package was generated
+// with, which its SDKs are published under. Branches of git sources cannot be checked this way and are accepted.
+func moduleWithRef(
+	source TFModuleSource,
+	pinned TFModuleVersion,
+	ref string,
+) (TFModuleSource, TFModuleVersion, error) {
+	refVersion := strings.TrimPrefix(ref, "v")
+	isVersion := isValidVersion(refVersion)
+
+	switch {
+	case source.IsLocalPath():
+		return "", "", fmt.Errorf("provider option %q does not apply to the local module %s",
+			moduleRefVariableName, source)
+	case source.IsGit():
+		if !isVersion {
+			return source.WithoutRef().WithRef(ref), TFModuleVersion(ref), nil
+		}
+	case !isVersion:
+		return "", "", fmt.Errorf("provider option %q must be a version of the module %s, got %q",
+			moduleRefVariableName, source, ref)
+	}
+
+	if err := checkSameMajorVersion(pinned, refVersion); err != nil {
+		return "", "", err
+	}
+	if source.IsGit() {
+		return source.WithoutRef().WithRef(ref), TFModuleVersion(refVersion), nil
+	}
+	return source, TFModuleVersion(refVersion), nil
+}
+
+// checkSameMajorVersion checks that the version a moduleRef override selects has the major version of the package.
+// Packages generated from a branch rather than a version are not restricted.
+func checkSameMajorVersion(pinned TFModuleVersion, refVersion string) error {
+	if !isValidVersion(string(pinned)) {
+		return nil
+	}
+	pinnedVersion := version.Must(version.NewVersion(string(pinned)))
+	selected := version.Must(version.NewVersion(refVersion))
+	if pinnedMajor, selectedMajor := pinnedVersion.Segments()[0], selected.Segments()[0]; pinnedMajor != selectedMajor {
+		return fmt.Errorf("provider option %q selects version %s of the module, but the package was generated from "+
+			"version %s and only supports versions %d.x; regenerate the package to change the major version",
+			moduleRefVariableName, refVersion, pinned, pinnedMajor)
+	}
+	return nil
+}
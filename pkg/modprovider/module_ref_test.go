@@ -0,0 +1,153 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestModuleRefOverridesTheModuleSource(t *testing.T) {
+	const gitSource = "git::https://example.com/vpc.git//modules/vpc?ref=v1.2.0&depth=1"
+
+	cases := []struct {
+		name            string
+		source          TFModuleSource
+		version         TFModuleVersion
+		ref             string
+		expectedSource  TFModuleSource
+		expectedVersion TFModuleVersion
+		expectErr       string
+	}{
+		{
+			name:            "git branch",
+			source:          gitSource,
+			version:         "1.2.0",
+			ref:             "develop",
+			expectedSource:  "git::https://example.com/vpc.git//modules/vpc?depth=1&ref=develop",
+			expectedVersion: "develop",
+		},
+		{
+			name:            "git tag",
+			source:          gitSource,
+			version:         "1.2.0",
+			ref:             "v1.3.0",
+			expectedSource:  "git::https://example.com/vpc.git//modules/vpc?depth=1&ref=v1.3.0",
+			expectedVersion: "1.3.0",
+		},
+		{
+			name:      "git tag of another major version",
+			source:    gitSource,
+			version:   "1.2.0",
+			ref:       "v2.0.0",
+			expectErr: "only supports versions 1.x",
+		},
+		{
+			name:            "registry version",
+			source:          "terraform-aws-modules/vpc/aws",
+			version:         "5.19.0",
+			ref:             "5.21.0",
+			expectedSource:  "terraform-aws-modules/vpc/aws",
+			expectedVersion: "5.21.0",
+		},
+		{
+			name:      "registry version of another major version",
+			source:    "terraform-aws-modules/vpc/aws",
+			version:   "5.19.0",
+			ref:       "6.0.0",
+			expectErr: "only supports versions 5.x",
+		},
+		{
+			name:      "registry branch",
+			source:    "terraform-aws-modules/vpc/aws",
+			version:   "5.19.0",
+			ref:       "main",
+			expectErr: `must be a version of the module terraform-aws-modules/vpc/aws, got "main"`,
+		},
+		{
+			name:      "local module",
+			source:    "./modules/vpc",
+			ref:       "main",
+			expectErr: "does not apply to the local module ./modules/vpc",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			source, version, err := moduleWithRef(tc.source, tc.version, tc.ref)
+			if tc.expectErr != "" {
+				assert.ErrorContains(t, err, tc.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSource, source)
+			assert.Equal(t, tc.expectedVersion, version)
+		})
+	}
+}
+
+func TestModuleRefIsUsedAtApply(t *testing.T) {
+	s := &server{params: &ParameterizeArgs{
+		TFModuleSource:  "git::https://example.com/vpc.git?ref=v1.2.0",
+		TFModuleVersion: "1.2.0",
+		PackageName:     "vpc",
+	}}
+
+	configure := func(config resource.PropertyMap) {
+		args, err := plugin.MarshalProperties(config, plugin.MarshalOptions{})
+		require.NoError(t, err)
+		_, err = s.Configure(context.Background(), &pulumirpc.ConfigureRequest{Args: args})
+		require.NoError(t, err)
+	}
+
+	moduleSourceAtApply := func() string {
+		source, version := s.module()
+		workingDir := t.TempDir()
+		err := tfsandbox.CreateTFFile("mod", source, version, workingDir, resource.PropertyMap{}, nil, /* outputs */
+			map[string]resource.PropertyMap{}, tfsandbox.CreateTFFileOpts{})
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+		require.NoError(t, err)
+		var tfFile struct {
+			Module map[string]map[string]any `json:"module"`
+		}
+		require.NoError(t, json.Unmarshal(contents, &tfFile))
+		assert.NotContains(t, tfFile.Module["mod"], "version", "git sources reference their version in the URL")
+		return tfFile.Module["mod"]["source"].(string)
+	}
+
+	configure(resource.PropertyMap{})
+	assert.Equal(t, "git::https://example.com/vpc.git?ref=v1.2.0&depth=1", moduleSourceAtApply())
+
+	configure(resource.PropertyMap{moduleRefVariableName: resource.NewStringProperty("develop")})
+	assert.Equal(t, "git::https://example.com/vpc.git?ref=develop&depth=1", moduleSourceAtApply())
+
+	t.Setenv(moduleRefEnvironmentVariable, "v1.3.0")
+	configure(resource.PropertyMap{})
+	assert.Equal(t, "git::https://example.com/vpc.git?ref=v1.3.0&depth=1", moduleSourceAtApply())
+}
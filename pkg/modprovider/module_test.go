@@ -1,11 +1,25 @@
 package modprovider
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
 
 	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
 )
@@ -18,13 +32,91 @@ func TestGetStateIncludesModuleVersion(t *testing.T) {
 		resource.PropertyKey(moduleResourceVersionPropName): resource.NewStringProperty(version123),
 	}
 
-	state, lock, version := h.getState(props)
+	state, lock, version, err := h.getState(props)
 
+	require.NoError(t, err)
 	require.Equal(t, []byte("state-bytes"), state)
 	require.Equal(t, []byte("lock-bytes"), lock)
 	require.Equal(t, tfsandbox.TFModuleVersion(version123), version)
 }
 
+func TestInvalidModuleStateIsReported(t *testing.T) {
+	h := &moduleHandler{}
+	moduleURN := urn.URN("urn:pulumi:test::prog::vpc:index:Module::myvpc")
+
+	tests := []struct {
+		name  string
+		props resource.PropertyMap
+		err   string
+	}{
+		{
+			name:  "missing",
+			props: resource.PropertyMap{"vpc_id": resource.NewStringProperty("vpc-1")},
+			err:   `the "__state" property is missing`,
+		},
+		{
+			name: "not a string",
+			props: resource.PropertyMap{
+				moduleResourceStatePropName: resource.MakeSecret(resource.NewObjectProperty(resource.PropertyMap{})),
+			},
+			err: `the "__state" property must be a string, got object`,
+		},
+		{
+			name: "not JSON",
+			props: resource.PropertyMap{
+				moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(`{"version": 4,`)),
+			},
+			err: `the "__state" property does not hold a Terraform state in the JSON format`,
+		},
+		{
+			name: "lock file not a string",
+			props: resource.PropertyMap{
+				moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(`{"version": 4}`)),
+				moduleResourceLockPropName:  resource.NewNumberProperty(1),
+			},
+			err: `the "__lock" property must be a string, got number`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rawState, _, _, err := h.getState(tt.props)
+			err = checkModuleState(rawState, err)
+			require.Error(t, err)
+			err = invalidModuleStateRecoveryError(moduleURN, err)
+			assert.ErrorContains(t, err, "the Terraform state of module myvpc is invalid: "+tt.err)
+			assert.ErrorContains(t, err, `set the "invalidModuleState" provider option to "reset"`)
+			assert.False(t, h.deployedWith(tt.props, version123, nil, moduleOptions{}))
+		})
+	}
+
+	t.Run("policy", func(t *testing.T) {
+		policy, err := parseInvalidModuleStatePolicy("")
+		require.NoError(t, err)
+		assert.Equal(t, invalidModuleStateError, policy)
+
+		_, err = parseInvalidModuleStatePolicy("ignore")
+		assert.ErrorContains(t, err, `provider option "invalidModuleState" must be one of "error" or "reset"`)
+	})
+}
+
+func TestDeleteWithoutModuleState(t *testing.T) {
+	ctx := context.Background()
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+
+	properties, err := plugin.MarshalProperties(resource.PropertyMap{"vpc_id": resource.NewStringProperty("vpc-1")},
+		h.marshalOpts())
+	require.NoError(t, err)
+
+	// the executor does not exist, as nothing is run
+	_, err = h.Delete(ctx, &pulumirpc.DeleteRequest{
+		Urn:        "urn:pulumi:test::prog::vpc:index:Module::myvpc",
+		Properties: properties,
+	}, "vpc", "./vpc", "", &InferredModuleSchema{}, map[string]resource.PropertyMap{},
+		moduleOptions{executor: filepath.Join(t.TempDir(), "missing")})
+	require.NoError(t, err)
+}
+
 func TestNeedsInitUpgrade(t *testing.T) {
 	sampleOutputs := resource.PropertyMap{}
 
@@ -71,3 +163,625 @@ func TestNeedsInitUpgrade(t *testing.T) {
 		})
 	}
 }
+
+func TestRenamedPropertiesRoundTrip(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("renamed", loadTestModule(t, "renamed"), &ModuleConfig{
+		Renames: &ModuleRenames{
+			Inputs:  map[string]string{vpcIDKey: "id"},
+			Outputs: map[string]string{vpcIDKey: "id"},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []tfsandbox.TFOutputSpec{{Name: vpcIDKey}}, tfOutputSpecs(inferredSchema))
+
+	tfInputs := inputsToTerraform(inferredSchema, resource.PropertyMap{
+		"id_": resource.NewStringProperty("vpc-123"),
+	})
+	require.Equal(t, resource.PropertyMap{
+		vpcIDKey: resource.NewStringProperty("vpc-123"),
+	}, tfInputs)
+
+	state := resource.MakeSecret(resource.NewStringProperty("state-bytes"))
+	pulumiOutputs := outputsToPulumi(inferredSchema, resource.PropertyMap{
+		vpcIDKey: resource.NewStringProperty("vpc-123"),
+		resource.PropertyKey(moduleResourceStatePropName): state,
+	})
+	require.Equal(t, resource.PropertyMap{
+		"id_": resource.NewStringProperty("vpc-123"),
+		resource.PropertyKey(moduleResourceStatePropName): state,
+	}, pulumiOutputs)
+}
+
+func TestResourceReferenceInputsAreKept(t *testing.T) {
+	ref := resource.MakeCustomResourceReference("urn:pulumi:test::proj::aws:ec2/vpc:Vpc::vpc", "vpc-123", "")
+	inputs, err := plugin.MarshalProperties(resource.PropertyMap{"id_": ref}, plugin.MarshalOptions{
+		KeepResources: true,
+	})
+	require.NoError(t, err)
+
+	h := &moduleHandler{}
+	moduleInputs, err := plugin.UnmarshalProperties(inputs, h.marshalOpts())
+	require.NoError(t, err)
+
+	inferredSchema, err := inferModuleSchemaFromContent("renamed", loadTestModule(t, "renamed"), &ModuleConfig{
+		Renames: &ModuleRenames{Inputs: map[string]string{vpcIDKey: "id"}},
+	})
+	require.NoError(t, err)
+
+	// The reference reaches the Terraform file generation intact, which passes the ID on to the module.
+	assert.Equal(t, resource.PropertyMap{vpcIDKey: ref}, inputsToTerraform(inferredSchema, moduleInputs))
+}
+
+// BenchmarkDiffUnchangedInputs counts the plans run to re-preview a module instance whose inputs did not change,
+// reported as plans/op. With skipUnchangedPlans the engine gets DIFF_NONE without a plan.
+//
+//	go test ./pkg/modprovider -run '^$' -bench BenchmarkDiffUnchangedInputs
+func BenchmarkDiffUnchangedInputs(b *testing.B) {
+	if runtime.GOOS == "windows" {
+		b.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	// the stub records every command it runs in commands.log
+	stub, err := filepath.Abs(filepath.Join("testdata", "output_refresh", "stub.sh"))
+	require.NoError(b, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(b, err)
+	// the stub also runs outside of the working directory of the module, as when the executor version is checked
+	b.Chdir(b.TempDir())
+
+	inputs, err := plugin.MarshalProperties(resource.PropertyMap{}, plugin.MarshalOptions{})
+	require.NoError(b, err)
+
+	olds, err := plugin.MarshalProperties(resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(
+			`{"version":4,"serial":1,"lineage":"bench","outputs":{},"resources":[]}`)),
+		moduleResourceLockPropName: resource.NewStringProperty(""),
+		moduleResourceProvidersConfigPropName: resource.MakeSecret(resource.NewStringProperty(
+			providersConfigDigest(map[string]resource.PropertyMap{}, moduleOptions{}))),
+	}, plugin.MarshalOptions{KeepSecrets: true})
+	require.NoError(b, err)
+
+	modURN := urn.URN("urn:pulumi:test::bench::bench:index:Module::simple")
+	req := &pulumirpc.DiffRequest{
+		Urn:       string(modURN),
+		OldInputs: inputs,
+		News:      inputs,
+		Olds:      olds,
+	}
+	workdir := tfsandboxWorkdir(b, stub, modURN)
+	b.Cleanup(func() {
+		os.RemoveAll(workdir)
+	})
+	commandsLog := filepath.Join(workdir, "commands.log")
+
+	for _, skip := range []bool{true, false} {
+		b.Run(fmt.Sprintf("skipUnchangedPlans=%v", skip), func(b *testing.B) {
+			require.NoError(b, os.WriteFile(commandsLog, nil, 0o600))
+			h := newModuleHandler(nil, newTestAuxProviderServer(b))
+			for b.Loop() {
+				_, err := h.Diff(ctx, req, TFModuleSource(src), "", map[string]resource.PropertyMap{},
+					&InferredModuleSchema{}, moduleOptions{executor: stub, skipUnchangedPlans: skip})
+				require.NoError(b, err)
+			}
+
+			log, err := os.ReadFile(commandsLog)
+			require.NoError(b, err)
+			plans := 0
+			for command := range strings.Lines(string(log)) {
+				if strings.HasPrefix(command, "plan ") {
+					plans++
+				}
+			}
+			if skip {
+				require.Zero(b, plans, "unchanged module instances should not be planned")
+			}
+			b.ReportMetric(float64(plans)/float64(b.N), "plans/op")
+		})
+	}
+}
+
+func TestDeployedWithProvidersConfig(t *testing.T) {
+	h := &moduleHandler{}
+	config := func(region string, token resource.PropertyValue) map[string]resource.PropertyMap {
+		return map[string]resource.PropertyMap{"aws": {
+			"region": resource.NewStringProperty(region),
+			"token":  token,
+		}}
+	}
+	deployed := config("us-west-2", resource.MakeSecret(resource.NewStringProperty("t1")))
+
+	oldOutputs := resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(`{"version":4}`)),
+	}
+	assert.False(t, h.deployedWith(oldOutputs, "", deployed, moduleOptions{}), "the provider configuration is not recorded")
+
+	recordProvidersConfig(oldOutputs, nil, deployed, moduleOptions{}, true)
+	assert.True(t, oldOutputs[moduleResourceProvidersConfigPropName].IsSecret())
+	assert.True(t, h.deployedWith(oldOutputs, "", deployed, moduleOptions{}))
+	assert.True(t, h.deployedWith(oldOutputs, "", config("us-west-2", resource.NewStringProperty("t1")),
+		moduleOptions{}),
+		"secrecy is not part of the configuration")
+	assert.False(t, h.deployedWith(oldOutputs, "", config("us-east-1", resource.NewStringProperty("t1")),
+		moduleOptions{}))
+	assert.False(t, h.deployedWith(oldOutputs, "", config("us-west-2", resource.MakeSecret(
+		resource.NewStringProperty("t2"))), moduleOptions{}), "changed secrets are changes")
+	assert.False(t, h.deployedWith(oldOutputs, "", config("us-west-2", resource.MakeComputed(
+		resource.NewStringProperty(""))), moduleOptions{}), "unknown configuration may change")
+	assert.False(t, h.deployedWith(oldOutputs, "", map[string]resource.PropertyMap{}, moduleOptions{}))
+
+	outputs := resource.PropertyMap{}
+	recordProvidersConfig(outputs, oldOutputs, config("us-east-1", resource.NewStringProperty("t1")),
+		moduleOptions{}, false)
+	assert.True(t, h.deployedWith(resource.PropertyMap{
+		moduleResourceStatePropName:           oldOutputs[moduleResourceStatePropName],
+		moduleResourceProvidersConfigPropName: outputs[moduleResourceProvidersConfigPropName],
+	}, "", deployed, moduleOptions{}), "the configuration of the last deployment is kept")
+}
+
+// Options that change the generated Terraform file must take unchanged module instances off the skipUnchangedPlans
+// fast path, or they would never be applied to instances deployed without them.
+func TestDeployedWithFileOptions(t *testing.T) {
+	h := &moduleHandler{}
+	deployed := map[string]resource.PropertyMap{"aws": {"region": resource.NewStringProperty("us-west-2")}}
+	oldOutputs := resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(`{"version":4}`)),
+	}
+	recordProvidersConfig(oldOutputs, nil, deployed, moduleOptions{}, true)
+
+	tests := []struct {
+		name string
+		opts moduleOptions
+	}{
+		{"pulumiDefaultTags", moduleOptions{pulumiDefaultTags: true}},
+		{"providerMeta", moduleOptions{providerMeta: map[string]resource.PropertyMap{
+			"aws": {"module_name": resource.NewStringProperty("vpc")},
+		}}},
+		{"extraTerraformFiles", moduleOptions{extraTerraformFiles: map[string]string{"extra.tf": "locals {}"}}},
+		{"moduleDependsOn", moduleOptions{moduleDependsOn: []string{"terraform_data.gate"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, h.deployedWith(oldOutputs, "", deployed, moduleOptions{}))
+			assert.False(t, h.deployedWith(oldOutputs, "", deployed, tt.opts), "enabling the option is a change")
+
+			outputs := oldOutputs.Copy()
+			recordProvidersConfig(outputs, oldOutputs, deployed, tt.opts, true)
+			assert.True(t, h.deployedWith(outputs, "", deployed, tt.opts))
+			assert.False(t, h.deployedWith(outputs, "", deployed, moduleOptions{}), "disabling the option is a change")
+		})
+	}
+}
+
+func TestDiffIgnoresSetElementOrder(t *testing.T) {
+	ctx := context.Background()
+	inferredModule, err := inferModuleSchemaFromContent("sg", loadTestModule(t, "set-of-objects"), nil)
+	require.NoError(t, err)
+
+	rule := func(fromPort float64, cidrBlocks ...string) resource.PropertyValue {
+		blocks := []resource.PropertyValue{}
+		for _, b := range cidrBlocks {
+			blocks = append(blocks, resource.NewStringProperty(b))
+		}
+		return resource.NewObjectProperty(resource.PropertyMap{
+			"from_port":   resource.NewNumberProperty(fromPort),
+			"to_port":     resource.NewNumberProperty(fromPort),
+			"cidr_blocks": resource.NewArrayProperty(blocks),
+		})
+	}
+	inputs := func(rules []resource.PropertyValue, subnets ...string) *structpb.Struct {
+		subnetIDs := []resource.PropertyValue{}
+		for _, s := range subnets {
+			subnetIDs = append(subnetIDs, resource.NewStringProperty(s))
+		}
+		marshaled, err := plugin.MarshalProperties(resource.PropertyMap{
+			"ingress_rules": resource.NewArrayProperty(rules),
+			"subnet_ids":    resource.NewArrayProperty(subnetIDs),
+		}, plugin.MarshalOptions{})
+		require.NoError(t, err)
+		return marshaled
+	}
+
+	olds, err := plugin.MarshalProperties(resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(
+			`{"version":4,"serial":1,"lineage":"test","outputs":{},"resources":[]}`)),
+		moduleResourceLockPropName: resource.NewStringProperty(""),
+		moduleResourceProvidersConfigPropName: resource.MakeSecret(resource.NewStringProperty(
+			providersConfigDigest(map[string]resource.PropertyMap{}, moduleOptions{}))),
+	}, plugin.MarshalOptions{KeepSecrets: true})
+	require.NoError(t, err)
+
+	oldInputs := inputs([]resource.PropertyValue{rule(80, "10.0.0.0/16"), rule(443, "10.0.0.0/16")}, "a", "b")
+
+	tests := []struct {
+		name     string
+		news     *structpb.Struct
+		expected pulumirpc.DiffResponse_DiffChanges
+	}{
+		{
+			name:     "reordered set elements",
+			news:     inputs([]resource.PropertyValue{rule(443, "10.0.0.0/16"), rule(80, "10.0.0.0/16")}, "a", "b"),
+			expected: pulumirpc.DiffResponse_DIFF_NONE,
+		},
+		{
+			name:     "changed set element",
+			news:     inputs([]resource.PropertyValue{rule(443, "10.0.0.0/16"), rule(80, "10.1.0.0/16")}, "a", "b"),
+			expected: pulumirpc.DiffResponse_DIFF_SOME,
+		},
+		{
+			name:     "reordered list elements",
+			news:     inputs([]resource.PropertyValue{rule(80, "10.0.0.0/16"), rule(443, "10.0.0.0/16")}, "b", "a"),
+			expected: pulumirpc.DiffResponse_DIFF_SOME,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newModuleHandler(nil, newTestAuxProviderServer(t))
+			resp, err := h.Diff(ctx, &pulumirpc.DiffRequest{
+				Urn:       "urn:pulumi:test::sg::sg:index:Module::sg",
+				OldInputs: oldInputs,
+				News:      tt.news,
+				Olds:      olds,
+			}, "./set-of-objects", "", map[string]resource.PropertyMap{}, inferredModule,
+				moduleOptions{skipUnchangedPlans: true})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, resp.Changes)
+		})
+	}
+}
+
+func TestModuleOutputsOnlySensitiveOutputsAreSecret(t *testing.T) {
+	// Mirrors the outputs produced by the generated pulumi.tf.json, which pairs every module output with an
+	// is_secret companion output reflecting whether Terraform considers it sensitive.
+	const isSecretPrefix = "internal_output_is_secret_"
+	tfState, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{
+			Outputs: map[string]*tfjson.StateOutput{
+				"password":                  {Value: "hunter2"},
+				isSecretPrefix + "password": {Value: true},
+				"endpoint":                  {Value: "db.example.com"},
+				isSecretPrefix + "endpoint": {Value: false},
+				"tags":                      {Value: map[string]any{"env": "dev"}},
+				isSecretPrefix + "tags":     {Value: false},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	outputs := moduleOutputsWithState(tfState, []byte("state-bytes"), []byte("lock-bytes"), version123)
+
+	require.Equal(t, resource.PropertyMap{
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"endpoint": resource.NewStringProperty("db.example.com"),
+		"tags": resource.NewObjectProperty(resource.PropertyMap{
+			"env": resource.NewStringProperty("dev"),
+		}),
+		moduleResourceStatePropName:   resource.MakeSecret(resource.NewStringProperty("state-bytes")),
+		moduleResourceLockPropName:    resource.NewStringProperty("lock-bytes"),
+		moduleResourceVersionPropName: resource.NewStringProperty(version123),
+	}, outputs)
+}
+
+func TestModuleOutputsNull(t *testing.T) {
+	const isSecretPrefix = "internal_output_is_secret_"
+	// Terraform omits null outputs from the state, only their is_secret companions remain.
+	tfState, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{
+			Outputs: map[string]*tfjson.StateOutput{
+				isSecretPrefix + vpcIDKey: {Value: false},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	outputs := moduleOutputsWithState(tfState, []byte("state-bytes"), []byte("lock-bytes"), version123)
+	require.Equal(t, resource.NewNullProperty(), outputs[vpcIDKey])
+
+	t.Run("warns when declared non-nil", func(t *testing.T) {
+		logger := &recordingLogger{}
+		warnOnNullNonNilOutputs(context.Background(), logger, &InferredModuleSchema{
+			NonNilOutputs: []resource.PropertyKey{vpcIDKey},
+		}, outputs)
+		require.Equal(t, []string{
+			`warn: module output "vpc_id" is declared as non-nil but the module returned null`,
+		}, logger.messages)
+	})
+
+	t.Run("no warning otherwise", func(t *testing.T) {
+		logger := &recordingLogger{}
+		warnOnNullNonNilOutputs(context.Background(), logger, &InferredModuleSchema{}, outputs)
+		require.Empty(t, logger.messages)
+	})
+}
+
+func TestOmitEmptyOutputs(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), &ModuleConfig{
+		OmitEmptyOutputs: true,
+	})
+	require.NoError(t, err)
+	// As merged from the nonNilOutputs configuration.
+	inferredModule.NonNilOutputs = []resource.PropertyKey{"required_id"}
+
+	outputs := resource.PropertyMap{
+		"vpc_id":                      resource.NewStringProperty("vpc-1"),
+		"null_output":                 resource.NewNullProperty(),
+		"empty_string":                resource.NewStringProperty(""),
+		"empty_list":                  resource.NewArrayProperty([]resource.PropertyValue{}),
+		"empty_map":                   resource.NewObjectProperty(resource.PropertyMap{}),
+		"empty_secret":                resource.MakeSecret(resource.NewNullProperty()),
+		"zero":                        resource.NewNumberProperty(0),
+		"unknown":                     resource.MakeComputed(resource.NewStringProperty("")),
+		"required_id":                 resource.NewNullProperty(),
+		moduleResourceStatePropName:   resource.MakeSecret(resource.NewStringProperty("state-bytes")),
+		moduleResourceLockPropName:    resource.NewStringProperty(""),
+		moduleResourceVersionPropName: resource.NewStringProperty(""),
+	}
+
+	assert.Equal(t, resource.PropertyMap{
+		"vpc_id":                      resource.NewStringProperty("vpc-1"),
+		"zero":                        resource.NewNumberProperty(0),
+		"unknown":                     resource.MakeComputed(resource.NewStringProperty("")),
+		"required_id":                 resource.NewNullProperty(),
+		moduleResourceStatePropName:   resource.MakeSecret(resource.NewStringProperty("state-bytes")),
+		moduleResourceLockPropName:    resource.NewStringProperty(""),
+		moduleResourceVersionPropName: resource.NewStringProperty(""),
+	}, omitEmptyOutputs(inferredModule, outputs))
+
+	t.Run("kept by default", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, outputs, omitEmptyOutputs(inferredModule, outputs))
+	})
+}
+
+func TestOmitNullInputs(t *testing.T) {
+	inputs := resource.PropertyMap{
+		"name":   resource.NewStringProperty("vpc"),
+		"cidr":   resource.NewNullProperty(),
+		"secret": resource.MakeSecret(resource.NewStringProperty("s3cr3t")),
+	}
+
+	inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), &ModuleConfig{
+		OmitNullInputs: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"name":   resource.NewStringProperty("vpc"),
+		"secret": resource.MakeSecret(resource.NewStringProperty("s3cr3t")),
+	}, omitNullInputs(inferredModule, inputs))
+
+	t.Run("kept by default", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), nil)
+		require.NoError(t, err)
+		assert.Equal(t, inputs, omitNullInputs(inferredModule, inputs))
+	})
+}
+
+func TestNullDefaultInputsAreOmitted(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("defaulting", loadTestModule(t, "defaulting"), nil)
+	require.NoError(t, err)
+
+	assert.NotContains(t, inferredModule.RequiredInputs, resource.PropertyKey("name"))
+	assert.Contains(t, inferredModule.RequiredInputs, resource.PropertyKey("fallback_name"))
+
+	inputs := resource.PropertyMap{
+		"name":          resource.NewNullProperty(),
+		"fallback_name": resource.NewNullProperty(),
+		"port":          resource.NewNumberProperty(8080),
+	}
+
+	// name defaults to null so passing null is left out; fallback_name has no default and keeps the null.
+	assert.Equal(t, resource.PropertyMap{
+		"fallback_name": resource.NewNullProperty(),
+		"port":          resource.NewNumberProperty(8080),
+	}, omitNullInputs(inferredModule, inputs))
+}
+
+func TestCheckReportsUnknownInputs(t *testing.T) {
+	h := &moduleHandler{}
+	moduleSchema := &InferredModuleSchema{
+		Inputs: map[resource.PropertyKey]*schema.PropertySpec{
+			vpcIDKey: {TypeSpec: stringType},
+		},
+	}
+
+	resp, err := h.Check(context.Background(), &pulumirpc.CheckRequest{
+		Urn: "urn:pulumi:test::test::vpc:index:Module::vpc",
+		News: &structpb.Struct{Fields: map[string]*structpb.Value{
+			vpcIDKey:   structpb.NewStringValue("vpc-123"),
+			"vcp_cidr": structpb.NewStringValue("10.0.0.0/16"),
+		}},
+	}, moduleSchema, nil, moduleOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, resp.GetFailures(), 1)
+	assert.Equal(t, "vcp_cidr", resp.GetFailures()[0].GetProperty())
+	assert.Contains(t, resp.GetFailures()[0].GetReason(), `unknown input "vcp_cidr"`)
+}
+
+func TestRefreshOnlyModeRejectsProgramChanges(t *testing.T) {
+	ctx := context.Background()
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	opts := moduleOptions{refreshOnly: true}
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+
+	t.Run("changed inputs", func(t *testing.T) {
+		olds, err := plugin.MarshalProperties(resource.PropertyMap{
+			"tagvalue": resource.NewStringProperty("a"),
+		}, plugin.MarshalOptions{})
+		require.NoError(t, err)
+		news, err := plugin.MarshalProperties(resource.PropertyMap{
+			"tagvalue": resource.NewStringProperty("b"),
+		}, plugin.MarshalOptions{})
+		require.NoError(t, err)
+
+		_, err = h.Diff(ctx, &pulumirpc.DiffRequest{Urn: string(modURN), OldInputs: olds, News: news}, "./simple", "",
+			map[string]resource.PropertyMap{}, &InferredModuleSchema{}, opts)
+		assert.EqualError(t, err, "the inputs of module m changed, but the provider is configured with "+
+			"refreshOnly: true and does not apply them; revert the changes or unset refreshOnly")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		_, _, err := h.applyModuleOperation(ctx, modURN, resource.PropertyMap{}, nil /* oldOutputs */, "./simple",
+			"", map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple", false /* preview */, opts)
+		assert.EqualError(t, err, "cannot create module m: the provider is configured with refreshOnly: true "+
+			"and there is no state to reconcile")
+	})
+}
+
+func TestDestroyTargets(t *testing.T) {
+	ctx := context.Background()
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	opts := moduleOptions{destroyTargets: []string{
+		"module.m.random_integer.priority",
+		"module.m2.random_integer.priority",
+		`module.m["a"].random_integer.priority`,
+	}}
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+
+	t.Run("selects the targets of the module instance", func(t *testing.T) {
+		assert.Equal(t, []string{"module.m.random_integer.priority", `module.m["a"].random_integer.priority`},
+			opts.destroyTargetsOf(modURN))
+		assert.Empty(t, opts.destroyTargetsOf("urn:pulumi:test::prog::simple:index:Module::other"))
+	})
+
+	t.Run("changed inputs", func(t *testing.T) {
+		olds, err := plugin.MarshalProperties(resource.PropertyMap{
+			"tagvalue": resource.NewStringProperty("a"),
+		}, plugin.MarshalOptions{})
+		require.NoError(t, err)
+		news, err := plugin.MarshalProperties(resource.PropertyMap{
+			"tagvalue": resource.NewStringProperty("b"),
+		}, plugin.MarshalOptions{})
+		require.NoError(t, err)
+
+		_, err = h.Diff(ctx, &pulumirpc.DiffRequest{Urn: string(modURN), OldInputs: olds, News: news}, "./simple", "",
+			map[string]resource.PropertyMap{}, &InferredModuleSchema{}, opts)
+		assert.EqualError(t, err, "the inputs of module m changed, but the provider is configured with "+
+			"destroyTargets targeting its resources and does not apply them; revert the changes or unset destroyTargets")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		_, _, err := h.applyModuleOperation(ctx, modURN, resource.PropertyMap{}, nil /* oldOutputs */, "./simple",
+			"", map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple", false /* preview */, opts)
+		assert.EqualError(t, err, "cannot create module m: the provider is configured with destroyTargets "+
+			"targeting its resources")
+	})
+}
+
+func TestPreviewOutputsAreTypedUnknowns(t *testing.T) {
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		OutputChanges: map[string]*tfjson.Change{
+			"bucket_regional_domain_name": {AfterUnknown: true},
+			"endpoints":                   {AfterUnknown: true},
+			"tags":                        {AfterUnknown: true},
+			"bucket":                      {AfterUnknown: true},
+			"anything":                    {AfterUnknown: true},
+			"partial":                     {AfterUnknown: []any{true}},
+		},
+	})
+	require.NoError(t, err)
+
+	inferredModule := &InferredModuleSchema{
+		Outputs: map[resource.PropertyKey]*schema.PropertySpec{
+			"bucket_regional_domain_name": {TypeSpec: stringType},
+			"endpoints":                   {TypeSpec: schema.TypeSpec{Type: "array", Items: &stringType}},
+			"tags":                        {TypeSpec: schema.TypeSpec{Type: objectTypeName, AdditionalProperties: &stringType}},
+			"bucket":                      {TypeSpec: refType("#/types/s3:index:Bucket")},
+			"anything":                    {TypeSpec: anyType},
+			"partial":                     {TypeSpec: anyType},
+		},
+	}
+
+	outputs := typeUnknownOutputs(inferredModule, outputsToPulumi(inferredModule, plan.Outputs()))
+	assert.Equal(t, resource.PropertyMap{
+		"bucket_regional_domain_name": resource.MakeComputed(resource.NewStringProperty("")),
+		"endpoints":                   resource.MakeComputed(resource.NewArrayProperty([]resource.PropertyValue{})),
+		"tags":                        resource.MakeComputed(resource.NewObjectProperty(resource.PropertyMap{})),
+		"bucket":                      resource.MakeComputed(resource.NewObjectProperty(resource.PropertyMap{})),
+		"anything":                    resource.MakeComputed(resource.NewStringProperty("")),
+		"partial":                     resource.MakeComputed(resource.NewArrayProperty([]resource.PropertyValue{})),
+	}, outputs)
+}
+
+func TestModuleUpgradeNotesAreReported(t *testing.T) {
+	ctx := context.Background()
+	moduleDir, err := filepath.Abs(filepath.Join("testdata", "modules", "upgrade-notes"))
+	require.NoError(t, err)
+
+	workdir := t.TempDir()
+	modulesDir := filepath.Join(workdir, ".terraform", "modules")
+	require.NoError(t, os.MkdirAll(modulesDir, 0o700))
+	contents, err := json.Marshal(tfsandbox.ModuleManifest{Modules: []tfsandbox.ModuleManifestRecord{
+		{Key: "", Source: "", Dir: "."},
+		{Key: "mod", Source: "registry.terraform.io/acme/notes/aws", Dir: moduleDir},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(modulesDir, "modules.json"), contents, 0o600))
+
+	logger := &recordingLogger{}
+	reportModuleUpgradeNotes(ctx, logger, workdir, "mod", "2.0.0")
+	require.Len(t, logger.messages, 1)
+	msg := logger.messages[0]
+	assert.True(t, strings.HasPrefix(msg, "info: Module mod was upgraded to version 2.0.0, see "+
+		"UPGRADE-2.0.md, CHANGELOG.md in "+moduleDir), msg)
+	assert.Contains(t, msg, "* Removed the `prefix` input")
+	assert.Contains(t, msg, "* Added the `name` input")
+	assert.NotContains(t, msg, "Fixed tagging", "only the changes of the new version are reported")
+
+	t.Run("without notes", func(t *testing.T) {
+		files, changes, err := moduleUpgradeNotes(filepath.Join("testdata", "modules", "simple"), "2.0.0")
+		require.NoError(t, err)
+		assert.Empty(t, files)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("version without changelog entry", func(t *testing.T) {
+		files, changes, err := moduleUpgradeNotes(moduleDir, "3.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"UPGRADE-2.0.md", "CHANGELOG.md"}, files)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestNoOpUpdateKeepsStoredState(t *testing.T) {
+	h := &moduleHandler{}
+	oldState := `{"version":4,"terraform_version":"1.9.0","serial":3,"lineage":"abc","outputs":{},"resources":[]}`
+	oldLock := "# lock\n"
+	oldOutputs := resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(oldState)),
+		moduleResourceLockPropName:  resource.NewStringProperty(oldLock),
+	}
+
+	// pulling the state after a no-op apply with a newer executor serializes it differently
+	newState := "{\n  \"version\": 4,\n  \"terraform_version\": \"1.10.2\",\n  \"serial\": 3,\n  \"lineage\": \"abc\",\n" +
+		"  \"outputs\": {},\n  \"resources\": []\n}\n"
+	stateProps := func(rawState, rawLockFile string) resource.PropertyMap {
+		return resource.PropertyMap{
+			moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(rawState)),
+			moduleResourceLockPropName:  resource.NewStringProperty(rawLockFile),
+		}
+	}
+	moduleOutputs := stateProps(newState, oldLock+"\n")
+	h.keepUnchangedState(moduleOutputs, oldOutputs)
+
+	state, lock, _, err := h.getState(moduleOutputs)
+	require.NoError(t, err)
+	assert.Equal(t, oldState, string(state))
+	assert.Equal(t, oldLock, string(lock))
+	assert.True(t, moduleOutputs[moduleResourceStatePropName].IsSecret())
+
+	t.Run("changed state", func(t *testing.T) {
+		changedState := `{"version":4,"terraform_version":"1.9.0","serial":4,"lineage":"abc","outputs":{},` +
+			`"resources":[{"mode":"managed","type":"terraform_data","name":"gate"}]}`
+		moduleOutputs := stateProps(changedState, oldLock)
+		h.keepUnchangedState(moduleOutputs, oldOutputs)
+
+		state, _, _, err := h.getState(moduleOutputs)
+		require.NoError(t, err)
+		assert.Equal(t, changedState, string(state))
+	})
+}
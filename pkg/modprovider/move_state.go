@@ -0,0 +1,221 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/sig"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// MoveStateCommandName is the provider subcommand that moves resource states between two module instances.
+const MoveStateCommandName = "move-state"
+
+// MoveState implements the move-state subcommand of the provider binary:
+//
+//	pulumi-resource-terraform-module move-state --stack-file <file> --from <module-urn> --to <module-urn> \
+//	    --address <address> [--address <address>...] [--module-naming <strategy>]
+//
+// The command edits a stack exported with `pulumi stack export --show-secrets`, moving the Terraform states of the
+// given resources from the __state of one module instance to the __state of the other. Addresses are relative to the
+// module instance, as in aws_s3_bucket.this or module.sub.aws_iam_role.this[0]. The stack file is rewritten in place
+// and is meant to be imported back with `pulumi stack import`.
+func MoveState(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet(MoveStateCommandName, flag.ContinueOnError)
+	flags.SetOutput(stdout)
+	stackFile := flags.String("stack-file", "", "stack exported with `pulumi stack export --show-secrets`")
+	from := flags.String("from", "", "URN of the module instance to move the resources from")
+	to := flags.String("to", "", "URN of the module instance to move the resources to")
+	var addresses stringsFlag
+	flags.Var(&addresses, "address", "address of a resource to move, relative to the module instance; repeatable")
+	naming := flags.String("module-naming", os.Getenv(moduleNamingEnvironmentVariable),
+		"naming strategy of the module instances, as in the moduleNaming provider option")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *stackFile == "" || *from == "" || *to == "" || len(addresses) == 0 {
+		return errors.New("--stack-file, --from, --to and at least one --address are required")
+	}
+	fromURN, err := urn.Parse(*from)
+	if err != nil {
+		return fmt.Errorf("invalid module URN: %w", err)
+	}
+	toURN, err := urn.Parse(*to)
+	if err != nil {
+		return fmt.Errorf("invalid module URN: %w", err)
+	}
+	if fromURN == toURN {
+		return errors.New("--from and --to must be different module instances")
+	}
+	strategy, err := parseModuleNamingStrategy(*naming)
+	if err != nil {
+		return err
+	}
+
+	contents, err := os.ReadFile(*stackFile)
+	if err != nil {
+		return err
+	}
+	moved, instances, err := moveStackResourceStates(contents, fromURN, toURN, strategy, addresses)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*stackFile, moved, 0600); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Moved %d resource instance(s) from %s to %s.\n"+
+		"Run `pulumi stack import --file %s` to apply the change.\n", instances, fromURN, toURN, *stackFile)
+	return nil
+}
+
+// moveStackResourceStates moves the Terraform states of resources between two module instances of an exported stack.
+// Only the __state outputs of the two instances are changed, the rest of the deployment is preserved as is. It returns
+// the updated stack along with the number of resource instances moved.
+func moveStackResourceStates(
+	stackFile []byte,
+	fromURN, toURN urn.URN,
+	strategy moduleNamingStrategy,
+	addresses []string,
+) ([]byte, int, error) {
+	stack, deployment, err := decodeExportedStack(stackFile)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resources, _ := deployment["resources"].([]any)
+	fromOutputs, err := moduleOutputsInDeployment(resources, fromURN)
+	if err != nil {
+		return nil, 0, err
+	}
+	toOutputs, err := moduleOutputsInDeployment(resources, toURN)
+	if err != nil {
+		return nil, 0, err
+	}
+	fromState, err := exportedModuleState(fromOutputs, fromURN)
+	if err != nil {
+		return nil, 0, err
+	}
+	toState, err := exportedModuleState(toOutputs, toURN)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	newFromState, newToState, instances, err := tfsandbox.MoveResourceStates(fromState, toState,
+		moduleInstanceName(fromURN, strategy), moduleInstanceName(toURN, strategy), addresses)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := setExportedModuleState(fromOutputs, newFromState); err != nil {
+		return nil, 0, err
+	}
+	if err := setExportedModuleState(toOutputs, newToState); err != nil {
+		return nil, 0, err
+	}
+
+	if stack.Deployment, err = json.Marshal(deployment); err != nil {
+		return nil, 0, err
+	}
+	updated, err := json.MarshalIndent(stack, "", "    ")
+	return updated, instances, err
+}
+
+// decodeExportedStack reads a stack exported with `pulumi stack export`. The deployment is decoded generically so that
+// it can be written back without losing what this provider does not know about.
+func decodeExportedStack(stackFile []byte) (apitype.UntypedDeployment, map[string]any, error) {
+	var stack apitype.UntypedDeployment
+	if err := json.Unmarshal(stackFile, &stack); err != nil {
+		return stack, nil, fmt.Errorf("failed to read the stack file: %w", err)
+	}
+	var deployment map[string]any
+	dec := json.NewDecoder(bytes.NewReader(stack.Deployment))
+	dec.UseNumber()
+	if err := dec.Decode(&deployment); err != nil {
+		return stack, nil, fmt.Errorf("failed to read the deployment of the stack file: %w", err)
+	}
+	return stack, deployment, nil
+}
+
+func moduleOutputsInDeployment(resources []any, moduleURN urn.URN) (map[string]any, error) {
+	i := slices.IndexFunc(resources, func(r any) bool {
+		res, ok := r.(map[string]any)
+		return ok && res["urn"] == string(moduleURN)
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("module instance %s not found in the stack file", moduleURN)
+	}
+	outputs, _ := resources[i].(map[string]any)["outputs"].(map[string]any)
+	if _, ok := outputs[moduleResourceStatePropName]; !ok {
+		return nil, fmt.Errorf("%s does not carry a module state", moduleURN)
+	}
+	return outputs, nil
+}
+
+// exportedModuleState reads the __state of a module instance, which is exported as a secret.
+func exportedModuleState(outputs map[string]any, moduleURN urn.URN) (json.RawMessage, error) {
+	switch state := outputs[moduleResourceStatePropName].(type) {
+	case string:
+		return json.RawMessage(state), nil
+	case map[string]any:
+		plaintext, ok := state["plaintext"].(string)
+		if state[sig.Key] != sig.Secret || !ok {
+			return nil, fmt.Errorf("the state of %s is encrypted, export the stack with --show-secrets", moduleURN)
+		}
+		var rawState string
+		if err := json.Unmarshal([]byte(plaintext), &rawState); err != nil {
+			return nil, fmt.Errorf("failed to decode the state of %s: %w", moduleURN, err)
+		}
+		return json.RawMessage(rawState), nil
+	default:
+		return nil, fmt.Errorf("unexpected state of %s", moduleURN)
+	}
+}
+
+func setExportedModuleState(outputs map[string]any, state json.RawMessage) error {
+	plaintext, err := json.Marshal(string(state))
+	if err != nil {
+		return err
+	}
+	outputs[moduleResourceStatePropName] = map[string]any{
+		sig.Key:     sig.Secret,
+		"plaintext": string(plaintext),
+	}
+	return nil
+}
+
+// stringsFlag is a flag that may be passed several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/sig"
+)
+
+func TestMoveStateBetweenModuleInstances(t *testing.T) {
+	t.Parallel()
+
+	networkURN := "urn:pulumi:dev::proj::vpc:index:Module::network"
+	subnetsURN := "urn:pulumi:dev::proj::vpc:index:Module::subnets"
+	// The raw Terraform states of the two instances, as exported with --show-secrets.
+	exportedState := func(state string) map[string]any {
+		plaintext, err := json.Marshal(state)
+		require.NoError(t, err)
+		return map[string]any{sig.Key: sig.Secret, "plaintext": string(plaintext)}
+	}
+	stack := map[string]any{
+		"version": 3,
+		"deployment": map[string]any{
+			"manifest": map[string]any{"time": "2026-01-01T00:00:00Z"},
+			"resources": []any{
+				map[string]any{"urn": "urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev", "custom": false},
+				map[string]any{"urn": networkURN, "outputs": map[string]any{
+					moduleResourceStatePropName: exportedState(`{"version":4,"serial":1,"resources":[` +
+						`{"module":"module.network","mode":"managed","type":"aws_vpc","name":"this","instances":[{}]},` +
+						`{"module":"module.network","mode":"managed","type":"aws_subnet","name":"private",` +
+						`"instances":[{"index_key":0,"attributes":{"id":"subnet-0"}},` +
+						`{"index_key":1,"attributes":{"id":"subnet-1"}}]}]}`),
+					"vpc_id": "vpc-1",
+				}},
+				map[string]any{"urn": subnetsURN, "outputs": map[string]any{
+					moduleResourceStatePropName: exportedState(`{"version":4,"serial":1,"resources":[]}`),
+				}},
+			},
+		},
+	}
+	contents, err := json.Marshal(stack)
+	require.NoError(t, err)
+	stackFile := filepath.Join(t.TempDir(), "stack.json")
+	require.NoError(t, os.WriteFile(stackFile, contents, 0600))
+
+	var stdout bytes.Buffer
+	err = MoveState([]string{
+		"--stack-file", stackFile, "--from", networkURN, "--to", subnetsURN, "--address", "aws_subnet.private",
+	}, &stdout)
+	require.NoError(t, err)
+	assert.Contains(t, stdout.String(), "Moved 2 resource instance(s)", "both instances of the subnet are moved")
+
+	contents, err = os.ReadFile(stackFile)
+	require.NoError(t, err)
+	var moved struct {
+		Version    int `json:"version"`
+		Deployment struct {
+			Resources []struct {
+				URN     string         `json:"urn"`
+				Outputs map[string]any `json:"outputs"`
+			} `json:"resources"`
+		} `json:"deployment"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &moved))
+	assert.Equal(t, 3, moved.Version)
+	require.Len(t, moved.Deployment.Resources, 3)
+
+	resourceTypes := func(outputs map[string]any) []string {
+		secret, ok := outputs[moduleResourceStatePropName].(map[string]any)
+		require.True(t, ok, "the state stays a secret")
+		assert.Equal(t, sig.Secret, secret[sig.Key])
+		var rawState string
+		require.NoError(t, json.Unmarshal([]byte(secret["plaintext"].(string)), &rawState))
+		var state struct {
+			Resources []struct {
+				Module string `json:"module"`
+				Type   string `json:"type"`
+			} `json:"resources"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(rawState), &state))
+		var types []string
+		for _, r := range state.Resources {
+			types = append(types, r.Module+"."+r.Type)
+		}
+		return types
+	}
+	assert.Equal(t, []string{"module.network.aws_vpc"}, resourceTypes(moved.Deployment.Resources[1].Outputs))
+	assert.Equal(t, "vpc-1", moved.Deployment.Resources[1].Outputs["vpc_id"])
+	assert.Equal(t, []string{"module.subnets.aws_subnet"}, resourceTypes(moved.Deployment.Resources[2].Outputs))
+
+	t.Run("encrypted state", func(t *testing.T) {
+		t.Parallel()
+
+		stack := map[string]any{
+			"version": 3,
+			"deployment": map[string]any{"resources": []any{
+				map[string]any{"urn": networkURN, "outputs": map[string]any{
+					moduleResourceStatePropName: map[string]any{sig.Key: sig.Secret, "ciphertext": "AAAA"},
+				}},
+				map[string]any{"urn": subnetsURN, "outputs": map[string]any{
+					moduleResourceStatePropName: map[string]any{sig.Key: sig.Secret, "ciphertext": "AAAA"},
+				}},
+			}},
+		}
+		contents, err := json.Marshal(stack)
+		require.NoError(t, err)
+		stackFile := filepath.Join(t.TempDir(), "stack.json")
+		require.NoError(t, os.WriteFile(stackFile, contents, 0600))
+
+		err = MoveState([]string{
+			"--stack-file", stackFile, "--from", networkURN, "--to", subnetsURN, "--address", "aws_subnet.private",
+		}, &bytes.Buffer{})
+		assert.ErrorContains(t, err, "export the stack with --show-secrets")
+	})
+}
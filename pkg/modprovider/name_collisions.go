@@ -0,0 +1,67 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+const (
+	// nameCollisionsError fails the inference of modules with inputs or outputs whose names normalize to the same
+	// Pulumi name, such as foo-bar and foo_bar. This is the default.
+	nameCollisionsError = "error"
+	// nameCollisionsSuffix gives the colliding inputs or outputs after the first, in the order of their Terraform
+	// names, a numeric suffix such as foo_bar_2.
+	nameCollisionsSuffix = "suffix"
+)
+
+func checkNameCollisionsMode(mode string) error {
+	if mode != nameCollisionsError && mode != nameCollisionsSuffix {
+		return fmt.Errorf("invalid nameCollisions module configuration %q, expected %q or %q",
+			mode, nameCollisionsError, nameCollisionsSuffix)
+	}
+	return nil
+}
+
+// resolveNameCollision returns the Pulumi key of the module input or output with the given Terraform name, normalized
+// to key. Keys already taken by another property are an error unless mode is nameCollisionsSuffix, in which case the
+// first free key with a numeric suffix is used instead. Taken keys would otherwise make the properties overwrite each
+// other in the field mappings.
+func resolveNameCollision(
+	kind string,
+	tfName string,
+	key resource.PropertyKey,
+	taken map[resource.PropertyKey]*schema.PropertySpec,
+	tfNames map[resource.PropertyKey]string,
+	mode string,
+) (resource.PropertyKey, error) {
+	if _, exists := taken[key]; !exists {
+		return key, nil
+	}
+	if mode != nameCollisionsSuffix {
+		return "", fmt.Errorf("more than one module %s maps to the Pulumi %s %q: %q and %q; rename one of them with "+
+			"renames, or set nameCollisions to %q in the module configuration", kind, kind, key, tfNames[key], tfName,
+			nameCollisionsSuffix)
+	}
+	for i := 2; ; i++ {
+		suffixed := resource.PropertyKey(fmt.Sprintf("%s_%d", key, i))
+		if _, exists := taken[suffixed]; !exists {
+			return suffixed, nil
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestNameCollisions(t *testing.T) {
+	module := loadTestModule(t, "colliding-names")
+
+	t.Run("error", func(t *testing.T) {
+		_, err := inferModuleSchemaFromContent("names", module, nil)
+		assert.EqualError(t, err, `more than one module input maps to the Pulumi input "foo_bar": "foo-bar" and `+
+			`"foo_bar"; rename one of them with renames, or set nameCollisions to "suffix" in the module configuration`)
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("names", module, &ModuleConfig{NameCollisions: "suffix"})
+		require.NoError(t, err)
+
+		// foo-bar sorts before foo_bar, so it keeps the normalized name
+		assert.Equal(t, numberType, inferredModule.Inputs["foo_bar"].TypeSpec)
+		assert.Equal(t, stringType, inferredModule.Inputs["foo_bar_2"].TypeSpec)
+		assert.Equal(t, map[resource.PropertyKey]resource.PropertyKey{
+			"foo_bar":   "foo-bar",
+			"foo_bar_2": "foo_bar",
+		}, inferredModule.SchemaFieldMappings.InputFieldMappings)
+
+		assert.Equal(t, numberType, inferredModule.Outputs["foo_bar"].TypeSpec)
+		assert.Equal(t, stringType, inferredModule.Outputs["foo_bar_2"].TypeSpec)
+		assert.Equal(t, map[resource.PropertyKey]resource.PropertyKey{
+			"foo_bar":   "foo-bar",
+			"foo_bar_2": "foo_bar",
+		}, inferredModule.SchemaFieldMappings.OutputFieldMappings)
+
+		// both values reach their own Terraform variable
+		assert.Equal(t, resource.PropertyMap{
+			"foo-bar": resource.NewNumberProperty(1),
+			"foo_bar": resource.NewStringProperty("one"),
+		}, inputsToTerraform(inferredModule, resource.PropertyMap{
+			"foo_bar":   resource.NewNumberProperty(1),
+			"foo_bar_2": resource.NewStringProperty("one"),
+		}))
+	})
+
+	t.Run("renames", func(t *testing.T) {
+		_, err := inferModuleSchemaFromContent("names", module, &ModuleConfig{
+			NameCollisions: "suffix",
+			Renames:        &ModuleRenames{Inputs: map[string]string{"foo_bar": "foo_bar"}},
+		})
+		assert.ErrorContains(t, err, `more than one module input maps to the Pulumi input "foo_bar"`)
+	})
+
+	t.Run("renames visited first", func(t *testing.T) {
+		// foo-bar sorts before foo_bar, which then collides with the rename
+		_, err := inferModuleSchemaFromContent("names", module, &ModuleConfig{
+			NameCollisions: "suffix",
+			Renames:        &ModuleRenames{Outputs: map[string]string{"foo-bar": "foo_bar"}},
+		})
+		assert.ErrorContains(t, err, `more than one module output maps to the Pulumi output "foo_bar"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := inferModuleSchemaFromContent("names", module, &ModuleConfig{NameCollisions: "merge"})
+		assert.EqualError(t, err, `invalid nameCollisions module configuration "merge", expected "error" or "suffix"`)
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// operationSummaryOutputName is the module output added by the operationSummary module configuration.
+const operationSummaryOutputName resource.PropertyKey = "operationSummary"
+
+const (
+	operationSummaryPlan  = "plan"
+	operationSummaryApply = "apply"
+
+	operationSummarySucceeded = "succeeded"
+	operationSummaryFailed    = "failed"
+)
+
+// operationSummary summarizes the last plan or apply of a module instance for CI pipelines, which read it with
+// `pulumi stack output` instead of scraping the logs. It only holds counts and durations, never attribute values, so
+// it cannot leak secrets and is not marked secret.
+type operationSummary struct {
+	operation string
+	status    string
+	create    int
+	update    int
+	replace   int
+	delete    int
+	unchanged int
+	duration  time.Duration
+}
+
+// inferOperationSummaryOutput adds the operationSummary output and its supporting type to the module schema.
+func inferOperationSummaryOutput(inferredModule *InferredModuleSchema, packageName packageName) error {
+	if _, exists := inferredModule.Outputs[operationSummaryOutputName]; exists {
+		return fmt.Errorf("the output %q summarizing the last operation collides with a module output",
+			operationSummaryOutputName)
+	}
+	token := fmt.Sprintf("%s:index:OperationSummary", packageName)
+	if _, exists := inferredModule.SupportingTypes[token]; exists {
+		return fmt.Errorf("the type %q of the operation summary collides with a supporting type", token)
+	}
+
+	count := func(what string) schema.PropertySpec {
+		return schema.PropertySpec{
+			Description: fmt.Sprintf("The number of child resources %s.", what),
+			TypeSpec:    integerType,
+		}
+	}
+	inferredModule.SupportingTypes[token] = &schema.ComplexTypeSpec{
+		ObjectTypeSpec: schema.ObjectTypeSpec{
+			Description: "Summary of the last plan or apply of the module.",
+			Type:        objectTypeName,
+			Properties: map[string]schema.PropertySpec{
+				"operation": {
+					Description: `The operation, either "plan" for previews or "apply" for updates.`,
+					TypeSpec:    stringType,
+				},
+				"status": {
+					Description: `The outcome of the operation, either "succeeded" or "failed".`,
+					TypeSpec:    stringType,
+				},
+				"create":    count("created"),
+				"update":    count("updated in place"),
+				"replace":   count("replaced"),
+				"delete":    count("deleted"),
+				"unchanged": count("left unchanged"),
+				"durationSeconds": {
+					Description: "How long the operation took, in seconds.",
+					TypeSpec:    numberType,
+				},
+			},
+			Required: []string{
+				"operation", "status", "create", "update", "replace", "delete", "unchanged", "durationSeconds",
+			},
+		},
+	}
+	inferredModule.Outputs[operationSummaryOutputName] = &schema.PropertySpec{
+		Description: "Summary of the last plan or apply of the module, with the number of child resources per " +
+			"kind of change and the duration. Contains no resource attributes.",
+		TypeSpec: refType("#/types/" + token),
+	}
+	return nil
+}
+
+// summarizeOperation counts the changes of a plan. The counts of an apply are those of the plan it carried out.
+func summarizeOperation(
+	operation string,
+	plan *tfsandbox.Plan,
+	duration time.Duration,
+	err error,
+) operationSummary {
+	summary := operationSummary{
+		operation: operation,
+		status:    operationSummarySucceeded,
+		duration:  duration,
+	}
+	if err != nil {
+		summary.status = operationSummaryFailed
+	}
+	plan.VisitResourcePlans(func(rp *tfsandbox.ResourcePlan) {
+		switch rp.ChangeKind() {
+		case tfsandbox.Create:
+			summary.create++
+		case tfsandbox.Update:
+			summary.update++
+		case tfsandbox.Replace, tfsandbox.ReplaceDestroyBeforeCreate:
+			summary.replace++
+		case tfsandbox.Delete:
+			summary.delete++
+		case tfsandbox.NoOp:
+			summary.unchanged++
+		}
+	})
+	return summary
+}
+
+func (s operationSummary) value() resource.PropertyValue {
+	// durations are rounded to milliseconds, finer precision is noise for pipelines
+	seconds := math.Round(s.duration.Seconds()*1000) / 1000
+	return resource.NewObjectProperty(resource.PropertyMap{
+		"operation":       resource.NewStringProperty(s.operation),
+		"status":          resource.NewStringProperty(s.status),
+		"create":          resource.NewNumberProperty(float64(s.create)),
+		"update":          resource.NewNumberProperty(float64(s.update)),
+		"replace":         resource.NewNumberProperty(float64(s.replace)),
+		"delete":          resource.NewNumberProperty(float64(s.delete)),
+		"unchanged":       resource.NewNumberProperty(float64(s.unchanged)),
+		"durationSeconds": resource.NewNumberProperty(seconds),
+	})
+}
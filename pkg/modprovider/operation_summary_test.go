@@ -0,0 +1,117 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestOperationSummary(t *testing.T) {
+	config := &ModuleConfig{OperationSummary: true}
+	inferredModule, err := inferModuleSchemaFromContent("buckets", loadTestModule(t, "child-outputs"), config)
+	require.NoError(t, err)
+
+	output := inferredModule.Outputs[operationSummaryOutputName]
+	require.NotNil(t, output)
+	assert.False(t, output.Secret)
+	assert.Equal(t, refType("#/types/buckets:index:OperationSummary"), output.TypeSpec)
+	summaryType := inferredModule.SupportingTypes["buckets:index:OperationSummary"]
+	require.NotNil(t, summaryType)
+
+	change := func(name string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address:       "module.mymod.aws_s3_bucket." + name,
+			ModuleAddress: "module.mymod",
+			Mode:          tfjson.ManagedResourceMode,
+			Type:          "aws_s3_bucket",
+			Name:          name,
+			Change: &tfjson.Change{
+				Actions:         actions,
+				Before:          map[string]any{"bucket": name},
+				After:           map[string]any{"bucket": name},
+				AfterSensitive:  map[string]any{"bucket": true},
+				BeforeSensitive: map[string]any{"bucket": true},
+			},
+		}
+	}
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			change("a", tfjson.ActionCreate),
+			change("b", tfjson.ActionCreate),
+			change("c", tfjson.ActionUpdate),
+			change("d", tfjson.ActionDelete, tfjson.ActionCreate),
+			change("e", tfjson.ActionDelete),
+			change("f", tfjson.ActionNoop),
+		},
+	})
+	require.NoError(t, err)
+
+	summary := summarizeOperation(operationSummaryApply, plan, 1234567*time.Microsecond, nil).value()
+	require.True(t, summary.IsObject())
+	assert.False(t, summary.ContainsSecrets())
+	assert.Equal(t, resource.NewObjectProperty(resource.PropertyMap{
+		"operation":       resource.NewStringProperty("apply"),
+		"status":          resource.NewStringProperty("succeeded"),
+		"create":          resource.NewNumberProperty(2),
+		"update":          resource.NewNumberProperty(1),
+		"replace":         resource.NewNumberProperty(1),
+		"delete":          resource.NewNumberProperty(1),
+		"unchanged":       resource.NewNumberProperty(1),
+		"durationSeconds": resource.NewNumberProperty(1.235),
+	}), summary)
+
+	// the summary holds exactly the required properties of its type
+	var keys []string
+	for k := range summary.ObjectValue() {
+		keys = append(keys, string(k))
+	}
+	assert.ElementsMatch(t, summaryType.Required, keys)
+	for _, k := range keys {
+		assert.Contains(t, summaryType.Properties, k)
+	}
+
+	failed := summarizeOperation(operationSummaryPlan, plan, time.Second, errors.New("boom")).value().ObjectValue()
+	assert.Equal(t, resource.NewStringProperty("plan"), failed["operation"])
+	assert.Equal(t, resource.NewStringProperty("failed"), failed["status"])
+}
+
+func TestOperationSummaryCollision(t *testing.T) {
+	_, err := inferModuleSchemaFromContent("buckets", loadTestModule(t, "child-outputs"), &ModuleConfig{
+		OperationSummary: true,
+		Renames:          &ModuleRenames{Outputs: map[string]string{"bucket_id": "operationSummary"}},
+	})
+	assert.ErrorContains(t, err, `the output "operationSummary" summarizing the last operation collides`)
+}
+
+func TestOperationSummaryIsNotAModuleOutput(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("buckets", loadTestModule(t, "child-outputs"),
+		&ModuleConfig{OperationSummary: true})
+	require.NoError(t, err)
+
+	// the module does not declare operationSummary, referring to it would fail every plan
+	assert.Equal(t, []string{"bucket_id", "internal_output_is_secret_bucket_id"},
+		renderedTFOutputs(t, "child-outputs", inferredModule))
+}
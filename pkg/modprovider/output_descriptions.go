@@ -0,0 +1,78 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// describeOutput enriches the description of a module output from the module docs with what was inferred about it:
+// its type, whether it passes an input through or is computed from the resources of the module, and whether it is
+// secret. It is applied when the module is configured with describeOutputs.
+func describeOutput(description string, typeSpec schema.TypeSpec, secret bool, expr hcl.Expression) string {
+	facts := []string{fmt.Sprintf("Inferred type: %s.", describeType(typeSpec))}
+	if variableName, ok := isVariableReference(expr); ok {
+		facts = append(facts, fmt.Sprintf("Passes through the `%s` input.", variableName))
+	} else if referencesResources(expr) {
+		facts = append(facts, "Computed from the resources of the module, so it may be unknown during previews.")
+	}
+	if secret {
+		facts = append(facts, "Secret: the value is sensitive and encrypted in the Pulumi state.")
+	}
+
+	inferred := strings.Join(facts, " ")
+	if description = strings.TrimSpace(description); description == "" {
+		return inferred
+	}
+	return description + "\n\n" + inferred
+}
+
+// describeType names a schema type in prose, such as "list of string" or "Endpoint object".
+func describeType(t schema.TypeSpec) string {
+	if token, ok := strings.CutPrefix(t.Ref, "#/types/"); ok {
+		name := token[strings.LastIndex(token, ":")+1:]
+		return name + " object"
+	}
+	switch {
+	case t.Ref == anyType.Ref:
+		return "any"
+	case t.Type == "array" && t.Items != nil:
+		return "list of " + describeType(*t.Items)
+	case t.Type == objectTypeName && t.AdditionalProperties != nil:
+		return "map of " + describeType(*t.AdditionalProperties)
+	case t.Type != "":
+		return t.Type
+	default:
+		return "any"
+	}
+}
+
+// referencesResources reports whether an expression refers to managed resources, data sources or nested modules,
+// whose attributes may only be known once the module is applied. Variables, locals and other symbols are not
+// considered, even though locals may themselves refer to resources.
+func referencesResources(expr hcl.Expression) bool {
+	for _, traversal := range expr.Variables() {
+		switch traversal.RootName() {
+		case "var", "local", "path", "terraform", "count", "each", "self":
+		default:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribeOutputs(t *testing.T) {
+	module := loadTestModule(t, "described-outputs")
+
+	inferredModule, err := inferModuleSchemaFromContent("buckets", module, &ModuleConfig{DescribeOutputs: true})
+	require.NoError(t, err)
+
+	assert.Equal(t, "The name of the bucket.\n\nInferred type: string. Passes through the `name` input.",
+		inferredModule.Outputs["name"].Description)
+	assert.Equal(t, "The ARNs of the buckets.\n\nInferred type: any. Computed from the resources of the "+
+		"module, so it may be unknown during previews.", inferredModule.Outputs["bucket_arns"].Description)
+	assert.Equal(t, "Inferred type: map of list of string. Passes through the `tags` input.",
+		inferredModule.Outputs["tags"].Description)
+	assert.Equal(t, "Inferred type: string. Passes through the `password` input. Secret: the value is sensitive "+
+		"and encrypted in the Pulumi state.", inferredModule.Outputs["password"].Description)
+
+	// descriptions come from the module docs alone by default
+	inferredModule, err = inferModuleSchemaFromContent("buckets", module, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "The ARNs of the buckets.", inferredModule.Outputs["bucket_arns"].Description)
+	assert.Empty(t, inferredModule.Outputs["password"].Description)
+}
@@ -0,0 +1,100 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// refreshMode selects how module instances are refreshed.
+type refreshMode string
+
+const (
+	// refreshModeFull reads every child resource from its provider, detecting drift. This is the default.
+	refreshModeFull refreshMode = "full"
+	// refreshModeOutputs re-evaluates the outputs of the module against the resources recorded in its state, without
+	// reading them from their providers, see refreshOutputs.
+	refreshModeOutputs refreshMode = "outputs"
+)
+
+func parseRefreshMode(s string) (refreshMode, error) {
+	switch m := refreshMode(s); m {
+	case "":
+		return refreshModeFull, nil
+	case refreshModeFull, refreshModeOutputs:
+		return m, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", refreshModeVariableName,
+			refreshModeFull, refreshModeOutputs, s)
+	}
+}
+
+// refreshOutputs re-evaluates the outputs of a module instance against the resources already in its state, like
+// `terraform plan -refresh=false` on an unchanged configuration. Providers are configured but not asked to read the
+// child resources, so drift is not detected; data sources are still read as in every plan. Nothing is applied, the
+// state of the module instance is left as it is and the fresh outputs are those of the returned plan.
+//
+// This is only meaningful when the configuration matches the state, so that the plan changes nothing but the outputs.
+// When the plan changes any resource, as after upgrading the module without updating it, or leaves an output unknown,
+// refreshOutputs returns a nil plan and state so that the caller falls back to a full refresh. Otherwise the returned
+// state is the prior state, which serves for reporting the views of the child resources.
+func refreshOutputs(
+	ctx context.Context,
+	tf *tfsandbox.ModuleRuntime,
+	logger tfsandbox.Logger,
+) (*tfsandbox.Plan, *tfsandbox.State, error) {
+	plan, err := tf.PlanNoRefresh(ctx, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error planning the refresh of outputs: %w", err)
+	}
+
+	var changed []string
+	plan.VisitResourcePlans(func(rp *tfsandbox.ResourcePlan) {
+		if rp.ChangeKind() != tfsandbox.NoOp {
+			changed = append(changed, string(rp.Address()))
+		}
+	})
+	if len(changed) > 0 {
+		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("the configuration of the module changes %d resources, "+
+			"refreshing them instead of only the outputs", len(changed)))
+		return nil, nil, nil
+	}
+	if resource.NewObjectProperty(plan.Outputs()).ContainsUnknowns() {
+		logger.Log(ctx, tfsandbox.Debug, "the outputs of the module are only known after apply, "+
+			"refreshing the resources instead of only the outputs")
+		return nil, nil, nil
+	}
+
+	state, err := tf.Show(ctx, logger)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading the state of the module: %w", err)
+	}
+	return plan, state, nil
+}
+
+// withPlannedOutputs replaces the module outputs recorded in state, which are those of the last apply, with the
+// outputs planned by refreshOutputs. The meta properties persisting the state are kept.
+func withPlannedOutputs(outputs resource.PropertyMap, state *tfsandbox.State, plan *tfsandbox.Plan) {
+	for key := range state.Outputs() {
+		delete(outputs, key)
+	}
+	maps.Copy(outputs, plan.Outputs())
+}
@@ -0,0 +1,142 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestParseRefreshMode(t *testing.T) {
+	for input, want := range map[string]refreshMode{
+		"":        refreshModeFull,
+		"full":    refreshModeFull,
+		"outputs": refreshModeOutputs,
+	} {
+		got, err := parseRefreshMode(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := parseRefreshMode("state")
+	assert.EqualError(t, err, `provider option "refreshMode" must be one of "full" or "outputs", got "state"`)
+}
+
+func TestRefreshOutputs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "output_refresh", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	opts := moduleOptions{executor: stub, refreshMode: refreshModeOutputs}
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+	workdir := tfsandboxWorkdir(t, opts.executor, modURN)
+	t.Cleanup(func() {
+		os.RemoveAll(workdir)
+	})
+
+	refresh := func(t *testing.T) (*tfsandbox.Plan, *tfsandbox.State, []string) {
+		tf, err := h.prepSandbox(ctx, modURN, resource.PropertyMap{}, nil, &InferredModuleSchema{},
+			TFModuleSource(src), "", map[string]resource.PropertyMap{}, opts)
+		require.NoError(t, err)
+		require.NoError(t, os.Remove(filepath.Join(workdir, "commands.log")))
+
+		plan, state, err := refreshOutputs(ctx, tf, tfsandbox.DiscardLogger)
+		require.NoError(t, err)
+		log, err := os.ReadFile(filepath.Join(workdir, "commands.log"))
+		require.NoError(t, err)
+		return plan, state, strings.Split(strings.TrimSpace(string(log)), "\n")
+	}
+	commandsOf := func(commands []string, name string) []string {
+		var found []string
+		for _, command := range commands {
+			if strings.HasPrefix(command, name+" ") {
+				found = append(found, command)
+			}
+		}
+		return found
+	}
+
+	t.Run("unchanged configuration", func(t *testing.T) {
+		plan, state, commands := refresh(t)
+		require.NotNil(t, plan)
+		require.NotNil(t, state)
+		assert.Equal(t, resource.PropertyMap{"greeting": resource.NewStringProperty("hello")}, state.Outputs(),
+			"the state is left as it is")
+
+		// The outputs come from the plan, planned from the state without reading the resources from the provider.
+		outputs := resource.PropertyMap{
+			"greeting":                  resource.NewStringProperty("hello"),
+			moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty("{}")),
+		}
+		withPlannedOutputs(outputs, state, plan)
+		assert.Equal(t, resource.PropertyMap{
+			"greeting":                  resource.NewStringProperty("bonjour"),
+			moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty("{}")),
+		}, outputs)
+
+		// The plan reads the data sources again, so their views are taken from it rather than the state.
+		amiOf := func(visit func(func(*tfsandbox.ResourceState))) (ami resource.PropertyValue) {
+			visit(func(rs *tfsandbox.ResourceState) { ami = rs.AttributeValues()["id"] })
+			return ami
+		}
+		assert.Equal(t, resource.NewStringProperty("ami-new"), amiOf(plan.VisitDataSources))
+		assert.Equal(t, resource.NewStringProperty("ami-old"), amiOf(state.VisitDataSources))
+
+		plans := commandsOf(commands, "plan")
+		require.Len(t, plans, 1)
+		assert.Contains(t, plans[0], "-refresh=false")
+		assert.Empty(t, commandsOf(commands, "apply"))
+		assert.Empty(t, commandsOf(commands, "refresh"))
+		for _, command := range commands {
+			assert.NotContains(t, command, "-refresh-only")
+		}
+	})
+
+	t.Run("changed configuration", func(t *testing.T) {
+		t.Setenv("OUTPUT_REFRESH_STUB_ACTION", "update")
+
+		plan, state, commands := refresh(t)
+		assert.Nil(t, plan)
+		assert.Nil(t, state, "a full refresh is needed")
+		assert.Empty(t, commandsOf(commands, "apply"))
+	})
+
+	t.Run("unknown outputs", func(t *testing.T) {
+		t.Setenv("OUTPUT_REFRESH_STUB_UNKNOWN", "true")
+
+		plan, state, _ := refresh(t)
+		assert.Nil(t, plan)
+		assert.Nil(t, state, "a full refresh is needed")
+	})
+}
@@ -29,6 +29,185 @@ type (
 // if needed to customize the behavior of the provider.
 type ModuleConfig struct {
 	*InferredModuleSchema `json:",inline"`
+
+	// Renames customizes the Pulumi names of module inputs and outputs in the generated SDK.
+	Renames *ModuleRenames `json:"renames,omitempty"`
+
+	// InferIntegers types number inputs and outputs that look like integers, such as ports and counts, as integers
+	// instead of numbers (see [isIntegerLikeVariable]).
+	InferIntegers bool `json:"inferIntegers,omitempty"`
+
+	// InferFromDefaults types inputs declared with type = any after the structure of their default values (see
+	// [shapeOfDefault]) instead of as Any.
+	InferFromDefaults bool `json:"inferFromDefaults,omitempty"`
+
+	// ChildOutputs exposes attributes of the resources declared by the module as additional module outputs, keyed by
+	// Terraform resource type. See [childResourceOutputName] for how the outputs are named.
+	ChildOutputs map[string][]string `json:"childOutputs,omitempty"`
+
+	// OmitEmptyOutputs leaves out module outputs that are null or empty from the outputs of the module resource.
+	// Outputs listed in nonNilOutputs are always kept.
+	OmitEmptyOutputs bool `json:"omitEmptyOutputs,omitempty"`
+
+	// OmitNullInputs leaves module inputs set to null out of the module block, so that the defaults of the module
+	// apply to them as to inputs that are not set.
+	OmitNullInputs bool `json:"omitNullInputs,omitempty"`
+
+	// ReadmeSummary uses the summary of the module README (see [readmeSummary]) as the description of the generated
+	// package.
+	ReadmeSummary bool `json:"readmeSummary,omitempty"`
+
+	// ResourceTypesSummary lists the types of the resources the module manages (see [moduleResourceTypes]) in the
+	// description of the generated package.
+	ResourceTypesSummary bool `json:"resourceTypesSummary,omitempty"`
+
+	// NonNilOutputOverrides overrides whether individual outputs are guaranteed to be non-nil, keyed by the Pulumi
+	// name of the output. Unlike nonNilOutputs, setting an output to false removes the guarantee, including one that
+	// comes from the built-in schema overrides of well-known modules.
+	NonNilOutputOverrides map[string]bool `json:"nonNilOutputOverrides,omitempty"`
+
+	// BackendBlocks controls how backend and cloud blocks declared by the module are handled, see
+	// [checkBackendBlocks]. Either "ignore" (the default) or "error".
+	BackendBlocks string `json:"backendBlocks,omitempty"`
+
+	// GitVersionScheme controls how the package version of git sources referencing no version is derived, see
+	// [pinGitSourceCommit]. Either "none" (the default) or "commit".
+	GitVersionScheme string `json:"gitVersionScheme,omitempty"`
+
+	// ViewGroups groups the views of child resources under intermediate views of logical components, keyed by the
+	// name of the component. See [groupViewSteps] for how the address patterns are matched.
+	ViewGroups map[string][]string `json:"viewGroups,omitempty"`
+
+	// OperationSummary adds an output summarizing the last plan or apply of the module resource, see
+	// [operationSummary].
+	OperationSummary bool `json:"operationSummary,omitempty"`
+
+	// DescribeOutputs adds what was inferred about module outputs, such as their type and whether they are secret, to
+	// their descriptions, see [describeOutput].
+	DescribeOutputs bool `json:"describeOutputs,omitempty"`
+
+	// NameCollisions controls how module inputs or outputs whose names normalize to the same Pulumi name are handled,
+	// see [resolveNameCollision]. Either "error" (the default) or "suffix".
+	NameCollisions string `json:"nameCollisions,omitempty"`
+
+	// TypeNaming controls how the supporting types of object inputs and outputs are named, see
+	// [nameSupportingTypes]. Either "path" (the default) or "short".
+	TypeNaming string `json:"typeNaming,omitempty"`
+
+	// MaxTypeNameLength bounds the length of the names of supporting types, numbering names that truncating makes
+	// collide. Zero, the default, leaves them unbounded.
+	MaxTypeNameLength int `json:"maxTypeNameLength,omitempty"`
+}
+
+// ModuleRenames maps Terraform names of module inputs and outputs to the names they should have in Pulumi. The
+// Terraform-side names are preserved when running the module.
+type ModuleRenames struct {
+	Inputs  map[string]string `json:"inputs,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
+
+func (c *ModuleConfig) inputRename(tfName string) (string, bool) {
+	if c == nil || c.Renames == nil {
+		return "", false
+	}
+	renamed, ok := c.Renames.Inputs[tfName]
+	return renamed, ok
+}
+
+func (c *ModuleConfig) outputRename(tfName string) (string, bool) {
+	if c == nil || c.Renames == nil {
+		return "", false
+	}
+	renamed, ok := c.Renames.Outputs[tfName]
+	return renamed, ok
+}
+
+func (c *ModuleConfig) inferIntegers() bool {
+	return c != nil && c.InferIntegers
+}
+
+func (c *ModuleConfig) inferFromDefaults() bool {
+	return c != nil && c.InferFromDefaults
+}
+
+func (c *ModuleConfig) childOutputs() map[string][]string {
+	if c == nil {
+		return nil
+	}
+	return c.ChildOutputs
+}
+
+func (c *ModuleConfig) readmeSummary() bool {
+	return c != nil && c.ReadmeSummary
+}
+
+func (c *ModuleConfig) resourceTypesSummary() bool {
+	return c != nil && c.ResourceTypesSummary
+}
+
+func (c *ModuleConfig) nonNilOutputOverrides() map[string]bool {
+	if c == nil {
+		return nil
+	}
+	return c.NonNilOutputOverrides
+}
+
+func (c *ModuleConfig) backendBlocks() string {
+	if c == nil || c.BackendBlocks == "" {
+		return backendBlocksIgnore
+	}
+	return c.BackendBlocks
+}
+
+func (c *ModuleConfig) gitVersionScheme() string {
+	if c == nil || c.GitVersionScheme == "" {
+		return gitVersionSchemeNone
+	}
+	return c.GitVersionScheme
+}
+
+func (c *ModuleConfig) viewGroups() viewGroups {
+	if c == nil {
+		return nil
+	}
+	return c.ViewGroups
+}
+
+func (c *ModuleConfig) omitEmptyOutputs() bool {
+	return c != nil && c.OmitEmptyOutputs
+}
+
+func (c *ModuleConfig) omitNullInputs() bool {
+	return c != nil && c.OmitNullInputs
+}
+
+func (c *ModuleConfig) operationSummary() bool {
+	return c != nil && c.OperationSummary
+}
+
+func (c *ModuleConfig) describeOutputs() bool {
+	return c != nil && c.DescribeOutputs
+}
+
+func (c *ModuleConfig) nameCollisions() string {
+	if c == nil || c.NameCollisions == "" {
+		return nameCollisionsError
+	}
+	return c.NameCollisions
+}
+
+func (c *ModuleConfig) typeNaming() string {
+	if c == nil || c.TypeNaming == "" {
+		return typeNamingPath
+	}
+	return c.TypeNaming
+}
+
+func (c *ModuleConfig) maxTypeNameLength() int {
+	if c == nil {
+		return 0
+	}
+	return c.MaxTypeNameLength
 }
 
 // The parameters for the provider identify the Terraform module to specialize to.
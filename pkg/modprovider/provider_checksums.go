@@ -0,0 +1,115 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/opentofu/depsfile"
+)
+
+// providerChecksumsPolicy selects how strictly the provider packages installed by init are verified against the
+// dependency lock file recorded in the state of a module instance.
+type providerChecksumsPolicy string
+
+const (
+	// providerChecksumsVerify verifies the packages of the providers recorded in the lock file against their
+	// checksums and records the providers that are not locked yet. This is the default and matches Terraform.
+	providerChecksumsVerify providerChecksumsPolicy = "verify"
+	// providerChecksumsStrict additionally refuses to change the lock file of existing module instances, like
+	// `terraform init -lockfile=readonly`, so that only packages matching the recorded checksums are ever installed.
+	providerChecksumsStrict providerChecksumsPolicy = "strict"
+)
+
+func parseProviderChecksumsPolicy(s string) (providerChecksumsPolicy, error) {
+	switch p := providerChecksumsPolicy(s); p {
+	case "":
+		return providerChecksumsVerify, nil
+	case providerChecksumsVerify, providerChecksumsStrict:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", providerChecksumsVariableName,
+			providerChecksumsVerify, providerChecksumsStrict, s)
+	}
+}
+
+// lockFilePath is the dependency lock file that init reads and writes in the working directory of a module instance.
+func lockFilePath(workdir string) string {
+	return filepath.Join(workdir, ".terraform.lock.hcl")
+}
+
+// readLockFile reads the dependency lock file of workdir, which is missing for modules requiring no providers.
+func readLockFile(workdir string) ([]byte, error) {
+	contents, err := os.ReadFile(lockFilePath(workdir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return contents, err
+}
+
+// enforceLockedProviders implements providerChecksumsStrict after init. It fails when init selected a provider or a
+// provider version that the recorded lock file does not list, and otherwise restores the recorded lock file so that
+// checksums added by init are not recorded either.
+func enforceLockedProviders(workdir string, recorded []byte) error {
+	before, diags := depsfile.LoadLocksFromBytes(recorded, lockFilePath(workdir))
+	if diags.HasErrors() {
+		return fmt.Errorf("error reading the recorded dependency lock file: %w", diags.Err())
+	}
+	current, err := readLockFile(workdir)
+	if err != nil {
+		return fmt.Errorf("error reading the dependency lock file: %w", err)
+	}
+	after, diags := depsfile.LoadLocksFromBytes(current, lockFilePath(workdir))
+	if diags.HasErrors() {
+		return fmt.Errorf("error reading the dependency lock file: %w", diags.Err())
+	}
+
+	// The registry host is left out, as it differs between Terraform and OpenTofu.
+	locked := map[string]string{}
+	for addr, lock := range before.AllProviders() {
+		locked[addr.Namespace+"/"+addr.Type] = lock.Version().String()
+	}
+	var changes []string
+	for addr, lock := range after.AllProviders() {
+		name := addr.Namespace + "/" + addr.Type
+		v, ok := locked[name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("provider %s %s is not locked", name, lock.Version()))
+		case v != lock.Version().String():
+			changes = append(changes, fmt.Sprintf("provider %s is locked at %s but init selected %s", name, v,
+				lock.Version()))
+		}
+	}
+	if len(changes) > 0 {
+		slices.Sort(changes)
+		return fmt.Errorf("the dependency lock file of the module cannot change since the %q provider option is %q: "+
+			"%s; change the module version to let init update the lock file", providerChecksumsVariableName,
+			providerChecksumsStrict, strings.Join(changes, "; "))
+	}
+
+	if slices.Equal(current, recorded) {
+		return nil
+	}
+	if err := os.WriteFile(lockFilePath(workdir), recorded, 0o600); err != nil {
+		return fmt.Errorf("error restoring the dependency lock file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestProviderChecksumVerification(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "provider_checksums", "stub.sh"))
+	require.NoError(t, err)
+
+	newRuntime := func(t *testing.T) *tfsandbox.ModuleRuntime {
+		tf, err := tfsandbox.NewRuntimeFromExecutable(ctx, tfsandbox.DiscardLogger, tfsandbox.Workdir{t.Name()}, nil,
+			stub)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+		return tf
+	}
+
+	recorded := []byte("provider \"registry.opentofu.org/hashicorp/aws\" {\n  version = \"5.0.0\"\n" +
+		"  hashes = [\n    \"zh:recorded\",\n  ]\n}\n")
+
+	t.Run("init fails on a checksum mismatch", func(t *testing.T) {
+		t.Setenv("STUB_CHECKSUM_MISMATCH", "1")
+		tf := newRuntime(t)
+		err := tf.Init(ctx, tfsandbox.DiscardLogger)
+		require.ErrorIs(t, err, tfsandbox.ErrProviderChecksumMismatch)
+		assert.ErrorContains(t, err, "provider package does not match the checksums recorded in the dependency "+
+			"lock file")
+		assert.ErrorContains(t, err, "hashicorp/aws 5.0.0 doesn't match any of the checksums")
+	})
+
+	t.Run("strict verification keeps the recorded lock file", func(t *testing.T) {
+		t.Setenv("STUB_LOCKED_AWS_VERSION", "5.0.0")
+		tf := newRuntime(t)
+		require.NoError(t, tf.Init(ctx, tfsandbox.DiscardLogger))
+
+		require.NoError(t, enforceLockedProviders(tf.WorkingDir(), recorded))
+		lockFile, err := readLockFile(tf.WorkingDir())
+		require.NoError(t, err)
+		assert.Equal(t, string(recorded), string(lockFile), "checksums added by init are not recorded")
+	})
+
+	t.Run("strict verification refuses new provider versions", func(t *testing.T) {
+		t.Setenv("STUB_LOCKED_AWS_VERSION", "5.1.0")
+		tf := newRuntime(t)
+		require.NoError(t, tf.Init(ctx, tfsandbox.DiscardLogger))
+
+		err := enforceLockedProviders(tf.WorkingDir(), recorded)
+		assert.ErrorContains(t, err, `the dependency lock file of the module cannot change since the `+
+			`"providerChecksums" provider option is "strict": provider hashicorp/aws is locked at 5.0.0 but init `+
+			`selected 5.1.0`)
+
+		err = enforceLockedProviders(tf.WorkingDir(), nil)
+		assert.ErrorContains(t, err, "provider hashicorp/aws 5.1.0 is not locked")
+	})
+
+	t.Run("the policy is validated", func(t *testing.T) {
+		p, err := parseProviderChecksumsPolicy("")
+		require.NoError(t, err)
+		assert.Equal(t, providerChecksumsVerify, p)
+
+		_, err = parseProviderChecksumsPolicy("lax")
+		assert.ErrorContains(t, err, `provider option "providerChecksums" must be one of "verify" or "strict"`)
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// providerEndpointsBlock is the provider block that overrides the URLs of service APIs, supported by the aws provider
+// among others. Pointing it to a local emulator such as LocalStack allows testing modules without cloud access.
+const providerEndpointsBlock = "endpoints"
+
+// providerEndpoints are service endpoint URLs keyed by provider name and then by service, such as
+// {"aws": {"s3": "http://localhost:4566"}}.
+type providerEndpoints map[string]map[string]string
+
+// providerEndpointsOption reads the endpoint overrides from the provider config, falling back to the environment when
+// the option is not set. The option may arrive either as an object or as a JSON-encoded string. Overrides have to
+// name providers that the module requires.
+func providerEndpointsOption(
+	config resource.PropertyMap,
+	providerVariables map[string]schema.PropertySpec,
+) (providerEndpoints, error) {
+	endpoints := providerEndpoints{}
+	v, ok := config[providerEndpointsVariableName]
+	switch {
+	case !ok || !v.HasValue():
+		raw := os.Getenv(providerEndpointsEnvironmentVariable)
+		if raw == "" {
+			return nil, nil
+		}
+		if err := json.Unmarshal([]byte(raw), &endpoints); err != nil {
+			return nil, fmt.Errorf("%s must map provider names to objects of endpoint URLs keyed by service: %w",
+				providerEndpointsEnvironmentVariable, err)
+		}
+	case unwrapSecret(v).IsString():
+		if err := json.Unmarshal([]byte(unwrapSecret(v).StringValue()), &endpoints); err != nil {
+			return nil, fmt.Errorf("provider option %q must map provider names to objects of endpoint URLs keyed "+
+				"by service: %w", providerEndpointsVariableName, err)
+		}
+	case unwrapSecret(v).IsObject():
+		for providerName, services := range unwrapSecret(v).ObjectValue() {
+			services = unwrapSecret(services)
+			if !services.IsObject() {
+				return nil, fmt.Errorf("provider option %q must map provider names to objects, got %v for %q",
+					providerEndpointsVariableName, services.TypeString(), providerName)
+			}
+			endpoints[string(providerName)] = map[string]string{}
+			for service, url := range services.ObjectValue() {
+				url = unwrapSecret(url)
+				if !url.IsString() {
+					return nil, fmt.Errorf("provider option %q must map services to URLs, got %v for %s.%s",
+						providerEndpointsVariableName, url.TypeString(), providerName, service)
+				}
+				endpoints[string(providerName)][string(service)] = url.StringValue()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("provider option %q must be an object, got %v", providerEndpointsVariableName,
+			v.TypeString())
+	}
+
+	var requiredProviders []string
+	for name := range providerVariables {
+		if !slices.Contains(providerOptionNames, name) {
+			requiredProviders = append(requiredProviders, name)
+		}
+	}
+	slices.Sort(requiredProviders)
+	for providerName := range endpoints {
+		if !slices.Contains(requiredProviders, providerName) {
+			return nil, fmt.Errorf("provider option %q overrides endpoints of provider %q, which the module does "+
+				"not require. Required providers: %s", providerEndpointsVariableName, providerName,
+				strings.Join(requiredProviders, ", "))
+		}
+	}
+	return endpoints, nil
+}
+
+// withProviderEndpoints writes the endpoint overrides into the endpoints blocks of the provider configurations.
+// Endpoints that the provider configuration sets itself take precedence.
+func withProviderEndpoints(
+	config map[string]resource.PropertyMap,
+	endpoints providerEndpoints,
+) map[string]resource.PropertyMap {
+	if len(endpoints) == 0 {
+		return config
+	}
+	if config == nil {
+		config = map[string]resource.PropertyMap{}
+	}
+	for providerName, services := range endpoints {
+		block := resource.PropertyMap{}
+		for service, url := range services {
+			block[resource.PropertyKey(service)] = resource.NewStringProperty(url)
+		}
+
+		providerConfig := resource.PropertyMap{}
+		for k, v := range config[providerName] {
+			providerConfig[k] = v
+		}
+		// the block may also be given as a list holding a single object, as in the Terraform JSON syntax
+		if configured := singleBlockObject(unwrapSecret(providerConfig[providerEndpointsBlock])); configured.IsObject() {
+			for service, url := range configured.ObjectValue() {
+				block[service] = url
+			}
+		}
+		providerConfig[providerEndpointsBlock] = resource.NewObjectProperty(block)
+		config[providerName] = providerConfig
+	}
+	return config
+}
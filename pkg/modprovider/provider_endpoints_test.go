@@ -0,0 +1,88 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestProviderEndpointsAreWrittenToProviderBlocks(t *testing.T) {
+	providerVariables := map[string]schema.PropertySpec{
+		awsKey:                        {TypeSpec: mapType(anyType)},
+		providerEndpointsVariableName: {TypeSpec: mapType(mapType(stringType))},
+	}
+
+	endpoints, err := providerEndpointsOption(resource.PropertyMap{
+		providerEndpointsVariableName: resource.NewObjectProperty(resource.PropertyMap{
+			awsKey: resource.NewObjectProperty(resource.PropertyMap{
+				"s3":  resource.NewStringProperty("http://localhost:4566"),
+				"sts": resource.NewStringProperty("http://localhost:4566"),
+			}),
+		}),
+	}, providerVariables)
+	require.NoError(t, err)
+
+	// The program points sts elsewhere, which takes precedence.
+	providersConfig := withProviderEndpoints(cleanProvidersConfig(resource.PropertyMap{
+		awsKey: resource.NewStringProperty(`{"region":"us-east-1","endpoints":[{"sts":"http://sts.local:4566"}]}`),
+	}), endpoints)
+
+	workingDir := t.TempDir()
+	err = tfsandbox.CreateTFFile("mod", "terraform-aws-modules/s3-bucket/aws", "4.5.0", workingDir,
+		resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Provider map[string]map[string]any `json:"provider"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+
+	awsProvider := tfFile.Provider[awsKey]
+	require.NotNil(t, awsProvider, "expected an aws provider block in %s", contents)
+	assert.Equal(t, "us-east-1", awsProvider["region"])
+	assert.Equal(t, map[string]any{
+		"s3":  "http://localhost:4566",
+		"sts": "http://sts.local:4566",
+	}, awsProvider["endpoints"])
+
+	t.Run("from the environment", func(t *testing.T) {
+		t.Setenv(providerEndpointsEnvironmentVariable, `{"aws": {"s3": "http://localhost:4566"}}`)
+		endpoints, err := providerEndpointsOption(resource.PropertyMap{}, providerVariables)
+		require.NoError(t, err)
+		assert.Equal(t, providerEndpoints{awsKey: {"s3": "http://localhost:4566"}}, endpoints)
+	})
+
+	t.Run("provider not required by the module", func(t *testing.T) {
+		_, err := providerEndpointsOption(resource.PropertyMap{
+			providerEndpointsVariableName: resource.NewStringProperty(`{"google": {"storage": "http://localhost"}}`),
+		}, providerVariables)
+		assert.ErrorContains(t, err, `provider option "providerEndpoints" overrides endpoints of provider "google", `+
+			`which the module does not require. Required providers: aws`)
+	})
+}
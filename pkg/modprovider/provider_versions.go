@@ -0,0 +1,156 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pulumi/opentofu/configs"
+	"github.com/pulumi/opentofu/depsfile"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// providerConstraint is a version constraint that a module places on a Terraform provider in required_providers.
+type providerConstraint struct {
+	// module is the key of the module in modules.json, such as mymod or mymod.nested.
+	module string
+	// provider is the namespace and type of the provider, such as hashicorp/aws.
+	provider   string
+	constraint version.Constraints
+}
+
+func (c providerConstraint) String() string {
+	return fmt.Sprintf("%s %s (required by module %s)", c.provider, c.constraint, c.module)
+}
+
+// moduleProviderConstraints reads the provider version constraints of the modules installed by init in workdir,
+// including nested modules. Modules are installed before providers, so these are available even when installing the
+// providers failed.
+func moduleProviderConstraints(workdir string) ([]providerConstraint, error) {
+	manifest, err := tfsandbox.ReadModuleManifest(workdir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read modules.json file: %w", err)
+	}
+
+	var constraints []providerConstraint
+	for _, m := range manifest.Modules {
+		if m.Key == "" {
+			// the root module is generated by the provider and constrains nothing
+			continue
+		}
+		dir := m.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workdir, dir)
+		}
+		mod, diags := configs.NewParser(nil).LoadConfigDir(dir, configs.NewStaticModuleCall(nil, nil, "", ""))
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error while loading module %s: %w", m.Key, diags)
+		}
+		if mod.ProviderRequirements == nil {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(mod.ProviderRequirements.RequiredProviders)) {
+			rp := mod.ProviderRequirements.RequiredProviders[name]
+			if len(rp.Requirement.Required) == 0 {
+				continue
+			}
+			constraints = append(constraints, providerConstraint{
+				module:     m.Key,
+				provider:   rp.Type.Namespace + "/" + rp.Type.Type,
+				constraint: rp.Requirement.Required,
+			})
+		}
+	}
+	slices.SortFunc(constraints, func(a, b providerConstraint) int {
+		return strings.Compare(a.module+" "+a.provider, b.module+" "+b.provider)
+	})
+	return constraints, nil
+}
+
+// lockedProviderVersions reads the provider versions selected by init from the lock file in workdir, keyed by the
+// namespace and type of the provider. The registry host is left out, as it differs between Terraform and OpenTofu.
+func lockedProviderVersions(workdir string) (map[string]*version.Version, error) {
+	locks, diags := depsfile.LoadLocksFromFile(filepath.Join(workdir, ".terraform.lock.hcl"))
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("error reading the dependency lock file: %w", diags.Err())
+	}
+	versions := map[string]*version.Version{}
+	for addr, lock := range locks.AllProviders() {
+		v, err := version.NewVersion(lock.Version().String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid version locked for provider %s: %w", addr, err)
+		}
+		versions[addr.Namespace+"/"+addr.Type] = v
+	}
+	return versions, nil
+}
+
+// providerVersionViolations lists the constraints that the locked provider versions do not satisfy.
+func providerVersionViolations(constraints []providerConstraint, locked map[string]*version.Version) []string {
+	var violations []string
+	for _, c := range constraints {
+		v, ok := locked[c.provider]
+		if !ok || c.constraint.Check(v) {
+			continue
+		}
+		violations = append(violations, fmt.Sprintf("module %s requires provider %s %s, but init selected %s",
+			c.module, c.provider, c.constraint, v))
+	}
+	return violations
+}
+
+// warnOnProviderVersionViolations checks the provider versions selected by init against the constraints of the
+// module. Init normally refuses to select versions violating the constraints, so this is a safeguard against stale
+// lock files and plugin caches, whose failures would otherwise surface as cryptic provider errors.
+func warnOnProviderVersionViolations(ctx context.Context, logger tfsandbox.Logger, workdir string) {
+	constraints, err := moduleProviderConstraints(workdir)
+	if err != nil {
+		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("Not checking provider versions: %v", err))
+		return
+	}
+	locked, err := lockedProviderVersions(workdir)
+	if err != nil {
+		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("Not checking provider versions: %v", err))
+		return
+	}
+	for _, violation := range providerVersionViolations(constraints, locked) {
+		logger.Log(ctx, tfsandbox.Warn, "Incompatible provider version: "+violation)
+	}
+}
+
+// explainProviderConstraints adds the provider version constraints of the module to an init error caused by them,
+// such as when no released version satisfies a constraint or the lock file selects a version that does not.
+func explainProviderConstraints(workdir string, initErr error) error {
+	if !strings.Contains(initErr.Error(), "constraint") {
+		return initErr
+	}
+	constraints, err := moduleProviderConstraints(workdir)
+	if err != nil || len(constraints) == 0 {
+		return initErr
+	}
+	lines := make([]string, len(constraints))
+	for i, c := range constraints {
+		lines[i] = "  " + c.String()
+	}
+	return fmt.Errorf("%w\nthe module constrains the provider versions as follows, check that the constraints can be "+
+		"satisfied together:\n%s", initErr, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,85 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestUnsatisfiableProviderVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "provider_versions", "stub.sh"))
+	require.NoError(t, err)
+
+	newRuntime := func(t *testing.T) *tfsandbox.ModuleRuntime {
+		tf, err := tfsandbox.NewRuntimeFromExecutable(ctx, tfsandbox.DiscardLogger, tfsandbox.Workdir{t.Name()}, nil,
+			stub)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+		return tf
+	}
+
+	t.Run("init fails", func(t *testing.T) {
+		tf := newRuntime(t)
+		err := tf.Init(ctx, tfsandbox.DiscardLogger)
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "no available releases match the given constraints >= 99.0")
+
+		err = explainProviderConstraints(tf.WorkingDir(), err)
+		assert.ErrorContains(t, err, "the module constrains the provider versions as follows")
+		assert.ErrorContains(t, err, "hashicorp/aws >= 99.0 (required by module mymod)")
+	})
+
+	t.Run("init selects an incompatible version", func(t *testing.T) {
+		t.Setenv("STUB_LOCKED_AWS_VERSION", "5.0.0")
+		tf := newRuntime(t)
+		require.NoError(t, tf.Init(ctx, tfsandbox.DiscardLogger))
+
+		logger := &recordingLogger{}
+		warnOnProviderVersionViolations(ctx, logger, tf.WorkingDir())
+		assert.Equal(t, []string{
+			"warn: Incompatible provider version: module mymod requires provider hashicorp/aws >= 99.0, but init " +
+				"selected 5.0.0",
+		}, logger.messages)
+	})
+
+	t.Run("init selects a compatible version", func(t *testing.T) {
+		t.Setenv("STUB_LOCKED_AWS_VERSION", "99.1.0")
+		tf := newRuntime(t)
+		require.NoError(t, tf.Init(ctx, tfsandbox.DiscardLogger))
+
+		logger := &recordingLogger{}
+		warnOnProviderVersionViolations(ctx, logger, tf.WorkingDir())
+		assert.Empty(t, logger.messages)
+	})
+}
+
+func TestExplainProviderConstraintsIgnoresUnrelatedErrors(t *testing.T) {
+	err := assert.AnError
+	assert.Equal(t, err, explainProviderConstraints(t.TempDir(), err))
+}
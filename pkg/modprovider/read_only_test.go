@@ -0,0 +1,61 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+func TestReadOnlyModeRefusesApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "read_only", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	opts := moduleOptions{executor: stub, readOnly: true}
+	h := newModuleHandler(nil, newTestAuxProviderServer(t))
+	t.Cleanup(func() {
+		os.RemoveAll(tfsandboxWorkdir(t, opts.executor, modURN))
+	})
+
+	outputs, views, err := h.applyModuleOperation(ctx, modURN, resource.PropertyMap{}, nil, TFModuleSource(src), "",
+		map[string]resource.PropertyMap{}, &InferredModuleSchema{}, "simple", false /* preview */, opts)
+	assert.EqualError(t, err, "refusing to apply module m: the provider is configured with readOnly: true")
+	assert.Nil(t, outputs)
+
+	// The planned changes are still reported.
+	require.Len(t, views, 1)
+	assert.Equal(t, "module.m.terraform_data.example", views[0].Name)
+	assert.Equal(t, err.Error(), views[0].Error)
+
+	assert.NoFileExists(t, filepath.Join(tfsandboxWorkdir(t, opts.executor, modURN), "mutations.log"),
+		"the executor must not be asked to apply")
+}
@@ -0,0 +1,120 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+const (
+	defaultReadinessTimeout = 10 * time.Minute
+	readinessPollInterval   = 10 * time.Second
+)
+
+// readinessCheck is the readiness condition configured with the readinessCommand provider option, which is polled
+// after applying a module instance until it holds. The zero value performs no check.
+type readinessCheck struct {
+	command  string
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func newReadinessCheck(command, timeout string) (readinessCheck, error) {
+	check := readinessCheck{command: command, timeout: defaultReadinessTimeout, interval: readinessPollInterval}
+	if timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return readinessCheck{}, fmt.Errorf("provider option %q must be a positive duration such as 10m, got %q",
+				readinessTimeoutVariableName, timeout)
+		}
+		check.timeout = d
+	}
+	return check, nil
+}
+
+// waitUntilReady runs the readiness command until it succeeds or the timeout elapses. The command receives the
+// outputs of the module instance as a JSON object on stdin, secrets included, and signals readiness by exiting with
+// status 0. It is split on whitespace and run directly rather than through a shell.
+func waitUntilReady(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	check readinessCheck,
+	outputs resource.PropertyMap,
+) error {
+	if check.command == "" {
+		return nil
+	}
+
+	input, err := json.Marshal(outputs.MapRepl(nil, plainOutputValue))
+	if err != nil {
+		return fmt.Errorf("failed to serialize module outputs: %w", err)
+	}
+
+	deadline := time.Now().Add(check.timeout)
+	for attempt := 1; ; attempt++ {
+		err := runReadinessCommand(ctx, check.command, input)
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(check.interval).After(deadline) {
+			return fmt.Errorf("module did not become ready within %v: %w", check.timeout, err)
+		}
+		logger.LogStatus(ctx, tfsandbox.Info, fmt.Sprintf("Waiting for the module to become ready (attempt %d): %v",
+			attempt, err))
+		select {
+		case <-ctx.Done():
+			return errors.Join(ctx.Err(), err)
+		case <-time.After(check.interval):
+		}
+	}
+}
+
+func runReadinessCommand(ctx context.Context, command string, input []byte) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return errors.New("readiness command is empty")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("readiness command %q failed: %w: %s", command, err, msg)
+		}
+		return fmt.Errorf("readiness command %q failed: %w", command, err)
+	}
+	return nil
+}
+
+// plainOutputValue unwraps secret outputs, which the readiness command receives in plain text.
+func plainOutputValue(v resource.PropertyValue) (any, bool) {
+	if v.IsSecret() {
+		return v.SecretValue().Element.MapRepl(nil, plainOutputValue), true
+	}
+	return nil, false
+}
@@ -0,0 +1,79 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestWaitUntilReady(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub readiness command is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "readiness", "stub.sh"))
+	require.NoError(t, err)
+
+	outputs := resource.PropertyMap{
+		"endpoint": resource.NewStringProperty("db.example.com:5432"),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+	}
+
+	t.Run("polls until ready", func(t *testing.T) {
+		received := filepath.Join(t.TempDir(), "outputs.json")
+		check, err := newReadinessCheck(stub+" "+received, "")
+		require.NoError(t, err)
+		check.interval = time.Millisecond
+
+		logger := &recordingLogger{}
+		require.NoError(t, waitUntilReady(ctx, logger, check, outputs))
+		assert.Len(t, logger.messages, 2)
+		assert.Contains(t, logger.messages[0], "info: Waiting for the module to become ready (attempt 1)")
+		assert.Contains(t, logger.messages[0], "database is still starting")
+
+		input, err := os.ReadFile(received)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"endpoint": "db.example.com:5432", "password": "hunter2"}`, string(input))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		check, err := newReadinessCheck("false", "5ms")
+		require.NoError(t, err)
+		check.interval = time.Millisecond
+
+		err = waitUntilReady(ctx, &recordingLogger{}, check, outputs)
+		assert.ErrorContains(t, err, `module did not become ready within 5ms: readiness command "false" failed`)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.NoError(t, waitUntilReady(ctx, &recordingLogger{}, readinessCheck{}, outputs))
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		_, err := newReadinessCheck(stub, "ten minutes")
+		assert.ErrorContains(t, err, `provider option "readinessTimeout" must be a positive duration`)
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readmeFileNames are the names of module READMEs, in order of preference.
+var readmeFileNames = []string{"README.md", "readme.md", "Readme.md", "README"}
+
+// readmeSummary returns the first paragraph of the README of the module in dir, which registries also show as the
+// summary of the module, or an empty string when the module has no README. Headings, badges and HTML markup that
+// precede the paragraph are skipped.
+func readmeSummary(dir string) (string, error) {
+	for _, name := range readmeFileNames {
+		//nolint:gosec // the README is read from the module being added
+		contents, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to read the module README: %w", err)
+		}
+		return firstParagraph(string(contents)), nil
+	}
+	return "", nil
+}
+
+func firstParagraph(markdown string) string {
+	var paragraph []string
+	inComment := false
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case inComment:
+			inComment = !strings.Contains(line, "-->")
+			continue
+		case strings.HasPrefix(line, "<!--"):
+			inComment = !strings.Contains(line, "-->")
+			continue
+		}
+
+		skipped := strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<") ||
+			strings.HasPrefix(line, "[![") || strings.HasPrefix(line, "![")
+		switch {
+		case line == "" || skipped:
+			if len(paragraph) > 0 {
+				return strings.Join(paragraph, " ")
+			}
+		default:
+			paragraph = append(paragraph, line)
+		}
+	}
+	return strings.Join(paragraph, " ")
+}
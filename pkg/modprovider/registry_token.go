@@ -16,6 +16,7 @@ package modprovider
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
@@ -90,9 +91,47 @@ func discoverCloudRegistryWith(d *disco.Disco, apiAddress, token string) (*cloud
 	return &cloudRegistry{host: registryHost, token: token}, nil
 }
 
+// registryTokens are the tokens to authenticate to private module registries with, keyed by registry host. They are
+// set with the registryTokens provider option or, for `pulumi package add`, the environment variable.
+type registryTokens map[svchost.Hostname]string
+
+func newRegistryTokens(tokens map[string]string) (registryTokens, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	normalized := make(registryTokens, len(tokens))
+	for host, token := range tokens {
+		h, err := svchost.ForComparison(host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid registry host %q: %w", host, err)
+		}
+		normalized[h] = token
+	}
+	return normalized, nil
+}
+
+// environmentRegistryTokens reads the registry tokens from the environment for operations that run without a
+// provider configuration, such as the schema inference of `pulumi package add`.
+func environmentRegistryTokens() (registryTokens, error) {
+	raw := os.Getenv(registryTokensEnvironmentVariable)
+	if raw == "" {
+		return nil, nil
+	}
+	tokens := map[string]string{}
+	if err := json.Unmarshal([]byte(raw), &tokens); err != nil {
+		return nil, fmt.Errorf("%s must map registry hosts to tokens: %w", registryTokensEnvironmentVariable, err)
+	}
+	return newRegistryTokens(tokens)
+}
+
 // injectRegistryToken lets `tofu init` authenticate to the Pulumi Cloud module registry without a
-// separate `terraform login`.
-func injectRegistryToken(ctx context.Context, logger tfsandbox.Logger) {
+// separate `terraform login`, as well as to the private registries the tokens are configured for.
+func injectRegistryToken(ctx context.Context, logger tfsandbox.Logger, tokens registryTokens) {
+	for host, token := range tokens {
+		// tokens configured explicitly take precedence over the TF_TOKEN_<host> variables of the environment
+		_ = os.Setenv(tfTokenEnvKey(host), token)
+	}
+
 	reg, err := pulumiCloudRegistry()
 	if err != nil {
 		logger.Log(ctx, tfsandbox.Warn,
@@ -108,20 +147,25 @@ func injectRegistryToken(ctx context.Context, logger tfsandbox.Logger) {
 	}
 }
 
-func cloudRegistryCredentials() auth.CredentialsSource {
+func cloudRegistryCredentials(tokens registryTokens) auth.CredentialsSource {
 	// A discovery failure surfaces to the user as an error from the subsequent registry request, so
 	// this loggerless path leaves the client unauthenticated rather than handling the error here.
 	reg, _ := pulumiCloudRegistry()
-	return credentialsForRegistry(reg)
+	return credentialsForRegistry(reg, tokens)
 }
 
-func credentialsForRegistry(reg *cloudRegistry) auth.CredentialsSource {
-	if reg == nil {
+func credentialsForRegistry(reg *cloudRegistry, tokens registryTokens) auth.CredentialsSource {
+	if reg == nil && len(tokens) == 0 {
 		return nil
 	}
-	return auth.StaticCredentialsSource(map[svchost.Hostname]map[string]interface{}{
-		reg.host: {"token": reg.token},
-	})
+	credentials := map[svchost.Hostname]map[string]interface{}{}
+	if reg != nil {
+		credentials[reg.host] = map[string]interface{}{"token": reg.token}
+	}
+	for host, token := range tokens {
+		credentials[host] = map[string]interface{}{"token": token}
+	}
+	return auth.StaticCredentialsSource(credentials)
 }
 
 // tfTokenEnvKey builds the TF_TOKEN_<host> variable name using OpenTofu's host-encoding convention.
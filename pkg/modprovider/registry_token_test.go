@@ -15,7 +15,13 @@
 package modprovider
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	svchost "github.com/hashicorp/terraform-svchost"
 	"github.com/hashicorp/terraform-svchost/disco"
@@ -96,7 +102,7 @@ func TestCredentialsForRegistry(t *testing.T) {
 	t.Parallel()
 
 	reg := &cloudRegistry{host: svchost.Hostname("tfe.pulumi.com"), token: "the-token"}
-	creds := credentialsForRegistry(reg)
+	creds := credentialsForRegistry(reg, nil)
 	require.NotNil(t, creds)
 
 	t.Run("registry host gets the token", func(t *testing.T) {
@@ -116,6 +122,140 @@ func TestCredentialsForRegistry(t *testing.T) {
 
 	t.Run("no registry yields no credentials", func(t *testing.T) {
 		t.Parallel()
-		assert.Nil(t, credentialsForRegistry(nil))
+		assert.Nil(t, credentialsForRegistry(nil, nil))
 	})
 }
+
+func TestCredentialsForRegistryWithConfiguredTokens(t *testing.T) {
+	t.Parallel()
+
+	reg := &cloudRegistry{host: svchost.Hostname("tfe.pulumi.com"), token: "the-token"}
+	tokens, err := newRegistryTokens(map[string]string{"Registry.Example.com": "private-token"})
+	require.NoError(t, err)
+
+	creds := credentialsForRegistry(reg, tokens)
+	require.NotNil(t, creds)
+
+	hc, err := creds.ForHost(svchost.Hostname("registry.example.com"))
+	require.NoError(t, err)
+	require.NotNil(t, hc)
+	assert.Equal(t, "private-token", hc.Token())
+
+	hc, err = creds.ForHost(reg.host)
+	require.NoError(t, err)
+	require.NotNil(t, hc)
+	assert.Equal(t, "the-token", hc.Token())
+}
+
+func TestLatestModuleVersionAuthenticatesWithRegistryToken(t *testing.T) {
+	var authorization string
+	registryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/v1/modules/acme/network/aws/versions" {
+			http.NotFound(w, r)
+			return
+		}
+		_, err := w.Write([]byte(`{"modules":[{"versions":[{"version":"1.2.0"},{"version":"1.3.0"},` +
+			`{"version":"2.0.0-beta"}]}]}`))
+		assert.NoError(t, err)
+	}))
+	t.Cleanup(registryServer.Close)
+
+	const registryHost = "registry.example.com"
+	tokens, err := newRegistryTokens(map[string]string{registryHost: "private-token"})
+	require.NoError(t, err)
+
+	services := disco.NewWithCredentialsSource(credentialsForRegistry(nil, tokens))
+	services.ForceHostServices(svchost.Hostname(registryHost), map[string]interface{}{
+		"modules.v1": registryServer.URL + "/v1/modules/",
+	})
+
+	latest, err := latestModuleVersionWith(context.Background(), services, registryHost+"/acme/network/aws",
+		registryRetryPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", latest.String())
+	assert.Equal(t, "Bearer private-token", authorization)
+}
+
+func TestInjectRegistryTokenSetsConfiguredTokens(t *testing.T) {
+	t.Setenv("TF_TOKEN_registry_example_com", "from-environment")
+
+	tokens, err := newRegistryTokens(map[string]string{"registry.example.com": "private-token"})
+	require.NoError(t, err)
+	injectRegistryToken(context.Background(), &recordingLogger{}, tokens)
+
+	assert.Equal(t, "private-token", os.Getenv("TF_TOKEN_registry_example_com"))
+}
+
+func TestEnvironmentRegistryTokens(t *testing.T) {
+	t.Setenv(registryTokensEnvironmentVariable, `{"registry.example.com":"private-token"}`)
+	tokens, err := environmentRegistryTokens()
+	require.NoError(t, err)
+	assert.Equal(t, registryTokens{"registry.example.com": "private-token"}, tokens)
+
+	t.Setenv(registryTokensEnvironmentVariable, `private-token`)
+	_, err = environmentRegistryTokens()
+	assert.ErrorContains(t, err, "must map registry hosts to tokens")
+}
+
+func TestLatestModuleVersionRetriesFailedListing(t *testing.T) {
+	const registryHost = "registry.example.com"
+	newServices := func(t *testing.T, handler http.HandlerFunc) *disco.Disco {
+		registryServer := httptest.NewServer(handler)
+		t.Cleanup(registryServer.Close)
+		services := disco.New()
+		services.ForceHostServices(svchost.Hostname(registryHost), map[string]interface{}{
+			"modules.v1": registryServer.URL + "/v1/modules/",
+		})
+		return services
+	}
+	policy := registryRetryPolicy{maxRetries: 2, backoff: time.Millisecond}
+
+	t.Run("transient failure", func(t *testing.T) {
+		var requests atomic.Int32
+		services := newServices(t, func(w http.ResponseWriter, _ *http.Request) {
+			// The registry client retries once by itself, so the first listing fails with two requests.
+			if requests.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, err := w.Write([]byte(`{"modules":[{"versions":[{"version":"1.2.0"},{"version":"1.3.0"}]}]}`))
+			assert.NoError(t, err)
+		})
+
+		latest, err := latestModuleVersionWith(context.Background(), services, registryHost+"/acme/network/aws",
+			policy)
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.0", latest.String())
+		assert.Equal(t, int32(3), requests.Load())
+	})
+
+	t.Run("missing module", func(t *testing.T) {
+		var requests atomic.Int32
+		services := newServices(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.NotFound(w, r)
+		})
+
+		_, err := latestModuleVersionWith(context.Background(), services, registryHost+"/acme/network/aws",
+			policy)
+		require.Error(t, err)
+		assert.Equal(t, int32(1), requests.Load(), "missing modules are not retried")
+	})
+}
+
+func TestRegistryRetryPolicyFromEnvironment(t *testing.T) {
+	t.Setenv(registryRetriesEnvironmentVariable, "")
+	policy, err := newRegistryRetryPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, defaultRegistryRetries, policy.maxRetries)
+
+	t.Setenv(registryRetriesEnvironmentVariable, "0")
+	policy, err = newRegistryRetryPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, 0, policy.maxRetries)
+
+	t.Setenv(registryRetriesEnvironmentVariable, "many")
+	_, err = newRegistryRetryPolicy()
+	assert.ErrorContains(t, err, "PULUMI_TERRAFORM_MODULE_REGISTRY_RETRIES must be a non-negative number of retries")
+}
@@ -0,0 +1,79 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/opentofu/addrs"
+	"github.com/pulumi/opentofu/configs"
+)
+
+// moduleResourceTypes returns the sorted types of the resources the module manages, including those managed by the
+// local modules it calls. Modules called from remote sources are not downloaded when inferring the schema, so their
+// resources are not included.
+func moduleResourceTypes(module *configs.Module) ([]string, error) {
+	resourceTypes := map[string]struct{}{}
+	if err := collectResourceTypes(module, resourceTypes); err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(resourceTypes)), nil
+}
+
+func collectResourceTypes(module *configs.Module, resourceTypes map[string]struct{}) error {
+	return visitLocalModules(module, func(m *configs.Module) {
+		for _, r := range m.ManagedResources {
+			resourceTypes[r.Type] = struct{}{}
+		}
+	})
+}
+
+// visitLocalModules calls visit with the module and, recursively, with the modules it calls from local paths. Modules
+// from other sources are not downloaded when inferring the schema, so they are not visited. Modules that fail to load
+// are skipped, and the errors loading them are returned once the others are visited.
+func visitLocalModules(module *configs.Module, visit func(*configs.Module)) error {
+	visit(module)
+	var errs []error
+	for _, call := range module.ModuleCalls {
+		source, ok := call.SourceAddr.(addrs.ModuleSourceLocal)
+		if !ok {
+			continue
+		}
+		parser := configs.NewParser(nil)
+		smc := configs.NewStaticModuleCall(nil, nil, "", "")
+		child, diagnostics := parser.LoadConfigDir(filepath.Join(module.SourceDir, string(source)), smc)
+		if diagnostics.HasErrors() {
+			errs = append(errs, fmt.Errorf("error while loading module %s called as %q: %w", source, call.Name,
+				diagnostics))
+			continue
+		}
+		errs = append(errs, visitLocalModules(child, visit))
+	}
+	return errors.Join(errs...)
+}
+
+// resourceTypesSummary describes the resource types a module manages for the package description.
+func resourceTypesSummary(resourceTypes []string) string {
+	if len(resourceTypes) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("The module manages resources of the following types: `%s`.",
+		strings.Join(resourceTypes, "`, `"))
+}
@@ -28,19 +28,42 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
 
+// Sources pinned to a commit get a version derived from the commit only with the commit gitVersionScheme, see
+// commitPackageVersion.
+//
 // TODO[pulumi/pulumi-terraform-module#50] this can get more complicated if versionSpec is a range and not a
 // precise version.
-func inferPackageVersion(versionSpec TFModuleVersion) packageVersion {
+func inferPackageVersion(
+	source TFModuleSource,
+	versionSpec TFModuleVersion,
+	gitVersionScheme string,
+) packageVersion {
 	if versionSpec == "" {
+		if v, ok := commitPackageVersion(source); ok && gitVersionScheme == gitVersionSchemeCommit {
+			return v
+		}
 		return defaultPackageVersion
 	}
 	return packageVersion(versionSpec)
 }
 
+// moduleSchemaOverride selects the overrides of the inferred module schema: the ones supplied with the module
+// configuration, or else the built-in overrides of well-known modules. It returns nil when there are none.
+func moduleSchemaOverride(pargs *ParameterizeArgs) *InferredModuleSchema {
+	if pargs.Config != nil && pargs.Config.InferredModuleSchema != nil {
+		return pargs.Config.InferredModuleSchema
+	}
+	overrides := parseModuleSchemaOverrides(string(pargs.PackageName))
+	if override, ok := hasBuiltinModuleSchemaOverrides(pargs.TFModuleSource, pargs.TFModuleVersion, overrides); ok {
+		return override
+	}
+	return nil
+}
+
 // sandbox will be available and having run `terraform init` it will have resolved and downloaded the module sources.
 // The code will need to run input/output schema inference for these sources to compute an appropriate PackageSpec.
 func pulumiSchemaForModule(pargs *ParameterizeArgs, inferredModule *InferredModuleSchema) (*schema.PackageSpec, error) {
-	pkgVer := inferPackageVersion(pargs.TFModuleVersion)
+	pkgVer := inferPackageVersion(pargs.TFModuleSource, pargs.TFModuleVersion, pargs.Config.gitVersionScheme())
 	packageName := string(pargs.PackageName)
 	repository := "github.com/pulumi/pulumi-terraform-module"
 	mainResourceToken := fmt.Sprintf("%s:index:%s", packageName, defaultComponentTypeName)
@@ -70,21 +93,8 @@ func pulumiSchemaForModule(pargs *ParameterizeArgs, inferredModule *InferredModu
 		return nil, err
 	}
 
-	// read module schema overrides and merge them with the
-	// inferred module schema when applicable
-	overrides := parseModuleSchemaOverrides(string(packageName))
-
-	override, hasOverride := hasBuiltinModuleSchemaOverrides(
-		pargs.TFModuleSource,
-		pargs.TFModuleVersion,
-		overrides)
-
-	if pargs.Config != nil && pargs.Config.InferredModuleSchema != nil {
-		suppliedSchemaOverride := pargs.Config.InferredModuleSchema
-		inferredModule = combineInferredModuleSchema(inferredModule, suppliedSchemaOverride)
-	} else if hasOverride {
-		inferredModule = combineInferredModuleSchema(inferredModule, override)
-	}
+	// merge the module schema overrides with the inferred module schema when applicable
+	inferredModule = combineInferredModuleSchema(inferredModule, moduleSchemaOverride(pargs))
 
 	supportingTypes := map[string]schema.ComplexTypeSpec{}
 	for token, typeSpec := range inferredModule.SupportingTypes {
@@ -106,6 +116,7 @@ func pulumiSchemaForModule(pargs *ParameterizeArgs, inferredModule *InferredModu
 			outputs[string(propertyName)] = *outputType
 		}
 	}
+	withLanguageNameOverrides(inputs, outputs, supportingTypes)
 
 	moduleExecutorVariable := schema.PropertySpec{
 		TypeSpec: schema.TypeSpec{
@@ -124,11 +135,400 @@ func pulumiSchemaForModule(pargs *ParameterizeArgs, inferredModule *InferredModu
 	}
 
 	inferredModule.ProvidersConfig.Variables[moduleExecutorVariableName] = moduleExecutorVariable
+	inferredModule.ProvidersConfig.Variables[skipUnchangedPlansVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "boolean",
+		},
+
+		Description: "When set, previews skip planning module instances whose inputs, module version and " +
+			"provider configuration are unchanged. This speeds up previews at the cost of not detecting changes " +
+			"that originate outside of the program.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{skipUnchangedPlansEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[providerMetaVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "object",
+			AdditionalProperties: &schema.TypeSpec{
+				Ref: "pulumi.json#/Any",
+			},
+		},
+
+		Description: "Contents of the provider_meta blocks to emit when running the module, keyed by the name " +
+			"of the Terraform provider. Some organizations rely on these for attribution. Terraform only sends " +
+			"them to resources declared next to the generated module call, such as those of extraTerraformFiles, " +
+			"not to the resources of the module itself.",
+	}
+	inferredModule.ProvidersConfig.Variables[extraTerraformFilesVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "object",
+			AdditionalProperties: &schema.TypeSpec{
+				Type: "string",
+			},
+		},
+
+		Description: "Additional Terraform files to write next to the module invocation, keyed by file name. " +
+			"These may declare data sources, locals or resources around the module call but must not redefine it.",
+	}
+	inferredModule.ProvidersConfig.Variables[moduleDependsOnVariableName] = schema.PropertySpec{
+		TypeSpec: arrayType(stringType),
+
+		Description: "References to Terraform resources or data sources, usually declared in extraTerraformFiles, " +
+			"to add to the depends_on meta-argument of module calls, such as terraform_data.gate. Terraform then " +
+			"only plans and applies modules after these, even though no module input refers to them.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{moduleDependsOnEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[pluginCacheDirVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "string",
+		},
+
+		Description: "A directory to cache Terraform provider plugins in. When set, module instances share " +
+			"downloaded providers instead of installing them separately, which speeds up modules requiring " +
+			"several providers.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{pluginCacheDirEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[initParallelismVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "integer",
+		},
+
+		Description: "How many module instances may install their providers and modules at the same time. " +
+			"Defaults to 0, which does not limit them.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{initParallelismEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[moduleCacheDirVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "A directory to cache downloaded modules in. When set, instances of the module share a single " +
+			"download of every version of the module instead of downloading it on every init.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{moduleCacheDirEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[stateBackupDirVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "A directory to back up the Terraform state of module instances to before every apply and " +
+			"destroy. Backups are timestamped copies of the state, which holds secrets in plaintext, readable only " +
+			"by the user running Pulumi.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{stateBackupDirEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[escEnvironmentVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "string",
+		},
+
+		Description: "An ESC environment of the form <org>/<project>/<environment> to read Terraform provider " +
+			"configurations from. The configurations are read from the terraformProviders object in the " +
+			"environment values, keyed by provider name. Configuration set in the program takes precedence.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{escEnvironmentEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[costEstimateCommandVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "string",
+		},
+
+		Description: "A command to estimate the cost of planned changes during previews. The command receives " +
+			"the Terraform JSON plan on stdin and prints the estimate to stdout, which is shown as an " +
+			"informational message.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{costEstimateCommandEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[readinessCommandVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "A command to poll after applying a module until the resources it manages are ready. The " +
+			"command receives the module outputs as a JSON object on stdin and signals readiness by exiting with " +
+			"status 0; it is retried every 10 seconds until readinessTimeout elapses.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{readinessCommandEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[readinessTimeoutVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How long to wait for readinessCommand to succeed, as a duration such as 30m. Defaults to 10m.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{readinessTimeoutEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[applyRetryPatternsVariableName] = schema.PropertySpec{
+		TypeSpec: arrayType(stringType),
+
+		Description: "Regular expressions matching transient errors on which a failed apply is retried with a " +
+			"backoff. Defaults to common AWS throttling errors; an empty list disables retries.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{applyRetryPatternsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[stateSizeWarningLimitVariableName] = schema.PropertySpec{
+		TypeSpec: integerType,
+
+		Description: "Size in bytes of the Terraform state of a module instance above which a warning is " +
+			"emitted. Defaults to 4 MiB; 0 disables the warning.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{stateSizeWarningLimitEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[stateSizeLimitVariableName] = schema.PropertySpec{
+		TypeSpec: integerType,
+
+		Description: "Size in bytes of the Terraform state of a module instance above which operations fail. " +
+			"Disabled by default.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{stateSizeLimitEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[readOnlyVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, modules are only planned: applying and destroying are refused, and the planned " +
+			"changes are reported instead.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{readOnlyEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[workdirCleanupVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "When to remove the working directories of module instances, which cache downloaded modules " +
+			"and providers: \"keep\" (the default), \"clean-on-success\" or \"always-clean\".",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{workdirCleanupEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[providerEndpointsVariableName] = schema.PropertySpec{
+		TypeSpec: mapType(mapType(stringType)),
+
+		// Like registryTokens, the environment variable holds a JSON object read by the provider.
+		Description: "Service endpoint URLs to write into the endpoints blocks of the Terraform providers, keyed by " +
+			"provider name and then by service, for example to run modules against LocalStack. Endpoints set in the " +
+			"provider configuration take precedence. Also read from the " + providerEndpointsEnvironmentVariable +
+			" environment variable as a JSON object.",
+	}
+	inferredModule.ProvidersConfig.Variables[gitFullCloneVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, modules from git sources are cloned with their whole history. By default they are " +
+			"shallow cloned, which is faster for large repositories. Also read from the " +
+			gitFullCloneEnvironmentVariable + " environment variable, which is how it applies to `pulumi package add`.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{gitFullCloneEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[invalidModuleStateVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How to handle module instances whose Terraform state stored in Pulumi is missing or " +
+			"malformed: \"error\" (the default) fails operations, \"reset\" runs the module from an empty state, " +
+			"which creates its resources anew.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{invalidModuleStateEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[failOnDriftVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, drift detected while refreshing modules fails the operation instead of being " +
+			"reconciled. Modules are refreshed before previews, so this makes previews fail on drift.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{failOnDriftEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[refreshOnlyVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, updates reconcile the state of modules with the infrastructure instead of applying " +
+			"the program, like `terraform apply -refresh-only`, accepting drift as the new desired state.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{refreshOnlyEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[destroyTargetsVariableName] = schema.PropertySpec{
+		TypeSpec: arrayType(stringType),
+
+		Description: "Addresses of child resources to destroy, such as module.vpc.aws_subnet.private[0]. Updates " +
+			"of the module instances declaring them destroy only these resources and whatever depends on them, like " +
+			"`terraform destroy -target`, keeping the other resources of the modules in the state.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{destroyTargetsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[pulumiDefaultTagsVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, the resources of modules are tagged with the Pulumi stack and project they belong " +
+			"to, as pulumi:stack and pulumi:project, through the default_tags of the aws provider. Tags set in the " +
+			"default_tags of the provider configuration take precedence.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{pulumiDefaultTagsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[secretsAuditVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, creating and updating modules logs the paths of the inputs and outputs handled as " +
+			"secrets, without their values, for auditing how sensitive data is handled.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{secretsAuditEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[taintedResourcesVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How to handle child resources that a failed apply leaves tainted, such as resources whose " +
+			"provisioners failed: \"replace\" (the default) replaces them on the next update like Terraform, " +
+			"\"untaint\" keeps them as they are.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{taintedResourcesEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[viewStepsBatchSizeVariableName] = schema.PropertySpec{
+		TypeSpec: integerType,
+
+		Description: "Size in bytes of the batches in which the states of child resources are reported to the " +
+			"engine. Lower it if modules with many resources exceed RPC message size limits. Defaults to 1 MiB; 0 " +
+			"reports all of them at once.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{viewStepsBatchSizeEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[providerChecksumsVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How strictly provider packages are verified against the dependency lock files recorded for " +
+			"module instances: \"verify\" (the default) checks the checksums of locked providers like Terraform, " +
+			"\"strict\" also refuses to lock new providers or versions unless the module version changes.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{providerChecksumsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[interactiveAuthVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How to handle providers requiring interactive authentication, such as expired AWS SSO " +
+			"sessions or Azure device code logins: \"fail\" (the default) stops the operation with instructions to " +
+			"authenticate before running Pulumi, \"allow\" lets it continue so that device code logins shown in the " +
+			"logs can be completed.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{interactiveAuthEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[dataSourceViewsVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, the data sources read by modules are reported as read-only child resources, so that " +
+			"what modules query can be audited. These views are marked with the \"data\" mode and are not managed.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{dataSourceViewsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[childResourceDiffsVariableName] = schema.PropertySpec{
+		TypeSpec: boolType,
+
+		Description: "When true, the child resources changed by modules report which of their attributes change and " +
+			"which force a replacement, so that `pulumi preview --diff` and `pulumi preview --json` detail what " +
+			"modules do to every resource.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{childResourceDiffsEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[childResourceTypesVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "The type tokens of the views of child resources: \"terraform\" (the default) types them after " +
+			"their Terraform type, \"pulumi\" uses the tokens of the corresponding Pulumi provider resources where " +
+			"known, such as aws:s3/bucket:Bucket for aws_s3_bucket, so that policy packs apply to them.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{childResourceTypesEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[refreshModeVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How module instances are refreshed: \"full\" (the default) reads every child resource from " +
+			"its provider, \"outputs\" only re-evaluates the module outputs against the resources in the state, " +
+			"without detecting drift.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{refreshModeEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[filesystemSandboxVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How much of the host modules may reach: \"off\" (the default) runs them with the environment " +
+			"of the provider, \"restricted\" passes on only the variables Terraform and common providers need, with " +
+			"the working directory as the home directory, and rejects modules referring to files outside of it.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{filesystemSandboxEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[unconfiguredProvidersVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How to handle providers the module requires that are not configured: \"ignore\" (the " +
+			"default) leaves them to Terraform, \"warn\" warns about them when checking module instances and " +
+			"\"error\" fails the checks. Providers configured through their usual environment variables, or set to " +
+			"an empty object, count as configured.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{unconfiguredProvidersEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[moduleRefVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "Overrides the ref of the module the package was generated from: a branch, tag or commit of git " +
+			"sources, or a version of registry modules. Versions must keep the major version of the package, so that " +
+			"the same SDK can run a development branch in one stack and a released tag in another.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{moduleRefEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[moduleNamingVariableName] = schema.PropertySpec{
+		TypeSpec: stringType,
+
+		Description: "How to name the Terraform module blocks of module instances: \"resource-name\" (the default) " +
+			"uses the Pulumi resource name as is, \"sanitized\" replaces characters that are not valid in Terraform " +
+			"identifiers and \"hashed\" uses a hash of the resource name. Changing it for existing modules replaces " +
+			"their resources.",
+		DefaultInfo: &schema.DefaultSpec{
+			Environment: []string{moduleNamingEnvironmentVariable},
+		},
+	}
+	inferredModule.ProvidersConfig.Variables[registryTokensVariableName] = schema.PropertySpec{
+		TypeSpec: schema.TypeSpec{
+			Type: "object",
+			AdditionalProperties: &schema.TypeSpec{
+				Type: "string",
+			},
+		},
+
+		// The environment variable holds a JSON object, which the SDK codegen cannot parse into a map default, so the
+		// provider reads it instead of declaring it in DefaultInfo.
+		Description: "Tokens to authenticate to private module registries with, keyed by registry host. Also read " +
+			"from the " + registryTokensEnvironmentVariable + " environment variable as a JSON object, which is " +
+			"how it applies to `pulumi package add`.",
+		Secret: true,
+	}
 
 	packageSpec := &schema.PackageSpec{
-		Name:    string(packageName),
-		Version: string(pkgVer),
-		Types:   supportingTypes,
+		Name:        string(packageName),
+		Version:     string(pkgVer),
+		Description: inferredModule.description,
+		Types:       supportingTypes,
 		Provider: schema.ResourceSpec{
 			InputProperties: inferredModule.ProvidersConfig.Variables,
 		},
@@ -0,0 +1,84 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/fsutil"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestSchemaInferenceTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	stub, err := filepath.Abs(filepath.Join("testdata", "schema_inference_timeout", "stub.sh"))
+	require.NoError(t, err)
+	src, err := filepath.Abs(filepath.Join("testdata", "modules", "simple"))
+	require.NoError(t, err)
+
+	t.Setenv(schemaInferenceTimeoutEnvironmentVariable, "2s")
+	workdir := tfsandbox.ModuleWorkdir(TFModuleSource(src), "")
+	t.Cleanup(func() {
+		assert.NoError(t, tfsandbox.RemoveWorkdir(workdir))
+	})
+
+	s := &server{moduleExecutor: stub, auxProviderServer: newTestAuxProviderServer(t)}
+	start := time.Now()
+	_, err = s.Parameterize(context.Background(), &pulumirpc.ParameterizeRequest{
+		Parameters: &pulumirpc.ParameterizeRequest_Args{
+			Args: &pulumirpc.ParameterizeRequest_ParametersArgs{Args: []string{src, "simple"}},
+		},
+	})
+	assert.ErrorContains(t, err, "timed out after 2s, set "+schemaInferenceTimeoutEnvironmentVariable)
+	assert.Less(t, time.Since(start), time.Minute, "the hanging init must be aborted")
+
+	// The FileMutex is released, so that a subsequent inference of the module can proceed.
+	locked := make(chan error, 1)
+	go func() {
+		mu := fsutil.NewFileMutex(schemaInferenceLockFile(workdir))
+		if err := mu.Lock(); err != nil {
+			locked <- err
+			return
+		}
+		locked <- mu.Unlock()
+	}()
+	select {
+	case err := <-locked:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "the schema inference FileMutex was not released")
+	}
+}
+
+func TestSchemaInferenceTimeoutInvalid(t *testing.T) {
+	for _, v := range []string{"soon", "0s", "-1m"} {
+		t.Setenv(schemaInferenceTimeoutEnvironmentVariable, v)
+		_, err := schemaInferenceTimeout()
+		assert.EqualError(t, err, schemaInferenceTimeoutEnvironmentVariable+` must be a positive duration such as 10m, `+
+			`got "`+v+`"`)
+	}
+}
@@ -16,11 +16,21 @@ package modprovider
 
 import (
 	"encoding/json"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	dotnet_codegen "github.com/pulumi/pulumi/pkg/v3/codegen/dotnet"
 	go_codegen "github.com/pulumi/pulumi/pkg/v3/codegen/go"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 )
 
 func TestParameterizationSpec(t *testing.T) {
@@ -80,6 +90,15 @@ func TestPulumiSchemaForModuleHasLanguageInfoGo(t *testing.T) {
 			expectedImportBasePath:  "github.com/pulumi/pulumi-terraform-module/sdks/go/bucket/v4/bucket",
 			expectedRootPackageName: "bucket",
 		},
+		{
+			name: "Go local module",
+			pArgs: ParameterizeArgs{
+				TFModuleSource: "/path/to/modules/dashed-module-fields",
+				PackageName:    "dashed",
+			},
+			expectedImportBasePath:  "github.com/pulumi/pulumi-terraform-module/sdks/go/dashed/dashed",
+			expectedRootPackageName: "dashed",
+		},
 	}
 	for _, tc := range testcases {
 		tc := tc
@@ -98,3 +117,215 @@ func TestPulumiSchemaForModuleHasLanguageInfoGo(t *testing.T) {
 		})
 	}
 }
+
+// The Go SDK codegen can only parse environment defaults into scalars and lists of strings; any other type generates
+// a provider constructor that does not compile.
+func TestProviderConfigEnvironmentDefaultsSupportedByGoCodegen(t *testing.T) {
+	spec, err := pulumiSchemaForModule(&ParameterizeArgs{
+		TFModuleSource: "/path/to/modules/dashed-module-fields",
+		PackageName:    "dashed",
+	}, &InferredModuleSchema{})
+	require.NoError(t, err)
+
+	for name, variable := range spec.Provider.InputProperties {
+		if variable.DefaultInfo == nil || len(variable.DefaultInfo.Environment) == 0 {
+			continue
+		}
+		switch variable.Type {
+		case "string", "boolean", "integer", "number":
+		case "array":
+			assert.Equal(t, "string", variable.Items.Type, "provider option %q", name)
+		default:
+			assert.Failf(t, "unsupported environment default", "provider option %q has type %q", name, variable.Type)
+		}
+	}
+}
+
+// The Go SDK of a local module is compiled as part of this Go module, under the import path it is generated for, so
+// that it builds against the same version of the Pulumi SDK without downloading anything.
+func TestGoSDKForLocalModuleCompiles(t *testing.T) {
+	goBinary, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is needed to compile the SDK")
+	}
+	repoRoot, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+
+	for _, module := range []string{"nested-types", "keywords"} {
+		t.Run(module, func(t *testing.T) {
+			name := packageName(strings.ReplaceAll(module, "-", ""))
+			inferredModule, err := inferModuleSchemaFromContent(name, loadTestModule(t, module), nil)
+			require.NoError(t, err)
+			spec, err := pulumiSchemaForModule(&ParameterizeArgs{
+				TFModuleSource: TFModuleSource("./modules/" + module),
+				PackageName:    name,
+			}, inferredModule)
+			require.NoError(t, err)
+
+			var goInfo go_codegen.GoPackageInfo
+			require.NoError(t, json.Unmarshal(spec.Language["go"], &goInfo))
+			importPath := goInfo.ImportBasePath
+			sdkDir, ok := strings.CutPrefix(path.Dir(importPath), "github.com/pulumi/pulumi-terraform-module/")
+			require.True(t, ok, "unexpected import path %s", importPath)
+
+			pkg, err := schema.ImportSpec(*spec, nil, schema.ValidationOptions{})
+			require.NoError(t, err)
+			files, err := go_codegen.GeneratePackage("test", pkg, nil)
+			require.NoError(t, err)
+
+			generated := t.TempDir()
+			overlay := map[string]string{}
+			for name, content := range files {
+				if filepath.Ext(name) != ".go" {
+					continue
+				}
+				file := filepath.Join(generated, filepath.FromSlash(name))
+				require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o700))
+				require.NoError(t, os.WriteFile(file, content, 0o600))
+				overlay[filepath.Join(repoRoot, filepath.FromSlash(sdkDir), filepath.FromSlash(name))] = file
+			}
+			overlayJSON, err := json.Marshal(map[string]any{"Replace": overlay})
+			require.NoError(t, err)
+			overlayFile := filepath.Join(generated, "overlay.json")
+			require.NoError(t, os.WriteFile(overlayFile, overlayJSON, 0o600))
+
+			build := exec.Command(goBinary, "build", "-overlay", overlayFile, importPath)
+			build.Dir = repoRoot
+			out, err := build.CombinedOutput()
+			require.NoError(t, err, "the Go SDK does not compile:\n%s", out)
+		})
+	}
+}
+
+func TestPackageDescriptionFromReadme(t *testing.T) {
+	pargs := &ParameterizeArgs{TFModuleSource: "acme/network/aws", TFModuleVersion: "1.0.0", PackageName: "network"}
+
+	inferredModule, err := inferModuleSchemaFromContent("network", loadTestModule(t, "readme"), &ModuleConfig{
+		ReadmeSummary: true,
+	})
+	require.NoError(t, err)
+	spec, err := pulumiSchemaForModule(pargs, inferredModule)
+	require.NoError(t, err)
+	// The title, badges and generated docs markers before the first paragraph are skipped.
+	assert.Equal(t, "Terraform module which creates VPC resources on AWS, with public and private subnets.",
+		spec.Description)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("network", loadTestModule(t, "readme"), nil)
+		require.NoError(t, err)
+		spec, err := pulumiSchemaForModule(pargs, inferredModule)
+		require.NoError(t, err)
+		assert.Empty(t, spec.Description)
+	})
+
+	t.Run("module without README", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("simple", loadTestModule(t, "simple"), &ModuleConfig{
+			ReadmeSummary: true,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, inferredModule.description)
+	})
+}
+
+func TestPackageDescriptionListsResourceTypes(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("logs", loadTestModule(t, "resource-types"), &ModuleConfig{
+		ResourceTypesSummary: true,
+	})
+	require.NoError(t, err)
+	// Data sources and the resources of remote modules are not listed, those of local modules are.
+	assert.Equal(t, "The module manages resources of the following types: "+
+		"`aws_s3_bucket`, `aws_s3_bucket_policy`, `aws_s3_bucket_versioning`.", inferredModule.description)
+
+	t.Run("after the README summary", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("network", loadTestModule(t, "readme"), &ModuleConfig{
+			ReadmeSummary:        true,
+			ResourceTypesSummary: true,
+		})
+		require.NoError(t, err)
+		// The module does not manage any resources.
+		assert.Equal(t, "Terraform module which creates VPC resources on AWS, with public and private subnets.",
+			inferredModule.description)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		inferredModule, err := inferModuleSchemaFromContent("logs", loadTestModule(t, "resource-types"), nil)
+		require.NoError(t, err)
+		assert.Empty(t, inferredModule.description)
+	})
+}
+
+func TestNonNilOutputOverrides(t *testing.T) {
+	pargs := &ParameterizeArgs{
+		TFModuleSource:  "acme/network/aws",
+		TFModuleVersion: "1.0.0",
+		PackageName:     "network",
+		Config: &ModuleConfig{
+			NonNilOutputOverrides: map[string]bool{"vpc_id": true},
+		},
+	}
+	componentToken := "network:index:Module"
+
+	inferredModule, err := inferModuleSchemaFromContent("network", loadTestModule(t, "renamed"), pargs.Config)
+	require.NoError(t, err)
+	spec, err := pulumiSchemaForModule(pargs, inferredModule)
+	require.NoError(t, err)
+	assert.Contains(t, spec.Resources[componentToken].Required, "vpc_id")
+	assert.Equal(t, []resource.PropertyKey{"vpc_id"}, inferredModule.NonNilOutputs)
+
+	t.Run("removing a guarantee", func(t *testing.T) {
+		pargs.Config.NonNilOutputOverrides["vpc_id"] = false
+		inferredModule, err := inferModuleSchemaFromContent("network", loadTestModule(t, "renamed"), pargs.Config)
+		require.NoError(t, err)
+		assert.Empty(t, inferredModule.NonNilOutputs)
+
+		// Stands in for a guarantee coming from the built-in schema overrides.
+		inferredModule = combineInferredModuleSchema(inferredModule, &InferredModuleSchema{
+			NonNilOutputs: []resource.PropertyKey{"vpc_id"},
+		})
+		spec, err := pulumiSchemaForModule(pargs, inferredModule)
+		require.NoError(t, err)
+		assert.NotContains(t, spec.Resources[componentToken].Required, "vpc_id")
+		assert.Empty(t, inferredModule.NonNilOutputs)
+	})
+}
+
+func TestLanguageNameCollisionsGenerateSafeCSharpNames(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("keywords", loadTestModule(t, "keywords"), nil)
+	require.NoError(t, err)
+	spec, err := pulumiSchemaForModule(&ParameterizeArgs{
+		TFModuleSource:  "acme/keywords/aws",
+		TFModuleVersion: "1.0.0",
+		PackageName:     "keywords",
+	}, inferredModule)
+	require.NoError(t, err)
+
+	csharpName := func(property schema.PropertySpec) string {
+		var info struct {
+			Name string `json:"name"`
+		}
+		if raw, ok := property.Language["csharp"]; ok {
+			require.NoError(t, json.Unmarshal(raw, &info))
+		}
+		return info.Name
+	}
+
+	// the Pulumi names are unchanged, so the module outputs still map to the Terraform outputs
+	outputs := spec.Resources["keywords:index:Module"].Properties
+	assert.Equal(t, "ModuleValue", csharpName(outputs["module"]))
+	assert.Equal(t, "GetValue", csharpName(outputs["get"]))
+	assert.Empty(t, csharpName(outputs["class"]), "keywords are escaped by the C# SDK itself")
+	assert.Empty(t, csharpName(outputs["listener"]))
+	assert.Equal(t, "ListenerValue",
+		csharpName(spec.Types["keywords:index:Listener"].Properties["listener"]))
+	assert.Empty(t, csharpName(spec.Types["keywords:index:Listener"].Properties["port"]))
+
+	// the C# SDK codegen picks up the overrides
+	pkg, err := schema.ImportSpec(*spec, map[string]schema.Language{"csharp": dotnet_codegen.Importer},
+		schema.ValidationOptions{})
+	require.NoError(t, err)
+	res, ok := pkg.GetResource("keywords:index:Module")
+	require.True(t, ok)
+	i := slices.IndexFunc(res.Properties, func(p *schema.Property) bool { return p.Name == "module" })
+	require.NotEqual(t, -1, i)
+	assert.Equal(t, dotnet_codegen.CSharpPropertyInfo{Name: "ModuleValue"}, res.Properties[i].Language["csharp"])
+}
@@ -0,0 +1,78 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// reportSecretsAudit lists the paths of the module inputs and outputs that are handled as secrets, without their
+// values, when the secretsAudit provider option is set. The meta-properties persisting the module state are always
+// secret and are not listed.
+func reportSecretsAudit(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	moduleInputs resource.PropertyMap,
+	moduleOutputs resource.PropertyMap,
+) {
+	outputs := moduleOutputs.Copy()
+	delete(outputs, moduleResourceStatePropName)
+	delete(outputs, moduleResourceLockPropName)
+	delete(outputs, moduleResourceVersionPropName)
+	delete(outputs, moduleResourceDataSourcesPropName)
+	delete(outputs, moduleResourceChildResourceTypesPropName)
+	delete(outputs, moduleResourceProvidersConfigPropName)
+
+	describe := func(paths []string) string {
+		if len(paths) == 0 {
+			return "none"
+		}
+		return strings.Join(paths, ", ")
+	}
+	logger.Log(ctx, tfsandbox.Info, fmt.Sprintf("Secret inputs: %s\nSecret outputs: %s",
+		describe(secretPaths(moduleInputs)), describe(secretPaths(outputs))))
+}
+
+// secretPaths lists the paths of the secret values in props in a stable order, such as password or
+// rules[0].token. Values nested in a secret are covered by the path of the secret and are not listed.
+func secretPaths(props resource.PropertyMap) []string {
+	var paths []string
+	var visit func(path resource.PropertyPath, v resource.PropertyValue)
+	visit = func(path resource.PropertyPath, v resource.PropertyValue) {
+		switch {
+		case v.IsSecret(), v.IsOutput() && v.OutputValue().Secret:
+			paths = append(paths, path.String())
+		case v.IsOutput():
+			visit(path, v.OutputValue().Element)
+		case v.IsArray():
+			for i, e := range v.ArrayValue() {
+				visit(append(slices.Clone(path), i), e)
+			}
+		case v.IsObject():
+			for _, key := range v.ObjectValue().StableKeys() {
+				visit(append(slices.Clone(path), string(key)), v.ObjectValue()[key])
+			}
+		}
+	}
+	visit(nil, resource.NewObjectProperty(props))
+	return paths
+}
@@ -23,7 +23,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
 
@@ -76,6 +79,84 @@ type server struct {
 	// for example, moduleExecutor could be set to "opentofu" to use the opentofu CLI.
 	// in which case we will try to find the opentofu binary in the PATH or download it if it is not available.
 	moduleExecutor string
+	// skipUnchangedPlans enables a fast path in Diff that returns no changes without running a plan when the
+	// inputs, the module version and the provider configuration of a module instance did not change.
+	skipUnchangedPlans bool
+	// providerMeta holds the contents of provider_meta blocks to emit for the required providers of the module.
+	providerMeta map[string]resource.PropertyMap
+	// pluginCacheDir is a provider plugin cache shared by module instances, disabled when empty.
+	pluginCacheDir string
+	// initLimiter bounds the number of concurrent inits following the initParallelism option, nil when unbounded.
+	initLimiter *tfsandbox.InitLimiter
+	// moduleCacheDir is a cache of downloaded modules shared by module instances, disabled when empty.
+	moduleCacheDir string
+	// stateBackupDir receives backups of the states of module instances before they change, disabled when empty.
+	stateBackupDir string
+	// extraTerraformFiles holds additional Terraform files written next to the module invocation, keyed by name.
+	extraTerraformFiles map[string]string
+	// moduleDependsOn are references added to the depends_on meta-argument of the module block.
+	moduleDependsOn []string
+	// escProvidersConfig holds Terraform provider configurations read from an ESC environment. Provider
+	// configuration set in the program takes precedence over these.
+	escProvidersConfig map[string]resource.PropertyMap
+	// escEnvironments caches the ESC environments opened while configuring the provider.
+	escEnvironments escEnvironmentCache
+	// costEstimateCommand is run on module plans during previews to estimate costs, disabled when empty.
+	costEstimateCommand string
+	// readiness is polled after applying module instances until their resources are ready.
+	readiness readinessCheck
+	// applyRetry selects the failed applies that are retried.
+	applyRetry applyRetryPolicy
+	// stateSizeLimits bound the size of the Terraform state stored for every module instance.
+	stateSizeLimits stateSizeLimits
+	// readOnly refuses to apply or destroy modules, limiting the provider to planning.
+	readOnly bool
+	// workdirCleanup selects when the working directories of module instances are removed.
+	workdirCleanup workdirCleanupPolicy
+	// failOnDrift turns drift detected while refreshing modules into errors.
+	failOnDrift bool
+	// refreshOnly makes updates accept drift instead of applying the program.
+	refreshOnly bool
+	// destroyTargets are the addresses of child resources that updates destroy instead of applying the program.
+	destroyTargets []string
+	// registryTokens authenticate to private module registries.
+	registryTokens registryTokens
+	// moduleNaming derives the names of the Terraform module blocks from the names of module instances.
+	moduleNaming moduleNamingStrategy
+	// invalidModuleState selects how module instances with a missing or malformed state are handled.
+	invalidModuleState invalidModuleStatePolicy
+	// gitFullClone clones modules from git sources with their whole history instead of shallow cloning them.
+	gitFullClone bool
+	// providerEndpoints override the service endpoints of the Terraform providers required by the module.
+	providerEndpoints providerEndpoints
+	// pulumiDefaultTags tags the resources of modules with the stack and project they belong to.
+	pulumiDefaultTags bool
+	// secretsAudit logs the paths of the secret inputs and outputs of modules.
+	secretsAudit bool
+	// taintedResources selects how child resources left tainted by a failed apply are handled.
+	taintedResources taintedResourcesPolicy
+	// providerChecksums selects how strictly provider packages are verified against the recorded lock files.
+	providerChecksums providerChecksumsPolicy
+	// viewStepsBatchSize bounds the size in bytes of every request publishing the views of child resources.
+	viewStepsBatchSize int
+	// dataSourceViews reports the data sources read by modules as read-only views.
+	dataSourceViews bool
+	// childResourceDiffs details the changes of child resources in their views.
+	childResourceDiffs bool
+	// childResourceTypes selects the type tokens of the views of child resources.
+	childResourceTypes childResourceTypesPolicy
+	// refreshMode selects how module instances are refreshed.
+	refreshMode refreshMode
+	// filesystemSandbox selects how much of the host modules may reach.
+	filesystemSandbox filesystemSandboxPolicy
+	// interactiveAuth selects how module operations needing interactive provider authentication are handled.
+	interactiveAuth interactiveAuthPolicy
+	// unconfiguredProviders selects how module instances requiring unconfigured providers are checked.
+	unconfiguredProviders unconfiguredProvidersPolicy
+	// moduleSource and moduleVersion are the module to run when the moduleRef provider option overrides the ref of
+	// the module of the package, see module.
+	moduleSource  TFModuleSource
+	moduleVersion TFModuleVersion
 
 	auxProviderServer *auxprovider.Server
 
@@ -100,12 +181,18 @@ func (s *server) Parameterize(
 
 	s.componentTypeName = defaultComponentTypeName
 	s.packageName = pargs.PackageName
-	s.packageVersion = inferPackageVersion(pargs.TFModuleVersion)
+	s.packageVersion = inferPackageVersion(pargs.TFModuleSource, pargs.TFModuleVersion,
+		pargs.Config.gitVersionScheme())
 	logger := newResourceLogger(s.hostClient, "")
 
+	timeout, err := schemaInferenceTimeout()
+	if err != nil {
+		return nil, err
+	}
+
 	workdir := tfsandbox.ModuleWorkdir(pargs.TFModuleSource, pargs.TFModuleVersion)
 	// Since multiple provider instances may be racing to infer a schema of the same module, use OS-level locking.
-	lockFile := filepath.Join(os.TempDir(), "pulumi-terraform-module-"+strings.Join(workdir, "-")+".lock")
+	lockFile := schemaInferenceLockFile(workdir)
 	logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("Acquiring schema inference FileMutex: %s", lockFile))
 	mu := fsutil.NewFileMutex(lockFile)
 	err = mu.Lock()
@@ -123,15 +210,27 @@ func (s *server) Parameterize(
 		executor = os.Getenv(moduleExecutorEnvironmentVariable)
 	}
 
-	tf, err := tfsandbox.PickModuleRuntime(ctx, logger, workdir, s.auxProviderServer, executor)
+	// Cancelling the context kills the executor, so that a hanging init fails and releases the FileMutex.
+	inferCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		inferCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	tf, err := tfsandbox.PickModuleRuntime(inferCtx, logger, workdir, s.auxProviderServer, executor)
 	if err != nil {
 		return nil, fmt.Errorf("sandbox construction failure: %w", err)
 	}
 
 	logger.LogStatus(ctx, tfsandbox.Debug, fmt.Sprintf("Using %s for schema inference", tf.Description()))
 
-	inferredModuleSchema, err := inferModuleSchema(ctx, tf, s.packageName,
-		pargs.TFModuleSource, pargs.TFModuleVersion, logger)
+	inferredModuleSchema, err := inferModuleSchema(inferCtx, tf, s.packageName,
+		pargs.TFModuleSource, pargs.TFModuleVersion, pargs.Config, logger)
+	if err != nil && errors.Is(inferCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("inferring the module schema for '%s' timed out after %s, set %s to allow more time",
+			pargs.TFModuleSource, timeout, schemaInferenceTimeoutEnvironmentVariable)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error while inferring module schema for '%s' version %s: %w",
 			pargs.TFModuleSource,
@@ -140,12 +239,36 @@ func (s *server) Parameterize(
 	}
 
 	s.inferredModuleSchema = inferredModuleSchema
+	// Packages are parameterized with arguments by `pulumi package add`, and with a value by the generated SDKs.
+	if req.GetArgs() != nil {
+		warnOnAnyTypes(ctx, logger, inferredModuleSchema, moduleSchemaOverride(&pargs))
+	}
 	return &pulumirpc.ParameterizeResponse{
 		Name:    string(s.packageName),
 		Version: string(s.packageVersion),
 	}, nil
 }
 
+// schemaInferenceLockFile is the FileMutex serializing the schema inference of the module in workdir.
+func schemaInferenceLockFile(workdir tfsandbox.Workdir) string {
+	return filepath.Join(os.TempDir(), "pulumi-terraform-module-"+strings.Join(workdir, "-")+".lock")
+}
+
+// schemaInferenceTimeout reads the time limit for schema inference from the environment; zero means no limit, which is
+// the case when the environment variable is unset.
+func schemaInferenceTimeout() (time.Duration, error) {
+	v := os.Getenv(schemaInferenceTimeoutEnvironmentVariable)
+	if v == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(v)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("%s must be a positive duration such as 10m, got %q",
+			schemaInferenceTimeoutEnvironmentVariable, v)
+	}
+	return timeout, nil
+}
+
 func dirExists(path string) bool {
 	_, err := os.Stat(path)
 	if err == nil {
@@ -158,9 +281,19 @@ func dirExists(path string) bool {
 }
 
 func extractConfigParamFromArgs(args []string) ([]string, string, bool) {
+	return extractFlagFromArgs(args, "--config", "-c")
+}
+
+func extractVersionManifestParamFromArgs(args []string) ([]string, string, bool) {
+	return extractFlagFromArgs(args, "--version-manifest")
+}
+
+// extractFlagFromArgs removes a flag such as `--config <file>` from args, returning the remaining args and the value.
+func extractFlagFromArgs(args []string, names ...string) ([]string, string, bool) {
 	for i, arg := range args {
-		if (arg == "--config" || arg == "-c") && i+1 < len(args) {
-			return args[:i], args[i+1], true
+		if slices.Contains(names, arg) && i+1 < len(args) {
+			rest := slices.Concat(args[:i], args[i+2:])
+			return rest, args[i+1], true
 		}
 	}
 	return args, "", false
@@ -190,8 +323,11 @@ func unmarshallConfigFile(configFilePath string, packageName string) (*ModuleCon
 // the accepted formats here are either:
 //
 //		<module-source> <version> <package-name> [--config <config-file>]
-//	 	<module-source> <package-name> [--config <config-file>]
+//	 	<module-source> <package-name> [--config <config-file>] [--version-manifest <manifest-file>]
 //		<local-module-source> <package-name> [--config <config-file>]
+//
+// When the version is omitted for a remote module, it is taken from the version manifest if one is given, and
+// otherwise resolved to the latest available version.
 func parseParameterizeRequest(
 	ctx context.Context,
 	request *pulumirpc.ParameterizeRequest,
@@ -200,6 +336,10 @@ func parseParameterizeRequest(
 	case request.GetArgs() != nil:
 		arguments := request.GetArgs()
 		args, configFile, hasConfig := extractConfigParamFromArgs(arguments.Args)
+		args, manifestFile, hasManifest := extractVersionManifestParamFromArgs(args)
+		if len(args) > 0 && !dirExists(args[0]) {
+			args = append([]string{string(TFModuleSource(args[0]).Normalized())}, args[1:]...)
+		}
 
 		applyConfigWhenAvailable := func(packageName string, args ParameterizeArgs) (ParameterizeArgs, error) {
 			if hasConfig {
@@ -240,6 +380,22 @@ func parseParameterizeRequest(
 					})
 				}
 
+				if hasManifest {
+					manifest, err := readVersionManifest(manifestFile)
+					if err != nil {
+						return ParameterizeArgs{}, err
+					}
+					pinned, err := manifest.moduleVersion(source)
+					if err != nil {
+						return ParameterizeArgs{}, err
+					}
+					return applyConfigWhenAvailable(args[1], ParameterizeArgs{
+						TFModuleSource:  source,
+						TFModuleVersion: pinned,
+						PackageName:     packageName(args[1]),
+					})
+				}
+
 				// if the second arg is not a version then it must be package name
 				// but the source is remote so we need to resolve the version ourselves
 				latest, err := latestModuleVersion(ctx, args[0])
@@ -247,11 +403,15 @@ func parseParameterizeRequest(
 					return ParameterizeArgs{}, err
 				}
 
-				return applyConfigWhenAvailable(args[1], ParameterizeArgs{
+				pargs, err := applyConfigWhenAvailable(args[1], ParameterizeArgs{
 					TFModuleSource:  TFModuleSource(args[0]),
 					TFModuleVersion: TFModuleVersion(latest.String()),
 					PackageName:     packageName(args[1]),
 				})
+				if err != nil {
+					return ParameterizeArgs{}, err
+				}
+				return pinGitSourceCommit(ctx, pargs, lsRemoteHead)
 			}
 
 			return ParameterizeArgs{}, fmt.Errorf("package name argument is required")
@@ -311,7 +471,7 @@ func (*server) GetPluginInfo(
 }
 
 func (s *server) Configure(
-	_ context.Context,
+	ctx context.Context,
 	req *pulumirpc.ConfigureRequest,
 ) (*pulumirpc.ConfigureResponse, error) {
 	config, err := plugin.UnmarshalProperties(req.Args, plugin.MarshalOptions{
@@ -340,6 +500,295 @@ func (s *server) Configure(
 		s.moduleExecutor = os.Getenv(moduleExecutorEnvironmentVariable)
 	}
 
+	s.skipUnchangedPlans, err = boolProviderOption(config, skipUnchangedPlansVariableName,
+		skipUnchangedPlansEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.readOnly, err = boolProviderOption(config, readOnlyVariableName, readOnlyEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.failOnDrift, err = boolProviderOption(config, failOnDriftVariableName, failOnDriftEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshOnly, err = boolProviderOption(config, refreshOnlyVariableName, refreshOnlyEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.destroyTargets, _, err = stringListProviderOption(config, destroyTargetsVariableName,
+		destroyTargetsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	if s.refreshOnly && len(s.destroyTargets) > 0 {
+		return nil, fmt.Errorf("provider options %q and %q cannot be combined", refreshOnlyVariableName,
+			destroyTargetsVariableName)
+	}
+
+	workdirCleanup, err := stringProviderOption(config, workdirCleanupVariableName,
+		workdirCleanupEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.workdirCleanup, err = parseWorkdirCleanupPolicy(workdirCleanup)
+	if err != nil {
+		return nil, err
+	}
+
+	s.gitFullClone, err = boolProviderOption(config, gitFullCloneVariableName, gitFullCloneEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pulumiDefaultTags, err = boolProviderOption(config, pulumiDefaultTagsVariableName,
+		pulumiDefaultTagsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.secretsAudit, err = boolProviderOption(config, secretsAuditVariableName, secretsAuditEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.dataSourceViews, err = boolProviderOption(config, dataSourceViewsVariableName, dataSourceViewsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.childResourceDiffs, err = boolProviderOption(config, childResourceDiffsVariableName,
+		childResourceDiffsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleRef, err := stringProviderOption(config, moduleRefVariableName, moduleRefEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.moduleSource, s.moduleVersion = "", ""
+	if moduleRef != "" && s.params != nil {
+		s.moduleSource, s.moduleVersion, err = moduleWithRef(s.params.TFModuleSource, s.params.TFModuleVersion,
+			moduleRef)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	taintedResources, err := stringProviderOption(config, taintedResourcesVariableName,
+		taintedResourcesEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.taintedResources, err = parseTaintedResourcesPolicy(taintedResources)
+	if err != nil {
+		return nil, err
+	}
+
+	interactiveAuth, err := stringProviderOption(config, interactiveAuthVariableName,
+		interactiveAuthEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.interactiveAuth, err = parseInteractiveAuthPolicy(interactiveAuth)
+	if err != nil {
+		return nil, err
+	}
+
+	childResourceTypes, err := stringProviderOption(config, childResourceTypesVariableName,
+		childResourceTypesEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.childResourceTypes, err = parseChildResourceTypesPolicy(childResourceTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshMode, err := stringProviderOption(config, refreshModeVariableName, refreshModeEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.refreshMode, err = parseRefreshMode(refreshMode)
+	if err != nil {
+		return nil, err
+	}
+
+	unconfiguredProviders, err := stringProviderOption(config, unconfiguredProvidersVariableName,
+		unconfiguredProvidersEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.unconfiguredProviders, err = parseUnconfiguredProvidersPolicy(unconfiguredProviders)
+	if err != nil {
+		return nil, err
+	}
+
+	filesystemSandbox, err := stringProviderOption(config, filesystemSandboxVariableName,
+		filesystemSandboxEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.filesystemSandbox, err = parseFilesystemSandboxPolicy(filesystemSandbox)
+	if err != nil {
+		return nil, err
+	}
+
+	providerChecksums, err := stringProviderOption(config, providerChecksumsVariableName,
+		providerChecksumsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.providerChecksums, err = parseProviderChecksumsPolicy(providerChecksums)
+	if err != nil {
+		return nil, err
+	}
+
+	invalidModuleState, err := stringProviderOption(config, invalidModuleStateVariableName,
+		invalidModuleStateEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidModuleState, err = parseInvalidModuleStatePolicy(invalidModuleState)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleNaming, err := stringProviderOption(config, moduleNamingVariableName, moduleNamingEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.moduleNaming, err = parseModuleNamingStrategy(moduleNaming)
+	if err != nil {
+		return nil, err
+	}
+
+	s.providerMeta, err = providerMetaOption(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.pluginCacheDir, err = stringProviderOption(config, pluginCacheDirVariableName,
+		pluginCacheDirEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	initParallelism, err := intProviderOption(config, initParallelismVariableName,
+		initParallelismEnvironmentVariable, 0)
+	if err != nil {
+		return nil, err
+	}
+	s.initLimiter = tfsandbox.NewInitLimiter(initParallelism)
+
+	s.moduleCacheDir, err = stringProviderOption(config, moduleCacheDirVariableName,
+		moduleCacheDirEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.stateBackupDir, err = stringProviderOption(config, stateBackupDirVariableName,
+		stateBackupDirEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.extraTerraformFiles, err = extraTerraformFilesOption(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s.moduleDependsOn, _, err = stringListProviderOption(config, moduleDependsOnVariableName,
+		moduleDependsOnEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	s.registryTokens, err = registryTokensOption(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var providerVariables map[string]schema.PropertySpec
+	if s.inferredModuleSchema != nil {
+		providerVariables = s.inferredModuleSchema.ProvidersConfig.Variables
+	}
+	s.providerEndpoints, err = providerEndpointsOption(config, providerVariables)
+	if err != nil {
+		return nil, err
+	}
+
+	s.costEstimateCommand, err = stringProviderOption(config, costEstimateCommandVariableName,
+		costEstimateCommandEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+
+	readinessCommand, err := stringProviderOption(config, readinessCommandVariableName,
+		readinessCommandEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	readinessTimeout, err := stringProviderOption(config, readinessTimeoutVariableName,
+		readinessTimeoutEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.readiness, err = newReadinessCheck(readinessCommand, readinessTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	retryPatterns, ok, err := stringListProviderOption(config, applyRetryPatternsVariableName,
+		applyRetryPatternsEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		retryPatterns = defaultApplyRetryPatterns
+	}
+	s.applyRetry, err = newApplyRetryPolicy(retryPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	s.stateSizeLimits.warning, err = intProviderOption(config, stateSizeWarningLimitVariableName,
+		stateSizeWarningLimitEnvironmentVariable, defaultStateSizeWarningLimit)
+	if err != nil {
+		return nil, err
+	}
+	s.stateSizeLimits.hard, err = intProviderOption(config, stateSizeLimitVariableName,
+		stateSizeLimitEnvironmentVariable, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s.viewStepsBatchSize, err = intProviderOption(config, viewStepsBatchSizeVariableName,
+		viewStepsBatchSizeEnvironmentVariable, defaultViewStepsBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	escEnvironment, err := stringProviderOption(config, escEnvironmentVariableName,
+		escEnvironmentEnvironmentVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.escProvidersConfig = nil
+	if escEnvironment != "" {
+		s.escProvidersConfig, err = loadESCProvidersConfig(ctx, escEnvironment,
+			s.escEnvironments.opener(openESCEnvironment))
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &pulumirpc.ConfigureResponse{
 		AcceptSecrets:   true,
 		SupportsPreview: true,
@@ -348,6 +797,321 @@ func (s *server) Configure(
 	}, nil
 }
 
+// providersConfig assembles the configuration of the Terraform providers required by the module.
+func (s *server) providersConfig() map[string]resource.PropertyMap {
+	providersConfig := mergeProvidersConfig(s.escProvidersConfig, cleanProvidersConfig(s.providerConfig))
+	providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
+	providersConfig = withProviderEndpoints(providersConfig, s.providerEndpoints)
+	providersConfig = fixupProvidersConfigForAWSIgnoreTags(providersConfig)
+	return fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
+}
+
+func (s *server) moduleOptions() moduleOptions {
+	return moduleOptions{
+		executor:            s.moduleExecutor,
+		skipUnchangedPlans:  s.skipUnchangedPlans,
+		providerMeta:        s.providerMeta,
+		pluginCacheDir:      s.pluginCacheDir,
+		initLimiter:         s.initLimiter,
+		moduleCacheDir:      s.moduleCacheDir,
+		costEstimateCommand: s.costEstimateCommand,
+		readiness:           s.readiness,
+		applyRetry:          s.applyRetry,
+		stateSizeLimits:     s.stateSizeLimits,
+		readOnly:            s.readOnly,
+		extraTerraformFiles: s.extraTerraformFiles,
+		moduleDependsOn:     s.moduleDependsOn,
+		workdirCleanup:      s.workdirCleanup,
+		failOnDrift:         s.failOnDrift,
+		refreshOnly:         s.refreshOnly,
+		destroyTargets:      s.destroyTargets,
+		registryTokens:      s.registryTokens,
+		moduleNaming:        s.moduleNaming,
+		invalidModuleState:  s.invalidModuleState,
+		gitFullClone:        s.gitFullClone,
+		pulumiDefaultTags:   s.pulumiDefaultTags,
+		secretsAudit:        s.secretsAudit,
+		taintedResources:    s.taintedResources,
+		viewStepsBatchSize:  s.viewStepsBatchSize,
+		providerChecksums:   s.providerChecksums,
+		dataSourceViews:     s.dataSourceViews,
+		childResourceDiffs:  s.childResourceDiffs,
+		filesystemSandbox:   s.filesystemSandbox,
+		interactiveAuth:     s.interactiveAuth,
+
+		unconfiguredProviders: s.unconfiguredProviders,
+		stateBackupDir:        s.stateBackupDir,
+		childResourceTypes:    s.childResourceTypes,
+		refreshMode:           s.refreshMode,
+	}
+}
+
+// registryTokensOption reads the tokens for private module registries keyed by registry host from the provider config,
+// falling back to the environment when the option is not set. The option may arrive either as an object or as a
+// JSON-encoded string, and is typically a secret.
+func registryTokensOption(config resource.PropertyMap) (registryTokens, error) {
+	v, ok := config[registryTokensVariableName]
+	if !ok || !v.HasValue() {
+		return environmentRegistryTokens()
+	}
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+
+	tokens := map[string]string{}
+	switch {
+	case v.IsString():
+		if err := json.Unmarshal([]byte(v.StringValue()), &tokens); err != nil {
+			return nil, fmt.Errorf("provider option %q must map registry hosts to tokens: %w",
+				registryTokensVariableName, err)
+		}
+	case v.IsObject():
+		for host, token := range v.ObjectValue() {
+			for token.IsSecret() {
+				token = token.SecretValue().Element
+			}
+			if !token.IsString() {
+				return nil, fmt.Errorf("provider option %q must map registry hosts to strings, got %v for %q",
+					registryTokensVariableName, token.TypeString(), host)
+			}
+			tokens[string(host)] = token.StringValue()
+		}
+	default:
+		return nil, fmt.Errorf("provider option %q must be an object, got %v", registryTokensVariableName,
+			v.TypeString())
+	}
+	return newRegistryTokens(tokens)
+}
+
+// extraTerraformFilesOption reads the additional Terraform files keyed by file name from the provider config. The
+// option may arrive either as an object or as a JSON-encoded string.
+func extraTerraformFilesOption(config resource.PropertyMap) (map[string]string, error) {
+	v, ok := config[extraTerraformFilesVariableName]
+	if !ok || !v.HasValue() {
+		return nil, nil
+	}
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+
+	files := map[string]string{}
+	switch {
+	case v.IsString():
+		if err := json.Unmarshal([]byte(v.StringValue()), &files); err != nil {
+			return nil, fmt.Errorf("provider option %q must map file names to their contents: %w",
+				extraTerraformFilesVariableName, err)
+		}
+	case v.IsObject():
+		for fileName, contents := range v.ObjectValue() {
+			if !contents.IsString() {
+				return nil, fmt.Errorf("provider option %q must map file names to strings, got %v for %q",
+					extraTerraformFilesVariableName, contents.TypeString(), fileName)
+			}
+			files[string(fileName)] = contents.StringValue()
+		}
+	default:
+		return nil, fmt.Errorf("provider option %q must be an object, got %v", extraTerraformFilesVariableName,
+			v.TypeString())
+	}
+	return files, nil
+}
+
+// providerMetaOption reads the provider_meta contents keyed by provider name from the provider config. Like provider
+// configurations, the option may arrive either as an object or as a JSON-encoded string.
+func providerMetaOption(config resource.PropertyMap) (map[string]resource.PropertyMap, error) {
+	v, ok := config[providerMetaVariableName]
+	if !ok || !v.HasValue() {
+		return nil, nil
+	}
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+
+	var meta resource.PropertyMap
+	switch {
+	case v.IsString():
+		deserialized := map[string]any{}
+		if err := json.Unmarshal([]byte(v.StringValue()), &deserialized); err != nil {
+			return nil, fmt.Errorf("provider option %q must be an object: %w", providerMetaVariableName, err)
+		}
+		meta = resource.NewPropertyMapFromMap(deserialized)
+	case v.IsObject():
+		meta = v.ObjectValue()
+	default:
+		return nil, fmt.Errorf("provider option %q must be an object, got %v", providerMetaVariableName, v.TypeString())
+	}
+
+	providerMeta := make(map[string]resource.PropertyMap, len(meta))
+	for providerName, contents := range meta {
+		if !contents.IsObject() {
+			return nil, fmt.Errorf("provider option %q must map provider names to objects, got %v for %q",
+				providerMetaVariableName, contents.TypeString(), providerName)
+		}
+		providerMeta[string(providerName)] = contents.ObjectValue()
+	}
+	return providerMeta, nil
+}
+
+// stringProviderOption reads a string option from the provider config, falling back to the given environment variable
+// when the option is not set.
+func stringProviderOption(config resource.PropertyMap, key string, envVar string) (string, error) {
+	v, ok := config[resource.PropertyKey(key)]
+	if !ok || !v.HasValue() {
+		return os.Getenv(envVar), nil
+	}
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	if !v.IsString() {
+		return "", fmt.Errorf("provider option %q must be a string, got %v", key, v.TypeString())
+	}
+	return v.StringValue(), nil
+}
+
+// stringListProviderOption reads a list of strings from the provider config, falling back to the given environment
+// variable when the option is not set. Both the option and the environment variable may hold a JSON-encoded array.
+// The second result tells whether the option was set at all.
+func stringListProviderOption(config resource.PropertyMap, key string, envVar string) ([]string, bool, error) {
+	v, ok := config[resource.PropertyKey(key)]
+	if !ok || !v.HasValue() {
+		raw, ok := os.LookupEnv(envVar)
+		if !ok {
+			return nil, false, nil
+		}
+		v = resource.NewStringProperty(raw)
+	}
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+
+	if v.IsString() {
+		var list []string
+		if err := json.Unmarshal([]byte(v.StringValue()), &list); err != nil {
+			return nil, false, fmt.Errorf("provider option %q must be a list of strings: %w", key, err)
+		}
+		return list, true, nil
+	}
+	if !v.IsArray() {
+		return nil, false, fmt.Errorf("provider option %q must be a list of strings, got %v", key, v.TypeString())
+	}
+	list := make([]string, 0, len(v.ArrayValue()))
+	for _, e := range v.ArrayValue() {
+		for e.IsSecret() {
+			e = e.SecretValue().Element
+		}
+		if !e.IsString() {
+			return nil, false, fmt.Errorf("provider option %q must be a list of strings, got an element of type %v",
+				key, e.TypeString())
+		}
+		list = append(list, e.StringValue())
+	}
+	return list, true, nil
+}
+
+// boolProviderOption reads a boolean option from the provider config, falling back to the given environment variable
+// when the option is not set. Legacy SDKs send primitive config values as strings, so both forms are accepted.
+func boolProviderOption(config resource.PropertyMap, key string, envVar string) (bool, error) {
+	raw := os.Getenv(envVar)
+	if v, ok := config[resource.PropertyKey(key)]; ok && v.HasValue() {
+		for v.IsSecret() {
+			v = v.SecretValue().Element
+		}
+		switch {
+		case v.IsBool():
+			return v.BoolValue(), nil
+		case v.IsString():
+			raw = v.StringValue()
+		default:
+			return false, fmt.Errorf("provider option %q must be a boolean, got %v", key, v.TypeString())
+		}
+	}
+	if raw == "" {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("provider option %q must be a boolean: %w", key, err)
+	}
+	return enabled, nil
+}
+
+// intProviderOption reads a non-negative integer option from the provider config, falling back to the given
+// environment variable and then to the default value when the option is not set. As with boolProviderOption, the
+// option may also arrive as a string.
+func intProviderOption(config resource.PropertyMap, key string, envVar string, defaultValue int) (int, error) {
+	raw := os.Getenv(envVar)
+	if v, ok := config[resource.PropertyKey(key)]; ok && v.HasValue() {
+		for v.IsSecret() {
+			v = v.SecretValue().Element
+		}
+		switch {
+		case v.IsNumber():
+			n := v.NumberValue()
+			if n < 0 || n != float64(int(n)) {
+				return 0, fmt.Errorf("provider option %q must be a non-negative integer, got %v", key, n)
+			}
+			return int(n), nil
+		case v.IsString():
+			raw = v.StringValue()
+		default:
+			return 0, fmt.Errorf("provider option %q must be an integer, got %v", key, v.TypeString())
+		}
+	}
+	if raw == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("provider option %q must be a non-negative integer, got %q", key, raw)
+	}
+	return n, nil
+}
+
+// providerOptionNames are the provider config keys that configure this provider rather than a Terraform provider.
+var providerOptionNames = []string{
+	"version",
+	"pluginDownloadURL",
+	moduleExecutorVariableName,
+	skipUnchangedPlansVariableName,
+	providerMetaVariableName,
+	extraTerraformFilesVariableName,
+	moduleDependsOnVariableName,
+	pluginCacheDirVariableName,
+	initParallelismVariableName,
+	escEnvironmentVariableName,
+	costEstimateCommandVariableName,
+	readinessCommandVariableName,
+	readinessTimeoutVariableName,
+	applyRetryPatternsVariableName,
+	stateSizeWarningLimitVariableName,
+	stateSizeLimitVariableName,
+	readOnlyVariableName,
+	workdirCleanupVariableName,
+	failOnDriftVariableName,
+	refreshOnlyVariableName,
+	destroyTargetsVariableName,
+	moduleNamingVariableName,
+	registryTokensVariableName,
+	invalidModuleStateVariableName,
+	gitFullCloneVariableName,
+	providerEndpointsVariableName,
+	pulumiDefaultTagsVariableName,
+	secretsAuditVariableName,
+	taintedResourcesVariableName,
+	viewStepsBatchSizeVariableName,
+	providerChecksumsVariableName,
+	dataSourceViewsVariableName,
+	moduleRefVariableName,
+	moduleCacheDirVariableName,
+	interactiveAuthVariableName,
+	childResourceDiffsVariableName,
+	filesystemSandboxVariableName,
+	unconfiguredProvidersVariableName,
+	stateBackupDirVariableName,
+	childResourceTypesVariableName,
+	refreshModeVariableName,
+}
+
 // cleanProvidersConfig takes config that was produced from provider inputs in the program:
 //
 //	const provider = new vpc.Provider("my-provider", {
@@ -370,16 +1134,16 @@ func (s *server) Configure(
 func cleanProvidersConfig(config resource.PropertyMap) map[string]resource.PropertyMap {
 	providersConfig := make(map[string]resource.PropertyMap)
 	for propertyKey, originalSerializedConfig := range config {
-		if string(propertyKey) == "version" ||
-			string(propertyKey) == "pluginDownloadURL" ||
-			string(propertyKey) == moduleExecutorVariableName {
+		if slices.Contains(providerOptionNames, string(propertyKey)) {
 			// skip properties that are not provider configurations
 			continue
 		}
 
-		// Disregard secret markers here; this works for both JSON-encoded strings and objects.
+		// Unwrap secret markers here; this works for both JSON-encoded strings and objects. The attributes of a
+		// secret configuration are marked secret instead so that they are passed to Terraform as sensitive values.
 		serializedConfig := originalSerializedConfig
-		if serializedConfig.IsSecret() {
+		secret := serializedConfig.IsSecret()
+		if secret {
 			serializedConfig = originalSerializedConfig.SecretValue().Element
 		}
 
@@ -391,7 +1155,8 @@ func cleanProvidersConfig(config resource.PropertyMap) map[string]resource.Prope
 			}
 
 			if len(deserialized) > 0 {
-				providersConfig[string(propertyKey)] = resource.NewPropertyMapFromMap(deserialized)
+				providersConfig[string(propertyKey)] = secretProviderConfig(
+					resource.NewPropertyMapFromMap(deserialized), secret)
 			}
 			continue
 		}
@@ -401,7 +1166,7 @@ func cleanProvidersConfig(config resource.PropertyMap) map[string]resource.Prope
 			// but send the actual object instead
 			// right now only YAML and Go programs send the actual object
 			// see https://github.com/pulumi/home/issues/3705 for reference
-			providersConfig[string(propertyKey)] = serializedConfig.ObjectValue()
+			providersConfig[string(propertyKey)] = secretProviderConfig(serializedConfig.ObjectValue(), secret)
 			continue
 		}
 		contract.Failf("cleanProvidersConfig failed to parse unsupported type: %v", serializedConfig)
@@ -410,6 +1175,41 @@ func cleanProvidersConfig(config resource.PropertyMap) map[string]resource.Prope
 	return providersConfig
 }
 
+// secretProviderConfig marks the attributes of a provider configuration that was secret as a whole secret. Only the
+// scalar values are marked: nested blocks, such as the assume_role and default_tags blocks of aws, are made of lists
+// and objects that Terraform requires in place, while a secret is passed as a reference to a sensitive local.
+func secretProviderConfig(config resource.PropertyMap, secret bool) resource.PropertyMap {
+	if !secret {
+		return config
+	}
+	return secretScalars(resource.NewObjectProperty(config)).ObjectValue()
+}
+
+// secretScalars marks the scalar values within v secret, leaving the objects and lists around them visible.
+func secretScalars(v resource.PropertyValue) resource.PropertyValue {
+	for v.IsSecret() {
+		v = v.SecretValue().Element
+	}
+	switch {
+	case v.IsObject():
+		obj := make(resource.PropertyMap, len(v.ObjectValue()))
+		for key, value := range v.ObjectValue() {
+			obj[key] = secretScalars(value)
+		}
+		return resource.NewObjectProperty(obj)
+	case v.IsArray():
+		arr := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, value := range v.ArrayValue() {
+			arr[i] = secretScalars(value)
+		}
+		return resource.NewArrayProperty(arr)
+	case v.IsNull():
+		return v
+	default:
+		return resource.MakeSecret(v)
+	}
+}
+
 func (s *server) Construct(
 	_ context.Context,
 	req *pulumirpc.ConstructRequest,
@@ -423,7 +1223,7 @@ func (s *server) Check(
 ) (*pulumirpc.CheckResponse, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		return s.moduleHandler.Check(ctx, req, s.inferredModuleSchema)
+		return s.moduleHandler.Check(ctx, req, s.inferredModuleSchema, s.providersConfig(), s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Check]: type %q is not supported yet", req.GetType())
 	}
@@ -455,6 +1255,14 @@ func (s *server) CheckConfig(
 		return nil, fmt.Errorf("CheckConfig failed to parse inputs: %w", err)
 	}
 
+	// malformed provider configurations are reported back to the user rather than crashing the provider later on
+	if failures := providersConfigFailures(config); len(failures) > 0 {
+		return &pulumirpc.CheckResponse{
+			Inputs:   req.News,
+			Failures: failures,
+		}, nil
+	}
+
 	// keep provider config in memory for use later.
 	// we keep one instance of provider configuration because each configuration is used
 	// once per provider process.
@@ -465,6 +1273,51 @@ func (s *server) CheckConfig(
 	}, nil
 }
 
+// providersConfigFailures validates the configurations of the Terraform providers in the provider config, which must
+// be objects or JSON-encoded objects as expected by cleanProvidersConfig. Unknown values are accepted as they are only
+// resolved during updates. The ignore_tags block of the aws provider is validated as well, see awsIgnoreTagsFailure.
+func providersConfigFailures(config resource.PropertyMap) []*pulumirpc.CheckFailure {
+	var failures []*pulumirpc.CheckFailure
+	for _, propertyKey := range config.StableKeys() {
+		if slices.Contains(providerOptionNames, string(propertyKey)) {
+			continue
+		}
+
+		serializedConfig := config[propertyKey]
+		for serializedConfig.IsSecret() {
+			serializedConfig = serializedConfig.SecretValue().Element
+		}
+
+		var reason string
+		switch {
+		case serializedConfig.ContainsUnknowns():
+			continue
+		case serializedConfig.IsString():
+			deserialized := map[string]interface{}{}
+			if err := json.Unmarshal([]byte(serializedConfig.StringValue()), &deserialized); err != nil {
+				reason = fmt.Sprintf("configuration for provider %q must be a JSON object: %v", propertyKey, err)
+			} else if propertyKey == awsProviderName {
+				reason = awsIgnoreTagsFailure(resource.NewPropertyMapFromMap(deserialized))
+			}
+		case serializedConfig.IsObject():
+			if propertyKey == awsProviderName {
+				reason = awsIgnoreTagsFailure(serializedConfig.ObjectValue())
+			}
+		default:
+			reason = fmt.Sprintf("configuration for provider %q must be an object, got %v", propertyKey,
+				serializedConfig.TypeString())
+		}
+
+		if reason != "" {
+			failures = append(failures, &pulumirpc.CheckFailure{
+				Property: string(propertyKey),
+				Reason:   reason,
+			})
+		}
+	}
+	return failures
+}
+
 // fixupProvidersConfigForAzureResourceManager ensures that the azurerm provider is configured
 // with an empty features block if it is required by the module but not explicitly configured
 func fixupProvidersConfigForAzureResourceManager(
@@ -492,11 +1345,10 @@ func (s *server) Diff(
 ) (*pulumirpc.DiffResponse, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		providersConfig := cleanProvidersConfig(s.providerConfig)
-		providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
-		providersConfig = fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
-		return s.moduleHandler.Diff(ctx, req, s.params.TFModuleSource, s.params.TFModuleVersion, providersConfig,
-			s.inferredModuleSchema, s.moduleExecutor)
+		providersConfig := s.providersConfig()
+		moduleSource, moduleVersion := s.module()
+		return s.moduleHandler.Diff(ctx, req, moduleSource, moduleVersion, providersConfig,
+			s.inferredModuleSchema, s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Diff]: type %q is not supported yet", req.GetType())
 	}
@@ -526,11 +1378,10 @@ func (s *server) Create(
 ) (*pulumirpc.CreateResponse, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		providersConfig := cleanProvidersConfig(s.providerConfig)
-		providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
-		providersConfig = fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
-		return s.moduleHandler.Create(ctx, req, s.params.TFModuleSource, s.params.TFModuleVersion, providersConfig,
-			s.inferredModuleSchema, s.packageName, s.moduleExecutor)
+		providersConfig := s.providersConfig()
+		moduleSource, moduleVersion := s.module()
+		return s.moduleHandler.Create(ctx, req, moduleSource, moduleVersion, providersConfig,
+			s.inferredModuleSchema, s.packageName, s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Create]: type %q is not supported yet", req.GetType())
 	}
@@ -542,11 +1393,10 @@ func (s *server) Update(
 ) (*pulumirpc.UpdateResponse, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		providersConfig := cleanProvidersConfig(s.providerConfig)
-		providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
-		providersConfig = fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
-		return s.moduleHandler.Update(ctx, req, s.params.TFModuleSource, s.params.TFModuleVersion, providersConfig,
-			s.inferredModuleSchema, s.packageName, s.moduleExecutor)
+		providersConfig := s.providersConfig()
+		moduleSource, moduleVersion := s.module()
+		return s.moduleHandler.Update(ctx, req, moduleSource, moduleVersion, providersConfig,
+			s.inferredModuleSchema, s.packageName, s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Update]: type %q is not supported yet", req.GetType())
 	}
@@ -558,12 +1408,10 @@ func (s *server) Delete(
 ) (*emptypb.Empty, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		providersConfig := cleanProvidersConfig(s.providerConfig)
-		providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
-		providersConfig = fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
-		return s.moduleHandler.Delete(ctx, req, s.packageName,
-			s.params.TFModuleSource, s.params.TFModuleVersion,
-			s.inferredModuleSchema, providersConfig, s.moduleExecutor)
+		providersConfig := s.providersConfig()
+		moduleSource, moduleVersion := s.module()
+		return s.moduleHandler.Delete(ctx, req, s.packageName, moduleSource, moduleVersion,
+			s.inferredModuleSchema, providersConfig, s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Delete]: type %q is not supported yet", req.GetType())
 	}
@@ -584,17 +1432,24 @@ func (s *server) Read(
 ) (*pulumirpc.ReadResponse, error) {
 	switch {
 	case req.GetType() == string(moduleTypeToken(s.packageName)):
-		providersConfig := cleanProvidersConfig(s.providerConfig)
-		providerVariables := s.inferredModuleSchema.ProvidersConfig.Variables
-		providersConfig = fixupProvidersConfigForAzureResourceManager(providersConfig, providerVariables)
-		return s.moduleHandler.Read(ctx, req, s.packageName,
-			s.params.TFModuleSource, s.params.TFModuleVersion,
-			s.inferredModuleSchema, providersConfig, s.moduleExecutor)
+		providersConfig := s.providersConfig()
+		moduleSource, moduleVersion := s.module()
+		return s.moduleHandler.Read(ctx, req, s.packageName, moduleSource, moduleVersion,
+			s.inferredModuleSchema, providersConfig, s.moduleOptions())
 	default:
 		return nil, fmt.Errorf("[Read]: type %q is not supported yet", req.GetType())
 	}
 }
 
+// module returns the source and version of the module to run: the module the package was generated from, unless the
+// moduleRef provider option overrides its ref.
+func (s *server) module() (TFModuleSource, TFModuleVersion) {
+	if s.moduleSource != "" {
+		return s.moduleSource, s.moduleVersion
+	}
+	return s.params.TFModuleSource, s.params.TFModuleVersion
+}
+
 func isChildResourceType(rawType string) bool {
 	typeTok, err := tokens.ParseTypeToken(rawType)
 	contract.AssertNoErrorf(err, "ParseTypeToken failed on %q", rawType)
@@ -16,13 +16,19 @@ package modprovider
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
 )
 
 const (
@@ -169,6 +175,31 @@ func TestParseParameterizeRequest(t *testing.T) {
 	})
 }
 
+func TestParseParameterizeRequestWithRegistryURL(t *testing.T) {
+	ctx := context.Background()
+
+	parse := func(source string) ParameterizeArgs {
+		args, err := parseParameterizeRequest(ctx, &pulumirpc.ParameterizeRequest{
+			Parameters: &pulumirpc.ParameterizeRequest_Args{
+				Args: &pulumirpc.ParameterizeRequest_ParametersArgs{
+					Args: []string{source, version005, consulPkg},
+				},
+			},
+		})
+		require.NoError(t, err)
+		return args
+	}
+
+	shorthand := parse(consulAwsSource)
+	for _, source := range []string{
+		"registry.terraform.io/" + consulAwsSource,
+		"registry.opentofu.org/" + consulAwsSource,
+		"https://registry.terraform.io/modules/" + consulAwsSource,
+	} {
+		assert.Equal(t, shorthand, parse(source), "%s should resolve like %s", source, consulAwsSource)
+	}
+}
+
 func TestParseParameterizeRequestWithConfig(t *testing.T) {
 	ctx := context.Background()
 	t.Run("parses args with path to config file", func(t *testing.T) {
@@ -231,7 +262,7 @@ func Test_cleanProvidersConfig(t *testing.T) {
 		cleaned := cleanProvidersConfig(inputConfig)
 		expected := map[string]resource.PropertyMap{
 			awsKey: {
-				resource.PropertyKey("accessKey"): resource.NewStringProperty("my-access-key"),
+				resource.PropertyKey("accessKey"): resource.MakeSecret(resource.NewStringProperty("my-access-key")),
 			},
 		}
 
@@ -262,9 +293,402 @@ func Test_cleanProvidersConfig(t *testing.T) {
 		cleaned := cleanProvidersConfig(inputConfig)
 		expected := map[string]resource.PropertyMap{
 			dockerKey: {
-				resource.PropertyKey("local"): resource.NewStringProperty("mydockerfile"),
+				resource.PropertyKey("local"): resource.MakeSecret(resource.NewStringProperty("mydockerfile")),
 			},
 		}
 		assert.Equal(t, expected, cleaned)
 	})
 }
+
+func TestSecretProviderConfigIsNotWrittenInPlaintext(t *testing.T) {
+	writeDir := t.TempDir()
+	t.Setenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE", writeDir)
+
+	providersConfig := cleanProvidersConfig(resource.PropertyMap{
+		awsKey: resource.MakeSecret(resource.NewStringProperty(`{"region":"us-west-2","secret_key":"hunter2"}`)),
+	})
+
+	workingDir := t.TempDir()
+	err := tfsandbox.CreateTFFile("mod", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+		resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Provider map[string]map[string]any `json:"provider"`
+	}
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+	assert.Regexp(t, `^\$\{sensitive\(local\.local\d\)\}$`, tfFile.Provider[awsKey]["secret_key"])
+	assert.Regexp(t, `^\$\{sensitive\(local\.local\d\)\}$`, tfFile.Provider[awsKey]["region"])
+
+	dumped, err := os.ReadFile(filepath.Join(writeDir, "mod.tf.json"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(dumped), "hunter2")
+	assert.Contains(t, string(dumped), `"[secret]"`)
+
+	t.Run("plaintext", func(t *testing.T) {
+		t.Setenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_SECRETS", "plaintext")
+		err := tfsandbox.CreateTFFile("mod", "terraform-aws-modules/vpc/aws", "5.19.0", t.TempDir(),
+			resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+		require.NoError(t, err)
+
+		dumped, err := os.ReadFile(filepath.Join(writeDir, "mod.tf.json"))
+		require.NoError(t, err)
+		assert.Contains(t, string(dumped), "hunter2")
+	})
+}
+
+func TestSecretProviderConfigWithNestedBlocks(t *testing.T) {
+	providersConfig := cleanProvidersConfig(resource.PropertyMap{
+		awsKey: resource.MakeSecret(resource.NewStringProperty(`{"region":"us-west-2",` +
+			`"assume_role":[{"role_arn":"arn:aws:iam::123456789012:role/deploy","session_name":"pulumi"}],` +
+			`"default_tags":[{"tags":{"team":"platform"}}],"allowed_account_ids":["123456789012"]}`)),
+	})
+
+	workingDir := t.TempDir()
+	err := tfsandbox.CreateTFFile("mod", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+		resource.PropertyMap{}, nil /* outputs */, providersConfig, tfsandbox.CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Provider map[string]map[string]any `json:"provider"`
+	}
+	contents, err := os.ReadFile(filepath.Join(workingDir, "pulumi.tf.json"))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+	awsProvider := tfFile.Provider[awsKey]
+	sensitive := `^\$\{sensitive\(local\.local\d+\)\}$`
+
+	// blocks stay blocks, only their attributes are sensitive
+	assumeRole, ok := awsProvider["assume_role"].([]any)
+	require.True(t, ok, "assume_role must be a list of blocks, got %v", awsProvider["assume_role"])
+	require.Len(t, assumeRole, 1)
+	block, ok := assumeRole[0].(map[string]any)
+	require.True(t, ok)
+	assert.Regexp(t, sensitive, block["role_arn"])
+	assert.Regexp(t, sensitive, block["session_name"])
+
+	defaultTags, ok := awsProvider["default_tags"].([]any)
+	require.True(t, ok, "default_tags must be a list of blocks, got %v", awsProvider["default_tags"])
+	tags, ok := defaultTags[0].(map[string]any)["tags"].(map[string]any)
+	require.True(t, ok)
+	assert.Regexp(t, sensitive, tags["team"])
+
+	accountIDs, ok := awsProvider["allowed_account_ids"].([]any)
+	require.True(t, ok)
+	assert.Regexp(t, sensitive, accountIDs[0])
+	assert.Regexp(t, sensitive, awsProvider["region"])
+	assert.NotContains(t, string(contents), `"region":"us-west-2"`)
+}
+
+func TestCheckConfigMalformedProvidersConfig(t *testing.T) {
+	s := &server{pulumiCliSupportsViews: true}
+	news, err := plugin.MarshalProperties(resource.PropertyMap{
+		"version":                resource.NewStringProperty("0.0.1"),
+		awsKey:                   resource.NewStringProperty(`{"region": "us-west-2"`),
+		dockerKey:                resource.NewNumberProperty(42),
+		"random":                 resource.NewStringProperty(`{}`),
+		readOnlyVariableName:     resource.NewBoolProperty(true),
+		providerMetaVariableName: resource.NewStringProperty(`{}`),
+	}, plugin.MarshalOptions{})
+	require.NoError(t, err)
+
+	var resp *pulumirpc.CheckResponse
+	require.NotPanics(t, func() {
+		resp, err = s.CheckConfig(context.Background(), &pulumirpc.CheckRequest{News: news})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Failures, 2)
+	assert.Equal(t, awsKey, resp.Failures[0].Property)
+	assert.Contains(t, resp.Failures[0].Reason, `configuration for provider "aws" must be a JSON object`)
+	assert.Equal(t, dockerKey, resp.Failures[1].Property)
+	assert.Equal(t, `configuration for provider "docker" must be an object, got number`, resp.Failures[1].Reason)
+	assert.Nil(t, s.providerConfig, "malformed provider config is not kept")
+}
+
+func TestBoolProviderOption(t *testing.T) {
+	const envVar = "PULUMI_TERRAFORM_MODULE_TEST_OPTION"
+	const key = "option"
+
+	cases := []struct {
+		name      string
+		config    resource.PropertyMap
+		env       string
+		expected  bool
+		expectErr bool
+	}{
+		{name: "unset"},
+		{
+			name:     "bool",
+			config:   resource.PropertyMap{key: resource.NewBoolProperty(true)},
+			expected: true,
+		},
+		{
+			name:     "string",
+			config:   resource.PropertyMap{key: resource.NewStringProperty("true")},
+			expected: true,
+		},
+		{
+			name:     "secret",
+			config:   resource.PropertyMap{key: resource.MakeSecret(resource.NewBoolProperty(true))},
+			expected: true,
+		},
+		{
+			name:     "env",
+			env:      "true",
+			expected: true,
+		},
+		{
+			name:     "config-overrides-env",
+			config:   resource.PropertyMap{key: resource.NewBoolProperty(false)},
+			env:      "true",
+			expected: false,
+		},
+		{
+			name:      "invalid",
+			config:    resource.PropertyMap{key: resource.NewStringProperty("sometimes")},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv(envVar, tc.env)
+			enabled, err := boolProviderOption(tc.config, key, envVar)
+			if tc.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, enabled)
+		})
+	}
+}
+
+func TestParseParameterizeRequestWithVersionManifest(t *testing.T) {
+	ctx := context.Background()
+	manifestPath := filepath.Join("testdata", "version_manifest", "versions.json")
+
+	parse := func(args ...string) (ParameterizeArgs, error) {
+		return parseParameterizeRequest(ctx, &pulumirpc.ParameterizeRequest{
+			Parameters: &pulumirpc.ParameterizeRequest_Args{
+				Args: &pulumirpc.ParameterizeRequest_ParametersArgs{Args: args},
+			},
+		})
+	}
+
+	t.Run("resolves the version from the manifest", func(t *testing.T) {
+		args, err := parse(consulAwsSource, consulPkg, "--version-manifest", manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, ParameterizeArgs{
+			TFModuleSource:  consulAwsSource,
+			TFModuleVersion: version005,
+			PackageName:     consulPkg,
+		}, args)
+	})
+
+	t.Run("combines with a config file", func(t *testing.T) {
+		args, err := parse(consulAwsSource, consulPkg,
+			"--version-manifest", manifestPath,
+			"--config", "testdata/module_configuration/simple-config.json")
+		require.NoError(t, err)
+		assert.Equal(t, TFModuleVersion(version005), args.TFModuleVersion)
+		require.NotNil(t, args.Config)
+		assert.Equal(t, []resource.PropertyKey{"output_name"}, args.Config.NonNilOutputs)
+	})
+
+	t.Run("explicit version takes precedence", func(t *testing.T) {
+		args, err := parse(consulAwsSource, version123, consulPkg, "--version-manifest", manifestPath)
+		require.NoError(t, err)
+		assert.Equal(t, TFModuleVersion(version123), args.TFModuleVersion)
+	})
+
+	t.Run("errors on modules missing from the manifest", func(t *testing.T) {
+		_, err := parse("terraform-aws-modules/vpc/aws", "vpc", "--version-manifest", manifestPath)
+		assert.ErrorContains(t, err, "module terraform-aws-modules/vpc/aws is not listed in version manifest")
+	})
+}
+
+func TestProviderMetaOption(t *testing.T) {
+	expected := map[string]resource.PropertyMap{
+		awsKey: {"module_name": resource.NewStringProperty("networking")},
+	}
+
+	t.Run("json-encoded", func(t *testing.T) {
+		meta, err := providerMetaOption(resource.PropertyMap{
+			providerMetaVariableName: resource.NewStringProperty(`{"aws":{"module_name":"networking"}}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, meta)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		meta, err := providerMetaOption(resource.PropertyMap{
+			providerMetaVariableName: resource.NewObjectProperty(resource.PropertyMap{
+				awsKey: resource.NewObjectProperty(expected[awsKey]),
+			}),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, expected, meta)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		meta, err := providerMetaOption(resource.PropertyMap{})
+		require.NoError(t, err)
+		assert.Nil(t, meta)
+	})
+
+	t.Run("not an object per provider", func(t *testing.T) {
+		_, err := providerMetaOption(resource.PropertyMap{
+			providerMetaVariableName: resource.NewStringProperty(`{"aws":"networking"}`),
+		})
+		assert.ErrorContains(t, err, `must map provider names to objects`)
+	})
+
+	t.Run("not part of the providers config", func(t *testing.T) {
+		cleaned := cleanProvidersConfig(resource.PropertyMap{
+			providerMetaVariableName: resource.NewStringProperty(`{"aws":{"module_name":"networking"}}`),
+		})
+		assert.Empty(t, cleaned)
+	})
+}
+
+func TestExtraTerraformFilesOption(t *testing.T) {
+	const sharedTF = "shared.tf"
+	const contents = `data "terraform_remote_state" "shared" { backend = "local" }`
+
+	t.Run("json-encoded", func(t *testing.T) {
+		encoded, err := json.Marshal(map[string]string{sharedTF: contents})
+		require.NoError(t, err)
+		files, err := extraTerraformFilesOption(resource.PropertyMap{
+			extraTerraformFilesVariableName: resource.NewStringProperty(string(encoded)),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{sharedTF: contents}, files)
+	})
+
+	t.Run("object", func(t *testing.T) {
+		files, err := extraTerraformFilesOption(resource.PropertyMap{
+			extraTerraformFilesVariableName: resource.NewObjectProperty(resource.PropertyMap{
+				sharedTF: resource.NewStringProperty(contents),
+			}),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{sharedTF: contents}, files)
+	})
+
+	t.Run("not a string per file", func(t *testing.T) {
+		_, err := extraTerraformFilesOption(resource.PropertyMap{
+			extraTerraformFilesVariableName: resource.NewObjectProperty(resource.PropertyMap{
+				sharedTF: resource.NewNumberProperty(1),
+			}),
+		})
+		assert.ErrorContains(t, err, "must map file names to strings")
+	})
+
+	t.Run("not part of the providers config", func(t *testing.T) {
+		cleaned := cleanProvidersConfig(resource.PropertyMap{
+			extraTerraformFilesVariableName: resource.NewStringProperty(`{}`),
+		})
+		assert.Empty(t, cleaned)
+	})
+}
+
+func TestStringProviderOption(t *testing.T) {
+	const envVar = "PULUMI_TERRAFORM_MODULE_TEST_OPTION"
+	const key = "option"
+
+	t.Run("config", func(t *testing.T) {
+		t.Setenv(envVar, "from-env")
+		value, err := stringProviderOption(resource.PropertyMap{key: resource.NewStringProperty("from-config")},
+			key, envVar)
+		require.NoError(t, err)
+		assert.Equal(t, "from-config", value)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(envVar, "from-env")
+		value, err := stringProviderOption(resource.PropertyMap{}, key, envVar)
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", value)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := stringProviderOption(resource.PropertyMap{key: resource.NewNumberProperty(1)}, key, envVar)
+		assert.ErrorContains(t, err, `provider option "option" must be a string`)
+	})
+}
+
+func TestIntProviderOption(t *testing.T) {
+	const envVar = "PULUMI_TERRAFORM_MODULE_TEST_OPTION"
+	const key = "option"
+
+	t.Run("config", func(t *testing.T) {
+		t.Setenv(envVar, "2")
+		value, err := intProviderOption(resource.PropertyMap{key: resource.NewNumberProperty(1)}, key, envVar, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 1, value)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(envVar, "2")
+		value, err := intProviderOption(resource.PropertyMap{}, key, envVar, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 2, value)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		value, err := intProviderOption(resource.PropertyMap{}, key, envVar, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 3, value)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := intProviderOption(resource.PropertyMap{key: resource.NewNumberProperty(1.5)}, key, envVar, 3)
+		assert.ErrorContains(t, err, `provider option "option" must be a non-negative integer`)
+	})
+}
+
+func TestStringListProviderOption(t *testing.T) {
+	const envVar = "PULUMI_TERRAFORM_MODULE_TEST_OPTION"
+	const key = "option"
+
+	t.Run("config", func(t *testing.T) {
+		t.Setenv(envVar, `["from-env"]`)
+		value, ok, err := stringListProviderOption(resource.PropertyMap{
+			key: resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("from-config")}),
+		}, key, envVar)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"from-config"}, value)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		t.Setenv(envVar, `["a", "b"]`)
+		value, ok, err := stringListProviderOption(resource.PropertyMap{}, key, envVar)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"a", "b"}, value)
+	})
+
+	t.Run("empty list", func(t *testing.T) {
+		value, ok, err := stringListProviderOption(resource.PropertyMap{key: resource.NewStringProperty("[]")},
+			key, envVar)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Empty(t, value)
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		_, ok, err := stringListProviderOption(resource.PropertyMap{}, key, envVar)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := stringListProviderOption(resource.PropertyMap{key: resource.NewNumberProperty(1)}, key, envVar)
+		assert.ErrorContains(t, err, `provider option "option" must be a list of strings`)
+	})
+}
@@ -0,0 +1,97 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// containsSetType reports whether values of type t may hold a set, at the top level or nested in collections and
+// objects.
+func containsSetType(t cty.Type) bool {
+	switch {
+	case t.IsSetType():
+		return true
+	case t.IsListType() || t.IsMapType():
+		return containsSetType(t.ElementType())
+	case t.IsObjectType():
+		for _, attributeType := range t.AttributeTypes() {
+			if containsSetType(attributeType) {
+				return true
+			}
+		}
+	case t.IsTupleType():
+		return slices.ContainsFunc(t.TupleElementTypes(), containsSetType)
+	}
+	return false
+}
+
+// normalizeSetInputs sorts the elements of the module inputs that Terraform treats as sets, which the Pulumi schema
+// represents as arrays, so that inputs only differing in the order of set elements compare equal.
+func normalizeSetInputs(inferredModule *InferredModuleSchema, inputs resource.PropertyMap) resource.PropertyMap {
+	if inferredModule == nil || len(inferredModule.setInputTypes) == 0 {
+		return inputs
+	}
+	normalized := inputs.Copy()
+	for key, t := range inferredModule.setInputTypes {
+		if v, ok := inputs[key]; ok {
+			normalized[key] = normalizeSetValue(t, v)
+		}
+	}
+	return normalized
+}
+
+func normalizeSetValue(t cty.Type, v resource.PropertyValue) resource.PropertyValue {
+	switch {
+	case v.IsSecret():
+		return resource.MakeSecret(normalizeSetValue(t, v.SecretValue().Element))
+	case v.IsOutput():
+		output := v.OutputValue()
+		output.Element = normalizeSetValue(t, output.Element)
+		return resource.NewOutputProperty(output)
+	case (t.IsSetType() || t.IsListType()) && v.IsArray():
+		elements := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, element := range v.ArrayValue() {
+			elements[i] = normalizeSetValue(t.ElementType(), element)
+		}
+		if t.IsSetType() {
+			slices.SortStableFunc(elements, func(a, b resource.PropertyValue) int {
+				return strings.Compare(a.String(), b.String())
+			})
+		}
+		return resource.NewArrayProperty(elements)
+	case t.IsMapType() && v.IsObject():
+		normalized := resource.PropertyMap{}
+		for k, element := range v.ObjectValue() {
+			normalized[k] = normalizeSetValue(t.ElementType(), element)
+		}
+		return resource.NewObjectProperty(normalized)
+	case t.IsObjectType() && v.IsObject():
+		normalized := resource.PropertyMap{}
+		for k, attribute := range v.ObjectValue() {
+			if t.HasAttribute(string(k)) {
+				attribute = normalizeSetValue(t.AttributeType(string(k)), attribute)
+			}
+			normalized[k] = attribute
+		}
+		return resource.NewObjectProperty(normalized)
+	default:
+		return v
+	}
+}
@@ -14,15 +14,6 @@
 
 package modprovider
 
-import (
-	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
-)
-
 const (
 	moduleStateResourceID = "moduleStateResource"
 )
-
-// getModuleName extracts the Terraform module instance name from the module's URN.
-func getModuleName(urn urn.URN) string {
-	return urn.Name()
-}
@@ -0,0 +1,76 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+const (
+	stateBackupApply   = "apply"
+	stateBackupDestroy = "destroy"
+)
+
+// stateBackupTimeFormat orders the backups of a module instance by the time they were taken when sorted by name.
+const stateBackupTimeFormat = "20060102T150405.000Z"
+
+// backupModuleState writes the Terraform state recorded for a module instance to the stateBackupDir directory before
+// an apply or destroy changes it, so that the state can be recovered by hand should the operation go wrong. Backups
+// are named after the project, stack and module instance, the time they were taken and the operation, as in
+// proj/dev/vpc-20260102T150405.000Z-apply.tfstate. The state holds the secrets of the module in plaintext like the
+// state files of Terraform, so backups are only readable by the user running Pulumi. Module instances that do not
+// record a state yet have nothing to back up.
+func (h *moduleHandler) backupModuleState(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	dir string,
+	urn urn.URN,
+	oldOutputs resource.PropertyMap,
+	operation string,
+) error {
+	if dir == "" || oldOutputs == nil {
+		return nil
+	}
+	rawState, _, _, err := h.getState(oldOutputs)
+	if err != nil {
+		return fmt.Errorf("failed to read the state of module %s to back it up: %w", urn.Name(), err)
+	}
+	if len(rawState) == 0 {
+		return nil
+	}
+
+	backupDir := filepath.Join(dir, urn.Project().String(), urn.Stack().String())
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the backup directory of the %q provider option: %w",
+			stateBackupDirVariableName, err)
+	}
+	path := filepath.Join(backupDir, fmt.Sprintf("%s-%s-%s.tfstate", sanitizeModuleName(urn.Name()),
+		time.Now().UTC().Format(stateBackupTimeFormat), operation))
+	if err := os.WriteFile(path, rawState, 0o600); err != nil {
+		return fmt.Errorf("failed to back up the state of module %s: %w", urn.Name(), err)
+	}
+	logger.Log(ctx, tfsandbox.Info, fmt.Sprintf("Backed up the state of module %s to %s before %s", urn.Name(),
+		path, operation))
+	return nil
+}
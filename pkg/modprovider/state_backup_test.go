@@ -0,0 +1,87 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+func TestBackupModuleState(t *testing.T) {
+	ctx := context.Background()
+	modURN := urn.URN("urn:pulumi:dev::proj::vpc:index:Module::my vpc")
+	rawState := `{"version":4,"serial":3,"resources":[]}`
+	oldOutputs := resource.PropertyMap{
+		moduleResourceStatePropName: resource.MakeSecret(resource.NewStringProperty(rawState)),
+	}
+	h := &moduleHandler{}
+
+	t.Run("apply", func(t *testing.T) {
+		dir := t.TempDir()
+		logger := &recordingLogger{}
+		err := h.backupModuleState(ctx, logger, dir, modURN, oldOutputs, stateBackupApply)
+		require.NoError(t, err)
+
+		backups, err := filepath.Glob(filepath.Join(dir, "proj", "dev", "*-apply.tfstate"))
+		require.NoError(t, err)
+		require.Len(t, backups, 1)
+		// the resource name is not a valid file name, so it is sanitized like module names
+		assert.Regexp(t, `/my_vpc_[0-9a-f]+-\d{8}T\d{6}\.\d{3}Z-apply\.tfstate$`, backups[0])
+
+		contents, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, rawState, string(contents))
+
+		info, err := os.Stat(backups[0])
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		info, err = os.Stat(filepath.Join(dir, "proj", "dev"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+		require.Len(t, logger.messages, 1)
+		assert.Contains(t, logger.messages[0], "Backed up the state of module my vpc to "+backups[0]+" before apply")
+		assert.NotContains(t, logger.messages[0], rawState)
+	})
+
+	t.Run("nothing to back up", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, h.backupModuleState(ctx, &recordingLogger{}, dir, modURN, nil, stateBackupApply))
+		require.NoError(t, h.backupModuleState(ctx, &recordingLogger{}, dir, modURN, resource.PropertyMap{},
+			stateBackupDestroy))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		require.NoError(t, h.backupModuleState(ctx, &recordingLogger{}, "", modURN, oldOutputs, stateBackupApply))
+	})
+
+	t.Run("unwritable", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0o600))
+		err := h.backupModuleState(ctx, &recordingLogger{}, file, modURN, oldOutputs, stateBackupDestroy)
+		assert.ErrorContains(t, err, `failed to create the backup directory of the "stateBackupDir" provider option`)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// defaultStateSizeWarningLimit is the size of the Terraform state of a module instance above which a warning is
+// emitted unless configured otherwise.
+const defaultStateSizeWarningLimit = 4 << 20
+
+// stateSizeLimits bound the size in bytes of the Terraform state that is stored in the Pulumi state of a module
+// instance. A zero limit is disabled.
+type stateSizeLimits struct {
+	// warning is a soft limit: exceeding it only emits a warning.
+	warning int
+	// hard is a limit that fails the operation when exceeded.
+	hard int
+}
+
+// checkStateSize measures the Terraform state kept in the __state property of the module outputs against the limits.
+func checkStateSize(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	moduleOutputs resource.PropertyMap,
+	limits stateSizeLimits,
+) error {
+	state, ok := moduleOutputs[moduleResourceStatePropName]
+	if !ok {
+		return nil
+	}
+	for state.IsSecret() {
+		state = state.SecretValue().Element
+	}
+	if !state.IsString() {
+		return nil
+	}
+
+	size := len(state.StringValue())
+	switch {
+	case limits.hard > 0 && size > limits.hard:
+		return fmt.Errorf("the Terraform state of the module is %s, exceeding the limit of %s set by %q; "+
+			"split the module into several module instances, or raise the limit by setting the %q provider option "+
+			"or the %s environment variable to a larger size in bytes",
+			formatByteSize(size), formatByteSize(limits.hard), stateSizeLimitVariableName,
+			stateSizeLimitVariableName, stateSizeLimitEnvironmentVariable)
+	case limits.warning > 0 && size > limits.warning:
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("The Terraform state of the module is %s, exceeding %s. "+
+			"Large states are stored in the Pulumi state with every update and slow down Pulumi operations; "+
+			"consider splitting the module into several module instances. To raise the threshold of this warning, "+
+			"set the %q provider option or the %s environment variable to a larger size in bytes, or to 0 to "+
+			"disable it.",
+			formatByteSize(size), formatByteSize(limits.warning),
+			stateSizeWarningLimitVariableName, stateSizeWarningLimitEnvironmentVariable))
+	}
+	return nil
+}
+
+func formatByteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
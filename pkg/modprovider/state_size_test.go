@@ -0,0 +1,62 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestCheckStateSize(t *testing.T) {
+	ctx := context.Background()
+
+	tfState, err := tfsandbox.NewState(&tfjson.State{Values: &tfjson.StateValues{}})
+	require.NoError(t, err)
+	largeState := []byte(`{"version":4,"resources":["` + strings.Repeat("x", 5<<20) + `"]}`)
+	outputs := moduleOutputsWithState(tfState, largeState, nil /* rawLockFile */, version123)
+
+	t.Run("warning", func(t *testing.T) {
+		logger := &recordingLogger{}
+		err := checkStateSize(ctx, logger, outputs, stateSizeLimits{warning: defaultStateSizeWarningLimit})
+		require.NoError(t, err)
+		require.Len(t, logger.messages, 1)
+		assert.Contains(t, logger.messages[0], "warn: The Terraform state of the module is 5.0 MiB, exceeding 4.0 MiB.")
+		assert.Contains(t, logger.messages[0], `set the "stateSizeWarningLimit" provider option or the `+
+			`PULUMI_TERRAFORM_MODULE_STATE_SIZE_WARNING_LIMIT environment variable`)
+	})
+
+	t.Run("hard limit", func(t *testing.T) {
+		logger := &recordingLogger{}
+		err := checkStateSize(ctx, logger, outputs, stateSizeLimits{warning: 1 << 20, hard: 2 << 20})
+		assert.ErrorContains(t, err, `the Terraform state of the module is 5.0 MiB, exceeding the limit of 2.0 MiB`)
+		assert.ErrorContains(t, err, `setting the "stateSizeLimit" provider option or the `+
+			`PULUMI_TERRAFORM_MODULE_STATE_SIZE_LIMIT environment variable`)
+		assert.Empty(t, logger.messages)
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		logger := &recordingLogger{}
+		err := checkStateSize(ctx, logger, outputs, stateSizeLimits{warning: 8 << 20})
+		require.NoError(t, err)
+		assert.Empty(t, logger.messages)
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// taintedResourcesPolicy selects how child resources left tainted by a failed apply are handled. Terraform taints
+// resources whose creation failed after the provider recorded their ID, such as when a provisioner fails, since they
+// may not be fully set up.
+type taintedResourcesPolicy string
+
+const (
+	// taintedResourcesReplace keeps the resources tainted so that the next update replaces them. This is the default
+	// and matches Terraform.
+	taintedResourcesReplace taintedResourcesPolicy = "replace"
+	// taintedResourcesUntaint clears the tainted flag, as in `terraform untaint`, so that the resources are kept.
+	taintedResourcesUntaint taintedResourcesPolicy = "untaint"
+)
+
+func parseTaintedResourcesPolicy(s string) (taintedResourcesPolicy, error) {
+	switch p := taintedResourcesPolicy(s); p {
+	case "":
+		return taintedResourcesReplace, nil
+	case taintedResourcesReplace, taintedResourcesUntaint:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q or %q, got %q", taintedResourcesVariableName,
+			taintedResourcesReplace, taintedResourcesUntaint, s)
+	}
+}
+
+// handleTaintedResources reports the child resources left tainted by an apply and applies the policy to them,
+// returning the state of the module instance afterwards.
+//
+// The apply that taints resources fails, so the module resource is recorded as partially initialized and the next
+// `pulumi up` updates it. Tainted resources are then replaced by the plan of that update unless they were untainted.
+//
+// Untainting is best effort: when it fails, a warning is logged and the state of the apply is returned as is, since
+// the resources it created must still be recorded.
+func handleTaintedResources(
+	ctx context.Context,
+	tf *tfsandbox.ModuleRuntime,
+	logger tfsandbox.Logger,
+	policy taintedResourcesPolicy,
+	state *tfsandbox.State,
+) *tfsandbox.State {
+	var tainted []tfsandbox.ResourceAddress
+	state.VisitResourceStates(func(rs *tfsandbox.ResourceState) {
+		if rs.Tainted() {
+			tainted = append(tainted, rs.Address())
+		}
+	})
+	if len(tainted) == 0 {
+		return state
+	}
+
+	if policy != taintedResourcesUntaint {
+		for _, addr := range tainted {
+			logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("%s is tainted after a failed apply and will be replaced "+
+				"by the next update", addr))
+		}
+		return state
+	}
+
+	for _, addr := range tainted {
+		if err := tf.Untaint(ctx, addr); err != nil {
+			logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Failed to untaint %s, it will be replaced by the next "+
+				"update: %v", addr, err))
+			continue
+		}
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("%s was tainted by a failed apply and has been untainted, so "+
+			"it is kept as is", addr))
+	}
+	untainted, err := tf.Show(ctx, logger)
+	if err != nil {
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Failed to read the state after untainting resources, keeping "+
+			"the state of the apply: %v", err))
+		return state
+	}
+	return untainted
+}
@@ -0,0 +1,26 @@
+#!/bin/sh
+# Stub executor for a module creating an S3 bucket. Plans create it, nothing is ever applied.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    ;;
+  plan)
+    for arg; do
+      case "$arg" in -out=*) touch "${arg#-out=}" ;; esac
+    done
+    ;;
+  show)
+    case "$*" in
+      *-json*plan.out*)
+        cat <<'PLAN'
+{"format_version":"1.2","terraform_version":"1.9.0","planned_values":{"root_module":{"child_modules":[{"address":"module.m","resources":[{"address":"module.m.aws_s3_bucket.logs","mode":"managed","type":"aws_s3_bucket","name":"logs","provider_name":"registry.terraform.io/hashicorp/aws","values":{"bucket":"logs","force_destroy":false,"tags_all":{"cost_center":"ops"}}}]}]}},"resource_changes":[{"address":"module.m.aws_s3_bucket.logs","module_address":"module.m","mode":"managed","type":"aws_s3_bucket","name":"logs","provider_name":"registry.terraform.io/hashicorp/aws","change":{"actions":["create"],"before":null,"after":{"bucket":"logs","force_destroy":false,"tags_all":{"cost_center":"ops"}}}}]}
+PLAN
+        ;;
+      *plan.out*) echo "Plan: 1 to add, 0 to change, 0 to destroy." ;;
+      *) echo '{"format_version":"1.0"}' ;;
+    esac
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
@@ -0,0 +1,7 @@
+#!/bin/sh
+# Stub cost estimation hook: checks that a JSON plan arrives on stdin and prints a fixed estimate.
+plan=$(cat)
+case "$plan" in
+  *'"format_version":"1.2"'*) echo "Monthly cost: \$42.00" ;;
+  *) echo "expected a JSON plan on stdin" >&2; exit 1 ;;
+esac
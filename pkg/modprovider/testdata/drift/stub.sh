@@ -0,0 +1,39 @@
+#!/bin/sh
+# Stub executor for a module whose resource was deleted outside of Pulumi: refresh-only plans report the drift, other
+# plans change nothing. Applying records the command in mutations.log in the working directory.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    ;;
+  plan)
+    rm -f refresh_only
+    for arg; do
+      case "$arg" in
+        -out=*) touch "${arg#-out=}" ;;
+        -refresh-only) touch refresh_only ;;
+      esac
+    done
+    ;;
+  show)
+    case "$*" in
+      *-json*plan.out*)
+        if [ -f refresh_only ]; then
+          cat <<'PLAN'
+{"format_version":"1.2","terraform_version":"1.9.0","planned_values":{"root_module":{}},"resource_drift":[{"address":"module.m.terraform_data.example","module_address":"module.m","mode":"managed","type":"terraform_data","name":"example","change":{"actions":["delete"],"before":{},"after":null}}]}
+PLAN
+        else
+          echo '{"format_version":"1.2","terraform_version":"1.9.0","planned_values":{"root_module":{}}}'
+        fi
+        ;;
+      *plan.out*) echo "No changes." ;;
+      *) echo '{"format_version":"1.0"}' ;;
+    esac
+    ;;
+  apply|destroy)
+    echo "$1" >> mutations.log
+    exit 1
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
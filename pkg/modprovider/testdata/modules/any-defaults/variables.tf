@@ -0,0 +1,25 @@
+variable "logging" {
+  type = any
+  default = {
+    enabled   = true
+    retention = 30
+    targets = [
+      { bucket = "logs", prefix = "app/" },
+    ]
+  }
+}
+
+variable "rules" {
+  type    = any
+  default = []
+}
+
+variable "settings" {
+  type    = any
+  default = null
+}
+
+variable "mixed" {
+  type    = any
+  default = ["a", 1]
+}
@@ -0,0 +1,27 @@
+variable "name" {
+  type = string
+}
+
+variable "settings" {
+  type = any
+}
+
+variable "overrides" {
+  type = map(any)
+}
+
+output "name" {
+  value = var.name
+}
+
+output "decoded" {
+  value = jsondecode(file("${path.module}/settings.json"))
+}
+
+output "settings" {
+  value = var.settings
+}
+
+output "overrides" {
+  value = var.overrides
+}
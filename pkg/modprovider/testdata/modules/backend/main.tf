@@ -0,0 +1,15 @@
+terraform {
+  backend "s3" {
+    bucket = "terraform-state"
+    key    = "network/terraform.tfstate"
+    region = "us-east-1"
+  }
+}
+
+variable "name" {
+  type = string
+}
+
+output "name" {
+  value = var.name
+}
@@ -0,0 +1,41 @@
+variable "subnets" {
+  type = list(string)
+}
+
+variable "environment" {
+  type = string
+}
+
+variable "create" {
+  type    = bool
+  default = true
+}
+
+resource "terraform_data" "subnet" {
+  count = length(var.subnets)
+  input = var.subnets[count.index]
+}
+
+output "enabled" {
+  value = length(terraform_data.subnet) > 0
+}
+
+output "is_production" {
+  value = var.environment == "prod"
+}
+
+output "single_subnet" {
+  value = length(var.subnets) <= 1
+}
+
+output "managed" {
+  value = var.create && length(var.subnets) > 0
+}
+
+output "disabled" {
+  value = !var.create
+}
+
+output "subnet_count" {
+  value = length(var.subnets) + 1
+}
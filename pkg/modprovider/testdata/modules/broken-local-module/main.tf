@@ -0,0 +1,5 @@
+resource "random_pet" "name" {}
+
+module "broken" {
+  source = "./broken"
+}
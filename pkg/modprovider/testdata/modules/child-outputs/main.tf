@@ -0,0 +1,26 @@
+variable "name" {
+  type = string
+}
+
+variable "replicas" {
+  type    = map(string)
+  default = {}
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+resource "aws_s3_bucket" "logs" {
+  count  = 2
+  bucket = "${var.name}-logs-${count.index}"
+}
+
+resource "aws_s3_bucket" "replica" {
+  for_each = var.replicas
+  bucket   = each.value
+}
+
+output "bucket_id" {
+  value = aws_s3_bucket.this.id
+}
@@ -0,0 +1,17 @@
+terraform {
+  cloud {
+    organization = "example"
+
+    workspaces {
+      name = "network"
+    }
+  }
+}
+
+variable "name" {
+  type = string
+}
+
+output "name" {
+  value = var.name
+}
@@ -0,0 +1,15 @@
+variable "foo_bar" {
+  type = string
+}
+
+variable "foo-bar" {
+  type = number
+}
+
+output "foo_bar" {
+  value = var.foo_bar
+}
+
+output "foo-bar" {
+  value = var.foo-bar
+}
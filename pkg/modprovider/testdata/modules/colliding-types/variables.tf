@@ -0,0 +1,18 @@
+variable "ingress_rules" {
+  type = list(object({
+    from_port = number
+    to_port   = number
+  }))
+  description = "Ingress rules by port"
+  default     = []
+}
+
+variable "ingress" {
+  type = object({
+    rules = list(object({
+      cidr_block = string
+    }))
+  })
+  description = "Ingress settings"
+  default     = null
+}
@@ -0,0 +1,47 @@
+variable "names" {
+  type    = list(string)
+  default = ["a", "b"]
+}
+
+variable "listener" {
+  type    = tuple([string, number])
+  default = ["http", 80]
+}
+
+variable "zones" {
+  type    = tuple([string, string])
+  default = ["us-east-1a", "us-east-1b"]
+}
+
+resource "terraform_data" "item" {
+  for_each = toset(var.names)
+  input    = each.key
+}
+
+output "by_name" {
+  value = { for name, item in terraform_data.item : name => item.output }
+}
+
+output "items" {
+  value = values(terraform_data.item)[*]
+}
+
+output "item_ids" {
+  value = values(terraform_data.item)[*].id
+}
+
+output "named_items" {
+  value = [for name, item in terraform_data.item : { name = name, id = item.id }]
+}
+
+output "item_names" {
+  value = [for name, item in terraform_data.item : name]
+}
+
+output "listener" {
+  value = var.listener
+}
+
+output "zones" {
+  value = var.zones
+}
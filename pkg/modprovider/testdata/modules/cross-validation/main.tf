@@ -0,0 +1,30 @@
+variable "create_vpc" {
+  type    = bool
+  default = false
+}
+
+variable "cidr" {
+  type    = string
+  default = null
+
+  validation {
+    condition     = !var.create_vpc || var.cidr != null
+    error_message = "cidr is required when create_vpc is true."
+  }
+
+  validation {
+    condition     = var.cidr == null || can(cidrhost(var.cidr, 0))
+    error_message = "cidr must be a valid CIDR block, got ${var.cidr}."
+  }
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+  default   = "changeme"
+
+  validation {
+    condition     = length(var.password) >= 8
+    error_message = "password ${var.password} is too short."
+  }
+}
@@ -0,0 +1,51 @@
+variable "name" {
+  type    = string
+  default = null
+}
+
+variable "fallback_name" {
+  type = string
+}
+
+variable "port" {
+  type    = number
+  default = null
+}
+
+variable "tags" {
+  type    = map(string)
+  default = null
+}
+
+variable "settings" {
+  type    = any
+  default = null
+}
+
+output "name" {
+  value = coalesce(var.name, var.fallback_name)
+}
+
+output "display_name" {
+  value = coalesce(var.name, "${var.fallback_name}-default")
+}
+
+output "port" {
+  value = try(var.port, 8080)
+}
+
+output "enabled" {
+  value = try(var.settings.enabled, var.settings.on, false)
+}
+
+output "tags" {
+  value = try(var.tags, null)
+}
+
+output "mixed" {
+  value = coalesce(var.port, var.name)
+}
+
+output "setting" {
+  value = coalesce(var.settings, var.name)
+}
@@ -0,0 +1,36 @@
+variable "name" {
+  type = string
+}
+
+variable "password" {
+  type      = string
+  sensitive = true
+}
+
+variable "tags" {
+  type    = map(list(string))
+  default = {}
+}
+
+resource "aws_s3_bucket" "this" {
+  bucket = var.name
+}
+
+output "name" {
+  description = "The name of the bucket."
+  value       = var.name
+}
+
+output "bucket_arns" {
+  description = "The ARNs of the buckets."
+  value       = [aws_s3_bucket.this.arn]
+}
+
+output "password" {
+  value     = var.password
+  sensitive = true
+}
+
+output "tags" {
+  value = var.tags
+}
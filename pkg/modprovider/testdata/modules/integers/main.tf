@@ -0,0 +1,49 @@
+variable "http_port" {
+  type = number
+}
+
+variable "replicas" {
+  type = number
+  validation {
+    condition     = floor(var.replicas) == var.replicas
+    error_message = "replicas must be a whole number"
+  }
+}
+
+variable "cpu_ratio" {
+  type = number
+}
+
+variable "timeout_seconds" {
+  type    = number
+  default = 30
+}
+
+variable "retry_backoff_seconds" {
+  type    = number
+  default = 0.5
+}
+
+variable "volume_size" {
+  type = number
+  validation {
+    condition     = var.volume_size >= 0.5
+    error_message = "volume_size must be at least half a GiB"
+  }
+}
+
+variable "subnets" {
+  type = list(string)
+}
+
+output "http_port" {
+  value = var.http_port
+}
+
+output "cpu_ratio" {
+  value = var.cpu_ratio
+}
+
+output "subnet_count" {
+  value = length(var.subnets)
+}
@@ -0,0 +1,15 @@
+variable "policy" {
+  type = map(string)
+}
+
+variable "settings_json" {
+  type = string
+}
+
+output "policy_json" {
+  value = jsonencode(var.policy)
+}
+
+output "settings" {
+  value = jsondecode(var.settings_json)
+}
@@ -0,0 +1,22 @@
+variable "listener" {
+  type = object({
+    listener = string
+    port     = number
+  })
+}
+
+output "module" {
+  value = "the module"
+}
+
+output "get" {
+  value = "a getter"
+}
+
+output "class" {
+  value = "a keyword"
+}
+
+output "listener" {
+  value = var.listener
+}
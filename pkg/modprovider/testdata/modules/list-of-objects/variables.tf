@@ -0,0 +1,13 @@
+variable "ingress_rules" {
+  type = list(object({
+    from_port   = number
+    to_port     = number
+    protocol    = optional(string, "tcp")
+    cidr_blocks = list(string)
+    source = optional(object({
+      security_group_id = string
+    }))
+  }))
+  description = "Ingress rules of the security group"
+  default     = []
+}
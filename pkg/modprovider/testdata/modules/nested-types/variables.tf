@@ -0,0 +1,32 @@
+variable "cluster" {
+  type = object({
+    name = string
+    node_groups = list(object({
+      instance_type = string
+      launch_template = object({
+        image_id = string
+        block_device_mappings = list(object({
+          device_name = string
+          ebs = object({
+            volume_size = number
+            kms_key = object({
+              id = string
+            })
+          })
+        }))
+      })
+    }))
+  })
+  description = "Cluster settings"
+}
+
+variable "database" {
+  type = object({
+    engine = string
+    kms_key = object({
+      arn = string
+    })
+  })
+  description = "Database settings"
+  default     = null
+}
@@ -0,0 +1,61 @@
+variable "name" {
+  type = string
+}
+
+variable "port" {
+  type    = number
+  default = 80
+}
+
+variable "names" {
+  type    = list(string)
+  default = ["a", "b"]
+}
+
+variable "prefix" {
+  type    = string
+  default = "app"
+}
+
+resource "terraform_data" "item" {
+  for_each = toset(var.names)
+  input    = each.key
+}
+
+output "endpoint" {
+  value = {
+    name = var.name
+    port = var.port
+    url  = "http://${var.name}:${var.port}"
+    tls = {
+      enabled = false
+    }
+    id = terraform_data.item["a"].id
+  }
+}
+
+output "ids_by_name" {
+  value = { for name, item in terraform_data.item : name => item.id }
+}
+
+output "urls_by_name" {
+  value = { for name in var.names : name => "http://${name}" }
+}
+
+output "names_by_prefix" {
+  value = { for name in var.names : substr(name, 0, 1) => name... }
+}
+
+output "prefixed" {
+  value = {
+    (var.prefix) = var.name
+  }
+}
+
+output "endpoints_by_name" {
+  value = { for name in var.names : name => { name = name, url = "http://${name}" } }
+}
+
+output "listeners" {
+  value = [{ protocol = "http", port = 80 }, { protocol = "https", port = 443 }]
+}
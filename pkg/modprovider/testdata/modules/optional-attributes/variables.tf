@@ -0,0 +1,11 @@
+variable "health_check" {
+  type = object({
+    enabled = bool
+    rules = optional(list(object({
+      port     = number
+      protocol = optional(string, "HTTP")
+    })))
+    tags = optional(map(string), {})
+  })
+  description = "Health check of the load balancer"
+}
@@ -0,0 +1,9 @@
+variable "vpc_id" {
+  type        = string
+  description = "The ID of the VPC"
+}
+
+output "vpc_id" {
+  value       = var.vpc_id
+  description = "The ID of the VPC"
+}
@@ -0,0 +1,25 @@
+resource "aws_s3_bucket" "this" {
+  bucket_prefix = "logs"
+}
+
+resource "aws_s3_bucket_versioning" "this" {
+  bucket = aws_s3_bucket.this.id
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+data "aws_iam_policy_document" "deny_insecure_transport" {
+}
+
+module "policy" {
+  source = "./modules/policy"
+  bucket = aws_s3_bucket.this.id
+}
+
+module "notifications" {
+  source  = "terraform-aws-modules/s3-bucket/aws//modules/notification"
+  version = "4.5.0"
+  bucket  = aws_s3_bucket.this.id
+}
@@ -0,0 +1,16 @@
+variable "bucket" {
+  type = string
+}
+
+resource "aws_s3_bucket_policy" "this" {
+  bucket = var.bucket
+  policy = "{}"
+}
+
+resource "aws_s3_bucket_versioning" "this" {
+  bucket = var.bucket
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
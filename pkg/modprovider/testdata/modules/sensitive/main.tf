@@ -0,0 +1,19 @@
+variable "password" {
+  type = string
+}
+
+variable "port" {
+  type = number
+}
+
+output "connection_string" {
+  value = sensitive("postgres://admin:${var.password}@db:${var.port}")
+}
+
+output "port" {
+  value = sensitive(var.port)
+}
+
+output "endpoint" {
+  value = "db:${var.port}"
+}
@@ -0,0 +1,14 @@
+variable "ingress_rules" {
+  type = set(object({
+    from_port   = number
+    to_port     = number
+    cidr_blocks = list(string)
+  }))
+  description = "Ingress rules of the security group"
+  default     = []
+}
+
+variable "subnet_ids" {
+  type    = list(string)
+  default = []
+}
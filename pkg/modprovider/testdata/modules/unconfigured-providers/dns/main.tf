@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    cloudflare = {
+      source = "cloudflare/cloudflare"
+    }
+  }
+}
+
+variable "name" {
+  type = string
+}
+
+resource "cloudflare_record" "cname" {
+  zone_id = "example"
+  name    = "assets"
+  type    = "CNAME"
+  content = var.name
+}
@@ -0,0 +1,18 @@
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+resource "random_pet" "name" {}
+
+resource "aws_s3_bucket" "bucket" {
+  bucket = random_pet.name.id
+}
+
+module "dns" {
+  source = "./dns"
+  name   = aws_s3_bucket.bucket.bucket_domain_name
+}
@@ -0,0 +1,34 @@
+#!/bin/sh
+# Stub executor for a module whose resource is in the state. Every command is recorded in commands.log in the working
+# directory. Plans change nothing but the greeting output, from hello in the state to bonjour, unless
+# OUTPUT_REFRESH_STUB_ACTION sets the action of the resource. OUTPUT_REFRESH_STUB_UNKNOWN leaves the greeting unknown.
+# Plans read the ubuntu data source again, as ami-new where the state holds ami-old.
+echo "$*" >> commands.log
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init|refresh)
+    ;;
+  plan)
+    for arg; do
+      case "$arg" in -out=*) touch "${arg#-out=}" ;; esac
+    done
+    ;;
+  show)
+    case "$*" in
+      *-json*plan.out*)
+        cat <<PLAN
+{"format_version":"1.2","terraform_version":"1.9.0","planned_values":{"root_module":{"child_modules":[{"address":"module.m","resources":[{"address":"module.m.terraform_data.example","mode":"managed","type":"terraform_data","name":"example","values":{"input":"hello"}},{"address":"module.m.data.aws_ami.ubuntu","mode":"data","type":"aws_ami","name":"ubuntu","provider_name":"registry.terraform.io/hashicorp/aws","values":{"id":"ami-new"}}]}]}},"resource_changes":[{"address":"module.m.terraform_data.example","module_address":"module.m","mode":"managed","type":"terraform_data","name":"example","change":{"actions":["${OUTPUT_REFRESH_STUB_ACTION:-no-op}"],"before":{"input":"hello"},"after":{"input":"hello"}}}],"output_changes":{"greeting":{"actions":["update"],"before":"hello","after":"bonjour","after_unknown":${OUTPUT_REFRESH_STUB_UNKNOWN:-false},"before_sensitive":false,"after_sensitive":false},"internal_output_is_secret_greeting":{"actions":["no-op"],"before":false,"after":false,"after_unknown":false,"before_sensitive":false,"after_sensitive":false}}}
+PLAN
+        ;;
+      *plan.out*) echo "No changes." ;;
+      *)
+        cat <<'STATE'
+{"format_version":"1.0","terraform_version":"1.9.0","values":{"outputs":{"greeting":{"value":"hello","type":"string","sensitive":false},"internal_output_is_secret_greeting":{"value":false,"type":"bool","sensitive":false}},"root_module":{"child_modules":[{"address":"module.m","resources":[{"address":"module.m.terraform_data.example","mode":"managed","type":"terraform_data","name":"example","provider_name":"terraform.io/builtin/terraform","values":{"input":"hello"}},{"address":"module.m.data.aws_ami.ubuntu","mode":"data","type":"aws_ami","name":"ubuntu","provider_name":"registry.terraform.io/hashicorp/aws","values":{"id":"ami-old"}}]}]}}}
+STATE
+        ;;
+    esac
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
@@ -0,0 +1,17 @@
+#!/bin/sh
+# Stub executor whose init locks STUB_LOCKED_AWS_VERSION of the AWS provider, adding a checksum for the local platform.
+# Init fails checksum verification instead when STUB_CHECKSUM_MISMATCH is set.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    if [ -n "$STUB_CHECKSUM_MISMATCH" ]; then
+      echo '{"@level":"error","@message":"Error: Failed to install provider","type":"diagnostic","diagnostic":{"severity":"error","summary":"Failed to install provider","detail":"Error while installing hashicorp/aws v5.0.0: the current package for registry.opentofu.org/hashicorp/aws 5.0.0 doesn'"'"'t match any of the checksums previously recorded in the dependency lock file"}}'
+      exit 1
+    fi
+    printf 'provider "registry.opentofu.org/hashicorp/aws" {\n  version = "%s"\n  hashes = [\n    "h1:local",\n    "zh:recorded",\n  ]\n}\n' \
+      "$STUB_LOCKED_AWS_VERSION" > .terraform.lock.hcl
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
@@ -0,0 +1,10 @@
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 99.0"
+    }
+  }
+}
+
+resource "aws_s3_bucket" "this" {}
@@ -0,0 +1,22 @@
+#!/bin/sh
+# Stub executor whose init installs the module next to this script, which requires an unreleased AWS provider version.
+# Init fails to install the provider unless STUB_LOCKED_AWS_VERSION is set, in which case that version is locked.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    mkdir -p .terraform/modules
+    dir="$(cd "$(dirname "$0")" && pwd)/module"
+    echo "{\"Modules\":[{\"Key\":\"\",\"Source\":\"\",\"Dir\":\".\"},{\"Key\":\"mymod\",\"Source\":\"$dir\",\"Dir\":\"$dir\"}]}" \
+      > .terraform/modules/modules.json
+    if [ -n "$STUB_LOCKED_AWS_VERSION" ]; then
+      printf 'provider "registry.opentofu.org/hashicorp/aws" {\n  version = "%s"\n}\n' "$STUB_LOCKED_AWS_VERSION" \
+        > .terraform.lock.hcl
+      exit 0
+    fi
+    echo '{"@level":"error","@message":"Error: Failed to query available provider packages","type":"diagnostic","diagnostic":{"severity":"error","summary":"Failed to query available provider packages","detail":"Could not retrieve the list of available versions for provider hashicorp/aws: no available releases match the given constraints >= 99.0"}}'
+    exit 1
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
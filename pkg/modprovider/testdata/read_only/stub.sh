@@ -0,0 +1,31 @@
+#!/bin/sh
+# Stub executor planning the creation of a single resource. Applying or destroying records the command in
+# mutations.log in the working directory and fails.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    ;;
+  plan)
+    for arg; do
+      case "$arg" in -out=*) touch "${arg#-out=}" ;; esac
+    done
+    ;;
+  show)
+    case "$*" in
+      *-json*plan.out*)
+        cat <<'PLAN'
+{"format_version":"1.2","terraform_version":"1.9.0","planned_values":{"root_module":{"child_modules":[{"address":"module.m","resources":[{"address":"module.m.terraform_data.example","mode":"managed","type":"terraform_data","name":"example","values":{}}]}]}},"resource_changes":[{"address":"module.m.terraform_data.example","module_address":"module.m","mode":"managed","type":"terraform_data","name":"example","change":{"actions":["create"],"before":null,"after":{}}}]}
+PLAN
+        ;;
+      *plan.out*) echo "Plan: 1 to add, 0 to change, 0 to destroy." ;;
+      *) echo '{"format_version":"1.0"}' ;;
+    esac
+    ;;
+  apply|destroy)
+    echo "$1" >> mutations.log
+    exit 1
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
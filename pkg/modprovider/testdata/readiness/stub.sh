@@ -0,0 +1,10 @@
+#!/bin/sh
+# Stub readiness condition: records the module outputs it receives on stdin in $1 and reports the module as ready
+# from the third attempt on, counting attempts in $1.attempts.
+cat > "$1"
+attempts=$(($(cat "$1.attempts" 2>/dev/null || echo 0) + 1))
+echo "$attempts" > "$1.attempts"
+if [ "$attempts" -lt 3 ]; then
+  echo "database is still starting" >&2
+  exit 1
+fi
@@ -0,0 +1,11 @@
+#!/bin/sh
+# Stub executor whose init never completes, standing in for a module download from a registry that hangs.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    exec sleep 600
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
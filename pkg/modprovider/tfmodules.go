@@ -20,10 +20,15 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hcl/v2"
@@ -35,9 +40,9 @@ import (
 	"github.com/pulumi/opentofu/configs"
 	"github.com/pulumi/opentofu/registry"
 	"github.com/pulumi/opentofu/registry/regsrc"
+	"github.com/pulumi/opentofu/registry/response"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
-	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 
 	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
 )
@@ -107,6 +112,47 @@ type InferredModuleSchema struct {
 	NonNilOutputs       []resource.PropertyKey                        `json:"nonNilOutputs"`
 	ProvidersConfig     schema.ConfigSpec                             `json:"providersConfig"`
 	SchemaFieldMappings *SchemaFieldMappings                          `json:"schemaFieldMappings,omitempty"`
+
+	// childResourceOutputs are the outputs exposing attributes of child resources requested with the childOutputs
+	// module configuration. They are derived from the module at every inference, so they are not serialized.
+	childResourceOutputs map[resource.PropertyKey]childResourceOutput
+
+	// setInputTypes are the Terraform types of the inputs that hold sets, possibly nested, which the Pulumi schema
+	// represents as arrays. Like childResourceOutputs, they are derived from the module and not serialized.
+	setInputTypes map[resource.PropertyKey]cty.Type
+
+	// nullDefaultInputs are the inputs whose variables default to null. Passing them null is the same as leaving them
+	// unset, so null values are not passed to the module (see [omitNullInputs]). They are derived from the module and
+	// not serialized.
+	nullDefaultInputs []resource.PropertyKey
+
+	// inputValidations are the validation rules of the module variables, evaluated by Check. Like setInputTypes, they
+	// are derived from the module and not serialized.
+	inputValidations *inputValidations
+
+	// requiredProviders are the Pulumi names of the providers the module requires, see [moduleRequiredProviders].
+	// They are derived from the module and not serialized.
+	requiredProviders []string
+
+	// nullableOutputs are the outputs that the nonNilOutputOverrides module configuration declares nullable, which
+	// schema overrides do not make non-nil again (see [combineInferredModuleSchema]).
+	nullableOutputs []resource.PropertyKey
+
+	// omitEmptyOutputs is set by the omitEmptyOutputs module configuration.
+	omitEmptyOutputs bool
+
+	// omitNullInputs is set by the omitNullInputs module configuration.
+	omitNullInputs bool
+
+	// viewGroups is set by the viewGroups module configuration.
+	viewGroups viewGroups
+
+	// operationSummary is set by the operationSummary module configuration.
+	operationSummary bool
+
+	// description is the description of the package, taken from the README of the module when requested with the
+	// readmeSummary module configuration.
+	description string
 }
 
 const (
@@ -118,6 +164,7 @@ var stringType = schema.TypeSpec{Type: stringTypeName}
 var anyType = schema.TypeSpec{Ref: "pulumi.json#/Any"}
 var boolType = schema.TypeSpec{Type: "boolean"}
 var numberType = schema.TypeSpec{Type: "number"}
+var integerType = schema.TypeSpec{Type: "integer"}
 
 func refType(ref string) schema.TypeSpec {
 	return schema.TypeSpec{
@@ -160,6 +207,20 @@ func convertType(
 	typeName string,
 	packageName packageName,
 	supportingTypes map[string]*schema.ComplexTypeSpec,
+) schema.TypeSpec {
+	return convertTypeIn(terraformType, typeName, "", packageName, supportingTypes)
+}
+
+// convertTypeIn converts the type of an element of a container, where container names the role of the element, such
+// as "item" for the elements of lists and sets or "value" for the values of maps. The container is only used to name
+// the supporting type of an object element when the name it would take otherwise is already taken by a different
+// type, see [supportingTypeToken].
+func convertTypeIn(
+	terraformType cty.Type,
+	typeName string,
+	container string,
+	packageName packageName,
+	supportingTypes map[string]*schema.ComplexTypeSpec,
 ) schema.TypeSpec {
 	if terraformType.Equals(cty.String) {
 		return stringType
@@ -174,32 +235,51 @@ func convertType(
 	}
 
 	if terraformType.IsListType() || terraformType.IsSetType() {
-		elementType := convertType(terraformType.ElementType(), typeName, packageName, supportingTypes)
+		// object elements, as in list(object({...})), become a supporting type named after the collection
+		elementType := convertTypeIn(terraformType.ElementType(), typeName, "item", packageName, supportingTypes)
 		return arrayType(elementType)
 	}
 
 	if terraformType.IsMapType() {
-		elementType := convertType(terraformType.ElementType(), typeName, packageName, supportingTypes)
+		elementType := convertTypeIn(terraformType.ElementType(), typeName, "value", packageName, supportingTypes)
 		return mapType(elementType)
 	}
 
+	if terraformType.IsTupleType() {
+		// tuples are arrays, typed by their elements when these all have the same type
+		elementTypes := terraformType.TupleElementTypes()
+		if len(elementTypes) > 0 && !slices.ContainsFunc(elementTypes, func(t cty.Type) bool {
+			return !t.Equals(elementTypes[0])
+		}) {
+			return arrayType(convertTypeIn(elementTypes[0], typeName, "item", packageName, supportingTypes))
+		}
+		return arrayType(anyType)
+	}
+
 	if terraformType.IsObjectType() {
 		propertiesMap := map[string]schema.PropertySpec{}
+		var required []string
 		for propertyName, propertyType := range terraformType.AttributeTypes() {
 			nestedTypeName := fmt.Sprintf("%s_%s", typeName, propertyName)
 			propertiesMap[propertyName] = schema.PropertySpec{
 				TypeSpec: convertType(propertyType, nestedTypeName, packageName, supportingTypes),
 			}
+			// attributes declared with optional(<type>) or optional(<type>, <default>) can be omitted
+			if !terraformType.AttributeOptional(propertyName) {
+				required = append(required, propertyName)
+			}
 		}
+		slices.Sort(required)
 
 		complexType := &schema.ComplexTypeSpec{
 			ObjectTypeSpec: schema.ObjectTypeSpec{
 				Type:       objectTypeName,
 				Properties: propertiesMap,
+				Required:   required,
 			},
 		}
 
-		objectTypeToken := fmt.Sprintf("%s:index:%s", packageName, formatPascalCaseTypeName(typeName))
+		objectTypeToken := supportingTypeToken(typeName, container, packageName, complexType, supportingTypes)
 		ref := fmt.Sprintf("#/types/%s", objectTypeToken)
 		supportingTypes[objectTypeToken] = complexType
 		return refType(ref)
@@ -215,15 +295,107 @@ func convertType(
 	return stringType
 }
 
-func inferExpressionType(expr hcl.Expression) schema.TypeSpec {
+// supportingTypeToken names the supporting type of an object type after typeName. Different paths can lead to the
+// same name, as with an ingress_rules input and the rules attribute of an ingress input, so a name already taken by a
+// structurally different type is qualified with the container of the object, then numbered until it is free. Names
+// taken by the same type are shared.
+func supportingTypeToken(
+	typeName string,
+	container string,
+	packageName packageName,
+	complexType *schema.ComplexTypeSpec,
+	supportingTypes map[string]*schema.ComplexTypeSpec,
+) string {
+	free := func(name string) (string, bool) {
+		token := fmt.Sprintf("%s:index:%s", packageName, formatPascalCaseTypeName(name))
+		existing, taken := supportingTypes[token]
+		return token, !taken || reflect.DeepEqual(existing, complexType)
+	}
+
+	if token, ok := free(typeName); ok {
+		return token
+	}
+	if container != "" {
+		typeName = fmt.Sprintf("%s_%s", typeName, container)
+		if token, ok := free(typeName); ok {
+			return token
+		}
+	}
+	for i := 2; ; i++ {
+		if token, ok := free(fmt.Sprintf("%s%d", typeName, i)); ok {
+			return token
+		}
+	}
+}
+
+// shapeOfDefault derives the type of a variable declared with type = any from the type of its default value. The
+// attributes of objects in the default are all optional, since the default only hints at the expected shape, and
+// tuples of elements of the same type become lists. Empty collections and null values do not tell anything about the
+// shape, so they are typed by the dynamic pseudo-type which stands for Any.
+func shapeOfDefault(t cty.Type) cty.Type {
+	switch {
+	case t == cty.NilType:
+		return cty.DynamicPseudoType
+	case t.IsObjectType():
+		if len(t.AttributeTypes()) == 0 {
+			return cty.DynamicPseudoType
+		}
+		attributes := map[string]cty.Type{}
+		for name, attributeType := range t.AttributeTypes() {
+			attributes[name] = shapeOfDefault(attributeType)
+		}
+		return cty.ObjectWithOptionalAttrs(attributes, slices.Collect(maps.Keys(attributes)))
+	case t.IsTupleType():
+		elementTypes := t.TupleElementTypes()
+		if len(elementTypes) == 0 {
+			return cty.DynamicPseudoType
+		}
+		shapes := make([]cty.Type, len(elementTypes))
+		for i, elementType := range elementTypes {
+			shapes[i] = shapeOfDefault(elementType)
+		}
+		if slices.ContainsFunc(shapes, func(s cty.Type) bool { return !s.Equals(shapes[0]) }) {
+			return cty.Tuple(shapes)
+		}
+		return cty.List(shapes[0])
+	case t.IsListType():
+		return cty.List(shapeOfDefault(t.ElementType()))
+	case t.IsSetType():
+		return cty.Set(shapeOfDefault(t.ElementType()))
+	case t.IsMapType():
+		return cty.Map(shapeOfDefault(t.ElementType()))
+	}
+	return t
+}
+
+// inputTypes looks up the type of a module input by the name of its Terraform variable, returning Any for variables
+// that are not known.
+type inputTypes func(tfVariableName string) schema.TypeSpec
+
+func inferExpressionType(expr hcl.Expression, inputType inputTypes) schema.TypeSpec {
 	if functionCall, ok := expr.(*hclsyntax.FunctionCallExpr); ok {
 		switch functionCall.Name {
 		case "compact":
 			// compact function has return type string[]
 			return arrayType(stringType)
+		case "jsonencode":
+			// jsonencode serializes its argument into a JSON string
+			return stringType
+		case "jsondecode":
+			// the shape of a decoded JSON value is only known at runtime, modules typically decode objects
+			return mapType(anyType)
+		case "coalesce":
+			// coalesce(<expr1>, <expr2>, ...) evaluates to its first non-null argument
+			if argsType, ok := commonArgumentType(functionCall.Args, inputType); ok {
+				return argsType
+			}
 		case "try":
 			// expressions of format: try(<expr1>, <expr2>, ..., <default>)
-			// we check the last argument to see if it is a string literal or null
+			// evaluate to the first argument that does not fail, when all of them have the same type so does try
+			if argsType, ok := commonArgumentType(functionCall.Args, inputType); ok {
+				return argsType
+			}
+			// otherwise we check the last argument to see if it is a string literal or null
 			// if it is, we return a string type
 			if len(functionCall.Args) > 0 {
 				lastArg := functionCall.Args[len(functionCall.Args)-1]
@@ -241,10 +413,31 @@ func inferExpressionType(expr hcl.Expression) schema.TypeSpec {
 		}
 	}
 
-	if _, ok := expr.(*hclsyntax.SplatExpr); ok {
+	if binaryOp, ok := expr.(*hclsyntax.BinaryOpExpr); ok {
+		switch binaryOp.Op {
+		case hclsyntax.OpEqual, hclsyntax.OpNotEqual,
+			hclsyntax.OpGreaterThan, hclsyntax.OpGreaterThanOrEqual,
+			hclsyntax.OpLessThan, hclsyntax.OpLessThanOrEqual,
+			hclsyntax.OpLogicalAnd, hclsyntax.OpLogicalOr:
+			// comparisons and logical operators evaluate to booleans
+			// for example length(aws_subnet.this) > 0
+			return boolType
+		}
+	}
+
+	if unaryOp, ok := expr.(*hclsyntax.UnaryOpExpr); ok && unaryOp.Op == hclsyntax.OpLogicalNot {
+		// !var.enabled is a boolean
+		return boolType
+	}
+
+	if splat, ok := expr.(*hclsyntax.SplatExpr); ok {
 		// splat expressions resolve to arrays
 		// for example aws_subnet.public[*].id
 		// is a computation: [ for subnet in aws_subnet.public: subnet.id ]
+		if _, wholeElements := splat.Each.(*hclsyntax.AnonSymbolExpr); wholeElements {
+			// aws_subnet.public[*] evaluates to the whole resource objects
+			return arrayType(anyType)
+		}
 		return arrayType(stringType)
 	}
 
@@ -253,15 +446,47 @@ func inferExpressionType(expr hcl.Expression) schema.TypeSpec {
 		// <condition> ? <true-result> : <false-result>
 		// we infer the type of the expression to be the type of the true-result
 		// assumes that the true-result and false-result have the same type
-		return inferExpressionType(conditional.TrueResult)
+		return inferExpressionType(conditional.TrueResult, inputType)
 	}
 
-	if _, ok := expr.(*hclsyntax.ForExpr); ok {
+	if forExpr, ok := expr.(*hclsyntax.ForExpr); ok {
+		// {for k, v in ...: k => v} evaluates to an object keyed by the results of the key expression, the keys are
+		// only known at runtime so it is a map
+		if forExpr.KeyExpr != nil {
+			elementType := anyType
+			if valueType, ok := commonArgumentType([]hclsyntax.Expression{forExpr.ValExpr}, inputType); ok {
+				elementType = valueType
+			}
+			if forExpr.Group {
+				// {for k, v in ...: k => v...} groups the values by key
+				return mapType(arrayType(elementType))
+			}
+			return mapType(elementType)
+		}
+		switch forExpr.ValExpr.(type) {
+		case *hclsyntax.ObjectConsExpr, *hclsyntax.TupleConsExpr:
+			// [for s in ...: {id = s.id}] evaluates to an array of objects or arrays
+			return arrayType(anyType)
+		}
 		// for expressions do not _necessarily_ return an array of strings
 		// but choosing this as a default for now until we have a proper type checker
 		return arrayType(stringType)
 	}
 
+	if objectCons, ok := expr.(*hclsyntax.ObjectConsExpr); ok && len(objectCons.Items) > 0 {
+		if _, fixedKeys := objectLiteralKeys(objectCons); !fixedKeys {
+			// {(var.prefix) = ...} has keys computed at runtime, so it is a map
+			values := make([]hclsyntax.Expression, 0, len(objectCons.Items))
+			for _, item := range objectCons.Items {
+				values = append(values, item.ValueExpr)
+			}
+			if valueType, ok := commonArgumentType(values, inputType); ok {
+				return mapType(valueType)
+			}
+			return mapType(anyType)
+		}
+	}
+
 	// default output type is any
 	// language SDKs are very strict when it comes to type schematized
 	// they have to match with the actual type at runtime from terraform
@@ -269,6 +494,252 @@ func inferExpressionType(expr hcl.Expression) schema.TypeSpec {
 	return anyType
 }
 
+// objectLiteralKeys returns the keys of an object constructor when they are all fixed names that are valid property
+// names, as in {id = aws_vpc.this.id, "cidr" = var.cidr}. Keys computed at runtime, such as (var.prefix) or
+// "${var.prefix}-id", make ok false.
+func objectLiteralKeys(expr *hclsyntax.ObjectConsExpr) (_ []string, ok bool) {
+	keys := make([]string, 0, len(expr.Items))
+	for _, item := range expr.Items {
+		// without an evaluation context only literal keys evaluate without errors
+		key, diags := item.KeyExpr.Value(nil)
+		if diags.HasErrors() || !key.IsKnown() || key.IsNull() || !key.Type().Equals(cty.String) {
+			return nil, false
+		}
+		if !hclsyntax.ValidIdentifier(key.AsString()) || containsDash(key.AsString()) {
+			return nil, false
+		}
+		keys = append(keys, key.AsString())
+	}
+	return keys, true
+}
+
+// objectLiteralType derives the Terraform type of an output built by an object constructor with fixed keys, as in:
+//
+//	value = { id = aws_vpc.this.id, cidr = var.cidr }
+//
+// Attributes are typed by their values when these are literals, strings, module inputs or nested objects with fixed
+// keys, and by the dynamic pseudo-type which stands for Any otherwise. Values may be null, so all the attributes are
+// optional. Objects without fixed keys are maps rather than objects and ok is false for them.
+func objectLiteralType(expr hcl.Expression, variables map[string]*configs.Variable) (_ cty.Type, ok bool) {
+	objectCons, isObjectCons := expr.(*hclsyntax.ObjectConsExpr)
+	if !isObjectCons || len(objectCons.Items) == 0 {
+		return cty.NilType, false
+	}
+	keys, ok := objectLiteralKeys(objectCons)
+	if !ok {
+		return cty.NilType, false
+	}
+
+	attributeTypes := map[string]cty.Type{}
+	for i, item := range objectCons.Items {
+		attributeTypes[keys[i]] = objectLiteralValueType(item.ValueExpr, variables)
+	}
+	return cty.ObjectWithOptionalAttrs(attributeTypes, keys), true
+}
+
+// objectOutputType types outputs built by object constructors with fixed keys as objects, and outputs holding
+// collections of such objects as collections of objects.
+func objectOutputType(expr hcl.Expression, variables map[string]*configs.Variable) (cty.Type, bool) {
+	if objectType, ok := objectLiteralType(expr, variables); ok {
+		return objectType, true
+	}
+	return objectCollectionType(expr, variables)
+}
+
+func objectLiteralValueType(expr hclsyntax.Expression, variables map[string]*configs.Variable) cty.Type {
+	if referencedVariableName, ok := isVariableReference(expr); ok {
+		if variable, ok := variables[referencedVariableName]; ok {
+			return variable.ConstraintType
+		}
+		return cty.DynamicPseudoType
+	}
+	switch expr := expr.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		if !expr.Val.IsNull() {
+			return expr.Val.Type()
+		}
+	case *hclsyntax.TemplateExpr:
+		return cty.String
+	case *hclsyntax.ObjectConsExpr:
+		if objectType, ok := objectLiteralType(expr, variables); ok {
+			return objectType
+		}
+	case *hclsyntax.ForExpr, *hclsyntax.TupleConsExpr:
+		if collectionType, ok := objectCollectionType(expr, variables); ok {
+			return collectionType
+		}
+	}
+	return cty.DynamicPseudoType
+}
+
+// objectCollectionType derives the Terraform type of an output holding objects built by object constructors with
+// fixed keys (see [objectLiteralType]), so that SDKs give access to their attributes:
+//
+//	value = [for s in aws_subnet.this : { id = s.id, cidr = s.cidr_block }]    # a list of objects
+//	value = { for k, s in aws_subnet.this : k => { id = s.id } }               # a map of objects
+//	value = [{ name = "a", port = 80 }, { name = "b", port = 8080 }]            # a list of objects
+//
+// Tuples are only typed as lists when all their elements have the same type.
+func objectCollectionType(expr hcl.Expression, variables map[string]*configs.Variable) (_ cty.Type, ok bool) {
+	switch expr := expr.(type) {
+	case *hclsyntax.ForExpr:
+		objectType, ok := objectLiteralType(expr.ValExpr, variables)
+		switch {
+		case !ok:
+			return cty.NilType, false
+		case expr.KeyExpr == nil:
+			return cty.List(objectType), true
+		case expr.Group:
+			return cty.Map(cty.List(objectType)), true
+		default:
+			return cty.Map(objectType), true
+		}
+	case *hclsyntax.TupleConsExpr:
+		if len(expr.Exprs) == 0 {
+			return cty.NilType, false
+		}
+		var elementType cty.Type
+		for i, element := range expr.Exprs {
+			objectType, ok := objectLiteralType(element, variables)
+			if !ok || (i > 0 && !objectType.Equals(elementType)) {
+				return cty.NilType, false
+			}
+			elementType = objectType
+		}
+		return cty.List(elementType), true
+	}
+	return cty.NilType, false
+}
+
+// argumentType infers the type of an argument of a function call: the type of the module input it references, of the
+// literal it holds or else of the expression. Null literals have no type of their own, for them ok is false.
+func argumentType(arg hclsyntax.Expression, inputType inputTypes) (_ schema.TypeSpec, ok bool) {
+	if referencedVariableName, ok := isVariableReference(arg); ok {
+		return inputType(referencedVariableName), true
+	}
+	switch arg := arg.(type) {
+	case *hclsyntax.LiteralValueExpr:
+		switch ty := arg.Val.Type(); {
+		case ty.Equals(cty.String):
+			return stringType, true
+		case ty.Equals(cty.Number):
+			return numberType, true
+		case ty.Equals(cty.Bool):
+			return boolType, true
+		case arg.Val.IsNull():
+			return schema.TypeSpec{}, false
+		}
+	case *hclsyntax.TemplateExpr:
+		// quoted strings, interpolated or not
+		return stringType, true
+	}
+	return inferExpressionType(arg, inputType), true
+}
+
+// commonArgumentType returns the type shared by the arguments of a function call, ignoring null literals. It reports
+// false when the arguments have different types or when their type is Any.
+func commonArgumentType(args []hclsyntax.Expression, inputType inputTypes) (schema.TypeSpec, bool) {
+	var common *schema.TypeSpec
+	for _, arg := range args {
+		argType, ok := argumentType(arg, inputType)
+		switch {
+		case !ok:
+			continue
+		case common == nil:
+			common = &argType
+		case !reflect.DeepEqual(*common, argType):
+			return schema.TypeSpec{}, false
+		}
+	}
+	if common == nil || reflect.DeepEqual(*common, anyType) {
+		return schema.TypeSpec{}, false
+	}
+	return *common, true
+}
+
+// integerNameSuffixes are the last words of snake_case names of number variables that conventionally hold integers.
+var integerNameSuffixes = []string{"count", "port", "size", "index", "days", "seconds"}
+
+// isIntegerLikeVariable guesses whether a Terraform number variable only accepts integers, either from its name (e.g.
+// http_port or instance_count) or from a validation rule using floor(var.<name>), as in:
+//
+//	condition = floor(var.replicas) == var.replicas
+//
+// Variables whose default or validation rules hold fractional numbers stay numbers whatever their name, as a
+// backoff_seconds variable defaulting to 0.5.
+func isIntegerLikeVariable(name string, variable *configs.Variable) bool {
+	if !variable.Type.Equals(cty.Number) || hasFractionalNumbers(variable) {
+		return false
+	}
+
+	words := strings.Split(name, "_")
+	if slices.Contains(integerNameSuffixes, words[len(words)-1]) {
+		return true
+	}
+
+	for _, validation := range variable.Validations {
+		body, ok := validation.Condition.(hclsyntax.Node)
+		if !ok {
+			continue
+		}
+		usesFloor := false
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if call, ok := node.(*hclsyntax.FunctionCallExpr); ok && call.Name == "floor" && len(call.Args) == 1 {
+				if referenced, ok := isVariableReference(call.Args[0]); ok && referenced == name {
+					usesFloor = true
+				}
+			}
+			return nil
+		})
+		if usesFloor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasFractionalNumbers reports whether the default or the validation rules of a number variable hold numbers that are
+// not integers.
+func hasFractionalNumbers(variable *configs.Variable) bool {
+	isFractional := func(v cty.Value) bool {
+		return v.Type() == cty.Number && v.IsKnown() && !v.IsNull() && !v.AsBigFloat().IsInt()
+	}
+	if variable.Default != cty.NilVal && isFractional(variable.Default) {
+		return true
+	}
+	fractional := false
+	for _, validation := range variable.Validations {
+		body, ok := validation.Condition.(hclsyntax.Node)
+		if !ok {
+			continue
+		}
+		hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+			if literal, ok := node.(*hclsyntax.LiteralValueExpr); ok && isFractional(literal.Val) {
+				fractional = true
+			}
+			return nil
+		})
+	}
+	return fractional
+}
+
+// isLengthCall checks if the given expression is a call to length, which always evaluates to an integer.
+func isLengthCall(expr hcl.Expression) bool {
+	functionCall, ok := expr.(*hclsyntax.FunctionCallExpr)
+	return ok && functionCall.Name == "length"
+}
+
+// unwrapSensitiveCall returns the argument of an expression of the form sensitive(<expr>) and whether the expression
+// was such a call. Other expressions are returned as is.
+func unwrapSensitiveCall(expr hcl.Expression) (hcl.Expression, bool) {
+	functionCall, ok := expr.(*hclsyntax.FunctionCallExpr)
+	if !ok || functionCall.Name != "sensitive" || len(functionCall.Args) != 1 {
+		return expr, false
+	}
+	return functionCall.Args[0], true
+}
+
 // isVariableReference checks if the given expression is a reference to a variable
 // the expression looks like this: var.<variable-name>
 // so we check if the expression is a scope traversal with two parts
@@ -299,7 +770,50 @@ func isValidVersion(inputVersion string) bool {
 	return err == nil
 }
 
+const (
+	defaultRegistryRetries = 3
+	initialRegistryBackoff = 2 * time.Second
+)
+
+// registryRetryPolicy retries failed requests listing the versions of a module, waiting for a backoff that doubles
+// with every retry. Modules that do not exist are not retried.
+type registryRetryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+func newRegistryRetryPolicy() (registryRetryPolicy, error) {
+	policy := registryRetryPolicy{maxRetries: defaultRegistryRetries, backoff: initialRegistryBackoff}
+	if v := os.Getenv(registryRetriesEnvironmentVariable); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 0 {
+			return registryRetryPolicy{}, fmt.Errorf("%s must be a non-negative number of retries, got %q",
+				registryRetriesEnvironmentVariable, v)
+		}
+		policy.maxRetries = retries
+	}
+	return policy, nil
+}
+
 func latestModuleVersion(ctx context.Context, moduleSource string) (*version.Version, error) {
+	tokens, err := environmentRegistryTokens()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := newRegistryRetryPolicy()
+	if err != nil {
+		return nil, err
+	}
+	return latestModuleVersionWith(ctx, disco.NewWithCredentialsSource(cloudRegistryCredentials(tokens)), moduleSource,
+		policy)
+}
+
+func latestModuleVersionWith(
+	ctx context.Context,
+	services *disco.Disco,
+	moduleSource string,
+	policy registryRetryPolicy,
+) (*version.Version, error) {
 	var source addrs.ModuleSourceRegistry
 	parsedSource, err := addrs.ParseModuleSource(moduleSource)
 	if err != nil {
@@ -317,13 +831,27 @@ func latestModuleVersion(ctx context.Context, moduleSource string) (*version.Ver
 		return nil, fmt.Errorf("module source for %s is not from a remote registry", moduleSource)
 	}
 
-	services := disco.NewWithCredentialsSource(cloudRegistryCredentials())
 	reg := registry.NewClient(services, nil)
 	regsrcAddr := regsrc.ModuleFromRegistryPackageAddr(source.Package)
-	resp, err := reg.ModuleVersions(ctx, regsrcAddr)
+	attempts := 0
+	resp, err := retryWithBackoff(ctx, policy.maxRetries, policy.backoff,
+		func() (*response.ModuleVersions, error) {
+			attempts++
+			return reg.ModuleVersions(ctx, regsrcAddr)
+		},
+		func(err error, _ int, _ time.Duration) bool {
+			return !registry.IsModuleNotFound(err)
+		})
 	if err != nil {
+		if attempts > 1 {
+			return nil, fmt.Errorf("failed to retrieve available versions for %s after %d attempts: %s", source,
+				attempts, err)
+		}
 		return nil, fmt.Errorf("failed to retrieve available versions for %s: %s", source, err)
 	}
+	if len(resp.Modules) == 0 {
+		return nil, fmt.Errorf("failed to find latest version for module %s", source)
+	}
 	modMeta := resp.Modules[0]
 	var latestVersion *version.Version
 	for _, mv := range modMeta.Versions {
@@ -353,7 +881,7 @@ func InferModuleSchema(
 	mod TFModuleSource,
 	ver TFModuleVersion,
 ) (*InferredModuleSchema, error) {
-	return inferModuleSchema(ctx, tf, packageName, mod, ver, newComponentLogger(nil, nil))
+	return inferModuleSchema(ctx, tf, packageName, mod, ver, nil, newComponentLogger(nil, nil))
 }
 
 func containsDash(s string) bool {
@@ -366,6 +894,7 @@ func inferModuleSchema(
 	packageName packageName,
 	mod TFModuleSource,
 	tfModuleVersion TFModuleVersion,
+	config *ModuleConfig, // optional
 	logger tfsandbox.Logger,
 ) (*InferredModuleSchema, error) {
 
@@ -374,6 +903,19 @@ func inferModuleSchema(
 		return nil, err
 	}
 
+	if err := checkBackendBlocks(ctx, logger, mod, module, config.backendBlocks()); err != nil {
+		return nil, err
+	}
+
+	return inferModuleSchemaFromContent(packageName, module, config)
+}
+
+// inferModuleSchemaFromContent computes the schema of a module that has already been loaded from disk.
+func inferModuleSchemaFromContent(
+	packageName packageName,
+	module *configs.Module,
+	config *ModuleConfig, // optional
+) (*InferredModuleSchema, error) {
 	inferredModuleSchema := &InferredModuleSchema{
 		Inputs:          make(map[resource.PropertyKey]*schema.PropertySpec),
 		Outputs:         make(map[resource.PropertyKey]*schema.PropertySpec),
@@ -389,6 +931,13 @@ func inferModuleSchema(
 		},
 	}
 
+	if err := checkNameCollisionsMode(config.nameCollisions()); err != nil {
+		return nil, err
+	}
+	if err := checkTypeNaming(config.typeNaming(), config.maxTypeNameLength()); err != nil {
+		return nil, err
+	}
+
 	providerFieldMappings := inferredModuleSchema.SchemaFieldMappings.ProviderFieldMappings
 	inputFieldMappings := inferredModuleSchema.SchemaFieldMappings.InputFieldMappings
 	outputFieldMappings := inferredModuleSchema.SchemaFieldMappings.OutputFieldMappings
@@ -409,18 +958,79 @@ func inferModuleSchema(
 		}
 	}
 
-	for variableName, variable := range module.Variables {
-		if containsDash(variableName) {
+	// Pulumi keys of the module inputs indexed by the Terraform variable name.
+	inputKeys := map[string]resource.PropertyKey{}
+	inputType := func(tfVariableName string) schema.TypeSpec {
+		if input, ok := inferredModuleSchema.Inputs[inputKeys[tfVariableName]]; ok {
+			return input.TypeSpec
+		}
+		return anyType
+	}
+
+	// Terraform names of the module inputs and outputs indexed by their Pulumi key, to report name collisions.
+	inputTFNames := map[resource.PropertyKey]string{}
+	outputTFNames := map[resource.PropertyKey]string{}
+	// Pulumi keys taken by renamed inputs and outputs
+	renamedInputs := map[resource.PropertyKey]bool{}
+	renamedOutputs := map[resource.PropertyKey]bool{}
+	// colliding renames are always an error since they are explicit, whichever of the properties is visited first
+	collisionsMode := func(renamed bool, key resource.PropertyKey, renamedKeys map[resource.PropertyKey]bool) string {
+		if renamed || renamedKeys[key] {
+			return nameCollisionsError
+		}
+		return config.nameCollisions()
+	}
+
+	// variables are visited in a stable order, so that colliding supporting types are named the same way every time
+	for _, tfVariableName := range slices.Sorted(maps.Keys(module.Variables)) {
+		variable := module.Variables[tfVariableName]
+		variableName := tfVariableName
+		renamed, isRenamed := config.inputRename(tfVariableName)
+		if isRenamed {
+			// the user asked for a specific Pulumi name for this input
+			variableName = renamed
+		} else if containsDash(variableName) {
 			// fields with dashes are not valid in Pulumi
 			// so we replace dashes with underscores
-			pulumiName := strings.ReplaceAll(variableName, "-", "_")
-			inputFieldMappings[resource.PropertyKey(pulumiName)] = resource.PropertyKey(variableName)
-			variableName = pulumiName
+			variableName = strings.ReplaceAll(variableName, "-", "_")
 		}
 
-		variableType := convertType(variable.Type, variableName, packageName, inferredModuleSchema.SupportingTypes)
+		normalized := tfsandbox.PulumiTopLevelKey(variableName)
+		key, err := resolveNameCollision("input", tfVariableName, normalized, inferredModuleSchema.Inputs,
+			inputTFNames, collisionsMode(isRenamed, normalized, renamedInputs))
+		if err != nil {
+			return nil, err
+		}
+		if key != normalized {
+			variableName = string(key)
+		}
+		switch {
+		case isRenamed:
+			renamedInputs[key] = true
+			inputFieldMappings[tfsandbox.PulumiTopLevelKey(renamed)] = resource.PropertyKey(tfVariableName)
+		case variableName != tfVariableName:
+			inputFieldMappings[resource.PropertyKey(variableName)] = resource.PropertyKey(tfVariableName)
+		}
+		inputTFNames[key] = tfVariableName
 
-		key := tfsandbox.PulumiTopLevelKey(variableName)
+		// the constraint type keeps track of optional object attributes, unlike variable.Type
+		variableType := convertType(variable.ConstraintType, variableName, packageName,
+			inferredModuleSchema.SupportingTypes)
+		if config.inferIntegers() && isIntegerLikeVariable(tfVariableName, variable) {
+			variableType = integerType
+		}
+		if config.inferFromDefaults() && variable.ConstraintType.Equals(cty.DynamicPseudoType) {
+			variableType = convertType(shapeOfDefault(variable.Default.Type()), variableName, packageName,
+				inferredModuleSchema.SupportingTypes)
+		}
+
+		inputKeys[tfVariableName] = key
+		if containsSetType(variable.ConstraintType) {
+			if inferredModuleSchema.setInputTypes == nil {
+				inferredModuleSchema.setInputTypes = map[resource.PropertyKey]cty.Type{}
+			}
+			inferredModuleSchema.setInputTypes[key] = variable.ConstraintType
+		}
 		inferredModuleSchema.Inputs[key] = &schema.PropertySpec{
 			Description: variable.Description,
 			Secret:      variable.Sensitive,
@@ -429,40 +1039,119 @@ func inferModuleSchema(
 
 		nullable := variable.NullableSet && variable.Nullable
 		hasDefault := variable.Default.Type() != cty.NilType
+		if hasDefault && variable.Default.IsNull() {
+			inferredModuleSchema.nullDefaultInputs = append(inferredModuleSchema.nullDefaultInputs, key)
+		}
 		optional := hasDefault || nullable
 		if !optional {
 			inferredModuleSchema.RequiredInputs = append(inferredModuleSchema.RequiredInputs, key)
 		}
 	}
 
-	for outputName, output := range module.Outputs {
-		if containsDash(outputName) {
+	inferredModuleSchema.inputValidations = newInputValidations(module, inputKeys)
+
+	// outputs are visited in a stable order, so that colliding names are suffixed the same way every time
+	for _, tfOutputName := range slices.Sorted(maps.Keys(module.Outputs)) {
+		output := module.Outputs[tfOutputName]
+		outputName := tfOutputName
+		renamed, isRenamed := config.outputRename(tfOutputName)
+		if isRenamed {
+			// the user asked for a specific Pulumi name for this output
+			outputName = renamed
+		} else if containsDash(outputName) {
 			// fields with dashes are not valid in Pulumi
 			// so we replace dashes with underscores
-			pulumiName := strings.ReplaceAll(outputName, "-", "_")
-			outputFieldMappings[resource.PropertyKey(pulumiName)] = resource.PropertyKey(outputName)
-			outputName = pulumiName
+			outputName = strings.ReplaceAll(outputName, "-", "_")
 		}
 
+		normalized := tfsandbox.PulumiTopLevelKey(outputName)
+		k, err := resolveNameCollision("output", tfOutputName, normalized, inferredModuleSchema.Outputs,
+			outputTFNames, collisionsMode(isRenamed, normalized, renamedOutputs))
+		if err != nil {
+			return nil, err
+		}
+		if k != normalized {
+			outputName = string(k)
+		}
+		switch {
+		case isRenamed:
+			renamedOutputs[k] = true
+			outputFieldMappings[tfsandbox.PulumiTopLevelKey(renamed)] = resource.PropertyKey(tfOutputName)
+		case outputName != tfOutputName:
+			outputFieldMappings[resource.PropertyKey(outputName)] = resource.PropertyKey(tfOutputName)
+		}
+		outputTFNames[k] = tfOutputName
+
 		// TODO[pulumi/pulumi-terraform-module#70] reconsider output type inference vs config
+		// outputs wrapped in sensitive(...) are secret whether or not they are flagged as sensitive, the type is
+		// inferred from the wrapped expression
+		expr, wrappedInSensitive := unwrapSensitiveCall(output.Expr)
+
 		var inferredType schema.TypeSpec
-		if referencedVariableName, ok := isVariableReference(output.Expr); ok {
-			k := tfsandbox.PulumiTopLevelKey(referencedVariableName)
-			tfName := string(k)
-			pulumiInputName := resource.PropertyKey(strings.ReplaceAll(tfName, "-", "_"))
-			inferredType = inferredModuleSchema.Inputs[pulumiInputName].TypeSpec
+		if referencedVariableName, ok := isVariableReference(expr); ok {
+			inferredType = inputType(referencedVariableName)
+		} else if config.inferIntegers() && isLengthCall(expr) {
+			inferredType = integerType
+		} else if objectType, ok := objectOutputType(expr, module.Variables); ok {
+			// outputs built by object constructors with fixed keys, or holding such objects, get a supporting type
+			// named after the output, unless an input of the same name already has one
+			typeName := outputName
+			token := fmt.Sprintf("%s:index:%s", packageName, formatPascalCaseTypeName(typeName))
+			if _, taken := inferredModuleSchema.SupportingTypes[token]; taken {
+				typeName = outputName + "_output"
+			}
+			inferredType = convertType(objectType, typeName, packageName, inferredModuleSchema.SupportingTypes)
 		} else {
-			inferredType = inferExpressionType(output.Expr)
+			inferredType = inferExpressionType(expr, inputType)
 		}
 
-		k := tfsandbox.PulumiTopLevelKey(outputName)
+		secret := output.Sensitive || wrappedInSensitive
+		description := output.Description
+		if config.describeOutputs() {
+			description = describeOutput(description, inferredType, secret, expr)
+		}
 		inferredModuleSchema.Outputs[k] = &schema.PropertySpec{
-			Description: output.Description,
-			Secret:      output.Sensitive,
+			Description: description,
+			Secret:      secret,
 			TypeSpec:    inferredType,
 		}
 	}
 
+	applyNonNilOutputOverrides(inferredModuleSchema, config.nonNilOutputOverrides())
+
+	if err := inferChildResourceOutputs(inferredModuleSchema, module, config.childOutputs()); err != nil {
+		return nil, err
+	}
+	// Only checked by the opt-in unconfiguredProviders provider option, so local modules that fail to load are not
+	// an error here: their providers are left out, and Terraform reports the modules when running them.
+	inferredModuleSchema.requiredProviders, _ = moduleRequiredProviders(module)
+	nameSupportingTypes(inferredModuleSchema, packageName, config.typeNaming(), config.maxTypeNameLength())
+	inferredModuleSchema.omitEmptyOutputs = config.omitEmptyOutputs()
+	inferredModuleSchema.omitNullInputs = config.omitNullInputs()
+	inferredModuleSchema.viewGroups = config.viewGroups()
+	if config.operationSummary() {
+		if err := inferOperationSummaryOutput(inferredModuleSchema, packageName); err != nil {
+			return nil, err
+		}
+		inferredModuleSchema.operationSummary = true
+	}
+	if config.readmeSummary() {
+		summary, err := readmeSummary(module.SourceDir)
+		if err != nil {
+			return nil, err
+		}
+		inferredModuleSchema.description = summary
+	}
+	if config.resourceTypesSummary() {
+		resourceTypes, err := moduleResourceTypes(module)
+		if err != nil {
+			return nil, err
+		}
+		// appended to the README summary as a separate paragraph
+		inferredModuleSchema.description = strings.TrimSpace(
+			inferredModuleSchema.description + "\n\n" + resourceTypesSummary(resourceTypes))
+	}
+
 	return inferredModuleSchema, nil
 }
 
@@ -502,6 +1191,26 @@ func hasBuiltinModuleSchemaOverrides(
 	return nil, false
 }
 
+// applyNonNilOutputOverrides marks outputs as non-nil or nullable as requested by the nonNilOutputOverrides module
+// configuration. Outputs declared nullable are recorded so that they take precedence over the schema overrides merged
+// later.
+func applyNonNilOutputOverrides(inferredSchema *InferredModuleSchema, overrides map[string]bool) {
+	for _, name := range slices.Sorted(maps.Keys(overrides)) {
+		key := resource.PropertyKey(name)
+		contains := slices.Contains(inferredSchema.NonNilOutputs, key)
+		switch {
+		case overrides[name] && !contains:
+			inferredSchema.NonNilOutputs = append(inferredSchema.NonNilOutputs, key)
+		case !overrides[name]:
+			inferredSchema.nullableOutputs = append(inferredSchema.nullableOutputs, key)
+			if contains {
+				inferredSchema.NonNilOutputs = slices.DeleteFunc(inferredSchema.NonNilOutputs,
+					func(k resource.PropertyKey) bool { return k == key })
+			}
+		}
+	}
+}
+
 // applyModuleSchemaOverrides takes an full inferred schema and adds information to it from
 // a partial schema. The partial schema is expected to be a subset of the full schema.
 func combineInferredModuleSchema(
@@ -512,8 +1221,12 @@ func combineInferredModuleSchema(
 		return inferredSchema
 	}
 
-	// add required outputs to the inferred schema if they are not already present
+	// add required outputs to the inferred schema if they are not already present, unless the module configuration
+	// declares them nullable
 	for _, requiredOutput := range partialInferredSchema.NonNilOutputs {
+		if slices.Contains(inferredSchema.nullableOutputs, requiredOutput) {
+			continue
+		}
 		alreadyExists := false
 		for _, existingRequiredOutput := range inferredSchema.NonNilOutputs {
 			if existingRequiredOutput == requiredOutput {
@@ -663,32 +1376,53 @@ func extractModuleContent(
 	return module, nil
 }
 
-type modulesJSON struct {
-	Modules []modulesJSONEntry `json:"Modules"`
-}
+// Values of the backendBlocks module configuration.
+const (
+	backendBlocksIgnore = "ignore"
+	backendBlocksError  = "error"
+)
 
-type modulesJSONEntry struct {
-	Key    string `json:"Key"`
-	Source string `json:"Source"`
-	Dir    string `json:"Dir"`
-}
+// checkBackendBlocks handles a backend or cloud block declared by the module. Terraform only honors these blocks in
+// the root module, which the provider generates to call the module, so they never take effect and the state of the
+// module stays managed by Pulumi. Such blocks are ignored with a warning unless mode is backendBlocksError. The check
+// is part of schema inference, which Parameterize runs for `pulumi package add` and for every program using the
+// package.
+func checkBackendBlocks(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	source TFModuleSource,
+	module *configs.Module,
+	mode string,
+) error {
+	if mode != backendBlocksIgnore && mode != backendBlocksError {
+		return fmt.Errorf("invalid backendBlocks module configuration %q, expected %q or %q",
+			mode, backendBlocksIgnore, backendBlocksError)
+	}
 
-func readModulesJSON(filePath string) (*modulesJSON, error) {
-	//nolint:gosec // G703: filePath is an internally constructed path to Terraform's modules.json, not user input
-	bytes, err := os.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read modules.json file: %w", err)
+	var block string
+	var declRange hcl.Range
+	switch {
+	case module.Backend != nil:
+		block, declRange = fmt.Sprintf("backend %q", module.Backend.Type), module.Backend.DeclRange
+	case module.CloudConfig != nil:
+		block, declRange = "cloud", module.CloudConfig.DeclRange
+	default:
+		return nil
 	}
-	var m modulesJSON
-	err = json.Unmarshal(bytes, &m)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal modules.json file: %w", err)
+	// the module files are all in the same directory
+	location := fmt.Sprintf("%s:%d", filepath.Base(declRange.Filename), declRange.Start.Line)
+
+	if mode == backendBlocksError {
+		return fmt.Errorf("module %s declares a %s block at %s, but the state of modules is managed by Pulumi. "+
+			"Remove the block, or set backendBlocks to %q in the module configuration to ignore it",
+			source, block, location, backendBlocksIgnore)
 	}
-	return &m, nil
+	logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Ignoring the %s block of module %s at %s: "+
+		"the state of modules is managed by Pulumi", block, source, location))
+	return nil
 }
 
-func findResolvedModuleDir(mj *modulesJSON, key string) (string, error) {
-	contract.Assertf(mj != nil, "mj cannot be nil")
+func findResolvedModuleDir(mj tfsandbox.ModuleManifest, key string) (string, error) {
 	matchCount := 0
 	var hit string
 	for _, mod := range mj.Modules {
@@ -719,20 +1453,28 @@ func resolveModuleSources(
 	inputs := resource.PropertyMap{}
 	outputs := []tfsandbox.TFOutputSpec{}
 	providerConfig := map[string]resource.PropertyMap{}
-	err := tfsandbox.CreateTFFile(key, source, version, tf.WorkingDir(), inputs, outputs, providerConfig)
+	// provider options are not available when adding a package, only their environment variables
+	fullGitClone, err := boolProviderOption(nil, gitFullCloneVariableName, gitFullCloneEnvironmentVariable)
+	if err != nil {
+		return "", err
+	}
+	err = tfsandbox.CreateTFFile(key, source, version, tf.WorkingDir(), inputs, outputs, providerConfig,
+		tfsandbox.CreateTFFileOpts{FullGitClone: fullGitClone})
 	if err != nil {
 		return "", fmt.Errorf("terraform file creation failed: %w", err)
 	}
 
 	// init will resolve module sources and create .terraform/modules folder
-	injectRegistryToken(ctx, logger)
+	tokens, err := environmentRegistryTokens()
+	if err != nil {
+		return "", err
+	}
+	injectRegistryToken(ctx, logger, tokens)
 	if err := tf.Init(ctx, logger); err != nil {
 		return "", fmt.Errorf("init failure (%s): %w", tf.Description(), err)
 	}
 
-	mjPath := filepath.Join(tf.WorkingDir(), ".terraform", "modules", "modules.json")
-
-	mj, err := readModulesJSON(mjPath)
+	mj, err := tfsandbox.ReadModuleManifest(tf.WorkingDir())
 	if err != nil {
 		return "", fmt.Errorf("failed to read modules resolution JSON: %w", err)
 	}
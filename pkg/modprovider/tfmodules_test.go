@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pulumi/opentofu/configs"
 	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 
@@ -335,6 +336,7 @@ func TestInferringInputsFromLocalPath(t *testing.T) {
 				packageName("schema-inference-example"),
 				TFModuleSource(p),
 				TFModuleVersion(""),
+				nil, /* config */
 				logger)
 			require.NoError(t, err)
 			require.NotNil(t, inferredSchema, "module schema should not be nil")
@@ -429,6 +431,7 @@ func TestInferringSchemaWithDashedFielsFromLocalPath(t *testing.T) {
 				packageName("dashed"),
 				TFModuleSource(p),
 				TFModuleVersion(""),
+				nil, /* config */
 				logger)
 			require.NoError(t, err)
 			require.NotNil(t, inferredSchema, "module schema should not be nil")
@@ -677,6 +680,24 @@ func TestResolveModuleSources(t *testing.T) {
 				assert.Contains(t, string(bytes), "index_document")
 			})
 
+			// git sources are shallow cloned unless the full history is requested.
+			t.Run("remote module source github clone depth", func(t *testing.T) {
+				ctx := context.Background()
+				moduleSource := TFModuleSource("github.com/yemisprojects/s3_website_module_demo?ref=v0.0.1")
+
+				shallowDir, err := resolveModuleSources(ctx, newTestRuntime(t, executor), moduleSource, "",
+					newTestLogger(t))
+				require.NoError(t, err)
+				assert.FileExists(t, filepath.Join(shallowDir, ".git", "shallow"))
+
+				t.Setenv(gitFullCloneEnvironmentVariable, "true")
+				fullDir, err := resolveModuleSources(ctx, newTestRuntime(t, executor), moduleSource, "",
+					newTestLogger(t))
+				require.NoError(t, err)
+				assert.DirExists(t, filepath.Join(fullDir, ".git"))
+				assert.NoFileExists(t, filepath.Join(fullDir, ".git", "shallow"))
+			})
+
 			t.Run("remote module source with version in source path", func(t *testing.T) {
 				ctx := context.Background()
 				moduleSource := TFModuleSource("github.com/yemisprojects/s3_website_module_demo?ref=v0.0.1")
@@ -705,3 +726,511 @@ func TestResolveModuleSources(t *testing.T) {
 		})
 	}
 }
+
+// This test will hit the network to download the s3-bucket module from the registry.
+func TestModuleResourceTypesOfS3BucketModule(t *testing.T) {
+	for _, executor := range getExecutorsFromEnv() {
+		t.Run("executor="+executor, func(t *testing.T) {
+			ctx := context.Background()
+			tf := newTestRuntime(t, executor)
+			module, err := extractModuleContent(ctx, tf, "terraform-aws-modules/s3-bucket/aws", "4.5.0",
+				newTestLogger(t))
+			require.NoError(t, err)
+
+			resourceTypes, err := moduleResourceTypes(module)
+			require.NoError(t, err)
+			assert.Contains(t, resourceTypes, "aws_s3_bucket")
+			assert.Contains(t, resourceTypes, "aws_s3_bucket_policy")
+			assert.Contains(t, resourceTypes, "aws_s3_bucket_public_access_block")
+			assert.Contains(t, resourceTypes, "aws_s3_bucket_versioning")
+		})
+	}
+}
+
+// loadTestModule parses a module from pkg/modprovider/testdata/modules without running terraform init.
+func loadTestModule(t *testing.T, name string) *configs.Module {
+	t.Helper()
+	parser := configs.NewParser(nil)
+	smc := configs.NewStaticModuleCall(nil, nil, "", "")
+	mod, diags := parser.LoadConfigDir(filepath.Join("testdata", "modules", name), smc)
+	require.False(t, diags.HasErrors(), "failed to load module %s: %v", name, diags)
+	return mod
+}
+
+func TestInferModuleSchemaWithRenames(t *testing.T) {
+	config := &ModuleConfig{
+		Renames: &ModuleRenames{
+			Inputs:  map[string]string{vpcIDKey: "id"},
+			Outputs: map[string]string{vpcIDKey: "id"},
+		},
+	}
+
+	inferredSchema, err := inferModuleSchemaFromContent("renamed", loadTestModule(t, "renamed"), config)
+	require.NoError(t, err)
+
+	// "id" is reserved for custom resources, so the renamed properties are disambiguated as "id_".
+	renamedKey := resource.PropertyKey("id_")
+
+	assert.Equal(t, map[resource.PropertyKey]*schema.PropertySpec{
+		renamedKey: {
+			Description: "The ID of the VPC",
+			TypeSpec:    stringType,
+		},
+	}, inferredSchema.Inputs)
+	assert.Equal(t, []resource.PropertyKey{renamedKey}, inferredSchema.RequiredInputs)
+
+	assert.Equal(t, map[resource.PropertyKey]*schema.PropertySpec{
+		renamedKey: {
+			Description: "The ID of the VPC",
+			TypeSpec:    stringType,
+		},
+	}, inferredSchema.Outputs)
+
+	// The Terraform names are preserved so that the module can be invoked as before.
+	assert.Equal(t, map[resource.PropertyKey]resource.PropertyKey{renamedKey: vpcIDKey},
+		inferredSchema.SchemaFieldMappings.InputFieldMappings)
+	assert.Equal(t, map[resource.PropertyKey]resource.PropertyKey{renamedKey: vpcIDKey},
+		inferredSchema.SchemaFieldMappings.OutputFieldMappings)
+}
+
+func TestInferModuleSchemaListOfObjects(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("sg", loadTestModule(t, "list-of-objects"), nil)
+	require.NoError(t, err)
+
+	// Elements of a list(object) input get a supporting type of their own rather than a loosely typed map.
+	assert.Equal(t, &schema.PropertySpec{
+		Description: "Ingress rules of the security group",
+		TypeSpec:    arrayType(refType("#/types/sg:index:IngressRules")),
+	}, inferredSchema.Inputs["ingress_rules"])
+
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"sg:index:IngressRules": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"from_port":   {TypeSpec: numberType},
+					"to_port":     {TypeSpec: numberType},
+					"protocol":    {TypeSpec: stringType},
+					"cidr_blocks": {TypeSpec: arrayType(stringType)},
+					"source":      {TypeSpec: refType("#/types/sg:index:IngressRulesSource")},
+				},
+				Required: []string{"cidr_blocks", "from_port", "to_port"},
+			},
+		},
+		"sg:index:IngressRulesSource": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"security_group_id": {TypeSpec: stringType},
+				},
+				Required: []string{"security_group_id"},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+}
+
+func TestInferModuleSchemaCollidingListOfObjects(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("sg", loadTestModule(t, "colliding-types"), nil)
+	require.NoError(t, err)
+
+	// The elements of ingress.rules and ingress_rules would both be named IngressRules; the one that comes second
+	// is qualified with its container instead of overwriting the other.
+	assert.Equal(t, arrayType(refType("#/types/sg:index:IngressRulesItem")),
+		inferredSchema.Inputs["ingress_rules"].TypeSpec)
+
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"sg:index:Ingress": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"rules": {TypeSpec: arrayType(refType("#/types/sg:index:IngressRules"))},
+				},
+				Required: []string{"rules"},
+			},
+		},
+		"sg:index:IngressRules": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"cidr_block": {TypeSpec: stringType},
+				},
+				Required: []string{"cidr_block"},
+			},
+		},
+		"sg:index:IngressRulesItem": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"from_port": {TypeSpec: numberType},
+					"to_port":   {TypeSpec: numberType},
+				},
+				Required: []string{"from_port", "to_port"},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+}
+
+func TestInferModuleSchemaSetOfObjects(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("sg", loadTestModule(t, "set-of-objects"), nil)
+	require.NoError(t, err)
+
+	// Sets are represented as arrays with typed elements, and remembered so that diffs ignore element order.
+	assert.Equal(t, &schema.PropertySpec{
+		Description: "Ingress rules of the security group",
+		TypeSpec:    arrayType(refType("#/types/sg:index:IngressRules")),
+	}, inferredSchema.Inputs["ingress_rules"])
+
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"sg:index:IngressRules": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"from_port":   {TypeSpec: numberType},
+					"to_port":     {TypeSpec: numberType},
+					"cidr_blocks": {TypeSpec: arrayType(stringType)},
+				},
+				Required: []string{"cidr_blocks", "from_port", "to_port"},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+
+	assert.Contains(t, inferredSchema.setInputTypes, resource.PropertyKey("ingress_rules"))
+	assert.NotContains(t, inferredSchema.setInputTypes, resource.PropertyKey("subnet_ids"))
+}
+
+func TestInferModuleSchemaFromDefaults(t *testing.T) {
+	module := loadTestModule(t, "any-defaults")
+
+	t.Run("disabled", func(t *testing.T) {
+		inferredSchema, err := inferModuleSchemaFromContent("mod", module, nil)
+		require.NoError(t, err)
+		for _, key := range []resource.PropertyKey{"logging", "rules", "settings", "mixed"} {
+			assert.Equal(t, anyType, inferredSchema.Inputs[key].TypeSpec, key)
+		}
+		assert.Empty(t, inferredSchema.SupportingTypes)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		inferredSchema, err := inferModuleSchemaFromContent("mod", module, &ModuleConfig{InferFromDefaults: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, refType("#/types/mod:index:Logging"), inferredSchema.Inputs["logging"].TypeSpec)
+		// Empty and null defaults do not tell the shape of the input, nor tuples of mixed types that of their elements.
+		assert.Equal(t, anyType, inferredSchema.Inputs["rules"].TypeSpec)
+		assert.Equal(t, anyType, inferredSchema.Inputs["settings"].TypeSpec)
+		assert.Equal(t, arrayType(anyType), inferredSchema.Inputs["mixed"].TypeSpec)
+
+		// Attributes of the default are not required, since values of any type may omit them.
+		assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+			"mod:index:Logging": {
+				ObjectTypeSpec: schema.ObjectTypeSpec{
+					Type: objectTypeName,
+					Properties: map[string]schema.PropertySpec{
+						"enabled":   {TypeSpec: boolType},
+						"retention": {TypeSpec: numberType},
+						"targets":   {TypeSpec: arrayType(refType("#/types/mod:index:LoggingTargets"))},
+					},
+				},
+			},
+			"mod:index:LoggingTargets": {
+				ObjectTypeSpec: schema.ObjectTypeSpec{
+					Type: objectTypeName,
+					Properties: map[string]schema.PropertySpec{
+						"bucket": {TypeSpec: stringType},
+						"prefix": {TypeSpec: stringType},
+					},
+				},
+			},
+		}, inferredSchema.SupportingTypes)
+		assert.Empty(t, inferredSchema.RequiredInputs)
+	})
+}
+
+func TestInferModuleSchemaIntegers(t *testing.T) {
+	module := loadTestModule(t, "integers")
+
+	types := func(props map[resource.PropertyKey]*schema.PropertySpec) map[resource.PropertyKey]schema.TypeSpec {
+		result := map[resource.PropertyKey]schema.TypeSpec{}
+		for k, p := range props {
+			result[k] = p.TypeSpec
+		}
+		return result
+	}
+
+	t.Run("numbers by default", func(t *testing.T) {
+		inferredSchema, err := inferModuleSchemaFromContent("integers", module, nil)
+		require.NoError(t, err)
+		assert.Equal(t, numberType, inferredSchema.Inputs["http_port"].TypeSpec)
+		assert.Equal(t, numberType, inferredSchema.Inputs["replicas"].TypeSpec)
+		assert.Equal(t, anyType, inferredSchema.Outputs["subnet_count"].TypeSpec)
+	})
+
+	t.Run("integer-like properties with inferIntegers", func(t *testing.T) {
+		inferredSchema, err := inferModuleSchemaFromContent("integers", module, &ModuleConfig{InferIntegers: true})
+		require.NoError(t, err)
+		assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+			"http_port":       integerType,
+			"replicas":        integerType,
+			"cpu_ratio":       numberType,
+			"timeout_seconds": integerType,
+			// fractional defaults and validation bounds keep integer-like names numbers
+			"retry_backoff_seconds": numberType,
+			"volume_size":           numberType,
+			"subnets":               arrayType(stringType),
+		}, types(inferredSchema.Inputs))
+		assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+			"http_port":    integerType,
+			"cpu_ratio":    numberType,
+			"subnet_count": integerType,
+		}, types(inferredSchema.Outputs))
+	})
+}
+
+func TestInferModuleSchemaJSONFunctions(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("json", loadTestModule(t, "json"), nil)
+	require.NoError(t, err)
+
+	// jsonencode(...) is always a string
+	assert.Equal(t, stringType, inferredSchema.Outputs["policy_json"].TypeSpec)
+	// jsondecode(...) has a dynamic shape
+	assert.Equal(t, mapType(anyType), inferredSchema.Outputs["settings"].TypeSpec)
+}
+
+func TestInferModuleSchemaBooleanExpressions(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("booleans", loadTestModule(t, "booleans"), nil)
+	require.NoError(t, err)
+
+	types := map[resource.PropertyKey]schema.TypeSpec{}
+	for k, p := range inferredSchema.Outputs {
+		types[k] = p.TypeSpec
+	}
+
+	assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+		// length(terraform_data.subnet) > 0
+		"enabled": boolType,
+		// var.environment == "prod"
+		"is_production": boolType,
+		// length(var.subnets) <= 1
+		"single_subnet": boolType,
+		// var.create && length(var.subnets) > 0
+		"managed": boolType,
+		// !var.create
+		"disabled": boolType,
+		// arithmetic is not a comparison
+		"subnet_count": anyType,
+	}, types)
+}
+
+func TestInferModuleSchemaSensitiveOutputs(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("sensitive", loadTestModule(t, "sensitive"), nil)
+	require.NoError(t, err)
+
+	// outputs wrapped in sensitive(...) are secret even though neither the output nor the variables are sensitive
+	assert.True(t, inferredSchema.Outputs["connection_string"].Secret)
+	assert.Equal(t, anyType, inferredSchema.Outputs["connection_string"].TypeSpec)
+	assert.True(t, inferredSchema.Outputs["port"].Secret)
+	assert.Equal(t, numberType, inferredSchema.Outputs["port"].TypeSpec, "typed after the wrapped expression")
+	assert.False(t, inferredSchema.Outputs["endpoint"].Secret)
+}
+
+func TestInferModuleSchemaObjectWithOptionalAttributes(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("lb", loadTestModule(t, "optional-attributes"), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, &schema.PropertySpec{
+		Description: "Health check of the load balancer",
+		TypeSpec:    refType("#/types/lb:index:HealthCheck"),
+	}, inferredSchema.Inputs["health_check"])
+	assert.Equal(t, []resource.PropertyKey{"health_check"}, inferredSchema.RequiredInputs)
+
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"lb:index:HealthCheck": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"enabled": {TypeSpec: boolType},
+					"rules":   {TypeSpec: arrayType(refType("#/types/lb:index:HealthCheckRules"))},
+					"tags":    {TypeSpec: mapType(stringType)},
+				},
+				// rules and tags are declared with optional(...)
+				Required: []string{"enabled"},
+			},
+		},
+		"lb:index:HealthCheckRules": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: objectTypeName,
+				Properties: map[string]schema.PropertySpec{
+					"port":     {TypeSpec: numberType},
+					"protocol": {TypeSpec: stringType},
+				},
+				Required: []string{"port"},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+}
+
+func TestInferModuleSchemaComplexOutputs(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("complex", loadTestModule(t, "complex-outputs"), nil)
+	require.NoError(t, err)
+
+	types := map[resource.PropertyKey]schema.TypeSpec{}
+	for k, p := range inferredSchema.Outputs {
+		types[k] = p.TypeSpec
+	}
+
+	// The declared types must match the values at runtime, otherwise SDKs fail to deserialize the outputs.
+	assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+		"by_name":     mapType(anyType),
+		"items":       arrayType(anyType),
+		"item_ids":    arrayType(stringType),
+		"named_items": arrayType(refType("#/types/complex:index:NamedItems")),
+		"item_names":  arrayType(stringType),
+		"listener":    arrayType(anyType),
+		"zones":       arrayType(stringType),
+	}, types)
+	assert.Equal(t, arrayType(anyType), inferredSchema.Inputs["listener"].TypeSpec)
+
+	// The objects built by the for expression are typed, with attributes of unknown types.
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"complex:index:NamedItems": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: "object",
+				Properties: map[string]schema.PropertySpec{
+					"name": {TypeSpec: anyType},
+					"id":   {TypeSpec: anyType},
+				},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+}
+
+func TestCheckBackendBlocks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("backend blocks are ignored by default", func(t *testing.T) {
+		logger := &recordingLogger{}
+		var config *ModuleConfig
+		err := checkBackendBlocks(ctx, logger, "./backend", loadTestModule(t, "backend"), config.backendBlocks())
+		require.NoError(t, err)
+		assert.Equal(t, []string{`warn: Ignoring the backend "s3" block of module ./backend at main.tf:2: ` +
+			`the state of modules is managed by Pulumi`}, logger.messages)
+	})
+
+	t.Run("backend blocks can be rejected", func(t *testing.T) {
+		config := &ModuleConfig{BackendBlocks: backendBlocksError}
+		err := checkBackendBlocks(ctx, &recordingLogger{}, "./backend", loadTestModule(t, "backend"),
+			config.backendBlocks())
+		assert.ErrorContains(t, err, `module ./backend declares a backend "s3" block at main.tf:2, `+
+			`but the state of modules is managed by Pulumi`)
+	})
+
+	t.Run("cloud blocks can be rejected", func(t *testing.T) {
+		err := checkBackendBlocks(ctx, &recordingLogger{}, "./cloud", loadTestModule(t, "cloud"), backendBlocksError)
+		assert.ErrorContains(t, err, "module ./cloud declares a cloud block at main.tf:2")
+	})
+
+	t.Run("modules without backend blocks", func(t *testing.T) {
+		logger := &recordingLogger{}
+		err := checkBackendBlocks(ctx, logger, "./simple", loadTestModule(t, "simple"), backendBlocksError)
+		require.NoError(t, err)
+		assert.Empty(t, logger.messages)
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		err := checkBackendBlocks(ctx, &recordingLogger{}, "./simple", loadTestModule(t, "simple"), "strip")
+		assert.ErrorContains(t, err, `invalid backendBlocks module configuration "strip"`)
+	})
+}
+
+func TestInferModuleSchemaDefaultingFunctions(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("defaulting", loadTestModule(t, "defaulting"), nil)
+	require.NoError(t, err)
+
+	types := map[resource.PropertyKey]schema.TypeSpec{}
+	for k, p := range inferredSchema.Outputs {
+		types[k] = p.TypeSpec
+	}
+
+	assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+		// coalesce(var.name, var.fallback_name) with string inputs
+		"name": stringType,
+		// an interpolated string is a string
+		"display_name": stringType,
+		// try(var.port, 8080)
+		"port": numberType,
+		// attributes of an untyped input have type Any, which the boolean default does not override
+		"enabled": anyType,
+		// null literals have no type of their own
+		"tags": mapType(stringType),
+		// arguments of different types
+		"mixed": anyType,
+		// an argument of type any
+		"setting": anyType,
+	}, types)
+}
+
+func TestInferModuleSchemaObjectOutputs(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("objects", loadTestModule(t, "object-outputs"), nil)
+	require.NoError(t, err)
+
+	types := map[resource.PropertyKey]schema.TypeSpec{}
+	for k, p := range inferredSchema.Outputs {
+		types[k] = p.TypeSpec
+	}
+
+	assert.Equal(t, map[resource.PropertyKey]schema.TypeSpec{
+		// an object constructor with fixed keys
+		"endpoint": refType("#/types/objects:index:Endpoint"),
+		// for expressions producing objects are keyed by values only known at runtime
+		"ids_by_name":  mapType(anyType),
+		"urls_by_name": mapType(stringType),
+		// grouping for expressions
+		"names_by_prefix": mapType(arrayType(anyType)),
+		// an object constructor with a computed key
+		"prefixed": mapType(stringType),
+		// collections of objects built by object constructors with fixed keys
+		"endpoints_by_name": mapType(refType("#/types/objects:index:EndpointsByName")),
+		"listeners":         arrayType(refType("#/types/objects:index:Listeners")),
+	}, types)
+
+	assert.Equal(t, map[string]*schema.ComplexTypeSpec{
+		"objects:index:Endpoint": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: "object",
+				Properties: map[string]schema.PropertySpec{
+					"name": {TypeSpec: stringType},
+					"port": {TypeSpec: numberType},
+					"url":  {TypeSpec: stringType},
+					"tls":  {TypeSpec: refType("#/types/objects:index:EndpointTls")},
+					"id":   {TypeSpec: anyType},
+				},
+			},
+		},
+		"objects:index:EndpointTls": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: "object",
+				Properties: map[string]schema.PropertySpec{
+					"enabled": {TypeSpec: boolType},
+				},
+			},
+		},
+		"objects:index:EndpointsByName": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: "object",
+				Properties: map[string]schema.PropertySpec{
+					"name": {TypeSpec: anyType},
+					"url":  {TypeSpec: stringType},
+				},
+			},
+		},
+		"objects:index:Listeners": {
+			ObjectTypeSpec: schema.ObjectTypeSpec{
+				Type: "object",
+				Properties: map[string]schema.PropertySpec{
+					"protocol": {TypeSpec: stringType},
+					"port":     {TypeSpec: numberType},
+				},
+			},
+		},
+	}, inferredSchema.SupportingTypes)
+}
@@ -0,0 +1,181 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+const (
+	// typeNamingPath names supporting types after the path leading to them from a module input or output, such as
+	// ClusterNodeGroupsLaunchTemplate for the launch_template attribute of the elements of the node_groups attribute
+	// of the cluster input. This is the default.
+	typeNamingPath = "path"
+	// typeNamingShort names nested supporting types after the attribute holding them, such as LaunchTemplate, and
+	// qualifies them with the name of their parent type, as in ClusterLaunchTemplate, when another type has that name.
+	typeNamingShort = "short"
+)
+
+// minTypeNameLength leaves room for a few letters and a numeric suffix in names bounded by maxTypeNameLength.
+const minTypeNameLength = 8
+
+func checkTypeNaming(mode string, maxLength int) error {
+	if mode != typeNamingPath && mode != typeNamingShort {
+		return fmt.Errorf("invalid typeNaming module configuration %q, expected %q or %q",
+			mode, typeNamingPath, typeNamingShort)
+	}
+	if maxLength < 0 || (maxLength > 0 && maxLength < minTypeNameLength) {
+		return fmt.Errorf("invalid maxTypeNameLength module configuration %d, expected at least %d",
+			maxLength, minTypeNameLength)
+	}
+	return nil
+}
+
+// nameSupportingTypes renames the supporting types inferred for a module following the typeNaming and
+// maxTypeNameLength module configuration. Types are named in the order they are reached from the inputs and then the
+// outputs of the module, sorted by name, so that the names are stable. Names that are already taken, or that
+// truncating to maxLength makes collide, are numbered, such as LaunchTemplate2.
+func nameSupportingTypes(inferredModule *InferredModuleSchema, packageName packageName, mode string, maxLength int) {
+	if mode == typeNamingPath && maxLength == 0 {
+		return
+	}
+
+	prefix := fmt.Sprintf("%s:index:", packageName)
+	renamed := map[string]string{}
+	taken := map[string]bool{}
+
+	// claim takes the first free name among candidates, or numbers the first one until it is free
+	claim := func(candidates ...string) string {
+		bound := func(name, suffix string) string {
+			if maxLength > 0 && len(name)+len(suffix) > maxLength {
+				name = name[:maxLength-len(suffix)]
+			}
+			return name + suffix
+		}
+		name := ""
+		for _, candidate := range candidates {
+			if name = bound(candidate, ""); !taken[name] {
+				break
+			}
+		}
+		for i := 2; taken[name]; i++ {
+			name = bound(candidates[0], fmt.Sprint(i))
+		}
+		taken[name] = true
+		return name
+	}
+
+	var visit func(token string, candidates ...string)
+	visitType := func(t schema.TypeSpec, parent, attribute string) {
+		for _, token := range referencedTypeTokens(t) {
+			if mode == typeNamingShort && parent != "" {
+				short := formatPascalCaseTypeName(attribute)
+				visit(token, short, parent+short)
+			} else {
+				visit(token, strings.TrimPrefix(token, prefix))
+			}
+		}
+	}
+	visit = func(token string, candidates ...string) {
+		typeSpec, ok := inferredModule.SupportingTypes[token]
+		if _, done := renamed[token]; done || !ok {
+			return
+		}
+		name := claim(candidates...)
+		renamed[token] = prefix + name
+		for _, attribute := range slices.Sorted(maps.Keys(typeSpec.Properties)) {
+			visitType(typeSpec.Properties[attribute].TypeSpec, name, attribute)
+		}
+	}
+
+	for _, properties := range []map[resource.PropertyKey]*schema.PropertySpec{
+		inferredModule.Inputs, inferredModule.Outputs,
+	} {
+		for _, k := range slices.Sorted(maps.Keys(properties)) {
+			visitType(properties[k].TypeSpec, "", string(k))
+		}
+	}
+	// types no input or output refers to keep their names, bounded by maxLength
+	for _, token := range slices.Sorted(maps.Keys(inferredModule.SupportingTypes)) {
+		visit(token, strings.TrimPrefix(token, prefix))
+	}
+
+	supportingTypes := map[string]*schema.ComplexTypeSpec{}
+	for token, typeSpec := range inferredModule.SupportingTypes {
+		for attribute, property := range typeSpec.Properties {
+			property.TypeSpec = renameTypeRefs(property.TypeSpec, renamed)
+			typeSpec.Properties[attribute] = property
+		}
+		supportingTypes[renamed[token]] = typeSpec
+	}
+	inferredModule.SupportingTypes = supportingTypes
+	for _, properties := range []map[resource.PropertyKey]*schema.PropertySpec{
+		inferredModule.Inputs, inferredModule.Outputs,
+	} {
+		for _, property := range properties {
+			property.TypeSpec = renameTypeRefs(property.TypeSpec, renamed)
+		}
+	}
+}
+
+// referencedTypeTokens returns the tokens of the supporting types a type refers to, directly or as the elements of
+// arrays and maps.
+func referencedTypeTokens(t schema.TypeSpec) []string {
+	var tokens []string
+	if token, ok := strings.CutPrefix(t.Ref, "#/types/"); ok {
+		tokens = append(tokens, token)
+	}
+	if t.Items != nil {
+		tokens = append(tokens, referencedTypeTokens(*t.Items)...)
+	}
+	if t.AdditionalProperties != nil {
+		tokens = append(tokens, referencedTypeTokens(*t.AdditionalProperties)...)
+	}
+	for _, oneOf := range t.OneOf {
+		tokens = append(tokens, referencedTypeTokens(oneOf)...)
+	}
+	return tokens
+}
+
+// renameTypeRefs returns a copy of t referring to the supporting types by their new tokens.
+func renameTypeRefs(t schema.TypeSpec, renamed map[string]string) schema.TypeSpec {
+	if token, ok := strings.CutPrefix(t.Ref, "#/types/"); ok {
+		if newToken, ok := renamed[token]; ok {
+			t.Ref = "#/types/" + newToken
+		}
+	}
+	if t.Items != nil {
+		items := renameTypeRefs(*t.Items, renamed)
+		t.Items = &items
+	}
+	if t.AdditionalProperties != nil {
+		additionalProperties := renameTypeRefs(*t.AdditionalProperties, renamed)
+		t.AdditionalProperties = &additionalProperties
+	}
+	if t.OneOf != nil {
+		oneOf := make([]schema.TypeSpec, len(t.OneOf))
+		for i, element := range t.OneOf {
+			oneOf[i] = renameTypeRefs(element, renamed)
+		}
+		t.OneOf = oneOf
+	}
+	return t
+}
@@ -0,0 +1,128 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestInferModuleSchemaShortTypeNames(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"), &ModuleConfig{
+		TypeNaming: typeNamingShort,
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		"eks:index:Cluster",
+		"eks:index:NodeGroups",
+		"eks:index:LaunchTemplate",
+		"eks:index:BlockDeviceMappings",
+		"eks:index:Ebs",
+		"eks:index:KmsKey",
+		"eks:index:Database",
+		// the kms_key of the database is qualified, since the kms_key of the cluster came first
+		"eks:index:DatabaseKmsKey",
+	}, slices.Collect(maps.Keys(inferredSchema.SupportingTypes)))
+
+	assert.Equal(t, refType("#/types/eks:index:Cluster"), inferredSchema.Inputs["cluster"].TypeSpec)
+	assert.Equal(t, arrayType(refType("#/types/eks:index:NodeGroups")),
+		inferredSchema.SupportingTypes["eks:index:Cluster"].Properties["node_groups"].TypeSpec)
+	assert.Equal(t, refType("#/types/eks:index:KmsKey"),
+		inferredSchema.SupportingTypes["eks:index:Ebs"].Properties["kms_key"].TypeSpec)
+	assert.Equal(t, refType("#/types/eks:index:DatabaseKmsKey"),
+		inferredSchema.SupportingTypes["eks:index:Database"].Properties["kms_key"].TypeSpec)
+	assertTypeRefsResolve(t, inferredSchema)
+}
+
+func TestInferModuleSchemaMaxTypeNameLength(t *testing.T) {
+	for _, typeNaming := range []string{typeNamingPath, typeNamingShort} {
+		t.Run(typeNaming, func(t *testing.T) {
+			inferredSchema, err := inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"),
+				&ModuleConfig{TypeNaming: typeNaming, MaxTypeNameLength: 24})
+			require.NoError(t, err)
+
+			assert.Len(t, inferredSchema.SupportingTypes, 8)
+			for token := range inferredSchema.SupportingTypes {
+				name := strings.TrimPrefix(token, "eks:index:")
+				assert.LessOrEqual(t, len(name), 24, token)
+			}
+			assertTypeRefsResolve(t, inferredSchema)
+		})
+	}
+
+	t.Run("numbered", func(t *testing.T) {
+		inferredSchema, err := inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"),
+			&ModuleConfig{MaxTypeNameLength: 24})
+		require.NoError(t, err)
+
+		// ClusterNodeGroupsLaunchTemplate and the names of the types nested in it all truncate to the same name
+		assert.Contains(t, inferredSchema.SupportingTypes, "eks:index:ClusterNodeGroupsLaunchT")
+		assert.Contains(t, inferredSchema.SupportingTypes, "eks:index:ClusterNodeGroupsLaunch2")
+		assert.Contains(t, inferredSchema.SupportingTypes, "eks:index:ClusterNodeGroupsLaunch3")
+	})
+}
+
+func TestInferModuleSchemaDefaultTypeNames(t *testing.T) {
+	inferredSchema, err := inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"), nil)
+	require.NoError(t, err)
+
+	assert.Contains(t, inferredSchema.SupportingTypes,
+		"eks:index:ClusterNodeGroupsLaunchTemplateBlockDeviceMappingsEbsKmsKey")
+	assert.Contains(t, inferredSchema.SupportingTypes, "eks:index:DatabaseKmsKey")
+}
+
+func TestInferModuleSchemaInvalidTypeNaming(t *testing.T) {
+	_, err := inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"), &ModuleConfig{
+		TypeNaming: "hashed",
+	})
+	assert.ErrorContains(t, err, `invalid typeNaming module configuration "hashed"`)
+
+	_, err = inferModuleSchemaFromContent("eks", loadTestModule(t, "nested-types"), &ModuleConfig{
+		MaxTypeNameLength: 4,
+	})
+	assert.ErrorContains(t, err, "invalid maxTypeNameLength module configuration 4")
+}
+
+// assertTypeRefsResolve checks that every type reference of the schema names one of its supporting types.
+func assertTypeRefsResolve(t *testing.T, inferredSchema *InferredModuleSchema) {
+	t.Helper()
+	var refs []string
+	collect := func(property schema.PropertySpec) {
+		refs = append(refs, referencedTypeTokens(property.TypeSpec)...)
+	}
+	for _, property := range inferredSchema.Inputs {
+		collect(*property)
+	}
+	for _, property := range inferredSchema.Outputs {
+		collect(*property)
+	}
+	for _, typeSpec := range inferredSchema.SupportingTypes {
+		for _, property := range typeSpec.Properties {
+			collect(property)
+		}
+	}
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		assert.Contains(t, inferredSchema.SupportingTypes, ref)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/opentofu/addrs"
+	"github.com/pulumi/opentofu/configs"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// unconfiguredProvidersPolicy selects how Check handles Terraform providers that the module requires but that are
+// neither configured on the Pulumi provider nor set up through the environment. Such providers otherwise only fail
+// once Terraform plans or applies the module, often with errors that do not name the missing configuration.
+type unconfiguredProvidersPolicy string
+
+const (
+	// unconfiguredProvidersIgnore leaves unconfigured providers to Terraform. This is the default.
+	unconfiguredProvidersIgnore unconfiguredProvidersPolicy = "ignore"
+	// unconfiguredProvidersWarn warns about unconfigured providers when checking module instances.
+	unconfiguredProvidersWarn unconfiguredProvidersPolicy = "warn"
+	// unconfiguredProvidersError fails checking module instances until every required provider is configured.
+	unconfiguredProvidersError unconfiguredProvidersPolicy = "error"
+)
+
+func parseUnconfiguredProvidersPolicy(s string) (unconfiguredProvidersPolicy, error) {
+	switch p := unconfiguredProvidersPolicy(s); p {
+	case "":
+		return unconfiguredProvidersIgnore, nil
+	case unconfiguredProvidersIgnore, unconfiguredProvidersWarn, unconfiguredProvidersError:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q, %q or %q, got %q",
+			unconfiguredProvidersVariableName, unconfiguredProvidersIgnore, unconfiguredProvidersWarn,
+			unconfiguredProvidersError, s)
+	}
+}
+
+// selfConfiguredProviders need no configuration, so they are never reported as unconfigured.
+var selfConfiguredProviders = []string{
+	"archive", "cloudinit", "external", "http", "local", "null", "random", "time", "tls",
+}
+
+// ambientProviderEnvironment are the environment variables by which common providers are given credentials without a
+// provider block, such as AWS_PROFILE for aws. Providers with one of these variables set are considered configured
+// through the environment. They are keyed by Pulumi names, where dashes become underscores.
+var ambientProviderEnvironment = map[string][]string{
+	"aws": {
+		"AWS_ACCESS_KEY_ID", "AWS_PROFILE", "AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+	},
+	"azurerm":      {"ARM_CLIENT_ID", "ARM_USE_MSI", "ARM_USE_OIDC", "ARM_USE_CLI"},
+	"azuread":      {"ARM_CLIENT_ID", "ARM_USE_MSI", "ARM_USE_OIDC", "ARM_USE_CLI"},
+	"google":       {"GOOGLE_CREDENTIALS", "GOOGLE_APPLICATION_CREDENTIALS", "GOOGLE_OAUTH_ACCESS_TOKEN"},
+	"google_beta":  {"GOOGLE_CREDENTIALS", "GOOGLE_APPLICATION_CREDENTIALS", "GOOGLE_OAUTH_ACCESS_TOKEN"},
+	"kubernetes":   {"KUBECONFIG", "KUBE_CONFIG_PATH", "KUBE_HOST"},
+	"helm":         {"KUBECONFIG", "KUBE_CONFIG_PATH", "KUBE_HOST"},
+	"cloudflare":   {"CLOUDFLARE_API_TOKEN", "CLOUDFLARE_API_KEY"},
+	"datadog":      {"DD_API_KEY", "DATADOG_API_KEY"},
+	"github":       {"GITHUB_TOKEN", "GITHUB_APP_ID"},
+	"digitalocean": {"DIGITALOCEAN_TOKEN", "DIGITALOCEAN_ACCESS_TOKEN"},
+}
+
+// moduleRequiredProviders returns the Pulumi names of the providers the module requires, either in its
+// required_providers blocks or implicitly through the types of its resources and data sources, including those of the
+// modules it calls from local paths. Providers required by child modules are named after their local name in the root
+// module when it requires them too, and after their type otherwise, which is what provider configurations are keyed
+// by.
+//
+// Local modules that fail to load are left out, and the providers of the others are returned along with the error.
+func moduleRequiredProviders(module *configs.Module) ([]string, error) {
+	rootNames := map[addrs.Provider]string{}
+	if module.ProviderRequirements != nil {
+		for name, requirement := range module.ProviderRequirements.RequiredProviders {
+			rootNames[requirement.Type] = name
+		}
+	}
+
+	required := map[addrs.Provider]struct{}{}
+	err := visitLocalModules(module, func(m *configs.Module) {
+		if m.ProviderRequirements != nil {
+			for _, requirement := range m.ProviderRequirements.RequiredProviders {
+				required[requirement.Type] = struct{}{}
+			}
+		}
+		for _, r := range m.ManagedResources {
+			required[r.Provider] = struct{}{}
+		}
+		for _, r := range m.DataResources {
+			required[r.Provider] = struct{}{}
+		}
+	})
+	names := map[string]struct{}{}
+	for provider := range required {
+		if provider.IsBuiltIn() {
+			continue
+		}
+		name, ok := rootNames[provider]
+		if !ok {
+			name = provider.Type
+		}
+		names[strings.ReplaceAll(name, "-", "_")] = struct{}{}
+	}
+	return slices.Sorted(maps.Keys(names)), err
+}
+
+// unconfiguredProviders returns the providers required by the module that have no configuration, in the program or
+// in ESC, and that are not set up through the environment. An empty configuration counts as configured, so that
+// providers configured entirely by other means can be acknowledged with {}.
+func unconfiguredProviders(
+	inferredModule *InferredModuleSchema,
+	providersConfig map[string]resource.PropertyMap,
+) []string {
+	var unconfigured []string
+	for _, name := range inferredModule.requiredProviders {
+		if _, configured := providersConfig[name]; configured || slices.Contains(selfConfiguredProviders, name) {
+			continue
+		}
+		if slices.ContainsFunc(ambientProviderEnvironment[name], func(env string) bool {
+			return os.Getenv(env) != ""
+		}) {
+			continue
+		}
+		unconfigured = append(unconfigured, name)
+	}
+	return unconfigured
+}
+
+// unconfiguredProviderFailures warns about or fails on the providers required by the module that are not configured,
+// following the unconfiguredProviders provider option.
+func unconfiguredProviderFailures(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	inferredModule *InferredModuleSchema,
+	providersConfig map[string]resource.PropertyMap,
+	policy unconfiguredProvidersPolicy,
+) []*pulumirpc.CheckFailure {
+	if policy != unconfiguredProvidersWarn && policy != unconfiguredProvidersError {
+		return nil
+	}
+	var failures []*pulumirpc.CheckFailure
+	for _, name := range unconfiguredProviders(inferredModule, providersConfig) {
+		message := fmt.Sprintf("the module requires the %q provider, which is not configured: set %q on the "+
+			"Pulumi provider, or set it to {} if the provider is configured through its environment", name, name)
+		if policy == unconfiguredProvidersWarn {
+			logger.Log(ctx, tfsandbox.Warn, message)
+			continue
+		}
+		failures = append(failures, &pulumirpc.CheckFailure{Reason: message})
+	}
+	return failures
+}
@@ -0,0 +1,107 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+func TestParseUnconfiguredProvidersPolicy(t *testing.T) {
+	for s, expected := range map[string]unconfiguredProvidersPolicy{
+		"":       unconfiguredProvidersIgnore,
+		"ignore": unconfiguredProvidersIgnore,
+		"warn":   unconfiguredProvidersWarn,
+		"error":  unconfiguredProvidersError,
+	} {
+		policy, err := parseUnconfiguredProvidersPolicy(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+
+	_, err := parseUnconfiguredProvidersPolicy("fail")
+	assert.EqualError(t, err, `provider option "unconfiguredProviders" must be one of "ignore", "warn" or "error", `+
+		`got "fail"`)
+}
+
+func TestInferModuleRequiredProviders(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("site", loadTestModule(t, "unconfigured-providers"), nil)
+	require.NoError(t, err)
+
+	// random is only implied by the random_pet resource and cloudflare is required by the child module
+	assert.Equal(t, []string{"aws", "cloudflare", "random"}, inferredModule.requiredProviders)
+}
+
+func TestInferModuleRequiredProvidersSkipsBrokenLocalModules(t *testing.T) {
+	inferredModule, err := inferModuleSchemaFromContent("site", loadTestModule(t, "broken-local-module"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"random"}, inferredModule.requiredProviders)
+
+	_, err = moduleResourceTypes(loadTestModule(t, "broken-local-module"))
+	assert.ErrorContains(t, err, `error while loading module ./broken called as "broken"`)
+}
+
+func TestCheckWarnsAboutUnconfiguredProviders(t *testing.T) {
+	ctx := context.Background()
+	for _, env := range append(ambientProviderEnvironment["aws"], ambientProviderEnvironment["cloudflare"]...) {
+		t.Setenv(env, "")
+	}
+	inferredModule, err := inferModuleSchemaFromContent("site", loadTestModule(t, "unconfigured-providers"), nil)
+	require.NoError(t, err)
+
+	t.Run("warn", func(t *testing.T) {
+		logger := &recordingLogger{}
+		failures := unconfiguredProviderFailures(ctx, logger, inferredModule, map[string]resource.PropertyMap{
+			"aws": {"region": resource.NewStringProperty("us-west-2")},
+		}, unconfiguredProvidersWarn)
+		assert.Empty(t, failures)
+		assert.Equal(t, []string{`warn: the module requires the "cloudflare" provider, which is not configured: ` +
+			`set "cloudflare" on the Pulumi provider, or set it to {} if the provider is configured through its ` +
+			`environment`}, logger.messages)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		logger := &recordingLogger{}
+		failures := unconfiguredProviderFailures(ctx, logger, inferredModule, map[string]resource.PropertyMap{},
+			unconfiguredProvidersError)
+		assert.Empty(t, logger.messages)
+		require.Len(t, failures, 2)
+		assert.Contains(t, failures[0].GetReason(), `the module requires the "aws" provider`)
+		assert.Contains(t, failures[1].GetReason(), `the module requires the "cloudflare" provider`)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv("CLOUDFLARE_API_TOKEN", "token")
+		failures := unconfiguredProviderFailures(ctx, &recordingLogger{}, inferredModule,
+			map[string]resource.PropertyMap{"aws": {}}, unconfiguredProvidersError)
+		assert.Empty(t, failures)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		resp, err := (&moduleHandler{}).Check(ctx, &pulumirpc.CheckRequest{
+			Urn: "urn:pulumi:test::test::site:index:Module::site",
+		}, inferredModule, map[string]resource.PropertyMap{"cloudflare": {}},
+			moduleOptions{unconfiguredProviders: unconfiguredProvidersError})
+		require.NoError(t, err)
+		require.Len(t, resp.GetFailures(), 1)
+		assert.Contains(t, resp.GetFailures()[0].GetReason(), `the module requires the "aws" provider`)
+	})
+}
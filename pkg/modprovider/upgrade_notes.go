@@ -0,0 +1,141 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// upgradeNotesPatterns match the files in which modules document how to upgrade between versions, such as the
+// UPGRADE-5.0.md guides of the terraform-aws-modules.
+var upgradeNotesPatterns = []string{"UPGRADE*.md", "upgrade*.md", "CHANGELOG.md", "changelog.md"}
+
+// maxChangelogLines bounds the changelog section reported for the version a module is upgraded to.
+const maxChangelogLines = 20
+
+// moduleUpgradeNotes finds the upgrade notes shipped with the module in dir. It returns the names of the files holding
+// them, and the section of the changelog describing version when there is one.
+func moduleUpgradeNotes(dir string, version TFModuleVersion) (files []string, changes string, err error) {
+	for _, pattern := range upgradeNotesPatterns {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, "", err
+		}
+		for _, match := range matches {
+			if name := filepath.Base(match); !slices.Contains(files, name) {
+				files = append(files, name)
+			}
+		}
+	}
+
+	for _, name := range files {
+		if !strings.EqualFold(name, "CHANGELOG.md") || version == "" {
+			continue
+		}
+		//nolint:gosec // the changelog is read from the module being deployed
+		contents, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read the module changelog: %w", err)
+		}
+		changes = changelogSection(string(contents), string(version))
+	}
+	return files, changes, nil
+}
+
+// changelogSection returns the section of a Markdown changelog under the heading naming version, without the heading.
+func changelogSection(changelog string, version string) string {
+	versionHeading := regexp.MustCompile(`^#+ .*\bv?` + regexp.QuoteMeta(version) + `\b`)
+	var section []string
+	level := 0
+	for _, line := range strings.Split(changelog, "\n") {
+		heading := len(line) - len(strings.TrimLeft(line, "#"))
+		switch {
+		case level == 0:
+			if versionHeading.MatchString(line) {
+				level = heading
+			}
+		case heading > 0 && heading <= level:
+			return strings.TrimSpace(strings.Join(section, "\n"))
+		default:
+			section = append(section, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(section, "\n"))
+}
+
+// reportModuleUpgradeNotes points to the upgrade notes of module instances whose module version changed, so that
+// users learn about breaking changes before they surface as a failed plan. Notes are advisory, so failures to find
+// them are only logged at the debug level.
+func reportModuleUpgradeNotes(
+	ctx context.Context,
+	logger tfsandbox.Logger,
+	workdir string,
+	moduleName string,
+	version TFModuleVersion,
+) {
+	dir, err := resolvedModuleDir(workdir, moduleName)
+	if err != nil {
+		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("Not looking for module upgrade notes: %v", err))
+		return
+	}
+
+	files, changes, err := moduleUpgradeNotes(dir, version)
+	switch {
+	case err != nil:
+		logger.Log(ctx, tfsandbox.Debug, fmt.Sprintf("Not looking for module upgrade notes: %v", err))
+		return
+	case len(files) == 0:
+		return
+	}
+
+	msg := fmt.Sprintf("Module %s was upgraded to version %s, see %s in %s for upgrade notes",
+		moduleName, versionOrUnknown(version), strings.Join(files, ", "), dir)
+	if changes != "" {
+		lines := strings.Split(changes, "\n")
+		if len(lines) > maxChangelogLines {
+			lines = append(lines[:maxChangelogLines], "...")
+		}
+		msg += ". Changes in " + string(version) + ":\n" + strings.Join(lines, "\n")
+	}
+	logger.Log(ctx, tfsandbox.Info, msg)
+}
+
+// resolvedModuleDir returns the directory init downloaded the module called moduleName in workdir to.
+func resolvedModuleDir(workdir string, moduleName string) (string, error) {
+	mj, err := tfsandbox.ReadModuleManifest(workdir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("no modules were installed in %s", workdir)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to read modules.json file: %w", err)
+	}
+	dir, err := findResolvedModuleDir(mj, moduleName)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(workdir, dir)
+	}
+	return dir, nil
+}
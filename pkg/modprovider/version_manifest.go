@@ -0,0 +1,68 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// versionManifest pins module versions in a central file, provided via --version-manifest <file>:
+//
+//	{
+//	  "modules": {
+//	    "terraform-aws-modules/vpc/aws": "5.19.0"
+//	  }
+//	}
+//
+// The keys are module sources as passed to `pulumi package add` and the values are versions. Registry sources match
+// regardless of whether they spell out the public registry host, see [TFModuleSource.Normalized].
+type versionManifest struct {
+	Modules map[TFModuleSource]TFModuleVersion `json:"modules"`
+
+	path string
+}
+
+func readVersionManifest(path string) (*versionManifest, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version manifest %s: %w", path, err)
+	}
+
+	manifest := &versionManifest{path: path}
+	if err := json.Unmarshal(file, manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal version manifest %s: %w", path, err)
+	}
+
+	for source, version := range manifest.Modules {
+		if !isValidVersion(string(version)) {
+			return nil, fmt.Errorf("version manifest %s pins module %s to an invalid version %q",
+				path, source, version)
+		}
+	}
+
+	return manifest, nil
+}
+
+// moduleVersion finds the version pinned for a module source.
+func (m *versionManifest) moduleVersion(source TFModuleSource) (TFModuleVersion, error) {
+	for listed, version := range m.Modules {
+		if listed.Normalized() == source.Normalized() {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("module %s is not listed in version manifest %s", source, m.path)
+}
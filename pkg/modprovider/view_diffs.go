@@ -0,0 +1,99 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"slices"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// detailViewSteps adds to the views of the child resources a plan changes which of their attributes change and which
+// force a replacement, as the detailed diffs of Pulumi resources. `pulumi preview --diff` and `pulumi preview --json`
+// then show what a module does to every child resource instead of its whole old and new states. It applies when the
+// childResourceDiffs provider option is set.
+func detailViewSteps(plan *tfsandbox.Plan, steps []*pulumirpc.ViewStep) {
+	replacePaths := map[string][]resource.PropertyPath{}
+	plan.VisitResourcePlans(func(rplan *tfsandbox.ResourcePlan) {
+		replacePaths[childResourceName(rplan.Address())] = rplan.ReplacePaths()
+	})
+
+	for _, step := range steps {
+		switch step.Op {
+		case pulumirpc.ViewStep_UPDATE, pulumirpc.ViewStep_REPLACE, pulumirpc.ViewStep_CREATE_REPLACEMENT:
+		default:
+			continue
+		}
+		if step.Old == nil || step.New == nil {
+			continue
+		}
+		detailViewStep(step, replacePaths[step.Name])
+	}
+}
+
+func detailViewStep(step *pulumirpc.ViewStep, replacePaths []resource.PropertyPath) {
+	opts := plugin.MarshalOptions{KeepUnknowns: true, KeepSecrets: true, KeepResources: true}
+	// steps whose inputs cannot be compared are left without details
+	olds, err := plugin.UnmarshalProperties(step.Old.Inputs, opts)
+	if err != nil {
+		return
+	}
+	news, err := plugin.UnmarshalProperties(step.New.Inputs, opts)
+	if err != nil {
+		return
+	}
+
+	diff := plugin.NewDetailedDiffFromObjectDiff(olds.Diff(news), true /* inputDiff */)
+	for _, replacePath := range replacePaths {
+		replaced := false
+		for path, propertyDiff := range diff {
+			parsed, err := resource.ParsePropertyPath(path)
+			if err == nil && (replacePath.Contains(parsed) || parsed.Contains(replacePath)) {
+				diff[path] = propertyDiff.ToReplace()
+				replaced = true
+			}
+		}
+		// Terraform may replace resources for attributes that are only known once applied.
+		if !replaced {
+			diff[replacePath.String()] = plugin.PropertyDiff{Kind: plugin.DiffUpdateReplace, InputDiff: true}
+		}
+		if key, ok := replacePath[0].(string); ok && !slices.Contains(step.Keys, key) {
+			step.Keys = append(step.Keys, key)
+		}
+	}
+
+	step.DetailedDiff = map[string]*pulumirpc.PropertyDiff{}
+	for path, propertyDiff := range diff {
+		step.DetailedDiff[path] = &pulumirpc.PropertyDiff{
+			// The kinds of plugin.DiffKind are numbered like those of the protocol.
+			Kind:      pulumirpc.PropertyDiff_Kind(propertyDiff.Kind), //nolint:gosec
+			InputDiff: propertyDiff.InputDiff,
+		}
+		parsed, err := resource.ParsePropertyPath(path)
+		if err != nil || len(parsed) == 0 {
+			continue
+		}
+		if key, ok := parsed[0].(string); ok && !slices.Contains(step.Diffs, key) {
+			step.Diffs = append(step.Diffs, key)
+		}
+	}
+	slices.Sort(step.Keys)
+	slices.Sort(step.Diffs)
+	step.HasDetailedDiff = true
+}
@@ -0,0 +1,140 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestDetailViewSteps(t *testing.T) {
+	const (
+		updatedAddr  = "module.m.aws_s3_bucket.logs"
+		replacedAddr = "module.m.random_integer.priority"
+		createdAddr  = "module.m.random_id.suffix"
+	)
+
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{
+					{
+						Address: updatedAddr,
+						Mode:    tfjson.ManagedResourceMode,
+						Type:    "aws_s3_bucket",
+						Name:    "logs",
+						AttributeValues: map[string]any{
+							"bucket": "logs",
+							"tags":   map[string]any{"env": "prod", "team": "infra"},
+						},
+					},
+					{
+						Address:         replacedAddr,
+						Mode:            tfjson.ManagedResourceMode,
+						Type:            "random_integer",
+						Name:            "priority",
+						AttributeValues: map[string]any{"min": 1, "max": 12},
+					},
+					{
+						Address:         createdAddr,
+						Mode:            tfjson.ManagedResourceMode,
+						Type:            "random_id",
+						Name:            "suffix",
+						AttributeValues: map[string]any{"byte_length": 4},
+					},
+				},
+			}},
+		}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: updatedAddr,
+				Mode:    tfjson.ManagedResourceMode,
+				Type:    "aws_s3_bucket",
+				Change: &tfjson.Change{
+					Actions: tfjson.Actions{tfjson.ActionUpdate},
+					Before:  map[string]any{"bucket": "logs", "tags": map[string]any{"env": "dev"}},
+					After:   map[string]any{"bucket": "logs", "tags": map[string]any{"env": "prod", "team": "infra"}},
+				},
+			},
+			{
+				Address: replacedAddr,
+				Mode:    tfjson.ManagedResourceMode,
+				Type:    "random_integer",
+				Change: &tfjson.Change{
+					Actions:      tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate},
+					Before:       map[string]any{"min": 1, "max": 10},
+					After:        map[string]any{"min": 1, "max": 12},
+					ReplacePaths: []any{[]any{"max"}, []any{true}}, // true is neither a name nor an index
+				},
+			},
+			{
+				Address: createdAddr,
+				Mode:    tfjson.ManagedResourceMode,
+				Type:    "random_id",
+				Change: &tfjson.Change{
+					Actions: tfjson.Actions{tfjson.ActionCreate},
+					After:   map[string]any{"byte_length": 4},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	steps := viewStepsPlan("testmod", plan)
+	detailViewSteps(plan, steps)
+
+	diffs := map[string]map[string]pulumirpc.PropertyDiff_Kind{}
+	for _, step := range steps {
+		switch step.Op {
+		case pulumirpc.ViewStep_UPDATE:
+			assert.Equal(t, []string{"tags"}, step.Diffs)
+			assert.Empty(t, step.Keys)
+		case pulumirpc.ViewStep_REPLACE, pulumirpc.ViewStep_CREATE_REPLACEMENT:
+			assert.Equal(t, []string{"max"}, step.Diffs)
+			assert.Equal(t, []string{"max"}, step.Keys)
+		default:
+			assert.False(t, step.HasDetailedDiff, "%s %s has no diff to detail", step.Op, step.Name)
+			continue
+		}
+		require.True(t, step.HasDetailedDiff)
+		kinds := map[string]pulumirpc.PropertyDiff_Kind{}
+		for path, diff := range step.DetailedDiff {
+			assert.True(t, diff.InputDiff)
+			kinds[path] = diff.Kind
+		}
+		diffs[step.Op.String()+" "+step.Name] = kinds
+	}
+
+	assert.Equal(t, map[string]map[string]pulumirpc.PropertyDiff_Kind{
+		"UPDATE " + updatedAddr: {
+			"tags.env":  pulumirpc.PropertyDiff_UPDATE,
+			"tags.team": pulumirpc.PropertyDiff_ADD,
+		},
+		"REPLACE " + replacedAddr: {
+			"max": pulumirpc.PropertyDiff_UPDATE_REPLACE,
+		},
+		"CREATE_REPLACEMENT " + replacedAddr: {
+			"max": pulumirpc.PropertyDiff_UPDATE_REPLACE,
+		},
+	}, diffs)
+}
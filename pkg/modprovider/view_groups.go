@@ -0,0 +1,137 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+const viewGroupTypeName = "Group"
+
+// viewGroups maps the names of logical components, such as "networking", to patterns of the addresses of the child
+// resources they group.
+type viewGroups map[string][]string
+
+func viewGroupTypeToken(pkgName packageName) tokens.Type {
+	return tokens.Type(fmt.Sprintf("%s:index:%s", pkgName, viewGroupTypeName))
+}
+
+// groupOf finds the group of the child resource at the given address. Patterns are matched with [path.Match] against
+// the address relative to the module block, such as aws_subnet.private[0] or module.vpc.aws_subnet.private[0] for a
+// nested module, so that "aws_subnet.*" matches all subnets of the module. Groups are tried in the order of their
+// names and the first match wins.
+func (g viewGroups) groupOf(addr string) (string, bool) {
+	relative := moduleRelativeAddress(addr)
+	for _, name := range slices.Sorted(maps.Keys(g)) {
+		for _, pattern := range g[name] {
+			if ok, _ := path.Match(pattern, relative); ok {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// moduleRelativeAddress strips the module block of the module instance from the address of a child resource.
+func moduleRelativeAddress(addr string) string {
+	rest, ok := strings.CutPrefix(addr, "module.")
+	if !ok {
+		return addr
+	}
+	if _, relative, ok := strings.Cut(rest, "."); ok {
+		return relative
+	}
+	return addr
+}
+
+// groupViewSteps parents the views of child resources matching the viewGroups module configuration to views of the
+// logical components they belong to, which organizes the resources of large modules in the console:
+//
+//	urn:pulumi:dev::proj::vpc:index:Module$vpc:index:Group$vpc:tf:aws_subnet::module.vpc.aws_subnet.private[0]
+//
+// A group is created along with its first resource and deleted along with its last one, so its step is published
+// before the steps of its resources unless it is being deleted, in which case it is published after them.
+func groupViewSteps(
+	packageName packageName,
+	inferredModule *InferredModuleSchema,
+	steps []*pulumirpc.ViewStep,
+) []*pulumirpc.ViewStep {
+	if inferredModule == nil || len(inferredModule.viewGroups) == 0 {
+		return steps
+	}
+
+	groupType := viewGroupTypeToken(packageName).String()
+	type groupExtent struct{ old, new bool }
+	groups := map[string]*groupExtent{}
+
+	for _, step := range steps {
+		group, ok := inferredModule.viewGroups.groupOf(step.Name)
+		if !ok {
+			continue
+		}
+		extent, ok := groups[group]
+		if !ok {
+			extent = &groupExtent{}
+			groups[group] = extent
+		}
+		for _, state := range []*pulumirpc.ViewStepState{step.Old, step.New} {
+			if state != nil {
+				state.ParentType = groupType
+				state.ParentName = group
+			}
+		}
+		extent.old = extent.old || step.Op != pulumirpc.ViewStep_CREATE
+		extent.new = extent.new || step.Op != pulumirpc.ViewStep_DELETE
+	}
+
+	var before, after []*pulumirpc.ViewStep
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		extent := groups[group]
+		state := &pulumirpc.ViewStepState{
+			Name:    group,
+			Type:    groupType,
+			Inputs:  viewStruct(resource.PropertyMap{}),
+			Outputs: viewStruct(resource.PropertyMap{}),
+		}
+		step := &pulumirpc.ViewStep{
+			Status: pulumirpc.ViewStep_OK,
+			Name:   group,
+			Type:   groupType,
+		}
+		switch {
+		case extent.old && extent.new:
+			step.Op, step.Old, step.New = pulumirpc.ViewStep_SAME, state, state
+		case extent.new:
+			step.Op, step.New = pulumirpc.ViewStep_CREATE, state
+		default:
+			step.Op, step.Old = pulumirpc.ViewStep_DELETE, state
+		}
+		if step.New != nil {
+			before = append(before, step)
+		} else {
+			after = append(after, step)
+		}
+	}
+
+	return slices.Concat(before, steps, after)
+}
@@ -0,0 +1,79 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// defaultViewStepsBatchSize is the size in bytes of the batches of view steps published to the engine unless
+// configured otherwise. It stays well below the 4 MiB default message size limit of gRPC.
+const defaultViewStepsBatchSize = 1 << 20
+
+// publishViewSteps publishes the views of the child resources of a module instance, splitting them into several
+// PublishViewSteps calls of at most batchSize bytes each. Modules with hundreds of resources otherwise produce
+// requests exceeding the RPC message size limits. Steps are published in order, so views of groups still precede the
+// views they parent. A single step larger than batchSize is published on its own, and a zero batchSize disables
+// batching.
+func publishViewSteps(
+	ctx context.Context,
+	client pulumirpc.ResourceStatusClient,
+	token string,
+	steps []*pulumirpc.ViewStep,
+	batchSize int,
+) error {
+	batches := batchViewSteps(steps, batchSize)
+	for i, batch := range batches {
+		_, err := client.PublishViewSteps(ctx, &pulumirpc.PublishViewStepsRequest{
+			Token: token,
+			Steps: batch,
+		})
+		if err != nil {
+			if len(batches) > 1 {
+				return fmt.Errorf("publishing batch %d of %d of view steps: %w", i+1, len(batches), err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// batchViewSteps splits steps into consecutive batches whose serialized size does not exceed batchSize bytes. There
+// is always at least one batch so that publishing an empty list of steps still reaches the engine.
+func batchViewSteps(steps []*pulumirpc.ViewStep, batchSize int) [][]*pulumirpc.ViewStep {
+	if batchSize <= 0 || len(steps) == 0 {
+		return [][]*pulumirpc.ViewStep{steps}
+	}
+
+	var batches [][]*pulumirpc.ViewStep
+	var batch []*pulumirpc.ViewStep
+	size := 0
+	for _, step := range steps {
+		// Account for the tag and length prefix wrapping every step in the request.
+		stepSize := proto.Size(step) + 8
+		if len(batch) > 0 && size+stepSize > batchSize {
+			batches = append(batches, batch)
+			batch, size = nil, 0
+		}
+		batch = append(batch, step)
+		size += stepSize
+	}
+	return append(batches, batch)
+}
@@ -0,0 +1,119 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/pulumix"
+	"github.com/pulumi/pulumi-terraform-module/pkg/pulumix/status"
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+type recordingStatusClient struct {
+	pulumirpc.ResourceStatusClient
+	requests []*pulumirpc.PublishViewStepsRequest
+}
+
+func (c *recordingStatusClient) PublishViewSteps(
+	_ context.Context,
+	in *pulumirpc.PublishViewStepsRequest,
+	_ ...grpc.CallOption,
+) (*pulumirpc.PublishViewStepsResponse, error) {
+	c.requests = append(c.requests, in)
+	return &pulumirpc.PublishViewStepsResponse{}, nil
+}
+
+type recordingStatusPool struct {
+	client *recordingStatusClient
+}
+
+func (p recordingStatusPool) Acquire(context.Context, pulumix.Logger, string) (status.Lease, error) {
+	return recordingStatusLease{p.client}, nil
+}
+
+type recordingStatusLease struct {
+	*recordingStatusClient
+}
+
+func (recordingStatusLease) Release() {}
+
+func TestPublishViewStepsOfLargeModulesInBatches(t *testing.T) {
+	const resourceCount = 500
+
+	plannedValues := &tfjson.StateModule{Address: "module.m"}
+	var changes []*tfjson.ResourceChange
+	for i := range resourceCount {
+		address := fmt.Sprintf("module.m.aws_s3_bucket.this[%d]", i)
+		plannedValues.Resources = append(plannedValues.Resources, &tfjson.StateResource{
+			Address:         address,
+			Mode:            tfjson.ManagedResourceMode,
+			Type:            "aws_s3_bucket",
+			Name:            "this",
+			Index:           i,
+			AttributeValues: map[string]any{"bucket": fmt.Sprintf("bucket-%d", i)},
+		})
+		changes = append(changes, &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    "aws_s3_bucket",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+		})
+	}
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{plannedValues},
+		}},
+		ResourceChanges: changes,
+	})
+	require.NoError(t, err)
+
+	steps := viewStepsPlan("testmod", plan)
+	require.Len(t, steps, resourceCount)
+
+	const batchSize = 16 << 10
+	client := &recordingStatusClient{}
+	err = publishViewSteps(context.Background(), client, "token", steps, batchSize)
+	require.NoError(t, err)
+
+	require.Greater(t, len(client.requests), 1, "expected the view steps to be published in several batches")
+	var published []*pulumirpc.ViewStep
+	for _, req := range client.requests {
+		assert.Equal(t, "token", req.Token)
+		assert.LessOrEqual(t, proto.Size(req), batchSize)
+		published = append(published, req.Steps...)
+	}
+	assert.Equal(t, steps, published, "batches preserve all steps and their order")
+
+	client = &recordingStatusClient{}
+	err = publishViewSteps(context.Background(), client, "token", steps, 0)
+	require.NoError(t, err)
+	assert.Len(t, client.requests, 1, "a zero batch size disables batching")
+
+	client = &recordingStatusClient{}
+	err = publishViewSteps(context.Background(), client, "token", nil, batchSize)
+	require.NoError(t, err)
+	assert.Len(t, client.requests, 1, "empty views are still published")
+}
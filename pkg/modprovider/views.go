@@ -16,6 +16,7 @@ package modprovider
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/protobuf/types/known/structpb"
 
@@ -31,15 +32,16 @@ func viewStepsPlan(
 	packageName packageName,
 	plan *tfsandbox.Plan,
 ) []*pulumirpc.ViewStep {
-	return viewStepsGeneric(packageName, plan, nil, true /* preview */)
+	return viewStepsGeneric(packageName, plan, nil, true /* preview */, nil /* resourceErrors */)
 }
 
 func viewStepsAfterApply(
 	packageName packageName,
 	plan *tfsandbox.Plan,
 	appliedState *tfsandbox.State,
+	resourceErrors map[tfsandbox.ResourceAddress][]string, // errors reported by TF for failed resources
 ) []*pulumirpc.ViewStep {
-	return viewStepsGeneric(packageName, plan, appliedState, false /*preview*/)
+	return viewStepsGeneric(packageName, plan, appliedState, false /*preview*/, resourceErrors)
 }
 
 func viewStepsAfterRefresh(
@@ -47,7 +49,7 @@ func viewStepsAfterRefresh(
 	plan *tfsandbox.Plan,
 	refreshedState *tfsandbox.State,
 ) []*pulumirpc.ViewStep {
-	return viewStepsGeneric(packageName, plan, refreshedState, false /*preview*/)
+	return viewStepsGeneric(packageName, plan, refreshedState, false /*preview*/, nil /* resourceErrors */)
 }
 
 func viewStepsGeneric(
@@ -55,10 +57,15 @@ func viewStepsGeneric(
 	plan *tfsandbox.Plan,
 	finalState *tfsandbox.State,
 	preview bool,
+	resourceErrors map[tfsandbox.ResourceAddress][]string,
 ) []*pulumirpc.ViewStep {
 	var steps []*pulumirpc.ViewStep
 	hasFinalState := finalState != nil
 
+	// Resources with create_before_destroy are replaced by creating the replacement first and deleting the replaced
+	// resource once everything depending on it has moved over. Their deletions are reported last, as Pulumi does.
+	var deletesReplaced []*pulumirpc.ViewStep
+
 	counter := 0
 
 	plan.VisitResourcePlans(func(rplan *tfsandbox.ResourcePlan) {
@@ -69,7 +76,7 @@ func viewStepsGeneric(
 			return
 		}
 
-		// TODO[pulumi/pulumi-terraform-module#61] sometimes addresses change but identity remains the same.
+		// Resources that moved are found under their new address; see viewStepsForResource for the old one.
 		addr := rplan.Address()
 
 		var finalRState *tfsandbox.ResourceState
@@ -81,16 +88,23 @@ func viewStepsGeneric(
 			}
 		}
 
-		rSteps := viewStepsForResource(packageName, rplan, finalRState, preview)
-		steps = append(steps, rSteps...)
+		rSteps := viewStepsForResource(packageName, rplan, finalRState, preview, resourceErrors[addr])
+		for _, step := range rSteps {
+			if rplan.ChangeKind() == tfsandbox.Replace && step.Op == pulumirpc.ViewStep_DELETE_REPLACED {
+				deletesReplaced = append(deletesReplaced, step)
+				continue
+			}
+			steps = append(steps, step)
+		}
 	})
+	steps = append(steps, deletesReplaced...)
 
 	// Resources that are present in finalState and priorState but have no Plan entry have not changed. Generate
 	// no-change ViewStep entries for these resources to that Pulumi resource counters are accurate.
 	if finalState != nil {
 		sameCounter := 0
 		finalState.VisitResourceStates(func(rs *tfsandbox.ResourceState) {
-			// TODO[pulumi/pulumi-terraform-module#61] sometimes addresses change but identity remains
+			// Resources that moved have a plan entry under their new address, so they are skipped as well.
 			addr := rs.Address()
 
 			// Skip planned resources.
@@ -162,12 +176,15 @@ func viewStepStatusCheck(
 	switch changeKind {
 
 	// Planned a create but there is no final state. Resource creation must have failed. Neither TF state nor TF
-	// plan contains the correct error message, so using a generic message for now before TF errors can be properly
-	// correlated to a resource by address.
+	// plan contains the error message; when TF reports one for the resource it replaces this generic message.
+	// Resources that were created but left tainted failed as well and are replaced by the next update.
 	case tfsandbox.Create:
 		if finalState == nil {
 			return fmt.Errorf("resource operation failed: %s missing final state", op.String())
 		}
+		if finalState.Tainted() {
+			return fmt.Errorf("resource operation failed: %s left the resource tainted", op.String())
+		}
 
 	// All these operations when successful imply the resource must exist in the final state.
 	case tfsandbox.NoOp, tfsandbox.Update:
@@ -185,6 +202,9 @@ func viewStepStatusCheck(
 		if op == pulumirpc.ViewStep_CREATE_REPLACEMENT && finalState == nil {
 			return fmt.Errorf("resource operation failed: %s missing final state", op.String())
 		}
+		if op == pulumirpc.ViewStep_CREATE_REPLACEMENT && finalState.Tainted() {
+			return fmt.Errorf("resource operation failed: %s left the resource tainted", op.String())
+		}
 
 	// These operations if successful imply the resource must not exist in the final state.
 	case tfsandbox.Delete, tfsandbox.Forget:
@@ -205,7 +225,7 @@ func viewStepForSameResource(
 	tfType := finalState.Type()
 	ty := childResourceTypeToken(packageName, tfType).String()
 	name := childResourceName(addr)
-	viewState := viewStepState(packageName, addr, tfType, finalState.AttributeValues())
+	viewState := viewStepState(packageName, addr, tfType, finalState.ProviderName(), finalState.AttributeValues())
 	return &pulumirpc.ViewStep{
 		Status: pulumirpc.ViewStep_OK,
 		Name:   name,
@@ -221,6 +241,7 @@ func viewStepsForResource(
 	rplan ResourcePlan,
 	finalState ResourceState, // may be nil when planning or failed to create
 	preview bool,
+	resourceErrors []string, // errors TF reported for this resource when applying
 ) []*pulumirpc.ViewStep {
 
 	addr := rplan.Address()
@@ -230,35 +251,56 @@ func viewStepsForResource(
 
 	var newViewState *pulumirpc.ViewStepState
 	if finalState != nil {
-		newViewState = viewStepState(packageName, addr, tfType, finalState.AttributeValues())
+		newViewState = viewStepState(packageName, addr, tfType, rplan.ProviderName(), finalState.AttributeValues())
 	} else {
 		planned, ok := rplan.PlannedValues()
 		if ok {
-			newViewState = viewStepState(packageName, addr, tfType, planned)
+			newViewState = viewStepState(packageName, addr, tfType, rplan.ProviderName(), planned)
 		}
 	}
 
+	ops := viewStepOp(rplan.ChangeKind(), rplan.Drift())
+
+	// When the address of a resource changes while it is kept, such as when a module upgrade moves it with a moved
+	// block, the view under the previous address is deleted and a view under the new address created, since views
+	// cannot be aliased. Only the views change; Terraform keeps the resource.
+	oldAddr, oldName := addr, name
+	prev, moved := rplan.PreviousAddress()
+	moved = moved && rplan.ChangeKind() != tfsandbox.Delete
+	if moved {
+		oldAddr, oldName = prev, childResourceName(prev)
+		ops = []pulumirpc.ViewStep_Op{pulumirpc.ViewStep_DELETE, pulumirpc.ViewStep_CREATE}
+	}
+
 	var oldViewState *pulumirpc.ViewStepState
 	before, hasBefore := rplan.Before()
 	if hasBefore {
-		oldViewState = viewStepState(packageName, addr, tfType, before)
+		oldViewState = viewStepState(packageName, oldAddr, tfType, rplan.ProviderName(), before)
 	}
 
 	steps := []*pulumirpc.ViewStep{}
+	var failedSteps []*pulumirpc.ViewStep
 
-	for _, op := range viewStepOp(rplan.ChangeKind(), rplan.Drift()) {
-		newViewStateToSend := newViewState
-		if op == pulumirpc.ViewStep_DELETE_REPLACED {
+	for _, op := range ops {
+		stepName, oldViewStateToSend, newViewStateToSend := name, oldViewState, newViewState
+		changeKind := rplan.ChangeKind()
+		switch {
+		case op == pulumirpc.ViewStep_DELETE_REPLACED:
 			newViewStateToSend = nil
+		case moved && op == pulumirpc.ViewStep_DELETE:
+			stepName, newViewStateToSend = oldName, nil
+		case moved && op == pulumirpc.ViewStep_CREATE:
+			// the view under the new address succeeds when the resource is in the final state, as for a create
+			oldViewStateToSend, changeKind = nil, tfsandbox.Create
 		}
 
 		step := &pulumirpc.ViewStep{
 			Status: pulumirpc.ViewStep_OK,
-			Name:   name,
+			Name:   stepName,
 			Type:   ty,
 
 			Op:  op,
-			Old: oldViewState,
+			Old: oldViewStateToSend,
 			New: newViewStateToSend,
 
 			// TODO[pulumi/pulumi-terraform-module#100] translate TF diff details to Pulumi view
@@ -270,21 +312,43 @@ func viewStepsForResource(
 		}
 
 		if !preview {
-			if err := viewStepStatusCheck(op, rplan.ChangeKind(), finalState); err != nil {
+			if err := viewStepStatusCheck(op, changeKind, finalState); err != nil {
 				step.Error = err.Error()
+				failedSteps = append(failedSteps, step)
 			}
 		}
 
 		steps = append(steps, step)
 	}
 
+	// Attribute the errors TF reported to the steps that failed, or to every step of the resource when the final
+	// state does not tell which one did, as with a failed update that leaves the prior state in place.
+	if !preview && len(resourceErrors) > 0 {
+		if len(failedSteps) == 0 {
+			failedSteps = steps
+		}
+		for _, step := range failedSteps {
+			step.Error = strings.Join(resourceErrors, "\n")
+		}
+	}
+
 	return steps
 }
 
+// viewStepState describes a child resource of a module instance. ParentType and ParentName are left unset so that the
+// engine parents every view to the module resource that owns it, unless the module configures groups of child
+// resources (see [groupViewSteps]). This nests child resources of each module instance under it in `pulumi stack` and
+// the console, with URNs such as:
+//
+//	urn:pulumi:dev::proj::randmod:index:Module$randmod:tf:random_integer::module.myrandmod.random_integer.priority
+//
+// The outputs of the view identify the Terraform resource it stands for (see [childResourceMetadata]), so that the
+// views listed by `pulumi stack export` or targeted by `pulumi state` commands can be mapped back to the module state.
 func viewStepState(
 	packageName packageName,
 	addr ResourceAddress,
 	tfType TFResourceType,
+	providerName string,
 	values resource.PropertyMap,
 ) *pulumirpc.ViewStepState {
 	ty := childResourceTypeToken(packageName, tfType).String()
@@ -293,11 +357,33 @@ func viewStepState(
 	return &pulumirpc.ViewStepState{
 		Name: name,
 		Type: ty,
-		// Everything is an input currently, as a first approximation. Outputs are empty.
-		Inputs: viewStruct(values),
+		// Everything is an input currently, as a first approximation.
+		Inputs:  viewStruct(values),
+		Outputs: viewStruct(childResourceMetadata(addr, tfType, providerName, values)),
 	}
 }
 
+// childResourceMetadata describes the Terraform resource behind a child resource view: its address, type, provider
+// and, when it has one, its ID.
+func childResourceMetadata(
+	addr ResourceAddress,
+	tfType TFResourceType,
+	providerName string,
+	values resource.PropertyMap,
+) resource.PropertyMap {
+	metadata := resource.PropertyMap{
+		"address": resource.NewStringProperty(string(addr)),
+		"type":    resource.NewStringProperty(string(tfType)),
+	}
+	if providerName != "" {
+		metadata["provider"] = resource.NewStringProperty(providerName)
+	}
+	if id, ok := values["id"]; ok && !id.IsNull() {
+		metadata["id"] = id
+	}
+	return metadata
+}
+
 func viewStepsAfterDestroy(
 	packageName packageName,
 	stateBeforeDestroy,
@@ -308,19 +394,14 @@ func viewStepsAfterDestroy(
 	stateBeforeDestroy.VisitResourceStates(func(rs ResourceState) {
 		// TODO[pulumi/pulumi-terraform-module#342]: check stateAfterDestroy to account for partial errors
 		// where not all resources were deleted.
-		ty := childResourceTypeToken(packageName, rs.Type()).String()
-		name := childResourceName(rs.Address())
+		oldViewState := viewStepState(packageName, rs.Address(), rs.Type(), rs.ProviderName(), rs.AttributeValues())
 
 		step := &pulumirpc.ViewStep{
 			Op:     pulumirpc.ViewStep_DELETE,
 			Status: pulumirpc.ViewStep_OK,
-			Type:   ty,
-			Name:   name,
-			Old: &pulumirpc.ViewStepState{
-				Type:   ty,
-				Name:   name,
-				Inputs: viewStruct(rs.AttributeValues()),
-			},
+			Type:   oldViewState.Type,
+			Name:   oldViewState.Name,
+			Old:    oldViewState,
 		}
 
 		steps = append(steps, step)
@@ -0,0 +1,343 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"fmt"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+func TestViewStepsAfterApplyAttributesErrors(t *testing.T) {
+	const (
+		okAddr    = "module.m.terraform_data.ok"
+		failsAddr = "module.m.terraform_data.fails"
+	)
+
+	created := func(address string) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    "terraform_data",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+		}
+	}
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues:   &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{created(okAddr), created(failsAddr)},
+	})
+	require.NoError(t, err)
+
+	state, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{{
+					Address:         okAddr,
+					Mode:            tfjson.ManagedResourceMode,
+					Type:            "terraform_data",
+					Name:            "ok",
+					AttributeValues: map[string]any{"id": "1"},
+				}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	steps := viewStepsAfterApply("testmod", plan, state, map[tfsandbox.ResourceAddress][]string{
+		failsAddr: {"local-exec provisioner error: exit status 1"},
+	})
+
+	errs := map[string]string{}
+	for _, step := range steps {
+		assert.Equal(t, pulumirpc.ViewStep_CREATE, step.Op)
+		errs[step.Name] = step.Error
+	}
+	assert.Equal(t, map[string]string{
+		okAddr:    "",
+		failsAddr: "local-exec provisioner error: exit status 1",
+	}, errs)
+}
+
+func TestViewStepsReportTaintedResources(t *testing.T) {
+	const addr = "module.m.terraform_data.fails"
+
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{{
+			Address: addr,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    "terraform_data",
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+		}},
+	})
+	require.NoError(t, err)
+
+	// The resource was created but its provisioner failed, so Terraform keeps it in the state as tainted.
+	state, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{{
+					Address:         addr,
+					Mode:            tfjson.ManagedResourceMode,
+					Type:            "terraform_data",
+					Name:            "fails",
+					AttributeValues: map[string]any{"id": "1"},
+					Tainted:         true,
+				}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	steps := viewStepsAfterApply("testmod", plan, state, nil)
+	require.Len(t, steps, 1)
+	assert.Equal(t, pulumirpc.ViewStep_CREATE, steps[0].Op)
+	assert.Equal(t, "resource operation failed: CREATE left the resource tainted", steps[0].Error)
+}
+
+func TestViewStepsCreateBeforeDestroyOrdering(t *testing.T) {
+	replaced := func(address string, actions ...tfjson.Action) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    "terraform_data",
+			Change:  &tfjson.Change{Actions: actions},
+		}
+	}
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			// create_before_destroy = true
+			replaced("module.m.terraform_data.a", tfjson.ActionCreate, tfjson.ActionDelete),
+			// the default lifecycle
+			replaced("module.m.terraform_data.b", tfjson.ActionDelete, tfjson.ActionCreate),
+			replaced("module.m.terraform_data.c", tfjson.ActionUpdate),
+		},
+	})
+	require.NoError(t, err)
+
+	var order []string
+	for _, step := range viewStepsPlan("testmod", plan) {
+		order = append(order, step.Name+" "+step.Op.String())
+	}
+	assert.Equal(t, []string{
+		"module.m.terraform_data.a CREATE_REPLACEMENT",
+		"module.m.terraform_data.a REPLACE",
+		"module.m.terraform_data.b DELETE_REPLACED",
+		"module.m.terraform_data.b REPLACE",
+		"module.m.terraform_data.b CREATE_REPLACEMENT",
+		"module.m.terraform_data.c UPDATE",
+		"module.m.terraform_data.a DELETE_REPLACED",
+	}, order)
+}
+
+func TestViewStepsDescribeChildResources(t *testing.T) {
+	const addr = "module.m.random_integer.priority"
+	const providerName = "registry.opentofu.org/hashicorp/random"
+
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		ResourceChanges: []*tfjson.ResourceChange{{
+			Address:      addr,
+			Mode:         tfjson.ManagedResourceMode,
+			Type:         "random_integer",
+			ProviderName: providerName,
+			Change: &tfjson.Change{
+				Actions: tfjson.Actions{tfjson.ActionUpdate},
+				Before:  map[string]any{"id": "2", "max": 10},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	state, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{{
+					Address:         addr,
+					Mode:            tfjson.ManagedResourceMode,
+					Type:            "random_integer",
+					Name:            "priority",
+					ProviderName:    providerName,
+					AttributeValues: map[string]any{"id": "2", "max": 20},
+				}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	expected := map[string]any{
+		"address":  addr,
+		"id":       "2",
+		"provider": providerName,
+		"type":     "random_integer",
+	}
+
+	steps := viewStepsAfterApply("testmod", plan, state, nil)
+	require.Len(t, steps, 1)
+	assert.Equal(t, expected, steps[0].Old.Outputs.AsMap())
+	assert.Equal(t, expected, steps[0].New.Outputs.AsMap())
+
+	// Deleting a view uses the same metadata, which the prior state of the view records.
+	steps = viewStepsAfterDestroy("testmod", state, nil)
+	require.Len(t, steps, 1)
+	assert.Equal(t, expected, steps[0].Old.Outputs.AsMap())
+}
+
+func TestViewStepsReplaceViewsOfMovedResources(t *testing.T) {
+	const oldAddr = "module.m.aws_s3_bucket.this"
+	const newAddr = "module.m.aws_s3_bucket.this[0]"
+
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{{
+					Address:         newAddr,
+					Mode:            tfjson.ManagedResourceMode,
+					Type:            "aws_s3_bucket",
+					Name:            "this",
+					Index:           0,
+					AttributeValues: map[string]any{"id": "my-bucket"},
+				}},
+			}},
+		}},
+		ResourceChanges: []*tfjson.ResourceChange{{
+			Address:         newAddr,
+			PreviousAddress: oldAddr,
+			Mode:            tfjson.ManagedResourceMode,
+			Type:            "aws_s3_bucket",
+			Change: &tfjson.Change{
+				Actions: tfjson.Actions{tfjson.ActionNoop},
+				Before:  map[string]any{"id": "my-bucket"},
+				After:   map[string]any{"id": "my-bucket"},
+			},
+		}},
+	})
+	require.NoError(t, err)
+
+	steps := viewStepsPlan("testmod", plan)
+	require.Len(t, steps, 2)
+
+	deleted := steps[0]
+	assert.Equal(t, pulumirpc.ViewStep_DELETE, deleted.Op)
+	assert.Equal(t, childResourceName(oldAddr), deleted.Name, "the view under the old address is deleted")
+	require.NotNil(t, deleted.Old)
+	assert.Nil(t, deleted.New)
+	assert.Equal(t, childResourceName(oldAddr), deleted.Old.Name)
+	assert.Equal(t, oldAddr, deleted.Old.Outputs.AsMap()["address"])
+
+	created := steps[1]
+	assert.Equal(t, pulumirpc.ViewStep_CREATE, created.Op)
+	assert.Equal(t, childResourceName(newAddr), created.Name, "a view under the new address takes its place")
+	assert.Nil(t, created.Old)
+	require.NotNil(t, created.New)
+	assert.Equal(t, childResourceName(newAddr), created.New.Name)
+	assert.Equal(t, deleted.Type, created.Type)
+	assert.Equal(t, newAddr, created.New.Outputs.AsMap()["address"])
+	assert.Equal(t, "my-bucket", created.New.Outputs.AsMap()["id"], "the resource is kept")
+}
+
+func TestViewStepsAreGroupedByComponent(t *testing.T) {
+	created := func(address, tfType string) *tfjson.ResourceChange {
+		return &tfjson.ResourceChange{
+			Address: address,
+			Mode:    tfjson.ManagedResourceMode,
+			Type:    tfType,
+			Change:  &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}},
+		}
+	}
+	planned := func(address, tfType, name string) *tfjson.StateResource {
+		return &tfjson.StateResource{Address: address, Mode: tfjson.ManagedResourceMode, Type: tfType, Name: name}
+	}
+	plan, err := tfsandbox.NewPlan(&tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{
+					planned("module.m.aws_vpc.this", "aws_vpc", "this"),
+					planned("module.m.aws_subnet.private[0]", "aws_subnet", "private"),
+					planned("module.m.aws_subnet.public[0]", "aws_subnet", "public"),
+				},
+			}},
+		}},
+		ResourceChanges: []*tfjson.ResourceChange{
+			created("module.m.aws_vpc.this", "aws_vpc"),
+			created("module.m.aws_subnet.private[0]", "aws_subnet"),
+			created("module.m.aws_subnet.public[0]", "aws_subnet"),
+		},
+	})
+	require.NoError(t, err)
+
+	inferredModule := &InferredModuleSchema{viewGroups: viewGroups{"networking": {"aws_subnet.*"}}}
+
+	parents := func(steps []*pulumirpc.ViewStep) []string {
+		var described []string
+		for _, step := range steps {
+			state := step.New
+			if state == nil {
+				state = step.Old
+			}
+			described = append(described, fmt.Sprintf("%s %s %s/%s", step.Name, step.Op, state.ParentType,
+				state.ParentName))
+		}
+		return described
+	}
+
+	steps := groupViewSteps("testmod", inferredModule, viewStepsPlan("testmod", plan))
+	assert.Equal(t, []string{
+		"networking CREATE /",
+		"module.m.aws_subnet.private[0] CREATE testmod:index:Group/networking",
+		"module.m.aws_subnet.public[0] CREATE testmod:index:Group/networking",
+		"module.m.aws_vpc.this CREATE /",
+	}, parents(steps))
+
+	state, err := tfsandbox.NewState(&tfjson.State{
+		Values: &tfjson.StateValues{RootModule: &tfjson.StateModule{
+			ChildModules: []*tfjson.StateModule{{
+				Address: "module.m",
+				Resources: []*tfjson.StateResource{{
+					Address: "module.m.aws_subnet.private[0]",
+					Mode:    tfjson.ManagedResourceMode,
+					Type:    "aws_subnet",
+					Name:    "private",
+					Index:   0,
+				}},
+			}},
+		}},
+	})
+	require.NoError(t, err)
+
+	// The group goes away with its last resource.
+	steps = groupViewSteps("testmod", inferredModule, viewStepsAfterDestroy("testmod", state, nil))
+	assert.Equal(t, []string{
+		"module.m.aws_subnet.private[0] DELETE testmod:index:Group/networking",
+		"networking DELETE /",
+	}, parents(steps))
+
+	// Without groups configured, views stay parented to the module.
+	steps = groupViewSteps("testmod", &InferredModuleSchema{}, viewStepsPlan("testmod", plan))
+	assert.Len(t, steps, 3)
+}
@@ -0,0 +1,71 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+
+	"github.com/pulumi/pulumi-terraform-module/pkg/tfsandbox"
+)
+
+// workdirCleanupPolicy selects when the working directory of a module instance is removed after an operation.
+// Working directories cache downloaded modules and providers under .terraform, so keeping them speeds up subsequent
+// operations at the cost of disk space.
+type workdirCleanupPolicy string
+
+const (
+	// workdirKeep keeps working directories for reuse and debugging. This is the default.
+	workdirKeep workdirCleanupPolicy = "keep"
+	// workdirCleanOnSuccess removes working directories after successful operations and keeps them for debugging
+	// failed ones.
+	workdirCleanOnSuccess workdirCleanupPolicy = "clean-on-success"
+	// workdirAlwaysClean removes working directories after every operation.
+	workdirAlwaysClean workdirCleanupPolicy = "always-clean"
+)
+
+func parseWorkdirCleanupPolicy(s string) (workdirCleanupPolicy, error) {
+	switch p := workdirCleanupPolicy(s); p {
+	case "":
+		return workdirKeep, nil
+	case workdirKeep, workdirCleanOnSuccess, workdirAlwaysClean:
+		return p, nil
+	default:
+		return "", fmt.Errorf("provider option %q must be one of %q, %q or %q, got %q", workdirCleanupVariableName,
+			workdirKeep, workdirCleanOnSuccess, workdirAlwaysClean, s)
+	}
+}
+
+// cleanupWorkdir removes the working directory of a module instance once an operation completed with opErr, as
+// selected by the workdirCleanup provider option. Failing to remove it does not fail the operation. Working
+// directories are kept while Terraform logs are written to them, see tfsandbox.TFLogEnabled.
+func cleanupWorkdir(ctx context.Context, logger tfsandbox.Logger, urn urn.URN, opts moduleOptions, opErr error) {
+	switch {
+	case opts.workdirCleanup == workdirAlwaysClean:
+	case opts.workdirCleanup == workdirCleanOnSuccess && opErr == nil:
+	default:
+		return
+	}
+
+	if tfsandbox.TFLogEnabled() {
+		logger.Log(ctx, tfsandbox.Debug, "Keeping the working directory, which holds the Terraform logs")
+		return
+	}
+	if err := tfsandbox.RemoveWorkdir(tfsandbox.ModuleInstanceWorkdir(opts.executor, urn)); err != nil {
+		logger.Log(ctx, tfsandbox.Warn, fmt.Sprintf("Failed to remove the working directory: %v", err))
+	}
+}
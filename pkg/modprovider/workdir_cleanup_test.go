@@ -0,0 +1,112 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modprovider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/urn"
+)
+
+func TestCleanupWorkdir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "read_only", "stub.sh"))
+	require.NoError(t, err)
+
+	failure := errors.New("apply failed")
+	tests := []struct {
+		policy  workdirCleanupPolicy
+		opErr   error
+		removed bool
+	}{
+		{policy: workdirKeep, opErr: nil, removed: false},
+		{policy: workdirCleanOnSuccess, opErr: nil, removed: true},
+		{policy: workdirCleanOnSuccess, opErr: failure, removed: false},
+		{policy: workdirAlwaysClean, opErr: nil, removed: true},
+		{policy: workdirAlwaysClean, opErr: failure, removed: true},
+	}
+
+	for _, tt := range tests {
+		name := string(tt.policy)
+		if tt.opErr != nil {
+			name += " after failure"
+		}
+		t.Run(name, func(t *testing.T) {
+			modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::" + t.Name())
+			workdir := tfsandboxWorkdir(t, stub, modURN)
+			t.Cleanup(func() { os.RemoveAll(workdir) })
+
+			cachedProvider := filepath.Join(workdir, ".terraform", "providers", "provider")
+			require.NoError(t, os.MkdirAll(filepath.Dir(cachedProvider), 0700))
+			require.NoError(t, os.WriteFile(cachedProvider, []byte{}, 0600))
+
+			logger := &recordingLogger{}
+			opts := moduleOptions{executor: stub, workdirCleanup: tt.policy}
+			cleanupWorkdir(ctx, logger, modURN, opts, tt.opErr)
+
+			if tt.removed {
+				assert.NoDirExists(t, workdir)
+			} else {
+				assert.FileExists(t, cachedProvider)
+			}
+			assert.Empty(t, logger.messages)
+		})
+	}
+}
+
+func TestCleanupWorkdirKeepsTerraformLogs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+	t.Setenv("PULUMI_TERRAFORM_MODULE_TF_LOG", "DEBUG")
+
+	stub, err := filepath.Abs(filepath.Join("testdata", "read_only", "stub.sh"))
+	require.NoError(t, err)
+	modURN := urn.URN("urn:pulumi:test::prog::simple:index:Module::m")
+	workdir := tfsandboxWorkdir(t, stub, modURN)
+	t.Cleanup(func() { os.RemoveAll(workdir) })
+
+	logger := &recordingLogger{}
+	cleanupWorkdir(context.Background(), logger, modURN,
+		moduleOptions{executor: stub, workdirCleanup: workdirAlwaysClean}, nil)
+
+	assert.DirExists(t, workdir)
+	assert.Equal(t, []string{"debug: Keeping the working directory, which holds the Terraform logs"}, logger.messages)
+}
+
+func TestParseWorkdirCleanupPolicy(t *testing.T) {
+	policy, err := parseWorkdirCleanupPolicy("")
+	require.NoError(t, err)
+	assert.Equal(t, workdirKeep, policy)
+
+	policy, err = parseWorkdirCleanupPolicy("always-clean")
+	require.NoError(t, err)
+	assert.Equal(t, workdirAlwaysClean, policy)
+
+	_, err = parseWorkdirCleanupPolicy("sometimes")
+	assert.ErrorContains(t, err, `provider option "workdirCleanup" must be one of`)
+}
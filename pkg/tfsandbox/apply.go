@@ -6,6 +6,8 @@ import (
 
 	"github.com/hashicorp/terraform-exec/tfexec"
 	tfjson "github.com/hashicorp/terraform-json"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
 
 // Apply runs the terraform apply command and returns the final state
@@ -22,9 +24,29 @@ func (t *ModuleRuntime) Apply(ctx context.Context, logger Logger, opts RefreshOp
 	return s, applyErr
 }
 
+// ApplyError is the error returned by Apply when the apply command fails. The error diagnostics reported by the
+// executor are logged as they happen and are not necessarily part of the message of the underlying error, so they are
+// also kept here for callers that need to inspect them.
+type ApplyError struct {
+	Err         error
+	Diagnostics []string
+
+	// ResourceErrors are the error diagnostics attributed to the child resources that failed, keyed by address.
+	ResourceErrors map[ResourceAddress][]string
+}
+
+func (e *ApplyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
 // Apply runs the terraform apply command and returns the final state
 func (t *ModuleRuntime) apply(ctx context.Context, logger Logger, opts RefreshOpts) (*tfjson.State, error) {
-	logWriter := newJSONLogPipe(ctx, logger)
+	recorder := &diagnosticsRecorder{Logger: logger}
+	logWriter := newJSONLogPipe(ctx, recorder)
 	defer logWriter.Close()
 
 	aOpts := []tfexec.ApplyOption{}
@@ -35,17 +57,31 @@ func (t *ModuleRuntime) apply(ctx context.Context, logger Logger, opts RefreshOp
 	if opts.RefreshOnly {
 		aOpts = append(aOpts, tfexec.RefreshOnly(true))
 	}
+	if len(opts.DestroyTargets) > 0 {
+		aOpts = append(aOpts, tfexec.Destroy(true))
+		for _, target := range opts.DestroyTargets {
+			aOpts = append(aOpts, tfexec.Target(target))
+		}
+	}
 
 	applyErr := t.tf.ApplyJSON(ctx, logWriter, t.applyOptions(aOpts...)...)
 	// if the apply failed just log it to debug logs and continue
 	// we want to return and process the partial state from a failed apply
 	if applyErr != nil {
 		logger.Log(ctx, Debug, fmt.Sprintf("error running tofu apply: %v", applyErr))
+		contract.IgnoreError(logWriter.Close())
+		applyErr = &ApplyError{
+			Err:            applyErr,
+			Diagnostics:    recorder.recorded(),
+			ResourceErrors: recorder.resourceErrors(),
+		}
 	}
 
 	// NOTE: the recommended default from terraform-json is to set JSONNumber=true
 	// otherwise some number values will lose precision when converted to float64
-	state, err := t.tf.Show(ctx, t.showOptions(tfexec.JSONNumber(true))...)
+	//
+	// The state is read even if the apply was cancelled, as it records the resources changed before that.
+	state, err := t.tf.Show(context.WithoutCancel(ctx), t.showOptions(tfexec.JSONNumber(true))...)
 	if err != nil {
 		return nil, fmt.Errorf("error running tofu show: %w", err)
 	}
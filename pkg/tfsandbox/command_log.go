@@ -0,0 +1,116 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// runningCommandPrefix prefixes the messages tfexec logs before running a command.
+const runningCommandPrefix = "[INFO] running Terraform command: "
+
+// redactedValue replaces secret values in logged command lines.
+const redactedValue = "[secret]"
+
+// commandLogger receives the command lines run by tfexec and logs them at the Debug level together with the working
+// directory and the TF_* variables of the environment, so that users can reproduce the commands manually. Values that
+// may hold secrets, such as variable assignments and registry tokens, are redacted.
+type commandLogger struct {
+	ctx     context.Context
+	logger  Logger
+	runtime *ModuleRuntime
+}
+
+func (l *commandLogger) Printf(format string, v ...any) {
+	msg := fmt.Sprintf(format, v...)
+	command, ok := strings.CutPrefix(msg, runningCommandPrefix)
+	if !ok {
+		l.logger.Log(l.ctx, Debug, msg)
+		return
+	}
+
+	env := maps.Clone(l.runtime.env)
+	if env == nil {
+		env = envMap(os.Environ())
+	}
+	if l.runtime.tfLogPath != "" {
+		env["TF_LOG"] = os.Getenv(tfLogEnvironmentVariable)
+		env["TF_LOG_PATH"] = l.runtime.tfLogPath
+	}
+	commandLine := append(redactEnv(env), redactArgs(strings.Fields(command))...)
+	l.logger.Log(l.ctx, Debug, fmt.Sprintf("Running `%s` in %s", strings.Join(commandLine, " "), l.runtime.WorkingDir()))
+}
+
+// logCommands makes the runtime log the commands it runs to logger.
+func (t *ModuleRuntime) logCommands(ctx context.Context, logger Logger) {
+	t.tf.SetLogger(&commandLogger{ctx: ctx, logger: logger, runtime: t})
+}
+
+// redactEnv renders the TF_* variables of env as sorted NAME=value assignments, redacting those that may be secret.
+func redactEnv(env map[string]string) []string {
+	var assignments []string
+	for name, value := range env {
+		if !strings.HasPrefix(name, "TF_") {
+			continue
+		}
+		if sensitiveEnvName(name) {
+			value = redactedValue
+		}
+		assignments = append(assignments, name+"="+value)
+	}
+	slices.Sort(assignments)
+	return assignments
+}
+
+func sensitiveEnvName(name string) bool {
+	if strings.HasPrefix(name, "TF_TOKEN_") || strings.HasPrefix(name, "TF_VAR_") {
+		return true
+	}
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{"TOKEN", "SECRET", "PASSWORD", "CREDENTIALS"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactArgs redacts the values of the -var and -backend-config flags of a command line, which may be secret.
+func redactArgs(args []string) []string {
+	redacted := make([]string, 0, len(args))
+	inValue := false
+	for _, arg := range args {
+		if inValue {
+			inValue = false
+			continue
+		}
+		switch {
+		case arg == "-var" || arg == "-backend-config":
+			redacted = append(redacted, arg, redactedValue)
+			inValue = true
+		case strings.HasPrefix(arg, "-var=") || strings.HasPrefix(arg, "-backend-config="):
+			flag, _, _ := strings.Cut(arg, "=")
+			redacted = append(redacted, flag+"="+redactedValue)
+		default:
+			redacted = append(redacted, arg)
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,151 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type commandRecordingLogger struct {
+	debug []string
+}
+
+func (l *commandRecordingLogger) Log(_ context.Context, level LogLevel, msg string) {
+	if level == Debug {
+		l.debug = append(l.debug, msg)
+	}
+}
+
+func (l *commandRecordingLogger) LogStatus(context.Context, LogLevel, string) {}
+
+func TestCommandsAreLogged(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "command_log", "tofu"))
+	require.NoError(t, err)
+	t.Setenv("TF_TOKEN_registry_example_com", "super-secret")
+	t.Setenv("TF_LOG", "WARN")
+
+	logger := &commandRecordingLogger{}
+	tf, err := NewRuntimeFromExecutable(ctx, logger, Workdir{t.Name()}, nil, stub)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+
+	_, err = tf.Plan(ctx, DiscardLogger)
+	require.Error(t, err)
+
+	var planCommand string
+	for _, msg := range logger.debug {
+		if strings.Contains(msg, "tofu plan") {
+			planCommand = msg
+		}
+	}
+	require.NotEmpty(t, planCommand, "expected the plan command line in %v", logger.debug)
+	assert.Contains(t, planCommand, "TF_LOG=WARN")
+	assert.Contains(t, planCommand, "TF_TOKEN_registry_example_com=[secret]")
+	assert.Contains(t, planCommand, stub+" plan -no-color -input=false -detailed-exitcode")
+	assert.True(t, strings.HasSuffix(planCommand, "` in "+tf.WorkingDir()), planCommand)
+	assert.NotContains(t, planCommand, "super-secret")
+}
+
+func TestCommandsAreLoggedWithTheEnvironmentTheyRunWith(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "command_log", "tofu"))
+	require.NoError(t, err)
+
+	logger := &commandRecordingLogger{}
+	tf, err := NewRuntimeFromExecutable(ctx, logger, Workdir{t.Name()}, nil, stub)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+	cacheDir := t.TempDir()
+	require.NoError(t, tf.UsePluginCache(cacheDir))
+	// the environment of the commands is captured by UsePluginCache, so later changes do not reach them
+	t.Setenv("TF_IN_AUTOMATION_LATE", "1")
+
+	_, err = tf.Plan(ctx, DiscardLogger)
+	require.Error(t, err)
+
+	var planCommand string
+	for _, msg := range logger.debug {
+		if strings.Contains(msg, "tofu plan") {
+			planCommand = msg
+		}
+	}
+	require.NotEmpty(t, planCommand, "expected the plan command line in %v", logger.debug)
+	assert.Contains(t, planCommand, "TF_PLUGIN_CACHE_DIR="+cacheDir)
+	assert.NotContains(t, planCommand, "TF_IN_AUTOMATION_LATE")
+}
+
+func TestTFLogIsPassedThrough(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "command_log", "tofu"))
+	require.NoError(t, err)
+
+	newRuntime := func(t *testing.T) *ModuleRuntime {
+		tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{t.Name()}, nil, stub)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+		_, err = tf.Plan(ctx, DiscardLogger)
+		require.Error(t, err)
+		return tf
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		t.Setenv(tfLogEnvironmentVariable, "DEBUG")
+		tf := newRuntime(t)
+
+		logs, err := os.ReadFile(filepath.Join(tf.WorkingDir(), tfLogFileName))
+		require.NoError(t, err)
+		assert.Contains(t, string(logs), "[DEBUG] running plan")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		// TF_LOG itself is cleared by tfexec
+		t.Setenv("TF_LOG", "DEBUG")
+		tf := newRuntime(t)
+
+		assert.NoFileExists(t, filepath.Join(tf.WorkingDir(), tfLogFileName))
+	})
+}
+
+func TestRedactArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"plan", "-var", redactedValue, "-backend-config=" + redactedValue, "-lock=false"},
+		redactArgs([]string{"plan", "-var", "password=hunter2", "-backend-config=token=abc", "-lock=false"}))
+
+	// positional arguments after a value are kept
+	assert.Equal(t,
+		[]string{"apply", "-var", redactedValue, "plan.out"},
+		redactArgs([]string{"apply", "-var", "password=hunter2", "plan.out"}))
+}
@@ -0,0 +1,63 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestApplyWaitsForModuleDependencies(t *testing.T) {
+	ctx := context.Background()
+
+	tofu := newTestTofu(t)
+	var buffer bytes.Buffer
+	logger := &testLogger{r: &buffer}
+
+	logFile := filepath.Join(t.TempDir(), "order.log")
+	ms := TFModuleSource(filepath.Join(getCwd(t), "testdata", "modules", "log_step"))
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{
+		"log_file": resource.NewStringProperty(logFile),
+	}, nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{
+		// Without the dependency the module would run while the gate is still sleeping.
+		ExtraFiles: map[string]string{"gate.tf": fmt.Sprintf(`
+resource "terraform_data" "gate" {
+  provisioner "local-exec" {
+    command = "sleep 2 && echo gate >> %s"
+  }
+}
+`, logFile)},
+		DependsOn: []string{"terraform_data.gate"},
+	})
+	require.NoError(t, err, "error creating tf file")
+
+	err = tofu.Init(ctx, logger)
+	require.NoErrorf(t, err, "error running tofu init: %s", buffer.String())
+	_, err = tofu.Apply(ctx, logger, RefreshOpts{})
+	require.NoErrorf(t, err, "error running tofu apply: %s", buffer.String())
+
+	order, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Equal(t, "gate\nmodule\n", string(order))
+}
@@ -0,0 +1,64 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestApplyDestroyTargets(t *testing.T) {
+	ctx := context.Background()
+
+	tofu := newTestTofu(t)
+	var buffer bytes.Buffer
+	logger := &testLogger{r: &buffer}
+
+	ms := TFModuleSource(filepath.Join(getCwd(t), "testdata", "modules", "two_resources"))
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{}, nil, /* outputs */
+		map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err, "error creating tf file")
+
+	err = tofu.Init(ctx, logger)
+	require.NoErrorf(t, err, "error running tofu init: %s", buffer.String())
+	_, err = tofu.Apply(ctx, logger, RefreshOpts{})
+	require.NoErrorf(t, err, "error running tofu apply: %s", buffer.String())
+
+	first := ResourceAddress("module.test.terraform_data.first")
+	second := ResourceAddress("module.test.terraform_data.second")
+	targets := []string{string(first)}
+
+	plan, err := tofu.PlanDestroyTargets(ctx, logger, targets)
+	require.NoErrorf(t, err, "error running tofu plan: %s", buffer.String())
+	firstPlan, ok := plan.FindResourcePlan(first)
+	require.True(t, ok, "expected a plan for %s", first)
+	assert.Equal(t, Delete, firstPlan.ChangeKind())
+	_, ok = plan.FindResourcePlan(second)
+	assert.False(t, ok, "expected %s not to be planned", second)
+
+	state, err := tofu.Apply(ctx, logger, RefreshOpts{NoRefresh: true, DestroyTargets: targets})
+	require.NoErrorf(t, err, "error running tofu apply: %s", buffer.String())
+	_, ok = state.FindResourceState(first)
+	assert.False(t, ok, "expected %s to be destroyed", first)
+	_, ok = state.FindResourceState(second)
+	assert.True(t, ok, "expected %s to be kept", second)
+}
@@ -17,6 +17,8 @@ package tfsandbox
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
@@ -61,17 +63,47 @@ func (p *ResourcePlan) Drift() bool {
 	return p.drift
 }
 
-// TODO sometimes address changes while identity remains the same, e.g see PreviousAddress. The call sites need to be
-// audited to make sure they handle this correctly.
+// The address of the resource after the change. The address may change while the identity of the resource remains the
+// same, see PreviousAddress.
 func (p *ResourcePlan) Address() ResourceAddress {
 	return ResourceAddress(p.resourceChange.Address)
 }
 
+// The address the resource had before the change when the change moves it, as moved blocks in the module do. The
+// resource is then kept rather than replaced.
+func (p *ResourcePlan) PreviousAddress() (ResourceAddress, bool) {
+	prev := p.resourceChange.PreviousAddress
+	if prev == "" || prev == p.resourceChange.Address {
+		return "", false
+	}
+	return ResourceAddress(prev), true
+}
+
 // The type of the resource undergoing changes.
 func (p *ResourcePlan) Type() TFResourceType {
 	return TFResourceType(p.resourceChange.Type)
 }
 
+// The address of the provider managing the resource, such as registry.opentofu.org/hashicorp/random.
+func (p *ResourcePlan) ProviderName() string {
+	return p.resourceChange.ProviderName
+}
+
+// The address of the module declaring the resource, such as module.mymod, or empty for the root module.
+func (p *ResourcePlan) ModuleAddress() string {
+	return p.resourceChange.ModuleAddress
+}
+
+// The name of the resource within its module, such as this for aws_s3_bucket.this.
+func (p *ResourcePlan) Name() string {
+	return p.resourceChange.Name
+}
+
+// The instance key of a resource expanded with count or for_each, see ResourceState.Index.
+func (p *ResourcePlan) Index() any {
+	return p.resourceChange.Index
+}
+
 // The new values planned for the resource. When resource is being removed it is not available, and will return false.
 func (p *ResourcePlan) PlannedValues() (resource.PropertyMap, bool) {
 	if p.plannedState == nil {
@@ -106,6 +138,36 @@ func (p *ResourcePlan) Before() (resource.PropertyMap, bool) {
 	return pm, true
 }
 
+// ReplacePaths returns the paths of the attributes that force the resource to be replaced, such as ami or tags.Name.
+// Paths with no steps that can be represented are left out.
+func (p *ResourcePlan) ReplacePaths() []resource.PropertyPath {
+	change := p.resourceChange.Change
+	if change == nil {
+		return nil
+	}
+	var paths []resource.PropertyPath
+	for _, rawPath := range change.ReplacePaths {
+		steps, ok := rawPath.([]any)
+		if !ok || len(steps) == 0 {
+			continue
+		}
+		path := resource.PropertyPath{}
+		for _, step := range steps {
+			switch step := step.(type) {
+			case string:
+				path = append(path, step)
+			case float64:
+				path = append(path, int(step))
+			}
+		}
+		if len(path) == 0 {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
 // Describes what change is being planned.
 func (p *ResourcePlan) ChangeKind() ChangeKind {
 	contract.Assertf(p.resourceChange != nil, "cannot determine ChangeKind")
@@ -141,6 +203,30 @@ type ResourceState struct {
 func (s *ResourceState) Address() ResourceAddress { return ResourceAddress(s.stateResource.Address) }
 func (s *ResourceState) Type() TFResourceType     { return TFResourceType(s.stateResource.Type) }
 
+// The address of the provider managing the resource, see ResourcePlan.ProviderName.
+func (s *ResourceState) ProviderName() string { return s.stateResource.ProviderName }
+
+// The address of the module declaring the resource, such as module.mymod, or empty for the root module.
+func (s *ResourceState) ModuleAddress() string {
+	// The state does not record the module address separately, but it prefixes the address of the resource.
+	resourcePart := "." + s.stateResource.Type + "." + s.stateResource.Name
+	if i := strings.LastIndex(s.stateResource.Address, resourcePart); i > 0 {
+		return s.stateResource.Address[:i]
+	}
+	return ""
+}
+
+// The name of the resource within its module, such as this for aws_s3_bucket.this.
+func (s *ResourceState) Name() string { return s.stateResource.Name }
+
+// The instance key of a resource expanded with count or for_each: a number for count, a string for for_each, or nil
+// for resources that are not expanded.
+func (s *ResourceState) Index() any { return s.stateResource.Index }
+
+// Whether the resource is tainted, as happens when creating it failed after the provider recorded its ID. Terraform
+// replaces tainted resources on the next apply.
+func (s *ResourceState) Tainted() bool { return s.stateResource.Tainted }
+
 func (s *ResourceState) AttributeValues() resource.PropertyMap {
 	return extractPropertyMapFromState(*s.stateResource)
 }
@@ -155,9 +241,21 @@ type Plan struct {
 //	return p.hasDrift
 //}
 
+// VisitResourcePlans visits the planned resources in the order of their addresses.
 func (p *Plan) VisitResourcePlans(visitor func(*ResourcePlan)) {
-	for _, rp := range p.byAddress {
-		visitor(rp)
+	for _, addr := range slices.Sorted(maps.Keys(p.byAddress)) {
+		visitor(p.byAddress[addr])
+	}
+}
+
+// VisitDataSources visits the data sources in the planned values in the order of their addresses, see
+// State.VisitDataSources.
+func (p *Plan) VisitDataSources(visitor func(*ResourceState)) {
+	if p.rawPlan.PlannedValues == nil {
+		return
+	}
+	for _, ds := range dataSourcesOfStateModule(p.rawPlan.PlannedValues.RootModule) {
+		visitor(ds)
 	}
 }
 
@@ -252,7 +350,7 @@ func (p *Plan) Outputs() resource.PropertyMap {
 		}
 		key := PulumiTopLevelKey(outputKey)
 		if isAfterUnknown(output.AfterUnknown) {
-			outputs[key] = unknown()
+			outputs[key] = unknownOfShape(output.AfterUnknown)
 		} else {
 			val := resource.NewPropertyValueRepl(output.After, nil, replaceJSONNumberValue)
 			if p.outputIsSecret(outputKey) {
@@ -300,6 +398,17 @@ func (s *State) VisitResourceStates(visitor func(*ResourceState)) {
 	}
 }
 
+// VisitDataSources visits the data sources read by the module in the order of their addresses. They are not visited by
+// VisitResourceStates as the module does not manage them.
+func (s *State) VisitDataSources(visitor func(*ResourceState)) {
+	if s.rawState == nil || s.rawState.Values == nil {
+		return
+	}
+	for _, ds := range dataSourcesOfStateModule(s.rawState.Values.RootModule) {
+		visitor(ds)
+	}
+}
+
 func (s *State) FindResourceState(addr ResourceAddress) (*ResourceState, bool) {
 	st, ok := s.byAddress[addr]
 	return st, ok
@@ -334,6 +443,18 @@ func (s *State) Outputs() resource.PropertyMap {
 		outputs[key] = val
 	}
 
+	// Terraform does not persist outputs that evaluate to null in the state, but their is_secret companions are
+	// still present. Use these to represent null outputs explicitly rather than dropping them.
+	for outputKey := range s.rawState.Values.Outputs {
+		if !isInternalOutputResource(outputKey) {
+			continue
+		}
+		key := PulumiTopLevelKey(strings.TrimPrefix(outputKey, terraformIsSecretOutputPrefix))
+		if _, ok := outputs[key]; !ok {
+			outputs[key] = resource.NewNullProperty()
+		}
+	}
+
 	return outputs
 }
 
@@ -352,6 +473,23 @@ func unknown() resource.PropertyValue {
 	})
 }
 
+// unknownOfShape returns an unknown whose element matches the shape of a partially unknown AfterUnknown value, so that
+// an output that is known to be a list or an object is not reported as an unknown string.
+func unknownOfShape(afterUnknown interface{}) resource.PropertyValue {
+	switch afterUnknown.(type) {
+	case []interface{}:
+		return resource.NewComputedProperty(resource.Computed{
+			Element: resource.NewArrayProperty([]resource.PropertyValue{}),
+		})
+	case map[string]interface{}:
+		return resource.NewComputedProperty(resource.Computed{
+			Element: resource.NewObjectProperty(resource.PropertyMap{}),
+		})
+	default:
+		return unknown()
+	}
+}
+
 // isInternalOutputResource returns true if the resource is an internal is_secret output
 // which is used to keep track of the secretness of the output.
 func isInternalOutputResource(name string) bool {
@@ -742,6 +742,30 @@ func Test_Plan_Outputs_PartialUnknown(t *testing.T) {
 	val, ok := outputs["fqdn"]
 	require.True(t, ok)
 	assert.True(t, val.IsComputed(), "expected fqdn to be unknown/computed, got %v", val)
+	assert.True(t, val.Input().Element.IsObject(), "expected fqdn to be an unknown object, got %v", val)
+}
+
+func Test_Plan_Outputs_UnknownShapes(t *testing.T) {
+	rawPlan := &tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		OutputChanges: map[string]*tfjson.Change{
+			"domain_name": {AfterUnknown: true},
+			terraformIsSecretOutputPrefix + "domain_name": {AfterUnknown: true},
+			"endpoints": {AfterUnknown: []interface{}{true, false}},
+			terraformIsSecretOutputPrefix + "endpoints": {AfterUnknown: true},
+			"bucket":                                 {AfterUnknown: map[string]interface{}{"arn": true}},
+			terraformIsSecretOutputPrefix + "bucket": {AfterUnknown: true},
+		},
+	}
+	p, err := NewPlan(rawPlan)
+	require.NoError(t, err)
+
+	outputs := p.Outputs()
+	assert.Equal(t, resource.PropertyMap{
+		"domain_name": resource.MakeComputed(resource.NewStringProperty("")),
+		"endpoints":   resource.MakeComputed(resource.NewArrayProperty([]resource.PropertyValue{})),
+		"bucket":      resource.MakeComputed(resource.NewObjectProperty(resource.PropertyMap{})),
+	}, outputs)
 }
 
 // Defense-in-depth: a non-bool After value on the is_secret companion should
@@ -783,3 +807,41 @@ func Test_State_outputIsSecret_NonBoolValue(t *testing.T) {
 		assert.False(t, s.outputIsSecret("weird"))
 	})
 }
+
+// Terraform drops null outputs from the state, leaving only the is_secret companion behind. These should still
+// come through as explicit nulls.
+func Test_State_Outputs_Null(t *testing.T) {
+	rawState := &tfjson.State{
+		Values: &tfjson.StateValues{
+			Outputs: map[string]*tfjson.StateOutput{
+				terraformIsSecretOutputPrefix + "dropped": {Value: false},
+				"explicit": {Value: nil},
+				terraformIsSecretOutputPrefix + "explicit": {Value: false},
+				"present": {Value: "value"},
+				terraformIsSecretOutputPrefix + "present": {Value: false},
+			},
+		},
+	}
+	s, err := NewState(rawState)
+	require.NoError(t, err)
+
+	assert.Equal(t, resource.PropertyMap{
+		"dropped":  resource.NewNullProperty(),
+		"explicit": resource.NewNullProperty(),
+		"present":  resource.NewStringProperty("value"),
+	}, s.Outputs())
+}
+
+func Test_Plan_Outputs_Null(t *testing.T) {
+	rawPlan := &tfjson.Plan{
+		PlannedValues: &tfjson.StateValues{RootModule: &tfjson.StateModule{}},
+		OutputChanges: map[string]*tfjson.Change{
+			"x":                                 {After: nil, AfterUnknown: false},
+			terraformIsSecretOutputPrefix + "x": {After: false, AfterUnknown: false},
+		},
+	}
+	p, err := NewPlan(rawPlan)
+	require.NoError(t, err)
+
+	assert.Equal(t, resource.PropertyMap{"x": resource.NewNullProperty()}, p.Outputs())
+}
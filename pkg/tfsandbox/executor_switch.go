@@ -0,0 +1,195 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pulumi/opentofu/configs"
+)
+
+// Hosts of the registries that providers without an explicit host in their source address resolve to.
+const (
+	terraformRegistryHost = "registry.terraform.io"
+	tofuRegistryHost      = "registry.opentofu.org"
+)
+
+var (
+	// stateProviderRef matches the provider["<host>/<namespace>/<type>"] references of the resources in a state.
+	stateProviderRef = regexp.MustCompile(`provider\[\\"([^/\\"]+)/([^/\\"]+)/([^/\\"]+)\\"\]`)
+	// lockedProvider matches the provider blocks of a dependency lock file.
+	lockedProvider = regexp.MustCompile(`(?m)^provider "([^/"]+)/([^"]+)"`)
+)
+
+// pendingState is a state and lock file pushed from the other executor, which init adapts to the executor of the
+// runtime before writing the state, see adaptToExecutor.
+type pendingState struct {
+	state json.RawMessage
+	lock  []byte
+}
+
+// detectedRegistryHosts caches the registry hosts of custom executables by path, see detectRegistryHost.
+var detectedRegistryHosts sync.Map
+
+// providerRegistryHost is the registry host that the executor of the runtime resolves providers such as
+// hashicorp/aws to. NewTofu and NewTerraform know it already, it is only detected for custom executables.
+func (t *ModuleRuntime) providerRegistryHost(ctx context.Context) string {
+	if t.registryHost == "" {
+		t.registryHost = detectRegistryHost(ctx, t.executable)
+	}
+	return t.registryHost
+}
+
+// detectRegistryHost tells OpenTofu from Terraform by the product name that `version` prints first, such as
+// "OpenTofu v1.9.0". The name of the executable decides when it cannot report its version.
+func detectRegistryHost(ctx context.Context, executable string) string {
+	if host, ok := detectedRegistryHosts.Load(executable); ok {
+		return host.(string)
+	}
+	host := terraformRegistryHost
+	out, err := exec.CommandContext(ctx, executable, "version").Output()
+	switch {
+	case err == nil && bytes.HasPrefix(out, []byte("OpenTofu ")):
+		host = tofuRegistryHost
+	case err == nil && bytes.HasPrefix(out, []byte("Terraform ")):
+	case strings.Contains(filepath.Base(executable), "tofu"):
+		host = tofuRegistryHost
+	}
+	detectedRegistryHosts.Store(executable, host)
+	return host
+}
+
+func otherRegistryHost(host string) string {
+	if host == terraformRegistryHost {
+		return tofuRegistryHost
+	}
+	return terraformRegistryHost
+}
+
+// fromOtherExecutor reports whether a state or lock file refer to providers of the registry of the other executor, as
+// after switching between terraform and tofu.
+func (t *ModuleRuntime) fromOtherExecutor(ctx context.Context, state json.RawMessage, lock []byte) bool {
+	other := otherRegistryHost(t.providerRegistryHost(ctx))
+	return bytes.Contains(state, []byte(`provider[\"`+other+`/`)) ||
+		bytes.Contains(lock, []byte(`provider "`+other+`/`))
+}
+
+// adaptToExecutor makes a state and lock file produced by terraform usable with tofu and vice versa, once init
+// installed the modules of the working directory.
+//
+// The two executors resolve providers without an explicit host to their own registries, so the same module requires
+// registry.terraform.io/hashicorp/aws under terraform and registry.opentofu.org/hashicorp/aws under tofu. Resource
+// providers recorded in the state are moved to the registry of the current executor, otherwise the executor would
+// consider the resources orphaned by a provider that is no longer configured. Providers whose source names a registry
+// host in the modules are left as they are, since both executors resolve them alike. The state is held back during
+// init, which would otherwise install the providers it refers to from the other registry.
+//
+// The lock file is kept: init keeps the versions it pins for providers of an explicit registry, and selects the other
+// providers again from the registry of the current executor, possibly at newer versions allowed by the module
+// constraints, which is reported as a warning.
+func (t *ModuleRuntime) adaptToExecutor(ctx context.Context, log Logger) error {
+	pending := t.pendingState
+	if pending == nil {
+		return nil
+	}
+	t.pendingState = nil
+
+	explicit, err := explicitProviderSources(t.WorkingDir())
+	if err != nil {
+		return fmt.Errorf("error reading the provider requirements of the module: %w", err)
+	}
+	host := t.providerRegistryHost(ctx)
+	from := otherRegistryHost(host)
+	if reselected := reselectedProviders(pending.lock, from, explicit); len(reselected) > 0 {
+		log.Log(ctx, Warn, fmt.Sprintf("The dependency lock file was written by another executor; init selected "+
+			"the providers %s again from %s, possibly at newer versions", strings.Join(reselected, ", "), host))
+	}
+	return t.pushState(ctx, moveStateProviders(pending.state, from, host, explicit))
+}
+
+// explicitProviderSources returns the providers that the modules of the working directory require from a source
+// naming a registry host, such as registry.terraform.io/hashicorp/aws, by their fully qualified address.
+func explicitProviderSources(workingDir string) (map[string]bool, error) {
+	dirs := []string{workingDir}
+	manifest, err := ReadModuleManifest(workingDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, m := range manifest.Modules {
+		if m.Key == "" {
+			// the root module is the working directory
+			continue
+		}
+		dir := m.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workingDir, dir)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	explicit := map[string]bool{}
+	for _, dir := range dirs {
+		mod, diags := configs.NewParser(nil).LoadConfigDir(dir, configs.NewStaticModuleCall(nil, nil, "", ""))
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("error while loading module %s: %w", dir, diags)
+		}
+		if mod.ProviderRequirements == nil {
+			continue
+		}
+		for _, rp := range mod.ProviderRequirements.RequiredProviders {
+			if strings.Count(rp.Source, "/") == 2 {
+				explicit[rp.Type.String()] = true
+			}
+		}
+	}
+	return explicit, nil
+}
+
+// moveStateProviders rewrites provider["<from>/ns/name"] references of the resources in the state to
+// provider["<to>/ns/name"], except for the providers in keep.
+func moveStateProviders(state json.RawMessage, from, to string, keep map[string]bool) json.RawMessage {
+	return stateProviderRef.ReplaceAllFunc(state, func(ref []byte) []byte {
+		m := stateProviderRef.FindSubmatch(ref)
+		host, namespace, name := string(m[1]), string(m[2]), string(m[3])
+		if host != from || keep[host+"/"+namespace+"/"+name] {
+			return ref
+		}
+		return []byte(`provider[\"` + to + "/" + namespace + "/" + name + `\"]`)
+	})
+}
+
+// reselectedProviders lists the providers that a lock file pins in the registry from and that init selects again
+// from the registry of the current executor, that is all but the ones in explicit.
+func reselectedProviders(lock []byte, from string, explicit map[string]bool) []string {
+	var reselected []string
+	for _, m := range lockedProvider.FindAllSubmatch(lock, -1) {
+		host, name := string(m[1]), string(m[2])
+		if host == from && !explicit[host+"/"+name] {
+			reselected = append(reselected, name)
+		}
+	}
+	slices.Sort(reselected)
+	return reselected
+}
@@ -0,0 +1,144 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DependencyGraph is the graph of the resources of a module instance and the modules declaring them. Modules point to
+// the resources and modules they declare, and resources point to the resources they depend on.
+//
+// Dependencies between module instances and other Pulumi resources are tracked by the Pulumi engine and do not appear
+// here.
+type DependencyGraph struct {
+	Nodes []DependencyGraphNode `json:"nodes"`
+	Edges []DependencyGraphEdge `json:"edges"`
+}
+
+// DependencyGraphNode is a module or a resource, identified by its address such as module.mymod or
+// module.mymod.aws_s3_bucket.this[0].
+type DependencyGraphNode struct {
+	Address string `json:"address"`
+	Kind    string `json:"kind"`
+}
+
+// DependencyGraphEdge is either a module declaring a resource or module, or a resource depending on another.
+type DependencyGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+const (
+	DependencyGraphModuleNode   = "module"
+	DependencyGraphResourceNode = "resource"
+	DependencyGraphDeclaresEdge = "declares"
+	DependencyGraphDependsEdge  = "depends_on"
+)
+
+// instanceKeyPattern matches the count and for_each keys of resource and module instances, as in
+// module.mymod["a"].aws_s3_bucket.this[0].
+var instanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// DependencyGraph computes the dependency graph of the resources in the state.
+//
+// Terraform records dependencies by configuration address, without instance keys, so a resource depending on a
+// resource expanded with count or for_each depends on all of its instances.
+func (s *State) DependencyGraph() *DependencyGraph {
+	g := &DependencyGraph{Nodes: []DependencyGraphNode{}, Edges: []DependencyGraphEdge{}}
+
+	addresses := []string{}
+	byConfigAddress := map[string][]string{}
+	for addr := range s.byAddress {
+		addresses = append(addresses, string(addr))
+		configAddress := instanceKeyPattern.ReplaceAllString(string(addr), "")
+		byConfigAddress[configAddress] = append(byConfigAddress[configAddress], string(addr))
+	}
+	slices.Sort(addresses)
+
+	modules := map[string]struct{}{}
+	var addModule func(address string)
+	addModule = func(address string) {
+		if _, ok := modules[address]; ok {
+			return
+		}
+		modules[address] = struct{}{}
+		if parent := parentModuleAddress(address); parent != "" {
+			addModule(parent)
+			g.Edges = append(g.Edges, DependencyGraphEdge{From: parent, To: address, Kind: DependencyGraphDeclaresEdge})
+		}
+	}
+
+	for _, addr := range addresses {
+		st := s.byAddress[ResourceAddress(addr)]
+		g.Nodes = append(g.Nodes, DependencyGraphNode{Address: addr, Kind: DependencyGraphResourceNode})
+		if module := st.ModuleAddress(); module != "" {
+			addModule(module)
+			g.Edges = append(g.Edges, DependencyGraphEdge{From: module, To: addr, Kind: DependencyGraphDeclaresEdge})
+		}
+		for _, dependency := range slices.Sorted(slices.Values(st.stateResource.DependsOn)) {
+			targets := slices.Clone(byConfigAddress[dependency])
+			slices.Sort(targets)
+			for _, target := range targets {
+				g.Edges = append(g.Edges, DependencyGraphEdge{From: addr, To: target, Kind: DependencyGraphDependsEdge})
+			}
+		}
+	}
+
+	for _, module := range slices.Sorted(maps.Keys(modules)) {
+		g.Nodes = append(g.Nodes, DependencyGraphNode{Address: module, Kind: DependencyGraphModuleNode})
+	}
+
+	slices.SortStableFunc(g.Edges, func(a, b DependencyGraphEdge) int {
+		return strings.Compare(a.From+"\x00"+a.To, b.From+"\x00"+b.To)
+	})
+	return g
+}
+
+// parentModuleAddress returns the address of the module declaring the module at address, or empty when it is
+// declared by the root module.
+func parentModuleAddress(address string) string {
+	if i := strings.LastIndex(address, ".module."); i > 0 {
+		return address[:i]
+	}
+	return ""
+}
+
+// DOT renders the graph in the Graphviz DOT language, for example to visualize it with dot -Tsvg.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph {\n")
+	for _, n := range g.Nodes {
+		shape := "box"
+		if n.Kind == DependencyGraphModuleNode {
+			shape = "folder"
+		}
+		fmt.Fprintf(&b, "  %q [shape=%s];\n", n.Address, shape)
+	}
+	for _, e := range g.Edges {
+		style := "solid"
+		if e.Kind == DependencyGraphDeclaresEdge {
+			style = "dashed"
+		}
+		fmt.Fprintf(&b, "  %q -> %q [style=%s];\n", e.From, e.To, style)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
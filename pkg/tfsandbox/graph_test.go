@@ -0,0 +1,138 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"encoding/json"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencyGraphRandmod(t *testing.T) {
+	// The state of tests/testdata/modules/randmod instantiated as myrandmod.
+	state, err := NewState(&tfjson.State{
+		Values: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				ChildModules: []*tfjson.StateModule{{
+					Address: "module.myrandmod",
+					Resources: []*tfjson.StateResource{{
+						Address: "module.myrandmod.random_integer.priority",
+						Mode:    tfjson.ManagedResourceMode,
+						Type:    "random_integer",
+						Name:    "priority",
+					}},
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	graph := state.DependencyGraph()
+	assert.Equal(t, []DependencyGraphNode{
+		{Address: "module.myrandmod.random_integer.priority", Kind: DependencyGraphResourceNode},
+		{Address: "module.myrandmod", Kind: DependencyGraphModuleNode},
+	}, graph.Nodes)
+	assert.Equal(t, []DependencyGraphEdge{
+		{From: "module.myrandmod", To: "module.myrandmod.random_integer.priority", Kind: DependencyGraphDeclaresEdge},
+	}, graph.Edges)
+
+	assert.Equal(t, `digraph {
+  "module.myrandmod.random_integer.priority" [shape=box];
+  "module.myrandmod" [shape=folder];
+  "module.myrandmod" -> "module.myrandmod.random_integer.priority" [style=dashed];
+}
+`, graph.DOT())
+
+	graphJSON, err := json.Marshal(graph)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"nodes": [
+			{"address": "module.myrandmod.random_integer.priority", "kind": "resource"},
+			{"address": "module.myrandmod", "kind": "module"}
+		],
+		"edges": [
+			{"from": "module.myrandmod", "to": "module.myrandmod.random_integer.priority", "kind": "declares"}
+		]
+	}`, string(graphJSON))
+}
+
+func TestDependencyGraphDependsOn(t *testing.T) {
+	state, err := NewState(&tfjson.State{
+		Values: &tfjson.StateValues{
+			RootModule: &tfjson.StateModule{
+				ChildModules: []*tfjson.StateModule{{
+					Address: "module.mymod",
+					Resources: []*tfjson.StateResource{
+						{
+							Address: "module.mymod.random_integer.seed[0]",
+							Mode:    tfjson.ManagedResourceMode,
+							Type:    "random_integer",
+							Name:    "seed",
+							Index:   0,
+						},
+						{
+							Address: "module.mymod.random_integer.seed[1]",
+							Mode:    tfjson.ManagedResourceMode,
+							Type:    "random_integer",
+							Name:    "seed",
+							Index:   1,
+						},
+					},
+					ChildModules: []*tfjson.StateModule{{
+						Address: "module.mymod.module.sub",
+						Resources: []*tfjson.StateResource{{
+							Address:   "module.mymod.module.sub.random_pet.name",
+							Mode:      tfjson.ManagedResourceMode,
+							Type:      "random_pet",
+							Name:      "name",
+							DependsOn: []string{"module.mymod.random_integer.seed"},
+						}},
+					}},
+				}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	graph := state.DependencyGraph()
+	assert.Equal(t, []DependencyGraphNode{
+		{Address: "module.mymod.module.sub.random_pet.name", Kind: DependencyGraphResourceNode},
+		{Address: "module.mymod.random_integer.seed[0]", Kind: DependencyGraphResourceNode},
+		{Address: "module.mymod.random_integer.seed[1]", Kind: DependencyGraphResourceNode},
+		{Address: "module.mymod", Kind: DependencyGraphModuleNode},
+		{Address: "module.mymod.module.sub", Kind: DependencyGraphModuleNode},
+	}, graph.Nodes)
+
+	// A dependency on a resource expanded with count is a dependency on each of its instances.
+	assert.Equal(t, []DependencyGraphEdge{
+		{From: "module.mymod", To: "module.mymod.module.sub", Kind: DependencyGraphDeclaresEdge},
+		{From: "module.mymod", To: "module.mymod.random_integer.seed[0]", Kind: DependencyGraphDeclaresEdge},
+		{From: "module.mymod", To: "module.mymod.random_integer.seed[1]", Kind: DependencyGraphDeclaresEdge},
+		{From: "module.mymod.module.sub", To: "module.mymod.module.sub.random_pet.name", Kind: DependencyGraphDeclaresEdge},
+		{
+			From: "module.mymod.module.sub.random_pet.name",
+			To:   "module.mymod.random_integer.seed[0]",
+			Kind: DependencyGraphDependsEdge,
+		},
+		{
+			From: "module.mymod.module.sub.random_pet.name",
+			To:   "module.mymod.random_integer.seed[1]",
+			Kind: DependencyGraphDependsEdge,
+		},
+	}, graph.Edges)
+}
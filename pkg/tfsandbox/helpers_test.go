@@ -11,7 +11,7 @@ import (
 	"github.com/pulumi/pulumi-terraform-module/pkg/tofuresolver"
 )
 
-func newTestTofu(t *testing.T) *ModuleRuntime {
+func newTestTofu(t testing.TB) *ModuleRuntime {
 	srv := newTestAuxProviderServer(t)
 
 	tofu, err := NewTofu(context.Background(), DiscardLogger, nil, srv, tofuresolver.ResolveOpts{})
@@ -37,7 +37,7 @@ func newTestTerraform(t *testing.T) *ModuleRuntime {
 	return tf
 }
 
-func newTestAuxProviderServer(t *testing.T) *auxprovider.Server {
+func newTestAuxProviderServer(t testing.TB) *auxprovider.Server {
 	srv, err := auxprovider.Serve()
 	require.NoError(t, err)
 	t.Cleanup(func() {
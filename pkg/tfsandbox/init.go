@@ -2,31 +2,97 @@ package tfsandbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/hashicorp/terraform-exec/tfexec"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/fsutil"
 )
 
+// ErrProviderChecksumMismatch is reported by init when the package of a provider does not match the checksums recorded
+// for it in the dependency lock file. The provider is not installed, since the package may have been tampered with.
+var ErrProviderChecksumMismatch = errors.New("provider package does not match the checksums recorded in the " +
+	"dependency lock file")
+
 // Run tofu init to initialize a new directory.
 //
 // TODO[pulumi/pulumi-terraform-module#67] speed up this slow operation.
 func (t *ModuleRuntime) Init(ctx context.Context, log Logger) error {
-	return t.runInit(ctx, log, t.initOptions()...)
+	return t.withModuleCache(ctx, log, true /* restore */, func() error {
+		return t.runInit(ctx, log, false /* upgrade */)
+	})
 }
 
 // Run tofu init with -upgrade to refresh provider selections when module constraints change.
 func (t *ModuleRuntime) InitUpgrade(ctx context.Context, log Logger) error {
-	return t.runInit(ctx, log, append(t.initOptions(), tfexec.Upgrade(true))...)
+	return t.withModuleCache(ctx, log, false /* restore */, func() error {
+		return t.runInit(ctx, log, true /* upgrade */)
+	})
 }
 
-func (t *ModuleRuntime) runInit(ctx context.Context, log Logger, opts ...tfexec.InitOption) error {
-	logWriter := newJSONLogPipe(ctx, log)
+func (t *ModuleRuntime) runInit(ctx context.Context, log Logger, upgrade bool) error {
+	release, err := t.initLimiter.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	opts := t.initOptions()
+	if upgrade {
+		opts = append(opts, tfexec.Upgrade(true))
+	}
+
+	// The plugin cache is not safe for concurrent writes. Inits that find every locked provider in the cache only
+	// read from it and run concurrently, while those that may install providers into it, because they select
+	// providers for the first time or upgrade them, take turns.
+	if t.pluginCacheDir != "" {
+		cached := false
+		if !upgrade {
+			cached, err = t.pluginCacheHasLockedProviders()
+			if err != nil {
+				return err
+			}
+		}
+		if !cached {
+			mu := fsutil.NewFileMutex(filepath.Join(t.pluginCacheDir, ".init.lock"))
+			if err := mu.Lock(); err != nil {
+				return fmt.Errorf("error locking plugin cache: %w", err)
+			}
+			defer func() {
+				contract.IgnoreError(mu.Unlock())
+			}()
+		}
+	}
+
+	recorder := &diagnosticsRecorder{Logger: log}
+	logWriter := newJSONLogPipe(ctx, recorder)
 	defer logWriter.Close()
 
 	// Run the terraform init command
 	if err := t.tf.InitJSON(ctx, logWriter, opts...); err != nil {
+		// The reasons are only reported as diagnostics, include them to make the error actionable.
+		contract.IgnoreError(logWriter.Close())
+		summaries := recorder.errorSummaries()
+		if slices.ContainsFunc(summaries, isChecksumMismatch) {
+			return fmt.Errorf("error running init (%s): %w: %w: %s", t.description, ErrProviderChecksumMismatch, err,
+				strings.Join(summaries, "; "))
+		}
+		if len(summaries) > 0 {
+			return fmt.Errorf("error running init (%s): %w: %s", t.description, err, strings.Join(summaries, "; "))
+		}
 		return fmt.Errorf("error running init (%s): %w", t.description, err)
 	}
 
-	return nil
+	return t.adaptToExecutor(ctx, log)
+}
+
+// isChecksumMismatch recognizes the diagnostics that Terraform and OpenTofu report when a provider package, either
+// downloaded or found in the plugin cache, does not match any of the checksums in the dependency lock file.
+func isChecksumMismatch(diagnostic string) bool {
+	return strings.Contains(diagnostic, "doesn't match any of the checksums")
 }
@@ -0,0 +1,94 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// redactedInventoryValue replaces the values of sensitive attributes in the inventory.
+const redactedInventoryValue = "[secret]"
+
+// InventoryEntry describes a resource managed by a module instance.
+type InventoryEntry struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	ARN     string `json:"arn,omitempty"`
+}
+
+// Inventory lists the resources managed by a module instance from its raw Terraform state, sorted by address. Data
+// sources are left out. The id and arn attributes are reported as [secret] when the state marks them sensitive.
+func Inventory(state json.RawMessage) ([]InventoryEntry, error) {
+	s, err := decodeRawState(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the state: %w", err)
+	}
+
+	var inventory []InventoryEntry
+	for _, resource := range s.resources {
+		if resource["mode"] != "managed" {
+			continue
+		}
+		typ, _ := resource["type"].(string)
+		name, _ := resource["name"].(string)
+		address := typ + "." + name
+		if module, ok := resource["module"].(string); ok && module != "" {
+			address = module + "." + address
+		}
+		for _, instance := range resourceInstances(resource) {
+			inventory = append(inventory, InventoryEntry{
+				Address: address + formatInstanceKey(instance),
+				Type:    typ,
+				ID:      inventoryAttribute(instance, "id"),
+				ARN:     inventoryAttribute(instance, "arn"),
+			})
+		}
+	}
+	slices.SortFunc(inventory, func(a, b InventoryEntry) int { return strings.Compare(a.Address, b.Address) })
+	return inventory, nil
+}
+
+func inventoryAttribute(instance map[string]any, name string) string {
+	attributes, _ := instance["attributes"].(map[string]any)
+	value, ok := attributes[name].(string)
+	if !ok || value == "" {
+		return ""
+	}
+	if isSensitiveAttribute(instance, name) {
+		return redactedInventoryValue
+	}
+	return value
+}
+
+// isSensitiveAttribute checks the sensitive_attributes of a resource instance, which lists the paths of the sensitive
+// values as sequences of steps such as {"type": "get_attr", "value": "arn"}.
+func isSensitiveAttribute(instance map[string]any, name string) bool {
+	paths, _ := instance["sensitive_attributes"].([]any)
+	for _, path := range paths {
+		steps, _ := path.([]any)
+		if len(steps) == 0 {
+			continue
+		}
+		step, _ := steps[0].(map[string]any)
+		if step["type"] == "get_attr" && step["value"] == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventoryRandmod(t *testing.T) {
+	t.Parallel()
+
+	// The state of tests/testdata/modules/randmod instantiated as myrandmod.
+	state := `{
+	  "version": 4,
+	  "serial": 1,
+	  "resources": [{
+	    "module": "module.myrandmod",
+	    "mode": "managed",
+	    "type": "random_integer",
+	    "name": "priority",
+	    "provider": "provider[\"registry.opentofu.org/hashicorp/random\"]",
+	    "instances": [{
+	      "schema_version": 0,
+	      "attributes": {"id": "2", "keepers": null, "max": 10, "min": 1, "result": 2, "seed": "9"},
+	      "sensitive_attributes": [[{"type": "get_attr", "value": "seed"}]]
+	    }]
+	  }]
+	}`
+
+	inventory, err := Inventory([]byte(state))
+	require.NoError(t, err)
+	assert.Equal(t, []InventoryEntry{{
+		Address: "module.myrandmod.random_integer.priority",
+		Type:    "random_integer",
+		ID:      "2",
+	}}, inventory)
+}
+
+func TestInventoryRedactsSensitiveAttributes(t *testing.T) {
+	t.Parallel()
+
+	state := `{
+	  "version": 4,
+	  "resources": [{
+	    "module": "module.secrets",
+	    "mode": "data",
+	    "type": "aws_caller_identity",
+	    "name": "current",
+	    "instances": [{"attributes": {"id": "123456789012", "arn": "arn:aws:iam::123456789012:root"}}]
+	  }, {
+	    "module": "module.secrets",
+	    "mode": "managed",
+	    "type": "aws_secretsmanager_secret",
+	    "name": "this",
+	    "instances": [{
+	      "index_key": "db",
+	      "attributes": {
+	        "id": "arn:aws:secretsmanager:us-east-1:123456789012:secret:db",
+	        "arn": "arn:aws:secretsmanager:us-east-1:123456789012:secret:db"
+	      },
+	      "sensitive_attributes": [[{"type": "get_attr", "value": "arn"}]]
+	    }]
+	  }]
+	}`
+
+	inventory, err := Inventory([]byte(state))
+	require.NoError(t, err)
+	assert.Equal(t, []InventoryEntry{{
+		Address: `module.secrets.aws_secretsmanager_secret.this["db"]`,
+		Type:    "aws_secretsmanager_secret",
+		ID:      "arn:aws:secretsmanager:us-east-1:123456789012:secret:db",
+		ARN:     "[secret]",
+	}}, inventory, "data sources are left out")
+}
@@ -19,9 +19,13 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"regexp"
+	"slices"
+	"sync"
 
 	"github.com/pulumi/opentofu/command/format"
 	"github.com/pulumi/opentofu/command/jsonformat"
+	viewsjson "github.com/pulumi/opentofu/command/views/json"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 
 	"github.com/pulumi/pulumi-terraform-module/pkg/pulumix"
@@ -51,9 +55,24 @@ type JSONLog struct {
 	Level LogLevel `json:"@level"`
 }
 
+// jsonLogPipe is the writer end of a JSON log pipe.
+type jsonLogPipe struct {
+	*io.PipeWriter
+	done chan struct{}
+}
+
+// Close closes the pipe and waits until all the messages written to it have been logged.
+func (p *jsonLogPipe) Close() error {
+	err := p.PipeWriter.Close()
+	<-p.done
+	return err
+}
+
 func newJSONLogPipe(ctx context.Context, logger Logger) io.WriteCloser {
 	reader, writer := io.Pipe()
+	done := make(chan struct{})
 	go func() {
+		defer close(done)
 		defer reader.Close() // Ensure we close the reader on our way out.
 
 		dec := json.NewDecoder(reader)
@@ -75,11 +94,114 @@ func newJSONLogPipe(ctx context.Context, logger Logger) io.WriteCloser {
 				return
 			}
 
+			stripControlSequences(&msg)
+			if observer, ok := logger.(jsonLogObserver); ok {
+				observer.observe(msg)
+			}
 			handleMessage(ctx, logger, msg)
 		}
 	}()
 
-	return writer
+	return &jsonLogPipe{PipeWriter: writer, done: done}
+}
+
+// jsonLogObserver is implemented by loggers that inspect the structured JSON log messages in addition to logging them.
+type jsonLogObserver interface {
+	observe(log JSONLog)
+}
+
+// diagnosticsRecorder is a Logger that remembers the error diagnostics passing through it, along with the resources
+// they are attributed to.
+type diagnosticsRecorder struct {
+	Logger
+	mu          sync.Mutex
+	diagnostics []string
+	// summaries are the error diagnostics formatted on a single line, in order.
+	summaries []string
+
+	// errored are the resources whose changes failed to apply, in the order of the failures.
+	errored []ResourceAddress
+	// attributed holds the error diagnostics naming the resource they concern.
+	attributed map[ResourceAddress][]string
+	// unattributed holds the error diagnostics that do not name a resource.
+	unattributed []string
+}
+
+func (r *diagnosticsRecorder) observe(log JSONLog) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch log.Type {
+	case jsonformat.LogApplyErrored:
+		if res, ok := log.Hook["resource"].(map[string]interface{}); ok {
+			if addr, ok := res["addr"].(string); ok {
+				r.errored = append(r.errored, ResourceAddress(addr))
+			}
+		}
+	case jsonformat.LogDiagnostic:
+		d := log.Diagnostic
+		if d == nil || d.Severity != "error" {
+			return
+		}
+		msg := d.Summary
+		if d.Detail != "" {
+			msg += ": " + d.Detail
+		}
+		r.summaries = append(r.summaries, msg)
+		if d.Address == "" {
+			r.unattributed = append(r.unattributed, msg)
+			return
+		}
+		if r.attributed == nil {
+			r.attributed = map[ResourceAddress][]string{}
+		}
+		addr := ResourceAddress(d.Address)
+		r.attributed[addr] = append(r.attributed[addr], msg)
+	}
+}
+
+// resourceErrors attributes the recorded error diagnostics to child resources. Diagnostics that do not name a resource
+// are attributed to the failed resource when only one failed, as they cannot be told apart otherwise.
+func (r *diagnosticsRecorder) resourceErrors() map[ResourceAddress][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	errs := map[ResourceAddress][]string{}
+	for addr, msgs := range r.attributed {
+		errs[addr] = slices.Clone(msgs)
+	}
+	if len(r.unattributed) > 0 {
+		failed := slices.Compact(slices.Sorted(slices.Values(r.errored)))
+		if len(failed) == 1 {
+			errs[failed[0]] = append(errs[failed[0]], r.unattributed...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (r *diagnosticsRecorder) Log(ctx context.Context, level LogLevel, msg string) {
+	if level == Error {
+		r.mu.Lock()
+		r.diagnostics = append(r.diagnostics, msg)
+		r.mu.Unlock()
+	}
+	r.Logger.Log(ctx, level, msg)
+}
+
+// errorSummaries returns the error diagnostics in the order they were reported, each formatted on a single line.
+func (r *diagnosticsRecorder) errorSummaries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.summaries)
+}
+
+func (r *diagnosticsRecorder) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.diagnostics)
 }
 
 func handleMessage(ctx context.Context, logger Logger, log JSONLog) {
@@ -94,6 +216,13 @@ func handleMessage(ctx context.Context, logger Logger, log JSONLog) {
 		// good status messages
 		logger.LogStatus(ctx, log.Level, log.Message)
 	case jsonformat.LogDiagnostic:
+		if log.Diagnostic != nil && log.Diagnostic.Severity == viewsjson.DiagnosticSeverityWarning {
+			// Like -compact-warnings, which the JSON view does not support: only the summary is shown, the full
+			// warning is kept in the debug logs.
+			logger.Log(ctx, log.Level, compactWarning(log.Diagnostic))
+			logger.Log(ctx, Debug, format.DiagnosticPlainFromJSON(log.Diagnostic, 78))
+			return
+		}
 		// Diagnostic messages are typically errors
 		logger.Log(ctx, log.Level, format.DiagnosticPlainFromJSON(log.Diagnostic, 78))
 	case jsonformat.LogChangeSummary:
@@ -104,3 +233,24 @@ func handleMessage(ctx context.Context, logger Logger, log JSONLog) {
 		return
 	}
 }
+
+func compactWarning(d *viewsjson.Diagnostic) string {
+	if d.Address != "" {
+		return "Warning: " + d.Summary + " (" + d.Address + ")"
+	}
+	return "Warning: " + d.Summary
+}
+
+// ansiControlSequencePattern matches ANSI escape sequences such as the ones setting colors.
+var ansiControlSequencePattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// stripControlSequences removes ANSI escape sequences from the messages of a JSON log message. The commands are run
+// with -no-color, but providers may still embed colors in the diagnostics they report, which render poorly in the
+// Pulumi output.
+func stripControlSequences(log *JSONLog) {
+	log.Message = ansiControlSequencePattern.ReplaceAllString(log.Message, "")
+	if d := log.Diagnostic; d != nil {
+		d.Summary = ansiControlSequencePattern.ReplaceAllString(d.Summary, "")
+		d.Detail = ansiControlSequencePattern.ReplaceAllString(d.Detail, "")
+	}
+}
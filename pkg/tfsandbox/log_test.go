@@ -0,0 +1,104 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnosticsRecorderAttributesErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("diagnostics naming a resource", func(t *testing.T) {
+		recorder := &diagnosticsRecorder{Logger: DiscardLogger}
+		pipe := newJSONLogPipe(ctx, recorder)
+		_, err := pipe.Write([]byte(`{"@level":"error","@message":"Error: boom","type":"diagnostic",` +
+			`"diagnostic":{"severity":"error","summary":"boom","detail":"it broke",` +
+			`"address":"module.m.terraform_data.a"}}
+{"@level":"error","@message":"Error: other","type":"diagnostic",` +
+			`"diagnostic":{"severity":"error","summary":"other","address":"module.m.terraform_data.b"}}
+{"@level":"warn","@message":"Warning: meh","type":"diagnostic",` +
+			`"diagnostic":{"severity":"warning","summary":"meh","address":"module.m.terraform_data.a"}}
+`))
+		require.NoError(t, err)
+		require.NoError(t, pipe.Close())
+
+		assert.Equal(t, map[ResourceAddress][]string{
+			"module.m.terraform_data.a": {"boom: it broke"},
+			"module.m.terraform_data.b": {"other"},
+		}, recorder.resourceErrors())
+	})
+
+	t.Run("unaddressed diagnostics with a single failed resource", func(t *testing.T) {
+		recorder := &diagnosticsRecorder{Logger: DiscardLogger}
+		pipe := newJSONLogPipe(ctx, recorder)
+		_, err := pipe.Write([]byte(`{"@level":"info","@message":"module.m.terraform_data.a: Creation errored",` +
+			`"type":"apply_errored","hook":{"resource":{"addr":"module.m.terraform_data.a"},"action":"create"}}
+{"@level":"error","@message":"Error: boom","type":"diagnostic","diagnostic":{"severity":"error","summary":"boom"}}
+`))
+		require.NoError(t, err)
+		require.NoError(t, pipe.Close())
+
+		assert.Equal(t, map[ResourceAddress][]string{
+			"module.m.terraform_data.a": {"boom"},
+		}, recorder.resourceErrors())
+	})
+
+	t.Run("unaddressed diagnostics with several failed resources", func(t *testing.T) {
+		recorder := &diagnosticsRecorder{Logger: DiscardLogger}
+		pipe := newJSONLogPipe(ctx, recorder)
+		_, err := pipe.Write([]byte(`{"@level":"info","type":"apply_errored",` +
+			`"hook":{"resource":{"addr":"module.m.terraform_data.a"}}}
+{"@level":"info","type":"apply_errored","hook":{"resource":{"addr":"module.m.terraform_data.b"}}}
+{"@level":"error","@message":"Error: boom","type":"diagnostic","diagnostic":{"severity":"error","summary":"boom"}}
+`))
+		require.NoError(t, err)
+		require.NoError(t, pipe.Close())
+
+		assert.Nil(t, recorder.resourceErrors())
+		assert.Len(t, recorder.recorded(), 1, "the diagnostic is still recorded")
+	})
+}
+
+func TestJSONLogIsPlainText(t *testing.T) {
+	ctx := context.Background()
+	logger := &levelRecordingLogger{}
+	pipe := newJSONLogPipe(ctx, logger)
+	_, err := pipe.Write([]byte(`{"@level":"info","@message":"\u001b[1mmodule.m.terraform_data.a: Creating...\u001b[0m",` +
+		`"type":"apply_start"}
+{"@level":"error","@message":"Error: boom","type":"diagnostic",` +
+		`"diagnostic":{"severity":"error","summary":"\u001b[31mboom\u001b[0m","detail":"it \u001b[1;33mbroke\u001b[0m"}}
+{"@level":"warn","@message":"Warning: meh","type":"diagnostic",` +
+		`"diagnostic":{"severity":"warning","summary":"\u001b[33mmeh\u001b[0m","detail":"the details",` +
+		`"address":"module.m.terraform_data.a"}}
+`))
+	require.NoError(t, err)
+	require.NoError(t, pipe.Close())
+
+	require.Len(t, logger.messages, 4)
+	for _, msg := range logger.messages {
+		assert.NotContains(t, msg, "\x1b", "expected no ANSI escape codes in %q", msg)
+	}
+	assert.Equal(t, "info: module.m.terraform_data.a: Creating...", logger.messages[0])
+	assert.Contains(t, logger.messages[1], "boom")
+	assert.Contains(t, logger.messages[1], "it broke")
+	assert.Equal(t, "warn: Warning: meh (module.m.terraform_data.a)", logger.messages[2],
+		"warnings are compacted to their summary")
+	assert.Contains(t, logger.messages[3], "the details", "the full warning is kept in the debug logs")
+}
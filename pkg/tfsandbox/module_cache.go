@@ -0,0 +1,249 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/fsutil"
+)
+
+// cachedModuleKey replaces the name of the module block in the modules cached by a moduleCache, as every module
+// instance calls the module under a block of its own name.
+const cachedModuleKey = "module"
+
+// moduleCache shares the modules downloaded by init between runtimes calling the same module, see UseModuleCache.
+type moduleCache struct {
+	// entry is the directory of the cache holding the module, named after its source and version.
+	entry string
+	// key is the name of the module block calling the module in the working directory.
+	key string
+}
+
+// UseModuleCache shares the downloads of the module called by the module block key with other runtimes, in an entry
+// of cacheDir addressed by the source and version of the module. Init copies the module from the cache when it is
+// there, records it in the manifest of downloaded modules so that Terraform does not download it again, and stores the
+// module in the cache otherwise. Copies to and from the same entry are serialized with a file lock.
+//
+// Entries are addressed by the reference to the module rather than by its contents, which are only known once it is
+// downloaded, so only references that do not move are cached: git sources referencing a commit, and registry modules
+// at a version, which registries treat as immutable. A version published again with other contents is still served
+// from the cache. Git tags can be moved, so git sources referencing a version tag are downloaded every time like
+// branches. Upgrades download the module again, as they may select a newer version.
+func (t *ModuleRuntime) UseModuleCache(cacheDir, key string, source TFModuleSource, version TFModuleVersion) error {
+	if !cacheableModuleSource(source, version) {
+		return nil
+	}
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return fmt.Errorf("error creating module cache dir: %w", err)
+	}
+	digest := sha256.Sum256([]byte(string(source) + "@" + string(version)))
+	t.moduleCache = &moduleCache{
+		entry: filepath.Join(cacheDir, hex.EncodeToString(digest[:])),
+		key:   key,
+	}
+	return nil
+}
+
+func cacheableModuleSource(source TFModuleSource, version TFModuleVersion) bool {
+	switch {
+	case source.IsLocalPath():
+		return false
+	case source.IsGit():
+		_, ok := source.ReferencedCommit()
+		return ok
+	default:
+		return version != ""
+	}
+}
+
+// withModuleCache runs init, restoring the module from the cache beforehand unless restore is false, and storing
+// it in the cache afterwards when it was not there. The cache entry is locked while it is read or written, but not
+// while init runs, which would serialize the inits of the instances of a module. The trade-off is that instances
+// initialized at the same time before the module is cached may each download it. The cache only saves downloads, so
+// failing to store a module is reported as a warning.
+func (t *ModuleRuntime) withModuleCache(ctx context.Context, log Logger, restore bool, init func() error) error {
+	c := t.moduleCache
+	if c == nil {
+		return init()
+	}
+
+	cached := false
+	if restore {
+		err := c.locked(func() error {
+			var err error
+			cached, err = c.restore(t.WorkingDir())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error restoring the module from the module cache: %w", err)
+		}
+	}
+
+	if err := init(); err != nil {
+		return err
+	}
+
+	if !cached {
+		if err := c.locked(func() error { return c.store(t.WorkingDir()) }); err != nil {
+			log.Log(ctx, Warn, fmt.Sprintf("Failed to store the module in the module cache: %v", err))
+		}
+	}
+	return nil
+}
+
+// locked runs f holding the file lock of the cache entry, which serializes the runtimes reading and writing it.
+func (c *moduleCache) locked(f func() error) error {
+	mu := fsutil.NewFileMutex(c.entry + ".lock")
+	if err := mu.Lock(); err != nil {
+		return fmt.Errorf("error locking module cache: %w", err)
+	}
+	defer func() {
+		contract.IgnoreError(mu.Unlock())
+	}()
+	return f()
+}
+
+// store copies the module and the modules it calls from the working directory to the cache entry. The manifest of the
+// entry is written last, so that only complete entries are restored.
+func (c *moduleCache) store(workingDir string) error {
+	manifest, err := ReadModuleManifest(workingDir)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(c.entry, "modules.json")); err == nil {
+		return nil
+	}
+
+	var records []ModuleManifestRecord
+	dirs := map[string]string{}
+	for _, record := range manifest.Modules {
+		key, ok := renameModuleKey(record.Key, c.key, cachedModuleKey)
+		if !ok {
+			continue
+		}
+		dir, ok := renameModuleDir(record.Dir, c.key, cachedModuleKey)
+		if !ok {
+			// Modules recorded outside of the modules directory are not downloaded.
+			return nil
+		}
+		dirs[downloadDir(record.Dir)] = downloadDir(dir)
+		record.Key, record.Dir = key, dir
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := os.RemoveAll(c.entry); err != nil {
+		return err
+	}
+	for from, to := range dirs {
+		src := filepath.Join(workingDir, modulesDir, from)
+		if err := os.CopyFS(filepath.Join(c.entry, to), os.DirFS(src)); err != nil {
+			return err
+		}
+	}
+	return writeModuleManifest(c.entry, ModuleManifest{Modules: records})
+}
+
+// restore copies the module from the cache entry to the working directory and records it in its manifest. It reports
+// whether the module is available in the working directory, either restored or downloaded by a previous init.
+func (c *moduleCache) restore(workingDir string) (bool, error) {
+	cached, err := readJSONFile[ModuleManifest](filepath.Join(c.entry, "modules.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	manifest, err := ReadModuleManifest(workingDir)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		manifest = ModuleManifest{Modules: []ModuleManifestRecord{{Key: "", Source: "", Dir: "."}}}
+	case err != nil:
+		return false, err
+	}
+	for _, record := range manifest.Modules {
+		if record.Key == c.key {
+			return true, nil
+		}
+	}
+
+	restored := map[string]bool{}
+	for _, record := range cached.Modules {
+		key, _ := renameModuleKey(record.Key, cachedModuleKey, c.key)
+		dir, _ := renameModuleDir(record.Dir, cachedModuleKey, c.key)
+		if from := downloadDir(record.Dir); !restored[from] {
+			restored[from] = true
+			dst := filepath.Join(workingDir, modulesDir, downloadDir(dir))
+			if err := os.RemoveAll(dst); err != nil {
+				return false, err
+			}
+			if err := os.CopyFS(dst, os.DirFS(filepath.Join(c.entry, from))); err != nil {
+				return false, err
+			}
+		}
+		record.Key, record.Dir = key, dir
+		manifest.Modules = append(manifest.Modules, record)
+	}
+	return true, writeModuleManifest(filepath.Join(workingDir, modulesDir), manifest)
+}
+
+// renameModuleKey renames the module block from in the key of a module or of a module it calls, such as from.vpc.
+func renameModuleKey(key, from, to string) (string, bool) {
+	if key == from {
+		return to, true
+	}
+	if rest, ok := strings.CutPrefix(key, from+"."); ok {
+		return to + "." + rest, true
+	}
+	return "", false
+}
+
+// renameModuleDir renames the module block from in the directory of a downloaded module, such as
+// .terraform/modules/from.vpc, or .terraform/modules/from/modules/vpc for modules of the same package.
+func renameModuleDir(dir, from, to string) (string, bool) {
+	rel, ok := strings.CutPrefix(filepath.ToSlash(dir), modulesDir+"/")
+	if !ok {
+		return "", false
+	}
+	top, rest, _ := strings.Cut(rel, "/")
+	renamed, ok := renameModuleKey(top, from, to)
+	if !ok {
+		return "", false
+	}
+	if rest != "" {
+		renamed += "/" + rest
+	}
+	return modulesDir + "/" + renamed, true
+}
+
+// downloadDir returns the directory of the modules directory that a module was downloaded to: modules of the same
+// package are found in the directory of the package.
+func downloadDir(dir string) string {
+	rel := strings.TrimPrefix(filepath.ToSlash(dir), modulesDir+"/")
+	top, _, _ := strings.Cut(rel, "/")
+	return top
+}
@@ -0,0 +1,98 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleCacheSharesDownloads(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "module_cache", "stub.sh"))
+	require.NoError(t, err)
+
+	cacheDir := t.TempDir()
+	downloads := filepath.Join(t.TempDir(), "downloads")
+	t.Setenv("STUB_DOWNLOADS", downloads)
+
+	initInstance := func(key string) *ModuleRuntime {
+		t.Setenv("STUB_MODULE_KEY", key)
+		tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{t.Name(), key}, nil, stub)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+		require.NoError(t, tf.UseModuleCache(cacheDir, key, "terraform-aws-modules/vpc/aws", "5.19.0"))
+		require.NoError(t, tf.Init(ctx, DiscardLogger))
+		return tf
+	}
+
+	initInstance("first")
+	second := initInstance("second")
+
+	downloaded, err := os.ReadFile(downloads)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(downloaded), "the module is downloaded once")
+
+	// The second instance finds the module under its own module block.
+	assert.FileExists(t, filepath.Join(second.WorkingDir(), modulesDir, "second", "main.tf"))
+	assert.FileExists(t, filepath.Join(second.WorkingDir(), modulesDir, "second", "modules", "subnets", "main.tf"))
+	manifest, err := ReadModuleManifest(second.WorkingDir())
+	require.NoError(t, err)
+	assert.Equal(t, []ModuleManifestRecord{
+		{Key: "", Source: "", Dir: "."},
+		{
+			Key:     "second",
+			Source:  "registry.opentofu.org/terraform-aws-modules/vpc/aws",
+			Version: "5.19.0",
+			Dir:     ".terraform/modules/second",
+		},
+		{Key: "second.subnets", Source: "./modules/subnets", Dir: ".terraform/modules/second/modules/subnets"},
+	}, manifest.Modules)
+
+	// Another version of the module is cached separately.
+	t.Setenv("STUB_MODULE_KEY", "third")
+	tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{t.Name(), "third"}, nil, stub)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+	require.NoError(t, tf.UseModuleCache(cacheDir, "third", "terraform-aws-modules/vpc/aws", "5.20.0"))
+	require.NoError(t, tf.Init(ctx, DiscardLogger))
+	downloaded, err = os.ReadFile(downloads)
+	require.NoError(t, err)
+	assert.Equal(t, "first\nthird\n", string(downloaded))
+}
+
+func TestModuleCacheSkipsMutableSources(t *testing.T) {
+	for source, cacheable := range map[TFModuleSource]bool{
+		"terraform-aws-modules/vpc/aws":                                                 true,
+		"git::https://example.com/vpc.git?ref=v1.2.0":                                   false, // tags can be moved
+		"git::https://example.com/vpc.git?ref=51d462976d84fdea54b47d80dcabbf680badcdb8": true,
+		"git::https://example.com/vpc.git?ref=main":                                     false,
+		"git::https://example.com/vpc.git":                                              false,
+		"./modules/vpc":                                                                 false,
+	} {
+		assert.Equal(t, cacheable, cacheableModuleSource(source, "1.2.0"), source)
+	}
+	assert.False(t, cacheableModuleSource("terraform-aws-modules/vpc/aws", ""), "registry modules need a version")
+}
@@ -0,0 +1,66 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// modulesDir is where init downloads modules to, relative to the working directory, along with the modules.json
+// manifest that records them.
+const modulesDir = ".terraform/modules"
+
+// ModuleManifestRecord is a record of the modules.json manifest in which init tracks the modules it downloaded. Key
+// is the path of module call names leading to the module, such as a.b, and Dir the directory of the module relative to
+// the working directory; the root module has an empty key.
+type ModuleManifestRecord struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+	Dir     string `json:"Dir"`
+}
+
+// ModuleManifest is the modules.json manifest of the modules that init downloaded to a working directory.
+type ModuleManifest struct {
+	Modules []ModuleManifestRecord `json:"Modules"`
+}
+
+// ReadModuleManifest reads the manifest of the modules init downloaded to the working directory. Errors are returned
+// as is, so that callers can tell a missing manifest apart with [os.ErrNotExist].
+func ReadModuleManifest(workingDir string) (ModuleManifest, error) {
+	return readJSONFile[ModuleManifest](filepath.Join(workingDir, modulesDir, "modules.json"))
+}
+
+func writeModuleManifest(dir string, manifest ModuleManifest) error {
+	contents, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "modules.json"), contents, 0o600)
+}
+
+func readJSONFile[T any](path string) (T, error) {
+	var v T
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return v, err
+	}
+	return v, json.Unmarshal(contents, &v)
+}
@@ -28,6 +28,10 @@ import (
 type RefreshOpts struct {
 	RefreshOnly bool // if set to true, passes -refresh-only to TF
 	NoRefresh   bool // if set to true, passes -refresh=false to TF; TF default is implicit -refresh=true
+
+	// DestroyTargets passes -destroy with a -target for each of the addresses to TF when set, destroying only the
+	// targeted resources and whatever depends on them.
+	DestroyTargets []string
 }
 
 // Plan runs terraform plan and returns the plan representation.
@@ -69,6 +73,25 @@ func (t *ModuleRuntime) PlanRefreshOnly(ctx context.Context, logger Logger) (*Pl
 	return p, nil
 }
 
+// PlanDestroyTargets plans the destruction of the resources with the given addresses without refreshing, like
+// `terraform plan -destroy -target=<address>`.
+func (t *ModuleRuntime) PlanDestroyTargets(ctx context.Context, logger Logger, targets []string) (*Plan, error) {
+	options := []tfexec.PlanOption{tfexec.Refresh(false), tfexec.Destroy(true)}
+	for _, target := range targets {
+		options = append(options, tfexec.Target(target))
+	}
+	plan, err := t.planWithOptions(ctx, logger, t.planOptions(options...))
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := NewPlan(plan)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 func (t *ModuleRuntime) plan(ctx context.Context, logger Logger) (*tfjson.Plan, error) {
 	return t.planWithOptions(ctx, logger, t.planOptions())
 }
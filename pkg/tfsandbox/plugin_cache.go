@@ -0,0 +1,107 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// dependencyLockFile is the file in which init records the versions and checksums of the providers it selected.
+const dependencyLockFile = ".terraform.lock.hcl"
+
+// InitLimiter bounds the number of inits running at the same time in the runtimes sharing it, see LimitInits.
+type InitLimiter struct {
+	slots chan struct{}
+}
+
+// NewInitLimiter returns a limiter letting up to n inits run at the same time. There is no limit when n is 0, in
+// which case the limiter is nil.
+func NewInitLimiter(n int) *InitLimiter {
+	if n <= 0 {
+		return nil
+	}
+	return &InitLimiter{slots: make(chan struct{}, n)}
+}
+
+// acquire waits for a slot to run init in and returns the function that releases it.
+func (l *InitLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("error waiting for other inits to finish: %w", ctx.Err())
+	}
+}
+
+// LimitInits makes init wait until the limiter lets it run, bounding the number of module instances installing
+// providers and modules at the same time. Neither Terraform nor OpenTofu install the providers of a module in
+// parallel, so running the inits of several module instances at once is what speeds up programs with many of them.
+func (t *ModuleRuntime) LimitInits(limiter *InitLimiter) {
+	t.initLimiter = limiter
+}
+
+// pluginCacheHasLockedProviders reports whether the plugin cache holds every provider recorded in the dependency lock
+// file of the working directory, at the locked version and for this platform. Init then only reads from the cache.
+// There is nothing to check without a lock file, as init has yet to select the providers.
+func (t *ModuleRuntime) pluginCacheHasLockedProviders() (bool, error) {
+	path := filepath.Join(t.WorkingDir(), dependencyLockFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return false, nil
+	}
+	file, diagnostics := hclparse.NewParser().ParseHCLFile(path)
+	if diagnostics.HasErrors() {
+		return false, fmt.Errorf("error reading %s: %w", dependencyLockFile, diagnostics)
+	}
+	content, _, diagnostics := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "provider", LabelNames: []string{"source"}}},
+	})
+	if diagnostics.HasErrors() {
+		return false, fmt.Errorf("error reading %s: %w", dependencyLockFile, diagnostics)
+	}
+
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+	for _, block := range content.Blocks {
+		attributes, diagnostics := block.Body.JustAttributes()
+		if diagnostics.HasErrors() {
+			return false, fmt.Errorf("error reading %s: %w", dependencyLockFile, diagnostics)
+		}
+		attribute, ok := attributes["version"]
+		if !ok {
+			return false, nil
+		}
+		version, diagnostics := attribute.Expr.Value(nil)
+		if diagnostics.HasErrors() || version.Type() != cty.String || !version.IsKnown() || version.IsNull() {
+			return false, nil
+		}
+		// the cache has the unpacked layout of filesystem mirrors, such as
+		// registry.opentofu.org/hashicorp/aws/5.0.0/linux_amd64
+		dir := filepath.Join(t.pluginCacheDir, filepath.FromSlash(block.Labels[0]), version.AsString(), platform)
+		if _, err := os.Stat(dir); err != nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
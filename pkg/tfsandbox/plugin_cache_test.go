@@ -0,0 +1,102 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentInits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "plugin_cache", "stub.sh"))
+	require.NoError(t, err)
+
+	const lockFile = `provider "registry.opentofu.org/hashicorp/random" {
+  version = "3.6.0"
+  hashes  = ["h1:p6WG1IPHnqx1fnJVKNjv733FBaArIugqy58HRZnpPCk="]
+}
+`
+
+	// maxConcurrentInits runs the inits of two module instances at once and returns how many ran at the same time.
+	maxConcurrentInits := func(t *testing.T, parallelism int, cached bool) int {
+		stubDir := t.TempDir()
+		overlaps := filepath.Join(stubDir, "overlaps")
+		t.Setenv("STUB_INITS", filepath.Join(stubDir, "inits"))
+		t.Setenv("STUB_OVERLAPS", overlaps)
+
+		cacheDir := t.TempDir()
+		if cached {
+			require.NoError(t, os.MkdirAll(filepath.Join(cacheDir, "registry.opentofu.org", "hashicorp", "random",
+				"3.6.0", runtime.GOOS+"_"+runtime.GOARCH), 0o700))
+		}
+		limiter := NewInitLimiter(parallelism)
+
+		var wg sync.WaitGroup
+		for i := range 2 {
+			tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{t.Name(), strconv.Itoa(i)}, nil, stub)
+			require.NoError(t, err)
+			t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+			require.NoError(t, tf.UsePluginCache(cacheDir))
+			tf.LimitInits(limiter)
+			require.NoError(t, os.WriteFile(filepath.Join(tf.WorkingDir(), dependencyLockFile), []byte(lockFile),
+				0o600))
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, tf.Init(ctx, DiscardLogger))
+			}()
+		}
+		wg.Wait()
+
+		contents, err := os.ReadFile(overlaps)
+		require.NoError(t, err)
+		counts := []int{}
+		for _, line := range strings.Fields(string(contents)) {
+			n, err := strconv.Atoi(line)
+			require.NoError(t, err)
+			counts = append(counts, n)
+		}
+		require.Len(t, counts, 2)
+		return slices.Max(counts)
+	}
+
+	t.Run("cached providers", func(t *testing.T) {
+		assert.Equal(t, 2, maxConcurrentInits(t, 0, true), "inits only reading the cache overlap")
+	})
+
+	t.Run("parallelism", func(t *testing.T) {
+		assert.Equal(t, 1, maxConcurrentInits(t, 1, true))
+	})
+
+	t.Run("providers to install", func(t *testing.T) {
+		assert.Equal(t, 1, maxConcurrentInits(t, 0, false), "inits writing to the cache take turns")
+	})
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"math/big"
 	"slices"
+	"strings"
 
 	tfjson "github.com/hashicorp/terraform-json"
 
@@ -75,6 +76,27 @@ func (sr stateResources) extractResourcesFromStateModule(module *tfjson.StateMod
 	return nil
 }
 
+// dataSourcesOfStateModule lists the data sources of a tfjson.StateModule and its child modules in the order of their
+// addresses. They are recorded next to the resources but are read rather than managed by the module.
+func dataSourcesOfStateModule(module *tfjson.StateModule) []*ResourceState {
+	var dataSources []*ResourceState
+	if module == nil {
+		return dataSources
+	}
+	for _, childModule := range module.ChildModules {
+		dataSources = append(dataSources, dataSourcesOfStateModule(childModule)...)
+	}
+	for _, resource := range module.Resources {
+		if resource.Mode == tfjson.DataResourceMode {
+			dataSources = append(dataSources, &ResourceState{stateResource: resource})
+		}
+	}
+	slices.SortFunc(dataSources, func(a, b *ResourceState) int {
+		return strings.Compare(string(a.Address()), string(b.Address()))
+	})
+	return dataSources
+}
+
 // mapReplv maps the values of a resource property based on a filter
 // The filter is an object that contains the keys of the attributes that might need to be updated
 //
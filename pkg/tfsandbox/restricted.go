@@ -0,0 +1,284 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// restrictedEnvNames are the variables of the environment kept by RestrictEnvironment, besides those with one of the
+// restrictedEnvPrefixes.
+var restrictedEnvNames = []string{
+	"PATH", "TMPDIR", "TMP", "TEMP", "TZ", "LANG", "SSL_CERT_FILE", "SSL_CERT_DIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+}
+
+// restrictedEnvPrefixes select the variables configuring Terraform and the credentials of common providers.
+var restrictedEnvPrefixes = []string{"TF_", "LC_", "AWS_", "ARM_", "AZURE_", "GOOGLE_", "CLOUDSDK_"}
+
+// RestrictEnvironment runs Terraform with a restricted environment: only the variables Terraform and the credentials
+// of common providers need are passed on, and HOME is the working directory, so that modules and providers do not
+// pick up files such as ~/.terraformrc or ~/.ssh from the home directory of the user. This should be called after the
+// process environment is final, since the environment is captured at this point.
+func (t *ModuleRuntime) RestrictEnvironment() error {
+	t.restrictedEnv = true
+	return t.setEnv()
+}
+
+// setEnv sets the environment of the Terraform commands from the process environment and the options of the runtime.
+func (t *ModuleRuntime) setEnv() error {
+	env := envMap(os.Environ())
+	if t.restrictedEnv {
+		for name := range env {
+			if !slices.Contains(restrictedEnvNames, name) && !slices.ContainsFunc(restrictedEnvPrefixes,
+				func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+				delete(env, name)
+			}
+		}
+		env["HOME"] = t.WorkingDir()
+	}
+	if t.pluginCacheDir != "" {
+		env["TF_PLUGIN_CACHE_DIR"] = t.pluginCacheDir
+	}
+	if err := t.tf.SetEnv(tfexec.CleanEnv(env)); err != nil {
+		return fmt.Errorf("error setting the environment of %s: %w", t.description, err)
+	}
+	t.env = env
+	return nil
+}
+
+// fileFunctions are the functions reading the files at the path given by their first argument.
+var fileFunctions = []string{
+	"file", "fileexists", "fileset", "filebase64", "filemd5", "filesha1", "filesha256", "filesha512",
+	"filebase64sha256", "filebase64sha512", "templatefile",
+}
+
+// pathAttributes are the arguments of resources and data sources commonly naming files to read or write, such as the
+// source_dir of archive_file or the filename of local_file.
+var pathAttributes = []string{"filename", "source_file", "source_dir", "source"}
+
+// CheckRestrictedPaths rejects modules referring to files outside of the working directory, such as with
+// file("/etc/passwd") or a templatefile under path.module/../../.. escaping the module. The module source, when it
+// is a local path, and the provider and module caches of the runtime may be referred to as well. Only paths known
+// before planning are checked: those given as literals, or starting with path.module, path.root or path.cwd.
+func (t *ModuleRuntime) CheckRestrictedPaths(source TFModuleSource) error {
+	workdir := t.WorkingDir()
+	allowed := []string{workdir}
+	moduleDirs := []string{filepath.Join(workdir, ".terraform", "modules")}
+	if source.IsLocalPath() {
+		dir, err := filepath.Abs(string(source))
+		if err != nil {
+			return err
+		}
+		allowed = append(allowed, dir)
+		moduleDirs = append(moduleDirs, dir)
+	}
+	if t.pluginCacheDir != "" {
+		allowed = append(allowed, t.pluginCacheDir)
+	}
+	if t.moduleCache != nil {
+		allowed = append(allowed, t.moduleCache.entry)
+	}
+
+	var violations []error
+	for _, moduleDir := range moduleDirs {
+		err := filepath.WalkDir(moduleDir, func(path string, d fs.DirEntry, err error) error {
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				return nil
+			case err != nil:
+				return err
+			case d.IsDir() || (!strings.HasSuffix(path, ".tf") && !strings.HasSuffix(path, ".tf.json")):
+				return nil
+			}
+			contents, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			c := &restrictedPathsChecker{workdir: workdir, moduleDir: filepath.Dir(path), allowed: allowed}
+			if strings.HasSuffix(path, ".tf.json") {
+				c.checkJSONFile(path, contents)
+			} else {
+				c.checkFile(path, contents)
+			}
+			violations = append(violations, c.violations...)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error checking the paths referred to by the module: %w", err)
+		}
+	}
+	return errors.Join(violations...)
+}
+
+type restrictedPathsChecker struct {
+	workdir    string
+	moduleDir  string
+	allowed    []string
+	violations []error
+}
+
+func (c *restrictedPathsChecker) checkFile(path string, contents []byte) {
+	file, diags := hclsyntax.ParseConfig(contents, path, hcl.InitialPos)
+	if diags.HasErrors() {
+		// Terraform reports syntax errors when planning.
+		return
+	}
+	_ = hclsyntax.VisitAll(file.Body.(*hclsyntax.Body), func(node hclsyntax.Node) hcl.Diagnostics {
+		c.checkNode(node)
+		return nil
+	})
+}
+
+// checkJSONFile checks the files in the JSON syntax, whose strings are templates holding expressions.
+func (c *restrictedPathsChecker) checkJSONFile(path string, contents []byte) {
+	var config any
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return
+	}
+	var walk func(key string, v any)
+	walk = func(key string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, element := range v {
+				walk(k, element)
+			}
+		case []any:
+			for _, element := range v {
+				walk(key, element)
+			}
+		case string:
+			expr, diags := hclsyntax.ParseTemplate([]byte(v), path, hcl.InitialPos)
+			if diags.HasErrors() {
+				return
+			}
+			if slices.Contains(pathAttributes, key) {
+				c.checkPath(expr, false /* relativeToWorkdir */)
+			}
+			_ = hclsyntax.VisitAll(expr, func(node hclsyntax.Node) hcl.Diagnostics {
+				c.checkNode(node)
+				return nil
+			})
+		}
+	}
+	walk("", config)
+}
+
+func (c *restrictedPathsChecker) checkNode(node hclsyntax.Node) {
+	switch node := node.(type) {
+	case *hclsyntax.FunctionCallExpr:
+		if slices.Contains(fileFunctions, node.Name) && len(node.Args) > 0 {
+			// Terraform reads relative paths from the working directory.
+			c.checkPath(node.Args[0], true /* relativeToWorkdir */)
+		}
+	case *hclsyntax.Attribute:
+		if slices.Contains(pathAttributes, node.Name) {
+			c.checkPath(node.Expr, false /* relativeToWorkdir */)
+		}
+	}
+}
+
+// checkPath records a violation when expr is a path outside of the allowed directories. Relative paths are only
+// checked when relativeToWorkdir is set, as the arguments naming them may resolve them otherwise, such as the
+// source of module blocks.
+func (c *restrictedPathsChecker) checkPath(expr hclsyntax.Expression, relativeToWorkdir bool) {
+	path, ok := c.staticPathPrefix(expr)
+	if !ok || path == "" {
+		return
+	}
+	switch {
+	case strings.HasPrefix(path, "~"):
+		// Always outside, since HOME is the working directory in the restricted environment.
+	case filepath.IsAbs(path):
+	case relativeToWorkdir:
+		path = filepath.Join(c.workdir, path)
+	default:
+		return
+	}
+	path = filepath.Clean(path)
+	for _, dir := range c.allowed {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+	}
+	rng := expr.Range()
+	c.violations = append(c.violations, fmt.Errorf("%s:%d: the module refers to %s, which is outside of its "+
+		"working directory", rng.Filename, rng.Start.Line, path))
+}
+
+// staticPathPrefix returns the part of a path expression that is known before planning: the literal parts up to the
+// first interpolation, starting with the directory of path.module, path.root or path.cwd when they come first.
+func (c *restrictedPathsChecker) staticPathPrefix(expr hclsyntax.Expression) (string, bool) {
+	var parts []hclsyntax.Expression
+	switch expr := expr.(type) {
+	case *hclsyntax.TemplateWrapExpr:
+		parts = []hclsyntax.Expression{expr.Wrapped}
+	case *hclsyntax.TemplateExpr:
+		parts = expr.Parts
+	default:
+		parts = []hclsyntax.Expression{expr}
+	}
+
+	var prefix strings.Builder
+	for i, part := range parts {
+		switch part := part.(type) {
+		case *hclsyntax.LiteralValueExpr:
+			if part.Val.Type() != cty.String || part.Val.IsNull() {
+				return prefix.String(), i > 0
+			}
+			prefix.WriteString(part.Val.AsString())
+			continue
+		case *hclsyntax.ScopeTraversalExpr:
+			if dir, ok := c.pathTraversal(part.Traversal); ok && i == 0 {
+				prefix.WriteString(dir)
+				continue
+			}
+		}
+		return prefix.String(), i > 0
+	}
+	return prefix.String(), true
+}
+
+// pathTraversal resolves path.module, path.root and path.cwd.
+func (c *restrictedPathsChecker) pathTraversal(traversal hcl.Traversal) (string, bool) {
+	if traversal.RootName() != "path" || len(traversal) != 2 {
+		return "", false
+	}
+	attr, ok := traversal[1].(hcl.TraverseAttr)
+	if !ok {
+		return "", false
+	}
+	switch attr.Name {
+	case "module":
+		return c.moduleDir, true
+	case "root", "cwd":
+		return c.workdir, true
+	default:
+		return "", false
+	}
+}
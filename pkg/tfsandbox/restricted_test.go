@@ -0,0 +1,85 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRestrictedTestRuntime(t *testing.T) *ModuleRuntime {
+	stub, err := filepath.Abs(filepath.Join("testdata", "restricted", "stub.sh"))
+	require.NoError(t, err)
+	tf, err := NewRuntimeFromExecutable(context.Background(), DiscardLogger, Workdir{t.Name()}, nil, stub)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+	return tf
+}
+
+func TestCheckRestrictedPaths(t *testing.T) {
+	tf := newRestrictedTestRuntime(t)
+
+	assert.NoError(t, tf.CheckRestrictedPaths("./testdata/modules/restricted_paths/allowed"))
+
+	err := tf.CheckRestrictedPaths("./testdata/modules/restricted_paths/escaping")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "main.tf:2: the module refers to /etc/passwd, which is outside of its working "+
+		"directory")
+	assert.Regexp(t, `main.tf:6: the module refers to \S+/outside.tpl`, err.Error())
+	assert.ErrorContains(t, err, "main.tf:10: the module refers to ~/.ssh/authorized_keys")
+}
+
+func TestCheckRestrictedPathsOfDownloadedModules(t *testing.T) {
+	tf := newRestrictedTestRuntime(t)
+
+	moduleDir := filepath.Join(tf.WorkingDir(), ".terraform", "modules", "mymod")
+	require.NoError(t, os.MkdirAll(moduleDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf.json"), []byte(`{
+  "locals": {"key": "${file(\"/root/.aws/credentials\")}", "own": "${file(\"${path.root}/pulumi.tf.json\")}"}
+}`), 0o600))
+
+	err := tf.CheckRestrictedPaths("terraform-aws-modules/vpc/aws")
+	assert.ErrorContains(t, err, "the module refers to /root/.aws/credentials")
+	assert.NotContains(t, err.Error(), "pulumi.tf.json")
+}
+
+func TestRestrictEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+	tf := newRestrictedTestRuntime(t)
+
+	envFile := filepath.Join(t.TempDir(), "env")
+	t.Setenv("TF_STUB_ENV", envFile)
+	t.Setenv("AWS_PROFILE", "dev")
+	t.Setenv("GITHUB_TOKEN", "secret")
+	require.NoError(t, tf.RestrictEnvironment())
+	require.NoError(t, tf.Init(context.Background(), DiscardLogger))
+
+	contents, err := os.ReadFile(envFile)
+	require.NoError(t, err)
+	env := envMap(strings.Split(strings.TrimSpace(string(contents)), "\n"))
+	assert.Equal(t, "dev", env["AWS_PROFILE"])
+	assert.Equal(t, tf.WorkingDir(), env["HOME"])
+	assert.NotContains(t, env, "GITHUB_TOKEN")
+	assert.NotEmpty(t, env["PATH"])
+}
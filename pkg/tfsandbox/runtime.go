@@ -41,6 +41,25 @@ type ModuleRuntime struct {
 	reattach    *tfexec.ReattachInfo
 	description string
 	executable  string
+
+	// pluginCacheDir is the provider plugin cache shared with other runtimes, if enabled by UsePluginCache.
+	pluginCacheDir string
+	// initLimiter bounds the number of inits running at the same time, if set by LimitInits.
+	initLimiter *InitLimiter
+	// moduleCache shares downloaded modules with other runtimes, if enabled by UseModuleCache.
+	moduleCache *moduleCache
+	// tfLogPath is the file Terraform logs are written to, if enabled by PULUMI_TERRAFORM_MODULE_TF_LOG.
+	tfLogPath string
+	// restrictedEnv limits the environment of Terraform, if enabled by RestrictEnvironment.
+	restrictedEnv bool
+	// env is the environment of the Terraform commands set by setEnv. It is nil until then, as the commands inherit
+	// the environment of the process.
+	env map[string]string
+	// registryHost is the registry the executor resolves providers without an explicit host to, set by NewTofu and
+	// NewTerraform and detected for custom executables, see providerRegistryHost.
+	registryHost string
+	// pendingState is a state pushed from the other executor, written once init adapted it, see adaptToExecutor.
+	pendingState *pendingState
 }
 
 func (t *ModuleRuntime) Description() string {
@@ -149,12 +168,18 @@ func NewTofu(ctx context.Context,
 		description = fmt.Sprintf("Tofu CLI %s", resolveOptions.Version.String())
 	}
 
-	return &ModuleRuntime{
-		tf:          tf,
-		reattach:    reattach,
-		description: description,
-		executable:  execPath,
-	}, nil
+	t := &ModuleRuntime{
+		tf:           tf,
+		reattach:     reattach,
+		description:  description,
+		executable:   execPath,
+		registryHost: tofuRegistryHost,
+	}
+	t.logCommands(ctx, logger)
+	if err := t.enableTFLog(ctx, logger); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 // NewTerreform will create a new client which can be used to
@@ -205,12 +230,18 @@ func NewTerraform(ctx context.Context, logger Logger, workdir Workdir, auxServer
 	// 	 return nil, fmt.Errorf("error setting up plugin cache: %w", err)
 	// }
 
-	return &ModuleRuntime{
-		tf:          tf,
-		reattach:    reattach,
-		description: "Terraform CLI",
-		executable:  execPath,
-	}, nil
+	t := &ModuleRuntime{
+		tf:           tf,
+		reattach:     reattach,
+		description:  "Terraform CLI",
+		executable:   execPath,
+		registryHost: terraformRegistryHost,
+	}
+	t.logCommands(ctx, logger)
+	if err := t.enableTFLog(ctx, logger); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 func fileExists(filename string) bool {
@@ -242,12 +273,17 @@ func NewRuntimeFromExecutable(
 		reattach = &auxServer.ReattachInfo
 	}
 
-	return &ModuleRuntime{
+	t := &ModuleRuntime{
 		tf:          tf,
 		reattach:    reattach,
 		executable:  moduleExecutor,
 		description: fmt.Sprintf("module runtime from executable %s", moduleExecutor),
-	}, nil
+	}
+	t.logCommands(ctx, logger)
+	if err := t.enableTFLog(ctx, logger); err != nil {
+		return nil, err
+	}
+	return t, nil
 }
 
 // PickModuleRuntime will return a ModuleRuntime based on the provided moduleExecutor.
@@ -291,6 +327,21 @@ func PickModuleRuntime(
 	return NewTerraform(ctx, logger, workdir, auxServer)
 }
 
+// UsePluginCache makes init install providers through a plugin cache at cacheDir that is shared between module
+// instances. Neither Terraform nor OpenTofu install providers in parallel, so for modules requiring several providers
+// most of the init time goes into downloads that the cache avoids repeating.
+//
+// The cache is not safe for concurrent writes, so inits that may install providers into it are serialized with a file
+// lock; inits finding the providers of their dependency lock file in the cache run concurrently. This should be called
+// after the process environment is final, since the environment is captured at this point.
+func (t *ModuleRuntime) UsePluginCache(cacheDir string) error {
+	if err := os.MkdirAll(cacheDir, 0o700); err != nil {
+		return fmt.Errorf("error creating plugin cache dir: %w", err)
+	}
+	t.pluginCacheDir = cacheDir
+	return t.setEnv()
+}
+
 //nolint:unused
 func setupPluginCache(tf *tfexec.Terraform) error {
 	cacheDir, err := getPluginCacheDir()
@@ -323,8 +374,6 @@ func getPluginCacheDir() (string, error) {
 }
 
 // internal helper from tfexec
-//
-//nolint:unused
 func envMap(environ []string) map[string]string {
 	env := map[string]string{}
 	for _, ev := range environ {
@@ -17,10 +17,14 @@ package tfsandbox
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"os"
 	"path"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 )
@@ -71,7 +75,7 @@ func TestTofuPlan(t *testing.T) {
 	ms := TFModuleSource(path.Join(getCwd(t), "testdata", "modules", "test_module"))
 	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.NewPropertyMapFromMap(map[string]interface{}{
 		inputVarKey: testStr,
-	}), outputs, providersConfig)
+	}), outputs, providersConfig, CreateTFFileOpts{})
 	assert.NoErrorf(t, err, "error creating tf file")
 
 	err = tofu.Init(ctx, DiscardLogger)
@@ -85,6 +89,55 @@ func TestTofuPlan(t *testing.T) {
 	assert.Equal(t, "module.test.terraform_data.example", childModules[0].Resources[0].Address)
 }
 
+func TestTofuPlanWithExtraFiles(t *testing.T) {
+	tofu := newTestTofu(t)
+	ctx := context.Background()
+
+	sharedState := filepath.Join(t.TempDir(), "shared.tfstate")
+	err := os.WriteFile(sharedState, []byte(`{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "serial": 1,
+  "lineage": "shared",
+  "outputs": {"greeting": {"value": "hello", "type": "string"}},
+  "resources": []
+}`), 0600)
+	require.NoError(t, err)
+
+	// The extra file declares a data source and a resource referencing it next to the module call.
+	extraFiles := map[string]string{
+		"shared.tf": fmt.Sprintf(`
+data "terraform_remote_state" "shared" {
+  backend = "local"
+  config = {
+    path = %q
+  }
+}
+
+resource "terraform_data" "greeting" {
+  input = data.terraform_remote_state.shared.outputs.greeting
+}
+`, sharedState),
+	}
+
+	ms := TFModuleSource(path.Join(getCwd(t), "testdata", "modules", "test_module"))
+	err = CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{}, []TFOutputSpec{},
+		map[string]resource.PropertyMap{}, CreateTFFileOpts{ExtraFiles: extraFiles})
+	require.NoError(t, err)
+
+	err = tofu.Init(ctx, DiscardLogger)
+	require.NoError(t, err)
+
+	plan, err := tofu.plan(ctx, DiscardLogger)
+	require.NoError(t, err)
+
+	resources := plan.PlannedValues.RootModule.Resources
+	require.Len(t, resources, 1)
+	assert.Equal(t, "terraform_data.greeting", resources[0].Address)
+	assert.Equal(t, "hello", resources[0].AttributeValues["input"])
+	assert.Len(t, plan.PlannedValues.RootModule.ChildModules, 1)
+}
+
 func TestTofuApply(t *testing.T) {
 	tofu := newTestTofu(t)
 	t.Logf("WorkingDir: %s", tofu.WorkingDir())
@@ -95,7 +148,7 @@ func TestTofuApply(t *testing.T) {
 	providersConfig := map[string]resource.PropertyMap{}
 	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.NewPropertyMapFromMap(map[string]interface{}{
 		inputVarKey: testStr,
-	}), emptyOutputs, providersConfig)
+	}), emptyOutputs, providersConfig, CreateTFFileOpts{})
 	assert.NoErrorf(t, err, "error creating tf file")
 
 	err = tofu.Init(ctx, DiscardLogger)
@@ -113,6 +166,96 @@ func TestTofuApply(t *testing.T) {
 	assert.NoErrorf(t, err, "error running tofu destroy")
 }
 
+func TestTofuApplyAttributesErrors(t *testing.T) {
+	tofu := newTestTofu(t)
+	ctx := context.Background()
+
+	ms := TFModuleSource(path.Join(getCwd(t), "testdata", "modules", "failing_module"))
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{}, []TFOutputSpec{},
+		map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	err = tofu.Init(ctx, DiscardLogger)
+	require.NoError(t, err)
+
+	state, err := tofu.Apply(ctx, DiscardLogger, RefreshOpts{})
+	require.NotNil(t, state, "the resources that were created are kept")
+
+	var applyErr *ApplyError
+	require.ErrorAs(t, err, &applyErr)
+	require.Contains(t, applyErr.ResourceErrors, ResourceAddress("module.test.terraform_data.fails"))
+	assert.NotContains(t, applyErr.ResourceErrors, ResourceAddress("module.test.terraform_data.ok"))
+	assert.Contains(t, applyErr.ResourceErrors["module.test.terraform_data.fails"][0], "local-exec")
+}
+
+func TestTofuTaintedResourcesAreReplaced(t *testing.T) {
+	tofu := newTestTofu(t)
+	ctx := context.Background()
+
+	ms := TFModuleSource(path.Join(getCwd(t), "testdata", "modules", "failing_module"))
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{}, []TFOutputSpec{},
+		map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err)
+	require.NoError(t, tofu.Init(ctx, DiscardLogger))
+
+	// The provisioner fails after the resource is created, which leaves it tainted.
+	state, err := tofu.Apply(ctx, DiscardLogger, RefreshOpts{})
+	require.Error(t, err)
+	require.NotNil(t, state)
+
+	fails, ok := state.FindResourceState("module.test.terraform_data.fails")
+	require.True(t, ok)
+	assert.True(t, fails.Tainted())
+	notFailing, ok := state.FindResourceState("module.test.terraform_data.ok")
+	require.True(t, ok)
+	assert.False(t, notFailing.Tainted())
+
+	// The next up replaces the tainted resource.
+	plan, err := tofu.PlanNoRefresh(ctx, DiscardLogger)
+	require.NoError(t, err)
+	rplan, ok := plan.FindResourcePlan("module.test.terraform_data.fails")
+	require.True(t, ok)
+	assert.Equal(t, ReplaceDestroyBeforeCreate, rplan.ChangeKind())
+
+	// Untainted resources are kept instead.
+	require.NoError(t, tofu.Untaint(ctx, "module.test.terraform_data.fails"))
+	plan, err = tofu.PlanNoRefresh(ctx, DiscardLogger)
+	require.NoError(t, err)
+	if rplan, ok := plan.FindResourcePlan("module.test.terraform_data.fails"); ok {
+		assert.Equal(t, NoOp, rplan.ChangeKind())
+	}
+}
+
+func TestTofuPlanCreateBeforeDestroy(t *testing.T) {
+	tofu := newTestTofu(t)
+	ctx := context.Background()
+
+	ms := TFModuleSource(path.Join(getCwd(t), "testdata", "modules", "create_before_destroy"))
+	writeTFFile := func(generation int) {
+		err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.NewPropertyMapFromMap(map[string]any{
+			"generation": generation,
+		}), []TFOutputSpec{}, map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+		require.NoError(t, err)
+	}
+
+	writeTFFile(1)
+	require.NoError(t, tofu.Init(ctx, DiscardLogger))
+	_, err := tofu.Apply(ctx, DiscardLogger, RefreshOpts{})
+	require.NoError(t, err)
+
+	writeTFFile(2)
+	plan, err := tofu.PlanNoRefresh(ctx, DiscardLogger)
+	require.NoError(t, err)
+
+	cbd, ok := plan.FindResourcePlan("module.test.terraform_data.cbd")
+	require.True(t, ok)
+	assert.Equal(t, Replace, cbd.ChangeKind())
+
+	dbc, ok := plan.FindResourcePlan("module.test.terraform_data.dbc")
+	require.True(t, ok)
+	assert.Equal(t, ReplaceDestroyBeforeCreate, dbc.ChangeKind())
+}
+
 func TestPickModuleRuntime(t *testing.T) {
 	srv := newTestAuxProviderServer(t)
 	ctx := context.Background()
@@ -146,3 +289,28 @@ func TestPickModuleRuntime(t *testing.T) {
 	assert.Equal(t, tf.executable, tfPath.executable)
 	assert.Contains(t, tfPath.Description(), "module runtime from executable "+tf.executable)
 }
+
+// BenchmarkInitMultiProvider measures init for a module requiring several providers, with and without a shared
+// plugin cache. Every iteration uses a fresh working directory like a new module instance would.
+//
+//	go test ./pkg/tfsandbox -run '^$' -bench BenchmarkInitMultiProvider -benchtime 5x
+func BenchmarkInitMultiProvider(b *testing.B) {
+	ctx := context.Background()
+	ms := TFModuleSource(path.Join(getCwd(b), "testdata", "modules", "multi_provider"))
+
+	for _, cache := range []bool{false, true} {
+		b.Run(fmt.Sprintf("pluginCache=%v", cache), func(b *testing.B) {
+			cacheDir := b.TempDir()
+			for b.Loop() {
+				tofu := newTestTofu(b)
+				if cache {
+					require.NoError(b, tofu.UsePluginCache(cacheDir))
+				}
+				err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), resource.PropertyMap{},
+					[]TFOutputSpec{}, map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+				require.NoError(b, err)
+				require.NoError(b, tofu.Init(ctx, DiscardLogger))
+			}
+		})
+	}
+}
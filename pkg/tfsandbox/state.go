@@ -44,8 +44,14 @@ func (t *ModuleRuntime) PullStateAndLockFile(_ context.Context) (state json.RawM
 }
 
 // PushStateAndLockFile writes the state and lock file to the Tofu working directory.
-func (t *ModuleRuntime) PushStateAndLockFile(_ context.Context, state json.RawMessage, lock []byte) error {
-	if err := t.pushState(context.Background(), state); err != nil {
+//
+// The state and lock file may have been produced by a different executor if the executor was switched between
+// terraform and tofu since the last deployment. The state is then only written by the next init, once it adapted the
+// state to the executor, see [ModuleRuntime.adaptToExecutor].
+func (t *ModuleRuntime) PushStateAndLockFile(ctx context.Context, state json.RawMessage, lock []byte) error {
+	if t.fromOtherExecutor(ctx, state, lock) {
+		t.pendingState = &pendingState{state: state, lock: lock}
+	} else if err := t.pushState(context.Background(), state); err != nil {
 		return err
 	}
 	if err := t.pushLockFile(context.Background(), lock); err != nil {
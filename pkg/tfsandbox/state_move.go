@@ -0,0 +1,269 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// relativeResourceAddressPattern matches addresses of resources or resource instances relative to the module block
+// of a module instance, such as aws_subnet.private, aws_subnet.private[0] or module.sub["a"].aws_iam_role.this.
+var relativeResourceAddressPattern = regexp.MustCompile(
+	`^((?:module\.[A-Za-z0-9_-]+(?:\[[^\]]*\])?\.)*)(data\.)?([A-Za-z0-9_-]+)\.([A-Za-z0-9_-]+)(\[[^\]]*\])?$`)
+
+// moduleInstanceKeyPattern matches the instance keys in the module path of a resource, as in module.sub["a"].
+var moduleInstanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// MoveResourceStates moves the states of resources from the Terraform state of one module instance to that of
+// another, as when a module instance is split into two without replacing the resources it manages.
+//
+// The two instances are identified by the names of their module blocks. Addresses are relative to the module block,
+// so aws_s3_bucket.this refers to module.<fromModule>.aws_s3_bucket.this in the source state and is moved to
+// module.<toModule>.aws_s3_bucket.this in the target state. An address without an instance key moves every instance
+// of the resource. Dependencies of the moved instances on resources moved along with them are retargeted to the
+// target module block, dependencies on resources staying behind are kept. The serials of both states are incremented.
+// The number of resource instances moved is returned along with the two states.
+func MoveResourceStates(
+	from, to json.RawMessage,
+	fromModule, toModule string,
+	addresses []string,
+) (json.RawMessage, json.RawMessage, int, error) {
+	fromState, err := decodeRawState(from)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to decode the source state: %w", err)
+	}
+	toState, err := decodeRawState(to)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to decode the target state: %w", err)
+	}
+
+	fromPrefix, toPrefix := "module."+fromModule, "module."+toModule
+	var moved []map[string]any
+	movedResources := map[string]bool{}
+	for _, address := range addresses {
+		m := relativeResourceAddressPattern.FindStringSubmatch(address)
+		if m == nil {
+			return nil, nil, 0, fmt.Errorf("invalid resource address %q", address)
+		}
+		mode := "managed"
+		if m[2] != "" {
+			mode = "data"
+		}
+		source := rawResourceKey{module: strings.TrimSuffix(fromPrefix+"."+m[1], "."), mode: mode, typ: m[3], name: m[4]}
+		target := source
+		target.module = strings.TrimSuffix(toPrefix+"."+m[1], ".")
+
+		resource, instances, err := fromState.takeInstances(source, m[5])
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("failed to move %q: %w", address, err)
+		}
+		if err := toState.putInstances(target, resource, instances); err != nil {
+			return nil, nil, 0, fmt.Errorf("failed to move %q: %w", address, err)
+		}
+		moved = append(moved, instances...)
+		movedResources[source.configAddress()] = true
+	}
+	for _, instance := range moved {
+		rewriteDependencies(instance, movedResources, fromPrefix, toPrefix)
+	}
+
+	newFrom, err := fromState.encode()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	newTo, err := toState.encode()
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return newFrom, newTo, len(moved), nil
+}
+
+// rawResourceKey identifies a resource in the resources list of a raw Terraform state.
+type rawResourceKey struct {
+	module string
+	mode   string
+	typ    string
+	name   string
+}
+
+// rawState is a Terraform state decoded just enough to move resources around. Everything else is preserved as is.
+type rawState struct {
+	fields    map[string]any
+	resources []map[string]any
+}
+
+func decodeRawState(state json.RawMessage) (*rawState, error) {
+	dec := json.NewDecoder(bytes.NewReader(state))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, errors.New("the state is empty")
+	}
+	s := &rawState{fields: fields}
+	resources, _ := fields["resources"].([]any)
+	for _, r := range resources {
+		resource, ok := r.(map[string]any)
+		if !ok {
+			return nil, errors.New("resources must be objects")
+		}
+		s.resources = append(s.resources, resource)
+	}
+	return s, nil
+}
+
+func (s *rawState) find(key rawResourceKey) int {
+	return slices.IndexFunc(s.resources, func(r map[string]any) bool {
+		return r["module"] == key.module && r["mode"] == key.mode && r["type"] == key.typ && r["name"] == key.name
+	})
+}
+
+// takeInstances removes the instance of the resource with the given key, formatted as in [0] or ["a"], or all of
+// its instances when the key is empty. It returns the resource the instances were taken from.
+func (s *rawState) takeInstances(key rawResourceKey, instanceKey string) (map[string]any, []map[string]any, error) {
+	i := s.find(key)
+	if i < 0 {
+		return nil, nil, fmt.Errorf("resource not found in the state of %s", key.moduleBlock())
+	}
+	resource := s.resources[i]
+	instances := resourceInstances(resource)
+
+	var taken []map[string]any
+	if instanceKey == "" {
+		taken, instances = instances, nil
+	} else {
+		j := slices.IndexFunc(instances, func(inst map[string]any) bool { return formatInstanceKey(inst) == instanceKey })
+		if j < 0 {
+			return nil, nil, fmt.Errorf("instance %s not found in the state of %s", instanceKey, key.moduleBlock())
+		}
+		taken = []map[string]any{instances[j]}
+		instances = slices.Delete(instances, j, j+1)
+	}
+
+	if len(instances) == 0 {
+		s.resources = slices.Delete(s.resources, i, i+1)
+	} else {
+		resource["instances"] = instances
+	}
+	return resource, taken, nil
+}
+
+// putInstances adds instances to the resource with the given key, creating it after the template if needed.
+func (s *rawState) putInstances(key rawResourceKey, template map[string]any, instances []map[string]any) error {
+	i := s.find(key)
+	if i < 0 {
+		resource := maps.Clone(template)
+		resource["module"] = key.module
+		resource["instances"] = instances
+		s.resources = append(s.resources, resource)
+		return nil
+	}
+
+	existing := resourceInstances(s.resources[i])
+	for _, inst := range instances {
+		if slices.ContainsFunc(existing, func(e map[string]any) bool {
+			return formatInstanceKey(e) == formatInstanceKey(inst)
+		}) {
+			return fmt.Errorf("the resource already exists in the state of %s", key.moduleBlock())
+		}
+	}
+	s.resources[i]["instances"] = append(existing, instances...)
+	return nil
+}
+
+func (s *rawState) encode() (json.RawMessage, error) {
+	resources := make([]any, len(s.resources))
+	for i, r := range s.resources {
+		resources[i] = r
+	}
+	s.fields["resources"] = resources
+
+	if serial, ok := s.fields["serial"].(json.Number); ok {
+		n, err := strconv.ParseInt(serial.String(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state serial %q: %w", serial, err)
+		}
+		s.fields["serial"] = n + 1
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(s.fields); err != nil {
+		return nil, err
+	}
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// configAddress is the address of the resource in the configuration, without module instance keys, as recorded in
+// the dependencies of resource instances.
+func (k rawResourceKey) configAddress() string {
+	address := moduleInstanceKeyPattern.ReplaceAllString(k.module, "") + "."
+	if k.mode == "data" {
+		address += "data."
+	}
+	return address + k.typ + "." + k.name
+}
+
+func (k rawResourceKey) moduleBlock() string {
+	block, _, _ := strings.Cut(k.module, ".module.")
+	return block
+}
+
+func resourceInstances(resource map[string]any) []map[string]any {
+	raw, _ := resource["instances"].([]any)
+	instances := make([]map[string]any, 0, len(raw))
+	for _, inst := range raw {
+		if inst, ok := inst.(map[string]any); ok {
+			instances = append(instances, inst)
+		}
+	}
+	return instances
+}
+
+// formatInstanceKey formats the index_key of a resource instance as it appears in resource addresses.
+func formatInstanceKey(instance map[string]any) string {
+	switch key := instance["index_key"].(type) {
+	case json.Number:
+		return "[" + key.String() + "]"
+	case string:
+		return fmt.Sprintf("[%q]", key)
+	default:
+		return ""
+	}
+}
+
+// rewriteDependencies points the dependencies of a moved instance on the moved resources, identified by their
+// addresses in the source module block, to the target module block. Dependencies on other resources are left
+// as they are.
+func rewriteDependencies(instance map[string]any, moved map[string]bool, fromPrefix, toPrefix string) {
+	deps, _ := instance["dependencies"].([]any)
+	for i, dep := range deps {
+		if d, ok := dep.(string); ok && moved[d] {
+			deps[i] = toPrefix + strings.TrimPrefix(d, fromPrefix)
+		}
+	}
+}
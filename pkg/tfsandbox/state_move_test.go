@@ -0,0 +1,170 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const networkState = `{
+  "version": 4,
+  "serial": 7,
+  "lineage": "network-lineage",
+  "outputs": {},
+  "resources": [
+    {
+      "module": "module.network",
+      "mode": "managed",
+      "type": "aws_vpc",
+      "name": "this",
+      "provider": "provider[\"registry.opentofu.org/hashicorp/aws\"]",
+      "instances": [{"schema_version": 1, "attributes": {"id": "vpc-1"}}]
+    },
+    {
+      "module": "module.network",
+      "mode": "managed",
+      "type": "aws_subnet",
+      "name": "private",
+      "provider": "provider[\"registry.opentofu.org/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": 0, "attributes": {"id": "subnet-0"}, "dependencies": ["module.network.aws_vpc.this"]},
+        {"index_key": 1, "attributes": {"id": "subnet-1"}, "dependencies": ["module.network.aws_vpc.this"]}
+      ]
+    }
+  ]
+}`
+
+const subnetsState = `{
+  "version": 4,
+  "serial": 2,
+  "lineage": "subnets-lineage",
+  "outputs": {},
+  "resources": []
+}`
+
+type testRawState struct {
+	Serial    int    `json:"serial"`
+	Lineage   string `json:"lineage"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Provider  string `json:"provider"`
+		Instances []struct {
+			IndexKey     any            `json:"index_key"`
+			Attributes   map[string]any `json:"attributes"`
+			Dependencies []string       `json:"dependencies"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+func TestMoveResourceStates(t *testing.T) {
+	t.Parallel()
+
+	decode := func(t *testing.T, state json.RawMessage) testRawState {
+		var s testRawState
+		require.NoError(t, json.Unmarshal(state, &s))
+		return s
+	}
+
+	t.Run("instance", func(t *testing.T) {
+		t.Parallel()
+
+		from, to, moved, err := MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"aws_subnet.private[1]"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, moved)
+
+		fromState := decode(t, from)
+		assert.Equal(t, 8, fromState.Serial)
+		assert.Equal(t, "network-lineage", fromState.Lineage)
+		require.Len(t, fromState.Resources, 2)
+		require.Len(t, fromState.Resources[1].Instances, 1)
+		assert.Equal(t, "subnet-0", fromState.Resources[1].Instances[0].Attributes["id"])
+
+		toState := decode(t, to)
+		assert.Equal(t, 3, toState.Serial)
+		assert.Equal(t, "subnets-lineage", toState.Lineage)
+		require.Len(t, toState.Resources, 1)
+		subnet := toState.Resources[0]
+		assert.Equal(t, "module.subnets", subnet.Module)
+		assert.Equal(t, "aws_subnet", subnet.Type)
+		assert.Equal(t, `provider["registry.opentofu.org/hashicorp/aws"]`, subnet.Provider)
+		require.Len(t, subnet.Instances, 1)
+		assert.Equal(t, float64(1), subnet.Instances[0].IndexKey)
+		assert.Equal(t, "subnet-1", subnet.Instances[0].Attributes["id"])
+		assert.Equal(t, []string{"module.network.aws_vpc.this"}, subnet.Instances[0].Dependencies,
+			"the VPC stays behind")
+	})
+
+	t.Run("dependencies moved along", func(t *testing.T) {
+		t.Parallel()
+
+		_, to, moved, err := MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"aws_subnet.private", "aws_vpc.this"})
+		require.NoError(t, err)
+		assert.Equal(t, 3, moved)
+
+		toState := decode(t, to)
+		require.Len(t, toState.Resources, 2)
+		subnets := toState.Resources[0]
+		assert.Equal(t, "aws_subnet", subnets.Type)
+		require.Len(t, subnets.Instances, 2)
+		for _, instance := range subnets.Instances {
+			assert.Equal(t, []string{"module.subnets.aws_vpc.this"}, instance.Dependencies)
+		}
+	})
+
+	t.Run("all instances", func(t *testing.T) {
+		t.Parallel()
+
+		from, to, moved, err := MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"aws_subnet.private"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, moved, "every instance of the resource is moved")
+
+		fromState := decode(t, from)
+		require.Len(t, fromState.Resources, 1)
+		assert.Equal(t, "aws_vpc", fromState.Resources[0].Type)
+
+		toState := decode(t, to)
+		require.Len(t, toState.Resources, 1)
+		assert.Len(t, toState.Resources[0].Instances, 2)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, _, err := MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"aws_subnet.public"})
+		assert.ErrorContains(t, err, `failed to move "aws_subnet.public": resource not found in the state of module.network`)
+
+		_, _, _, err = MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"aws_subnet.private[2]"})
+		assert.ErrorContains(t, err, "instance [2] not found")
+
+		_, _, _, err = MoveResourceStates([]byte(networkState), []byte(networkState), "network", "network",
+			[]string{"aws_vpc.this"})
+		assert.ErrorContains(t, err, "the resource already exists in the state of module.network")
+
+		_, _, _, err = MoveResourceStates([]byte(networkState), []byte(subnetsState), "network", "subnets",
+			[]string{"not an address"})
+		assert.ErrorContains(t, err, `invalid resource address "not an address"`)
+	})
+}
@@ -19,9 +19,12 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
@@ -52,7 +55,7 @@ func TestState(t *testing.T) {
 			err := CreateTFFile(testStr, ms, "", tf.WorkingDir(),
 				resource.NewPropertyMapFromMap(map[string]interface{}{
 					inputVarKey: testStr,
-				}), outputs, providersConfig)
+				}), outputs, providersConfig, CreateTFFileOpts{})
 			require.NoError(t, err, "error creating tf file")
 
 			err = tf.Init(ctx, DiscardLogger)
@@ -162,7 +165,7 @@ func TestStateMatchesPlan(t *testing.T) {
 			}
 			emptyProviders := map[string]resource.PropertyMap{}
 			err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(),
-				resource.NewPropertyMapFromMap(inputs), outputs, emptyProviders)
+				resource.NewPropertyMapFromMap(inputs), outputs, emptyProviders, CreateTFFileOpts{})
 			require.NoError(t, err, "error creating tf file")
 
 			err = tofu.Init(ctx, DiscardLogger)
@@ -201,6 +204,18 @@ func (t *testLogger) LogStatus(_ context.Context, _ LogLevel, input string) {
 	contract.AssertNoErrorf(err, "test logger failed to write")
 }
 
+type levelRecordingLogger struct {
+	messages []string
+}
+
+func (l *levelRecordingLogger) Log(_ context.Context, level LogLevel, msg string) {
+	l.messages = append(l.messages, string(level)+": "+msg)
+}
+
+func (l *levelRecordingLogger) LogStatus(_ context.Context, level LogLevel, msg string) {
+	l.messages = append(l.messages, string(level)+": "+msg)
+}
+
 func TestSecretOutputs(t *testing.T) {
 	t.Run("nested secrets", func(t *testing.T) {
 		ctx := context.Background()
@@ -219,7 +234,7 @@ func TestSecretOutputs(t *testing.T) {
 		}
 		emptyProviders := map[string]resource.PropertyMap{}
 		err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(),
-			resource.NewPropertyMapFromMap(inputs), outputs, emptyProviders)
+			resource.NewPropertyMapFromMap(inputs), outputs, emptyProviders, CreateTFFileOpts{})
 		require.NoError(t, err, "error creating tf file")
 
 		err = tofu.Init(ctx, logger)
@@ -248,3 +263,148 @@ func TestSecretOutputs(t *testing.T) {
 		}, moduleOutputs)
 	})
 }
+
+// Test that an object holding lists of objects with secret and unknown leaves flows into a module variable and back
+// out of the module.
+func TestNestedComplexInput(t *testing.T) {
+	ctx := context.Background()
+
+	tofu := newTestTofu(t)
+	var buffer bytes.Buffer
+	logger := &testLogger{r: &buffer}
+
+	ms := TFModuleSource(filepath.Join(getCwd(t), "testdata", "modules", "nested_object_module"))
+	outputs := []TFOutputSpec{{Name: "service"}}
+	emptyProviders := map[string]resource.PropertyMap{}
+
+	listener := func(port, token resource.PropertyValue) resource.PropertyValue {
+		return resource.NewObjectProperty(resource.PropertyMap{
+			"port":  port,
+			"token": token,
+			"tags": resource.NewObjectProperty(resource.PropertyMap{
+				"env": resource.NewStringProperty("dev"),
+			}),
+		})
+	}
+	service := func(secondPort resource.PropertyValue) resource.PropertyMap {
+		return resource.PropertyMap{
+			"service": resource.NewObjectProperty(resource.PropertyMap{
+				"name": resource.NewStringProperty(testStr),
+				"listeners": resource.NewArrayProperty([]resource.PropertyValue{
+					listener(resource.NewNumberProperty(80), resource.MakeSecret(resource.NewStringProperty("t1"))),
+					resource.NewOutputProperty(resource.Output{
+						Known:   true,
+						Element: listener(secondPort, resource.MakeSecret(resource.NewStringProperty("t2"))),
+					}),
+				}),
+			}),
+		}
+	}
+
+	// During previews the port of the second listener is not known yet.
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(),
+		service(resource.MakeComputed(resource.NewStringProperty(""))), outputs, emptyProviders, CreateTFFileOpts{})
+	require.NoError(t, err, "error creating tf file")
+
+	err = tofu.Init(ctx, logger)
+	require.NoErrorf(t, err, "error running tofu init: %s", buffer.String())
+	plan, err := tofu.Plan(ctx, logger)
+	require.NoErrorf(t, err, "error running tofu plan: %s", buffer.String())
+	require.True(t, plan.Outputs()["service"].ContainsUnknowns(), "expected an unknown output, got %v", plan.Outputs())
+
+	err = CreateTFFile(testStr, ms, "", tofu.WorkingDir(),
+		service(resource.NewNumberProperty(443)), outputs, emptyProviders, CreateTFFileOpts{})
+	require.NoError(t, err, "error creating tf file")
+
+	state, err := tofu.Apply(ctx, logger, RefreshOpts{})
+	require.NoErrorf(t, err, "error running tofu apply: %s", buffer.String())
+
+	// The secret leaves make the whole output secret.
+	require.Equal(t, resource.PropertyMap{
+		"service": resource.MakeSecret(resource.NewObjectProperty(resource.PropertyMap{
+			"name": resource.NewStringProperty(testStr),
+			"listeners": resource.NewArrayProperty([]resource.PropertyValue{
+				listener(resource.NewNumberProperty(80), resource.NewStringProperty("t1")),
+				listener(resource.NewNumberProperty(443), resource.NewStringProperty("t2")),
+			}),
+		})),
+	}, state.Outputs())
+}
+
+func TestPushStateAndLockFileFromOtherExecutor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "executor_switch", "stub.sh"))
+	require.NoError(t, err)
+
+	terraformState := json.RawMessage(`{"version":4,"terraform_version":"1.5.7","resources":[` +
+		`{"mode":"managed","type":"aws_s3_bucket","name":"this",` +
+		`"provider":"module.b.provider[\"registry.terraform.io/hashicorp/aws\"]"},` +
+		`{"mode":"managed","type":"google_storage_bucket","name":"this",` +
+		`"provider":"module.b.provider[\"registry.terraform.io/hashicorp/google\"]"}]}`)
+	terraformLock := []byte(`provider "registry.terraform.io/hashicorp/aws" {
+  version = "5.94.1"
+}
+
+provider "registry.terraform.io/hashicorp/google" {
+  version = "6.30.0"
+}
+`)
+
+	newRuntime := func(t *testing.T, executable string) *ModuleRuntime {
+		tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{"executor_switch", t.Name()}, nil,
+			executable)
+		require.NoError(t, err)
+		t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+
+		// the module names the registry of the google provider, but not that of the aws provider
+		moduleDir := filepath.Join(tf.WorkingDir(), modulesDir, "b")
+		require.NoError(t, os.MkdirAll(moduleDir, 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "main.tf"), []byte(`terraform {
+  required_providers {
+    aws    = { source = "hashicorp/aws" }
+    google = { source = "registry.terraform.io/hashicorp/google" }
+  }
+}
+`), 0o600))
+		require.NoError(t, writeModuleManifest(filepath.Join(tf.WorkingDir(), modulesDir), ModuleManifest{
+			Modules: []ModuleManifestRecord{{Key: "", Dir: "."}, {Key: "b", Dir: filepath.Join(modulesDir, "b")}},
+		}))
+		return tf
+	}
+
+	t.Run("terraform state is moved to tofu", func(t *testing.T) {
+		tofu := newRuntime(t, stub)
+		require.NoError(t, tofu.PushStateAndLockFile(ctx, terraformState, terraformLock))
+		assert.NoFileExists(t, filepath.Join(tofu.WorkingDir(), defaultStateFile), "the state waits for init")
+
+		logger := &levelRecordingLogger{}
+		require.NoError(t, tofu.Init(ctx, logger))
+
+		state, lock, err := tofu.PullStateAndLockFile(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, string(state), `module.b.provider[\"registry.opentofu.org/hashicorp/aws\"]`)
+		assert.Contains(t, string(state), `module.b.provider[\"registry.terraform.io/hashicorp/google\"]`,
+			"providers from an explicit registry stay")
+		assert.NotContains(t, string(state), `registry.terraform.io/hashicorp/aws`)
+		assert.Equal(t, string(terraformLock), string(lock), "the lock file is kept")
+		assert.Contains(t, logger.messages, "warn: The dependency lock file was written by another executor; "+
+			"init selected the providers hashicorp/aws again from registry.opentofu.org, possibly at newer versions")
+	})
+
+	t.Run("terraform state is unchanged for terraform", func(t *testing.T) {
+		// the stub reports to be Terraform under that name
+		terraformStub := filepath.Join(t.TempDir(), terraformName)
+		require.NoError(t, os.Symlink(stub, terraformStub))
+		tf := newRuntime(t, terraformStub)
+		require.NoError(t, tf.PushStateAndLockFile(ctx, terraformState, terraformLock))
+
+		state, lock, err := tf.PullStateAndLockFile(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(terraformState), string(state))
+		require.Equal(t, string(terraformLock), string(lock))
+	})
+}
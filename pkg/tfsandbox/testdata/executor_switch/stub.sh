@@ -0,0 +1,20 @@
+#!/bin/sh
+# Stub executor reporting its version like OpenTofu, or like Terraform when run through a link named terraform.
+# Init changes nothing in the working directory.
+product=OpenTofu
+if [ "$(basename "$0")" = terraform ]; then
+  product=Terraform
+fi
+case "$1" in
+  version)
+    if [ "$2" = -json ]; then
+      echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{}}'
+    else
+      echo "$product v1.9.0"
+      echo "on linux_amd64"
+    fi
+    ;;
+  init)
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
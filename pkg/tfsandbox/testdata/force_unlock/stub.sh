@@ -0,0 +1,15 @@
+#!/bin/sh
+# Stub executor emulating a locking backend: the ID of the held lock is stored in held.lock in the working directory.
+case "$1" in
+  force-unlock)
+    for id; do :; done
+    if [ "$(cat held.lock 2>/dev/null)" = "$id" ]; then
+      rm held.lock
+      echo "Terraform state has been successfully unlocked!"
+    else
+      echo "Failed to unlock state: lock ID \"$id\" does not match the existing lock" >&2
+      exit 1
+    fi
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
@@ -0,0 +1,20 @@
+#!/bin/sh
+# Stub executor whose init downloads the registry module called by the STUB_MODULE_KEY block unless modules.json
+# records it already, as Terraform does. Every download is counted by appending a line to STUB_DOWNLOADS.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    if grep -q "\"Key\":\"$STUB_MODULE_KEY\"" .terraform/modules/modules.json 2>/dev/null; then
+      exit 0
+    fi
+    mkdir -p ".terraform/modules/$STUB_MODULE_KEY/modules/subnets"
+    echo 'variable "cidr" {}' > ".terraform/modules/$STUB_MODULE_KEY/main.tf"
+    echo 'variable "count" {}' > ".terraform/modules/$STUB_MODULE_KEY/modules/subnets/main.tf"
+    printf '{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"%s","Source":"registry.opentofu.org/terraform-aws-modules/vpc/aws","Version":"5.19.0","Dir":".terraform/modules/%s"},{"Key":"%s.subnets","Source":"./modules/subnets","Dir":".terraform/modules/%s/modules/subnets"}]}' \
+      "$STUB_MODULE_KEY" "$STUB_MODULE_KEY" "$STUB_MODULE_KEY" "$STUB_MODULE_KEY" > .terraform/modules/modules.json
+    echo "$STUB_MODULE_KEY" >> "$STUB_DOWNLOADS"
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
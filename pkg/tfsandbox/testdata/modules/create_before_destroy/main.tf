@@ -0,0 +1,15 @@
+variable "generation" {
+  type = number
+}
+
+resource "terraform_data" "cbd" {
+  triggers_replace = var.generation
+
+  lifecycle {
+    create_before_destroy = true
+  }
+}
+
+resource "terraform_data" "dbc" {
+  triggers_replace = var.generation
+}
@@ -0,0 +1,18 @@
+variable "greeting" {
+  type    = string
+  default = "hello"
+}
+
+variable "name" {
+  type     = string
+  default  = "world"
+  nullable = false
+}
+
+output "greeting" {
+  value = var.greeting
+}
+
+output "name" {
+  value = var.name
+}
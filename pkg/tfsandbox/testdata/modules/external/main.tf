@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    external = {
+      source = "hashicorp/external"
+    }
+  }
+}
+
+variable "name" {
+  type = string
+}
+
+data "external" "query" {
+  program = ["sh", "${path.module}/query.sh"]
+  query = {
+    name = var.name
+  }
+}
+
+output "name" {
+  value = data.external.query.result.name
+}
+
+output "greeting" {
+  value = data.external.query.result.greeting
+}
+
+output "working_dir" {
+  value = data.external.query.result.working_dir
+}
@@ -0,0 +1,5 @@
+#!/bin/sh
+# Echoes the name of the query along with the environment and the directory the script runs in.
+set -e
+name=$(sed -e 's/.*"name" *: *"\([^"]*\)".*/\1/')
+printf '{"name":"%s","greeting":"%s","working_dir":"%s"}\n' "$name" "$EXTERNAL_TEST_GREETING" "$(pwd)"
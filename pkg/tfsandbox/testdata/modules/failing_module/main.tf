@@ -0,0 +1,9 @@
+resource "terraform_data" "ok" {
+  input = "ok"
+}
+
+resource "terraform_data" "fails" {
+  provisioner "local-exec" {
+    command = "echo failing on purpose && exit 1"
+  }
+}
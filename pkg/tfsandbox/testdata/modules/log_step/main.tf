@@ -0,0 +1,9 @@
+variable "log_file" {
+  type = string
+}
+
+resource "terraform_data" "step" {
+  provisioner "local-exec" {
+    command = "echo module >> ${var.log_file}"
+  }
+}
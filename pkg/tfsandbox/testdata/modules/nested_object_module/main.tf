@@ -0,0 +1,18 @@
+variable "service" {
+  type = object({
+    name = string
+    listeners = list(object({
+      port  = number
+      token = string
+      tags  = map(string)
+    }))
+  })
+}
+
+resource "terraform_data" "service" {
+  input = var.service
+}
+
+output "service" {
+  value = terraform_data.service.output
+}
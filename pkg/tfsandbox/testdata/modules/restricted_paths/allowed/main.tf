@@ -0,0 +1,12 @@
+resource "aws_cloudwatch_log_group" "this" {
+  # not a file
+  name = "/aws/lambda/handler"
+}
+
+resource "terraform_data" "config" {
+  input = templatefile("${path.module}/config.tpl", { name = var.name })
+}
+
+variable "name" {
+  type = string
+}
@@ -0,0 +1,12 @@
+resource "terraform_data" "credentials" {
+  input = file("/etc/passwd")
+}
+
+resource "terraform_data" "config" {
+  input = templatefile("${path.module}/../../../../../../outside.tpl", {})
+}
+
+resource "local_file" "key" {
+  filename = "~/.ssh/authorized_keys"
+  content  = "ssh-ed25519 AAAA"
+}
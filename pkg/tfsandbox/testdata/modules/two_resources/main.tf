@@ -0,0 +1,7 @@
+resource "terraform_data" "first" {
+  input = "first"
+}
+
+resource "terraform_data" "second" {
+  input = "second"
+}
@@ -0,0 +1,16 @@
+#!/bin/sh
+# Stub executor whose init takes a while. Every init marks itself as running in STUB_INITS and, before finishing,
+# appends the number of inits running at that point to STUB_OVERLAPS.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    mkdir -p "$STUB_INITS"
+    touch "$STUB_INITS/$$"
+    sleep 0.5
+    ls "$STUB_INITS" | wc -l | tr -d ' ' >> "$STUB_OVERLAPS"
+    rm "$STUB_INITS/$$"
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
@@ -0,0 +1,11 @@
+#!/bin/sh
+# Stub executor whose init writes the environment it runs with to TF_STUB_ENV, so that it can be inspected.
+case "$1" in
+  version)
+    echo '{"terraform_version":"1.9.0","platform":"linux_amd64","provider_selections":{},"terraform_outdated":false}'
+    ;;
+  init)
+    env > "$TF_STUB_ENV"
+    ;;
+  *) echo "unsupported command: $1" >&2; exit 1 ;;
+esac
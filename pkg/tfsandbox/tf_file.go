@@ -2,11 +2,17 @@ package tfsandbox
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
 	"github.com/pulumi/pulumi-terraform-bridge/v3/pkg/tfbridge"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -23,6 +29,7 @@ const (
 	unknownProxyResourceOutputProp = "value"
 	terraformIsSecretOutputPrefix  = "internal_output_is_secret_"
 	pulumiTFJsonFileName           = "pulumi.tf.json"
+	extraTFFilePrefix              = "pulumi-extra-"
 )
 
 func writeTerraformFilesToDirectory() (string, bool) {
@@ -33,6 +40,24 @@ func writeTerraformFilesToDirectory() (string, bool) {
 	return writeDir, writeDir != ""
 }
 
+// writeTerraformFilesAsHCL reports whether the files written to the directory of writeTerraformFilesToDirectory use
+// the native Terraform syntax rather than JSON, which PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_FORMAT=hcl selects.
+func writeTerraformFilesAsHCL() bool {
+	return strings.EqualFold(os.Getenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_FORMAT"), "hcl")
+}
+
+// redactedLocalValue replaces the values of the locals holding secrets in the files written to the directory of
+// writeTerraformFilesToDirectory.
+const redactedLocalValue = "[secret]"
+
+// writeTerraformFilesWithSecrets reports whether the files written to the directory of
+// writeTerraformFilesToDirectory keep secret values in plaintext. Secrets are redacted unless
+// PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_SECRETS=plaintext is set, since these files are meant to be shared when
+// debugging and outlive the working directory.
+func writeTerraformFilesWithSecrets() bool {
+	return strings.EqualFold(os.Getenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_SECRETS"), "plaintext")
+}
+
 type locals struct {
 	entries map[string]interface{}
 	counter int
@@ -57,12 +82,17 @@ func (l *locals) createLocal(v interface{}) string {
 // For each secret that we encounter we first create a local variable to store the value, and then we replace the secret
 // value with a reference to that local `${sensitive(local.<key>)}`
 //
+// Secret maps and objects keep their keys visible: rather than hiding the whole value, every value inside of them is
+// made sensitive on its own, see decodeSecret. Plans and diffs then show which keys changed without revealing their
+// values.
+//
 // For example, this Pulumi ts code:
 //
 //	new module.Module("name", {
 //	   property: pulumi.secret({
 //	       key: {
-//	           nestedKey: pulumi.secret("value")
+//	           nestedKey: "value",
+//	           list: ["a", "b"]
 //	       }
 //	   })
 //	})
@@ -71,20 +101,25 @@ func (l *locals) createLocal(v interface{}) string {
 //
 //		{
 //	    "locals": {
-//	      "local2": {
-//	        "key": {
-//	          "nestedKey": "${sensitive(local.local1)}"
-//	        }
-//	      },
+//	      "local1": ["a", "b"],
 //	      "local2": "value"
 //	    },
-//		   "property": "${sensitive(local.local2)}"
+//		   "property": {
+//	      "key": {
+//	        "list": "${sensitive(local.local1)}",
+//	        "nestedKey": "${sensitive(local.local2)}"
+//	      }
+//	    }
 //		}
 func (l *locals) decode(pv resource.PropertyValue) (interface{}, bool) {
 	// paranoid asserts
 	contract.Assertf(!pv.IsAsset(), "did not expect assets here")
 	contract.Assertf(!pv.IsArchive(), "did not expect archives here")
-	contract.Assertf(!pv.IsResourceReference(), "did not expect resource references here")
+
+	// Resource references are passed to Terraform as the scalar identifying the resource
+	if pv.IsResourceReference() {
+		return resourceReferenceValue(pv.ResourceReferenceValue()).MapRepl(nil, l.decode), true
+	}
 
 	// Replace computed's with references and stop
 	if pv.IsComputed() || (pv.IsOutput() && !pv.OutputValue().Known) {
@@ -98,15 +133,11 @@ func (l *locals) decode(pv resource.PropertyValue) (interface{}, bool) {
 	// secret values are encoded using the sensitive function
 	// and we need to recurse depth first to handle nested secrets
 	if pv.IsSecret() {
-		result := pv.SecretValue().Element.MapRepl(nil, l.decode)
-		key := l.createLocal(result)
-		return fmt.Sprintf("${sensitive(local.%s)}", key), true
+		return l.decodeSecret(pv.SecretValue().Element), true
 	}
 
 	if pv.IsOutput() && pv.OutputValue().Secret {
-		result := pv.OutputValue().Element.MapRepl(nil, l.decode)
-		key := l.createLocal(result)
-		return fmt.Sprintf("${sensitive(local.%s)}", key), true
+		return l.decodeSecret(pv.OutputValue().Element), true
 	}
 
 	// If the output value is known, process the underlying value
@@ -118,6 +149,95 @@ func (l *locals) decode(pv resource.PropertyValue) (interface{}, bool) {
 	return nil, false
 }
 
+// decodeSecret decodes the element of a secret value. Objects, which is also how Terraform maps are passed, are
+// decoded key by key in a stable order with every value made sensitive, so that only the values are hidden. Any other
+// value is stored in a local and referenced through the sensitive function.
+func (l *locals) decodeSecret(element resource.PropertyValue) interface{} {
+	if element.IsObject() {
+		obj := element.ObjectValue()
+		result := make(map[string]interface{}, len(obj))
+		for _, k := range obj.StableKeys() {
+			v := obj[k]
+			// The value is made sensitive already, so there is no need to wrap it twice.
+			for v.IsSecret() {
+				v = v.SecretValue().Element
+			}
+			result[string(k)] = l.decodeSecret(v)
+		}
+		return result
+	}
+
+	result := element.MapRepl(nil, l.decode)
+	key := l.createLocal(result)
+	return fmt.Sprintf("${sensitive(local.%s)}", key)
+}
+
+// resourceReferenceValue returns the value a resource reference passed as a module input stands for: the ID of a
+// custom resource, which may not be known yet, or the URN of a component resource, which has no ID.
+func resourceReferenceValue(ref resource.ResourceReference) resource.PropertyValue {
+	switch {
+	case ref.ID.IsComputed():
+		return ref.ID
+	case ref.ID.IsString() && ref.ID.StringValue() != "":
+		return ref.ID
+	}
+	return resource.NewStringProperty(string(ref.URN))
+}
+
+// CreateTFFileOpts customizes the generated pulumi.tf.json file.
+type CreateTFFileOpts struct {
+	// ProviderMeta is written to terraform.provider_meta blocks, keyed by provider name. Providers use these to
+	// receive module-level metadata such as attribution, see
+	// https://developer.hashicorp.com/terraform/internals/provider-meta. Terraform only sends it to the resources of
+	// the module declaring the blocks, which is the generated root module rather than the called one.
+	ProviderMeta map[string]resource.PropertyMap
+
+	// ExtraFiles holds additional Terraform files to write next to the generated file, keyed by file name. They may
+	// declare supplementary data sources, locals and resources around the module call but not redefine it.
+	ExtraFiles map[string]string
+
+	// DependsOn lists references to resources or data sources, usually declared in ExtraFiles, that the module call
+	// depends on. They are written to the depends_on meta-argument of the module block, so Terraform only runs the
+	// module after them.
+	DependsOn []string
+
+	// FullGitClone downloads modules from git sources with their whole history. By default they are shallow cloned,
+	// see [TFModuleSource.WithShallowClone].
+	FullGitClone bool
+}
+
+// containsUnknowns is like resource.PropertyValue.ContainsUnknowns but also looks into known outputs, which may hold
+// unknown values deeper in the input.
+func containsUnknowns(pv resource.PropertyValue) bool {
+	switch {
+	case pv.IsComputed():
+		return true
+	case pv.IsOutput():
+		return !pv.OutputValue().Known || containsUnknowns(pv.OutputValue().Element)
+	case pv.IsSecret():
+		return containsUnknowns(pv.SecretValue().Element)
+	case pv.IsResourceReference():
+		return containsUnknowns(resourceReferenceValue(pv.ResourceReferenceValue()))
+	case pv.IsArray():
+		return slices.ContainsFunc(pv.ArrayValue(), containsUnknowns)
+	case pv.IsObject():
+		for _, v := range pv.ObjectValue() {
+			if containsUnknowns(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UnwrapSecrets is a replacer for resource.PropertyValue.MapRepl that maps secrets to their plain values.
+func UnwrapSecrets(pv resource.PropertyValue) (interface{}, bool) {
+	if pv.IsSecret() {
+		return pv.SecretValue().Element.MapRepl(nil, UnwrapSecrets), true
+	}
+	return nil, false
+}
+
 // Writes a pulumi.tf.json file in the workingDir that instructs Terraform to call a given module instance.
 // Unknown inputs (e.g. output values) are handled by using a "auxprovider.unk" resource as a proxy.
 func CreateTFFile(
@@ -128,6 +248,7 @@ func CreateTFFile(
 	inputs resource.PropertyMap,
 	outputs []TFOutputSpec,
 	providerConfig map[string]resource.PropertyMap,
+	opts CreateTFFileOpts,
 ) error {
 	absoluteSource := string(source)
 	if source.IsLocalPath() {
@@ -136,6 +257,8 @@ func CreateTFFile(
 		if err != nil {
 			return err
 		}
+	} else if !opts.FullGitClone {
+		absoluteSource = string(source.WithShallowClone())
 	}
 
 	moduleProps := map[string]interface{}{
@@ -153,13 +276,27 @@ func CreateTFFile(
 	// see https://developer.hashicorp.com/terraform/language/syntax/json
 	tfFile := map[string]interface{}{
 		// NOTE: other available sections
-		// "terraform": map[string]interface{}{},
 		// "provider":  map[string]interface{}{},
 		// "locals":    map[string]interface{}{},
 		// "variable":  map[string]interface{}{},
 	}
 
-	containsUnknowns := inputs.ContainsUnknowns()
+	if len(opts.ProviderMeta) > 0 {
+		providerMeta := map[string]interface{}{}
+		for providerName, meta := range opts.ProviderMeta {
+			// provider_meta blocks only accept static values, so secrets are not preserved here.
+			providerMeta[providerName] = meta.MapRepl(nil, UnwrapSecrets)
+		}
+		tfFile["terraform"] = map[string]interface{}{
+			"provider_meta": providerMeta,
+		}
+	}
+
+	// Provider configurations may reference unknowns too, they are decoded the same way as the inputs.
+	hasUnknowns := containsUnknowns(resource.NewObjectProperty(inputs))
+	for _, config := range providerConfig {
+		hasUnknowns = hasUnknowns || containsUnknowns(resource.NewObjectProperty(config))
+	}
 
 	resources := map[string]map[string]interface{}{}
 	mOutputs := map[string]map[string]interface{}{}
@@ -167,7 +304,7 @@ func CreateTFFile(
 
 	// NOTE: this should only happen at plan time. At apply time all computed values
 	// should be resolved
-	if containsUnknowns {
+	if hasUnknowns {
 		resources[unknownProxyResourceType] = map[string]interface{}{
 			unknownProxyResourceName: map[string]interface{}{},
 		}
@@ -197,6 +334,10 @@ func CreateTFFile(
 		moduleProps[tfKey] = v
 	}
 
+	if len(opts.DependsOn) > 0 {
+		moduleProps["depends_on"] = opts.DependsOn
+	}
+
 	if len(providers) > 0 {
 		providersField := map[string]string{}
 		for providerName := range providers {
@@ -268,6 +409,10 @@ func CreateTFFile(
 		return err
 	}
 
+	if err := writeExtraTFFiles(name, workingDir, opts.ExtraFiles); err != nil {
+		return err
+	}
+
 	if writeDir, ok := writeTerraformFilesToDirectory(); ok {
 		if _, err := os.Stat(writeDir); os.IsNotExist(err) {
 			// create the directory if it doesn't exist
@@ -276,7 +421,25 @@ func CreateTFFile(
 			}
 		}
 
+		if len(locals.entries) > 0 && !writeTerraformFilesWithSecrets() {
+			// Every local holds a secret, see locals.decode.
+			redacted := make(map[string]interface{}, len(locals.entries))
+			for key := range locals.entries {
+				redacted[key] = redactedLocalValue
+			}
+			tfFile["locals"] = redacted
+			if contents, err = json.MarshalIndent(tfFile, "", "  "); err != nil {
+				return err
+			}
+		}
+
 		file := path.Join(writeDir, fmt.Sprintf("%s.tf.json", name))
+		if writeTerraformFilesAsHCL() {
+			file = path.Join(writeDir, fmt.Sprintf("%s.tf", name))
+			if contents, err = formatTFFileAsHCL(contents); err != nil {
+				return err
+			}
+		}
 		if err := os.WriteFile(file, contents, 0600); err != nil {
 			return err
 		}
@@ -284,3 +447,61 @@ func CreateTFFile(
 
 	return nil
 }
+
+// writeExtraTFFiles writes the user-provided Terraform files into the working directory. The files are prefixed so
+// that files dropped from the configuration since the previous operation can be removed.
+func writeExtraTFFiles(moduleName string, workingDir string, files map[string]string) error {
+	stale, err := filepath.Glob(filepath.Join(workingDir, extraTFFilePrefix+"*"))
+	if err != nil {
+		return err
+	}
+	for _, f := range stale {
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+	}
+
+	for fileName, contents := range files {
+		if err := validateExtraTFFile(moduleName, fileName, contents); err != nil {
+			return fmt.Errorf("invalid extra Terraform file %q: %w", fileName, err)
+		}
+		err := os.WriteFile(filepath.Join(workingDir, extraTFFilePrefix+fileName), []byte(contents), 0600)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateExtraTFFile(moduleName string, fileName string, contents string) error {
+	if fileName != filepath.Base(fileName) {
+		return errors.New("file names must not contain path separators")
+	}
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	switch {
+	case strings.HasSuffix(fileName, ".tf.json"):
+		file, diags = hcljson.Parse([]byte(contents), fileName)
+	case strings.HasSuffix(fileName, ".tf"):
+		file, diags = hclsyntax.ParseConfig([]byte(contents), fileName, hcl.InitialPos)
+	default:
+		return errors.New("file names must end in .tf or .tf.json")
+	}
+	if diags.HasErrors() {
+		return diags
+	}
+
+	content, _, diags := file.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "module", LabelNames: []string{"name"}}},
+	})
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, block := range content.Blocks {
+		if block.Labels[0] == moduleName {
+			return fmt.Errorf("redefines the module block %q", moduleName)
+		}
+	}
+	return nil
+}
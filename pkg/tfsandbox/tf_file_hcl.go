@@ -0,0 +1,173 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// formatTFFileAsHCL renders the Terraform JSON configuration generated by CreateTFFile in the native Terraform
+// syntax, which is easier to read when inspecting the files written with PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE.
+// Terraform keeps running the JSON configuration, so the rendering favors readability over fidelity: nested blocks of
+// provider configurations, for example, are rendered as object attributes.
+func formatTFFileAsHCL(contents []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	var tfFile map[string]map[string]any
+	if err := decoder.Decode(&tfFile); err != nil {
+		return nil, fmt.Errorf("failed to decode the Terraform JSON configuration: %w", err)
+	}
+
+	f := hclwrite.NewEmptyFile()
+	body := f.Body()
+
+	if terraform, ok := tfFile["terraform"]; ok {
+		terraformBlock := body.AppendNewBlock("terraform", nil).Body()
+		for _, name := range sortedKeys(objectValue(terraform["provider_meta"])) {
+			meta := objectValue(terraform["provider_meta"])[name]
+			setAttributes(terraformBlock.AppendNewBlock("provider_meta", []string{name}).Body(), objectValue(meta))
+		}
+	}
+	for _, name := range sortedKeys(tfFile["provider"]) {
+		setAttributes(appendBlock(body, "provider", name), objectValue(tfFile["provider"][name]))
+	}
+	if locals, ok := tfFile["locals"]; ok {
+		setAttributes(appendBlock(body, "locals"), locals)
+	}
+	for _, resourceType := range sortedKeys(tfFile["resource"]) {
+		resources := objectValue(tfFile["resource"][resourceType])
+		for _, name := range sortedKeys(resources) {
+			setAttributes(appendBlock(body, "resource", resourceType, name), objectValue(resources[name]))
+		}
+	}
+	for _, name := range sortedKeys(tfFile["module"]) {
+		props := objectValue(tfFile["module"][name])
+		moduleBlock := appendBlock(body, "module", name)
+		for _, k := range sortedKeys(props) {
+			switch k {
+			case "depends_on":
+				// references to resources, written without interpolation sequences
+				var refs []hclwrite.Tokens
+				for _, ref := range props[k].([]any) {
+					refs = append(refs, rawTokens(ref.(string)))
+				}
+				moduleBlock.SetAttributeRaw(k, hclwrite.TokensForTuple(refs))
+			case "providers":
+				// provider configurations passed to the module, keyed by their names in the module
+				var attrs []hclwrite.ObjectAttrTokens
+				for _, providerName := range sortedKeys(objectValue(props[k])) {
+					attrs = append(attrs, hclwrite.ObjectAttrTokens{
+						Name:  hclwrite.TokensForIdentifier(providerName),
+						Value: rawTokens(objectValue(props[k])[providerName].(string)),
+					})
+				}
+				moduleBlock.SetAttributeRaw(k, hclwrite.TokensForObject(attrs))
+			default:
+				moduleBlock.SetAttributeRaw(k, valueTokens(props[k]))
+			}
+		}
+	}
+	for _, name := range sortedKeys(tfFile["output"]) {
+		setAttributes(appendBlock(body, "output", name), objectValue(tfFile["output"][name]))
+	}
+
+	return hclwrite.Format(f.Bytes()), nil
+}
+
+func appendBlock(body *hclwrite.Body, blockType string, labels ...string) *hclwrite.Body {
+	if len(body.Blocks()) > 0 {
+		body.AppendNewline()
+	}
+	return body.AppendNewBlock(blockType, labels).Body()
+}
+
+func setAttributes(body *hclwrite.Body, attrs map[string]any) {
+	for _, k := range sortedKeys(attrs) {
+		body.SetAttributeRaw(k, valueTokens(attrs[k]))
+	}
+}
+
+// valueTokens renders a value of the JSON configuration as an expression.
+func valueTokens(v any) hclwrite.Tokens {
+	switch v := v.(type) {
+	case map[string]any:
+		var attrs []hclwrite.ObjectAttrTokens
+		for _, k := range sortedKeys(v) {
+			name := hclwrite.TokensForValue(cty.StringVal(k))
+			if hclsyntax.ValidIdentifier(k) {
+				name = hclwrite.TokensForIdentifier(k)
+			}
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: name, Value: valueTokens(v[k])})
+		}
+		return hclwrite.TokensForObject(attrs)
+	case []any:
+		var elems []hclwrite.Tokens
+		for _, e := range v {
+			elems = append(elems, valueTokens(e))
+		}
+		return hclwrite.TokensForTuple(elems)
+	case string:
+		return templateTokens(v)
+	case json.Number:
+		return hclwrite.Tokens{{Type: hclsyntax.TokenNumberLit, Bytes: []byte(v.String())}}
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(v))
+	default:
+		return hclwrite.TokensForValue(cty.NullVal(cty.DynamicPseudoType))
+	}
+}
+
+// templateTokens renders a string of the JSON configuration, which Terraform evaluates as a template. A string that
+// consists of a single interpolation such as ${local.local1} is rendered as the interpolated expression.
+func templateTokens(s string) hclwrite.Tokens {
+	if inner, ok := strings.CutPrefix(s, "${"); ok && strings.HasSuffix(inner, "}") {
+		expr := strings.TrimSuffix(inner, "}")
+		if !strings.ContainsAny(expr, "{}") {
+			return rawTokens(expr)
+		}
+	}
+
+	tokens := hclwrite.TokensForValue(cty.StringVal(s))
+	for _, token := range tokens {
+		if token.Type == hclsyntax.TokenQuotedLit {
+			// TokensForValue escapes template sequences, which are meant to be evaluated here
+			token.Bytes = bytes.ReplaceAll(token.Bytes, []byte("$${"), []byte("${"))
+			token.Bytes = bytes.ReplaceAll(token.Bytes, []byte("%%{"), []byte("%{"))
+		}
+	}
+	return tokens
+}
+
+func rawTokens(expr string) hclwrite.Tokens {
+	return hclwrite.Tokens{{Type: hclsyntax.TokenIdent, Bytes: []byte(expr)}}
+}
+
+func objectValue(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	return slices.Sorted(maps.Keys(m))
+}
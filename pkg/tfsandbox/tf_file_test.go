@@ -3,15 +3,18 @@ package tfsandbox
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/pulumi/opentofu/configs"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 )
 
@@ -281,7 +284,7 @@ func TestCreateTFFile(t *testing.T) {
 			err = CreateTFFile("simple", TFModuleSource(localModulePath), "",
 				tofu.WorkingDir(), resource.PropertyMap{
 					"tfVar": tt.inputsValue,
-				}, tt.outputs, tt.providersConfig)
+				}, tt.outputs, tt.providersConfig, CreateTFFileOpts{})
 			assert.NoError(t, err)
 
 			contents, err := os.ReadFile(filepath.Join(tofu.WorkingDir(), pulumiTFJsonFileName))
@@ -299,6 +302,431 @@ func TestCreateTFFile(t *testing.T) {
 	}
 }
 
+func TestCreateTFFileProviderMeta(t *testing.T) {
+	t.Parallel()
+	workingDir := t.TempDir()
+
+	err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, resource.PropertyMap{},
+		nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{
+			ProviderMeta: map[string]resource.PropertyMap{
+				"aws": {
+					"module_name": resource.NewStringProperty("networking"),
+					"team":        resource.MakeSecret(resource.NewStringProperty("platform")),
+				},
+			},
+		})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+	require.NoError(t, err)
+
+	var tfFile map[string]any
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+	assert.Equal(t, map[string]any{
+		"provider_meta": map[string]any{
+			"aws": map[string]any{
+				"module_name": "networking",
+				"team":        "platform",
+			},
+		},
+	}, tfFile["terraform"])
+}
+
+func TestCreateTFFileExtraFiles(t *testing.T) {
+	t.Parallel()
+	workingDir := t.TempDir()
+
+	extraFiles := map[string]string{
+		"shared.tf": `
+data "terraform_remote_state" "shared" {
+  backend = "local"
+}
+
+locals {
+  shared_outputs = data.terraform_remote_state.shared.outputs
+}
+`,
+	}
+	err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, resource.PropertyMap{},
+		nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{ExtraFiles: extraFiles})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, extraTFFilePrefix+"shared.tf"))
+	require.NoError(t, err)
+	assert.Equal(t, extraFiles["shared.tf"], string(contents))
+
+	// Files dropped from the configuration are removed.
+	err = CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, resource.PropertyMap{},
+		nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(workingDir, extraTFFilePrefix+"shared.tf"))
+	assert.FileExists(t, filepath.Join(workingDir, pulumiTFJsonFileName))
+}
+
+func TestCreateTFFileDependsOn(t *testing.T) {
+	t.Parallel()
+	workingDir := t.TempDir()
+
+	err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, resource.PropertyMap{},
+		nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{
+			DependsOn: []string{"terraform_data.gate"},
+		})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Module map[string]map[string]any `json:"module"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+	assert.Equal(t, []any{"terraform_data.gate"}, tfFile.Module["simple"]["depends_on"])
+}
+
+func TestCreateTFFileNullInputs(t *testing.T) {
+	t.Parallel()
+	workingDir := t.TempDir()
+
+	err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, resource.PropertyMap{
+		"cidr": resource.NewNullProperty(),
+	}, nil /* outputs */, map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+	require.NoError(t, err)
+
+	var tfFile struct {
+		Module map[string]map[string]any `json:"module"`
+	}
+	require.NoError(t, json.Unmarshal(contents, &tfFile))
+
+	// Explicit nulls are passed to the module, while inputs that are not set are left out.
+	cidr, ok := tfFile.Module["simple"]["cidr"]
+	assert.True(t, ok, "expected the null input in %s", contents)
+	assert.Nil(t, cidr)
+	assert.NotContains(t, tfFile.Module["simple"], "name")
+}
+
+func TestCreateTFFileShallowClonesGitSources(t *testing.T) {
+	t.Parallel()
+
+	moduleSource := func(t *testing.T, opts CreateTFFileOpts) any {
+		workingDir := t.TempDir()
+		err := CreateTFFile("simple", "git::https://example.com/vpc.git?ref=v1.2.0", "", workingDir,
+			resource.PropertyMap{}, nil /* outputs */, map[string]resource.PropertyMap{}, opts)
+		require.NoError(t, err)
+
+		contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+		require.NoError(t, err)
+
+		var tfFile struct {
+			Module map[string]map[string]any `json:"module"`
+		}
+		require.NoError(t, json.Unmarshal(contents, &tfFile))
+		return tfFile.Module["simple"]["source"]
+	}
+
+	assert.Equal(t, "git::https://example.com/vpc.git?ref=v1.2.0&depth=1", moduleSource(t, CreateTFFileOpts{}))
+	assert.Equal(t, "git::https://example.com/vpc.git?ref=v1.2.0", moduleSource(t, CreateTFFileOpts{
+		FullGitClone: true,
+	}))
+}
+
+func TestOmittedInputsUseModuleDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	ms := TFModuleSource(filepath.Join(getCwd(t), "testdata", "modules", "defaults"))
+	outputs := []TFOutputSpec{{Name: "greeting"}, {Name: "name"}}
+
+	apply := func(t *testing.T, inputs resource.PropertyMap) resource.PropertyMap {
+		tofu := newTestTofu(t)
+		err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(), inputs, outputs,
+			map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+		require.NoError(t, err, "error creating tf file")
+
+		err = tofu.Init(ctx, DiscardLogger)
+		require.NoError(t, err, "error running tofu init")
+		state, err := tofu.Apply(ctx, DiscardLogger, RefreshOpts{})
+		require.NoError(t, err, "error running tofu apply")
+		return state.Outputs()
+	}
+
+	t.Run("omitted", func(t *testing.T) {
+		assert.Equal(t, resource.PropertyMap{
+			"greeting": resource.NewStringProperty("hello"),
+			"name":     resource.NewStringProperty("world"),
+		}, apply(t, resource.PropertyMap{}))
+	})
+
+	t.Run("null", func(t *testing.T) {
+		// Null overrides the default of nullable variables only.
+		assert.Equal(t, resource.PropertyMap{
+			"greeting": resource.NewNullProperty(),
+			"name":     resource.NewStringProperty("world"),
+		}, apply(t, resource.PropertyMap{
+			"greeting": resource.NewNullProperty(),
+			"name":     resource.NewNullProperty(),
+		}))
+	})
+}
+
+func TestExternalDataSourceScriptsRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the external program is a shell script")
+	}
+	ctx := context.Background()
+	t.Setenv("EXTERNAL_TEST_GREETING", "hello")
+
+	ms := TFModuleSource(filepath.Join(getCwd(t), "testdata", "modules", "external"))
+	tofu := newTestTofu(t)
+	err := CreateTFFile(testStr, ms, "", tofu.WorkingDir(),
+		resource.PropertyMap{"name": resource.NewStringProperty("world")},
+		[]TFOutputSpec{{Name: "name"}, {Name: "greeting"}, {Name: "working_dir"}},
+		map[string]resource.PropertyMap{}, CreateTFFileOpts{})
+	require.NoError(t, err, "error creating tf file")
+
+	err = tofu.Init(ctx, DiscardLogger)
+	require.NoError(t, err, "error running tofu init")
+	state, err := tofu.Apply(ctx, DiscardLogger, RefreshOpts{})
+	require.NoError(t, err, "error running tofu apply")
+
+	workingDir, err := filepath.EvalSymlinks(tofu.WorkingDir())
+	require.NoError(t, err)
+	// The script receives the process environment and, like with Terraform, runs in the root module directory.
+	assert.Equal(t, resource.PropertyMap{
+		"name":        resource.NewStringProperty("world"),
+		"greeting":    resource.NewStringProperty("hello"),
+		"working_dir": resource.NewStringProperty(workingDir),
+	}, state.Outputs())
+}
+
+func TestCreateTFFileWritesHCL(t *testing.T) {
+	writeDir := t.TempDir()
+	t.Setenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE", writeDir)
+	t.Setenv("PULUMI_TERRAFORM_MODULE_WRITE_TF_FILE_FORMAT", "hcl")
+
+	inputs := resource.PropertyMap{
+		"name":     resource.NewStringProperty(`vpc-"main"`),
+		"cidr":     resource.MakeComputed(resource.NewStringProperty("")),
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"tags": resource.NewObjectProperty(resource.PropertyMap{
+			"team":             resource.NewStringProperty("network"),
+			"kubernetes.io/cl": resource.NewStringProperty("shared"),
+		}),
+		"azs":     resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("us-east-1a")}),
+		"max_azs": resource.NewNumberProperty(3),
+		"flag":    resource.NewBoolProperty(true),
+	}
+	err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", t.TempDir(), inputs,
+		[]TFOutputSpec{{Name: "vpc_id"}},
+		map[string]resource.PropertyMap{"aws": {"region": resource.NewStringProperty("us-east-1")}},
+		CreateTFFileOpts{
+			ProviderMeta: map[string]resource.PropertyMap{
+				"aws": {"module_name": resource.NewStringProperty("vpc")},
+			},
+			DependsOn: []string{"terraform_data.gate"},
+		})
+	require.NoError(t, err)
+
+	assert.NoFileExists(t, filepath.Join(writeDir, "simple.tf.json"))
+	contents, err := os.ReadFile(filepath.Join(writeDir, "simple.tf"))
+	require.NoError(t, err)
+	t.Logf("%s", contents)
+
+	parser := configs.NewParser(nil)
+	module, diags := parser.LoadConfigDir(writeDir, configs.NewStaticModuleCall(nil, nil, "", ""))
+	require.False(t, diags.HasErrors(), "the dumped file is not valid: %v", diags)
+
+	require.Contains(t, module.ModuleCalls, "simple")
+	assert.Equal(t, "terraform-aws-modules/vpc/aws", module.ModuleCalls["simple"].SourceAddrRaw)
+	assert.Len(t, module.ModuleCalls["simple"].DependsOn, 1)
+	assert.Len(t, module.ModuleCalls["simple"].Providers, 1)
+	assert.Contains(t, module.ProviderConfigs, "aws")
+	assert.Contains(t, module.Locals, "local1")
+	assert.Contains(t, module.ManagedResources, unknownProxyResourceType+"."+unknownProxyResourceName)
+	assert.Contains(t, module.Outputs, "vpc_id")
+	assert.Contains(t, module.Outputs, terraformIsSecretOutputPrefix+"vpc_id")
+	// Interpolations are rendered as plain expressions.
+	assert.Regexp(t, `password += sensitive\(local\.local1\)`, string(contents))
+	assert.Regexp(t, `cidr += pulumiaux_unk\.unknown_proxy\.value`, string(contents))
+}
+
+func TestCreateTFFileNestedUnknowns(t *testing.T) {
+	t.Parallel()
+
+	nestedUnknown := resource.NewObjectProperty(resource.PropertyMap{
+		"listeners": resource.NewArrayProperty([]resource.PropertyValue{
+			resource.NewOutputProperty(resource.Output{
+				Known: true,
+				Element: resource.NewObjectProperty(resource.PropertyMap{
+					"port":  resource.MakeComputed(resource.NewStringProperty("")),
+					"token": resource.MakeSecret(resource.NewStringProperty("t1")),
+				}),
+			}),
+		}),
+	})
+
+	tests := []struct {
+		name            string
+		inputs          resource.PropertyMap
+		providersConfig map[string]resource.PropertyMap
+	}{
+		{
+			name:   "unknown in a known output",
+			inputs: resource.PropertyMap{"service": nestedUnknown},
+		},
+		{
+			name:   "unknown in a secret",
+			inputs: resource.PropertyMap{"service": resource.MakeSecret(nestedUnknown)},
+		},
+		{
+			name:   "unknown in a provider configuration",
+			inputs: resource.PropertyMap{},
+			providersConfig: map[string]resource.PropertyMap{
+				"aws": {"region": resource.MakeComputed(resource.NewStringProperty(""))},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			workingDir := t.TempDir()
+
+			err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir, tt.inputs,
+				nil /* outputs */, tt.providersConfig, CreateTFFileOpts{})
+			require.NoError(t, err)
+
+			contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+			require.NoError(t, err)
+
+			// Every reference to the unknown proxy must resolve to a declared resource.
+			require.Contains(t, string(contents), unknownProxyValueRef)
+			var tfFile struct {
+				Resource map[string]map[string]any `json:"resource"`
+			}
+			require.NoError(t, json.Unmarshal(contents, &tfFile))
+			assert.Contains(t, tfFile.Resource[unknownProxyResourceType], unknownProxyResourceName,
+				"expected the unknown proxy resource in %s", contents)
+		})
+	}
+}
+
+func TestCreateTFFileResourceReferences(t *testing.T) {
+	t.Parallel()
+
+	bucketURN := resource.URN("urn:pulumi:test::proj::aws:s3/bucket:Bucket::bucket")
+	componentURN := resource.URN("urn:pulumi:test::proj::my:index:Component::component")
+
+	tests := []struct {
+		name     string
+		input    resource.PropertyValue
+		expected any
+	}{
+		{
+			name:     "custom resource",
+			input:    resource.MakeCustomResourceReference(bucketURN, "bucket-1234", ""),
+			expected: "bucket-1234",
+		},
+		{
+			name: "custom resource with an unknown ID",
+			input: resource.NewResourceReferenceProperty(resource.ResourceReference{
+				URN: bucketURN,
+				ID:  resource.MakeComputed(resource.NewStringProperty("")),
+			}),
+			expected: unknownProxyValueRef,
+		},
+		{
+			name:     "component resource",
+			input:    resource.MakeComponentResourceReference(componentURN, ""),
+			expected: string(componentURN),
+		},
+		{
+			name: "secret resource reference",
+			input: resource.MakeSecret(
+				resource.MakeCustomResourceReference(bucketURN, "bucket-1234", "")),
+			expected: sensitiveLocal1Ref,
+		},
+		{
+			name: "resource references in a list",
+			input: resource.NewArrayProperty([]resource.PropertyValue{
+				resource.MakeCustomResourceReference(bucketURN, "bucket-1234", ""),
+			}),
+			expected: []any{"bucket-1234"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			workingDir := t.TempDir()
+
+			err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", workingDir,
+				resource.PropertyMap{"bucket": tt.input}, nil /* outputs */, nil /* providersConfig */, CreateTFFileOpts{})
+			require.NoError(t, err)
+
+			contents, err := os.ReadFile(filepath.Join(workingDir, pulumiTFJsonFileName))
+			require.NoError(t, err)
+
+			var tfFile struct {
+				Module   map[string]map[string]any `json:"module"`
+				Resource map[string]map[string]any `json:"resource"`
+			}
+			require.NoError(t, json.Unmarshal(contents, &tfFile))
+			assert.Equal(t, tt.expected, tfFile.Module["simple"]["bucket"])
+			if tt.expected == unknownProxyValueRef {
+				assert.Contains(t, tfFile.Resource[unknownProxyResourceType], unknownProxyResourceName)
+			}
+		})
+	}
+}
+
+func TestCreateTFFileRejectsInvalidExtraFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		extraFiles    map[string]string
+		expectedError string
+	}{
+		{
+			name:          "redefines the module",
+			extraFiles:    map[string]string{"override.tf": `module "simple" { source = "./elsewhere" }`},
+			expectedError: `invalid extra Terraform file "override.tf": redefines the module block "simple"`,
+		},
+		{
+			name:          "redefines the module in JSON",
+			extraFiles:    map[string]string{"override.tf.json": `{"module": {"simple": {"source": "./elsewhere"}}}`},
+			expectedError: `invalid extra Terraform file "override.tf.json": redefines the module block "simple"`,
+		},
+		{
+			name:          "not a Terraform file",
+			extraFiles:    map[string]string{"notes.txt": "hello"},
+			expectedError: "file names must end in .tf or .tf.json",
+		},
+		{
+			name:          "escapes the working directory",
+			extraFiles:    map[string]string{"../outside.tf": ""},
+			expectedError: "file names must not contain path separators",
+		},
+		{
+			name:          "does not parse",
+			extraFiles:    map[string]string{"broken.tf": `locals {`},
+			expectedError: "broken.tf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := CreateTFFile("simple", "terraform-aws-modules/vpc/aws", "5.19.0", t.TempDir(),
+				resource.PropertyMap{}, nil /* outputs */, map[string]resource.PropertyMap{},
+				CreateTFFileOpts{ExtraFiles: tt.extraFiles})
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
 func Test_decode(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -477,6 +905,51 @@ func Test_decode(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "secret map keeps its keys visible",
+			inputsValue: resource.PropertyMap{
+				key1Key: resource.MakeSecret(resource.NewObjectProperty(resource.PropertyMap{
+					"db_password":  resource.NewStringProperty("hunter2"),
+					"api_token":    resource.MakeSecret(resource.NewStringProperty("t0k3n")),
+					"replica_urls": resource.NewArrayProperty([]resource.PropertyValue{resource.NewStringProperty("a")}),
+					"nested": resource.NewObjectProperty(resource.PropertyMap{
+						keyKey: resource.NewNumberProperty(8),
+					}),
+				})),
+			},
+			expected: map[string]interface{}{
+				key1Key: map[string]interface{}{
+					"api_token":    sensitiveLocal1Ref,
+					"db_password":  "${sensitive(local.local2)}",
+					"nested":       map[string]interface{}{keyKey: "${sensitive(local.local3)}"},
+					"replica_urls": "${sensitive(local.local4)}",
+				},
+			},
+			expectedLocals: map[string]interface{}{
+				local1Key: "t0k3n",
+				"local2":  "hunter2",
+				"local3":  float64(8),
+				"local4":  []interface{}{"a"},
+			},
+		},
+		{
+			name: "output secret map keeps its keys visible",
+			inputsValue: resource.PropertyMap{
+				key1Key: resource.NewOutputProperty(resource.Output{
+					Element: resource.NewObjectProperty(resource.PropertyMap{
+						keyKey: resource.NewStringProperty(testValue),
+					}),
+					Known:  true,
+					Secret: true,
+				}),
+			},
+			expected: map[string]interface{}{
+				key1Key: map[string]interface{}{keyKey: sensitiveLocal1Ref},
+			},
+			expectedLocals: map[string]interface{}{
+				local1Key: testValue,
+			},
+		},
 		{
 			name: "single nested sensitive value",
 			inputsValue: resource.PropertyMap{
@@ -0,0 +1,55 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// tfLogEnvironmentVariable sets the TF_LOG level of every Terraform command run by the provider process, for ad-hoc
+// debugging of a single `pulumi up`. tfexec clears TF_LOG unless logs are written to a file, so setting TF_LOG
+// itself has no effect.
+const tfLogEnvironmentVariable = "PULUMI_TERRAFORM_MODULE_TF_LOG"
+
+// tfLogFileName is the file in the working directory that Terraform logs are appended to.
+const tfLogFileName = "terraform.log"
+
+// TFLogEnabled reports whether PULUMI_TERRAFORM_MODULE_TF_LOG makes runtimes write Terraform logs to their working
+// directories.
+func TFLogEnabled() bool {
+	return os.Getenv(tfLogEnvironmentVariable) != ""
+}
+
+// enableTFLog makes the runtime write Terraform logs at the level of PULUMI_TERRAFORM_MODULE_TF_LOG to a log file in
+// its working directory. It only configures this runtime, leaving the process environment unchanged.
+func (t *ModuleRuntime) enableTFLog(ctx context.Context, logger Logger) error {
+	if !TFLogEnabled() {
+		return nil
+	}
+	level := os.Getenv(tfLogEnvironmentVariable)
+	if err := t.tf.SetLog(level); err != nil {
+		return fmt.Errorf("error setting the Terraform log level from %s: %w", tfLogEnvironmentVariable, err)
+	}
+	logPath := filepath.Join(t.WorkingDir(), tfLogFileName)
+	if err := t.tf.SetLogPath(logPath); err != nil {
+		return fmt.Errorf("error setting the Terraform log path: %w", err)
+	}
+	t.tfLogPath = logPath
+	logger.Log(ctx, Debug, fmt.Sprintf("Writing Terraform logs at level %s to %s", level, logPath))
+	return nil
+}
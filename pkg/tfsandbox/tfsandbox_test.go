@@ -5,7 +5,7 @@ import (
 	"testing"
 )
 
-func getCwd(t *testing.T) string {
+func getCwd(t testing.TB) string {
 	cwd, err := os.Getwd()
 	if err != nil {
 		t.FailNow()
@@ -2,7 +2,10 @@ package tfsandbox
 
 import (
 	"net/url"
+	"regexp"
 	"strings"
+
+	"github.com/blang/semver"
 )
 
 // Reference to a Terraform module, for example "terraform-aws-modules/vpc/aws".
@@ -36,6 +39,147 @@ func (s TFModuleSource) ReferencedVersionInURL() (string, bool) {
 	return ref, ref != ""
 }
 
+// Normalized rewrites registry sources that spell out the public registry host, such as
+// registry.terraform.io/terraform-aws-modules/vpc/aws or the browser URL
+// https://registry.terraform.io/modules/terraform-aws-modules/vpc/aws, to the canonical shorthand
+// terraform-aws-modules/vpc/aws. Both the Terraform and the OpenTofu public registries are recognized, as the
+// shorthand resolves to the registry of the executor in use. A trailing version segment, as in the URL of the page of
+// a given version, is dropped since the version of the module is given separately. Other sources are returned
+// unchanged.
+func (s TFModuleSource) Normalized() TFModuleSource {
+	source := strings.TrimPrefix(string(s), "https://")
+	for _, host := range []string{terraformRegistryHost, tofuRegistryHost} {
+		path, ok := strings.CutPrefix(source, host+"/")
+		if !ok {
+			continue
+		}
+
+		pkg, subdir, hasSubdir := strings.Cut(path, "//")
+		parts := strings.Split(strings.TrimSuffix(pkg, "/"), "/")
+		if len(parts) > 3 && parts[0] == "modules" {
+			// registry.terraform.io/modules/<namespace>/<name>/<provider> as found in the browser
+			parts = parts[1:]
+		}
+		if len(parts) == 4 && isRegistryPageVersion(parts[3]) {
+			// the page of a version, as in .../<provider>/5.18.1 or .../<provider>/latest
+			parts = parts[:3]
+		}
+		if len(parts) != 3 {
+			return s
+		}
+
+		normalized := strings.Join(parts, "/")
+		if hasSubdir {
+			normalized += "//" + subdir
+		}
+		return TFModuleSource(normalized)
+	}
+	return s
+}
+
+// isRegistryPageVersion reports whether the segment of a registry URL after the provider of a module selects a version.
+func isRegistryPageVersion(segment string) bool {
+	if segment == "latest" {
+		return true
+	}
+	_, err := semver.Parse(segment)
+	return err == nil
+}
+
+// gitSourcePrefixes are the prefixes of module sources that Terraform downloads with git, see
+// https://developer.hashicorp.com/terraform/language/modules/sources
+var gitSourcePrefixes = []string{"git::", "github.com/", "bitbucket.org/", "git@"}
+
+// commitRef matches refs that are commit hashes rather than branch or tag names.
+var commitRef = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// IsGit determines whether the module is downloaded by cloning a git repository.
+func (s TFModuleSource) IsGit() bool {
+	for _, prefix := range gitSourcePrefixes {
+		if strings.HasPrefix(string(s), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithShallowClone requests a shallow clone of git sources, as in git::https://example.com/vpc.git?ref=v1.2.0&depth=1,
+// so that the history of the repository is not downloaded. Sources that already set a depth are returned unchanged,
+// as are sources referencing a commit: git only shallow clones branches and tags.
+func (s TFModuleSource) WithShallowClone() TFModuleSource {
+	if !s.IsGit() {
+		return s
+	}
+	_, rawQuery, hasQuery := strings.Cut(string(s), "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || query.Has("depth") || commitRef.MatchString(query.Get("ref")) {
+		return s
+	}
+	if hasQuery {
+		return s + "&depth=1"
+	}
+	return s + "?depth=1"
+}
+
+// ReferencedCommit returns the commit hash a git source references, as in
+// git::https://example.com/vpc.git?ref=51d462976d84fdea54b47d80dcabbf680badcdb8.
+func (s TFModuleSource) ReferencedCommit() (string, bool) {
+	if !s.IsGit() {
+		return "", false
+	}
+	_, rawQuery, _ := strings.Cut(string(s), "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil || !commitRef.MatchString(query.Get("ref")) {
+		return "", false
+	}
+	return query.Get("ref"), true
+}
+
+// WithRef references ref in a git source, which is expected not to reference one yet.
+func (s TFModuleSource) WithRef(ref string) TFModuleSource {
+	if strings.Contains(string(s), "?") {
+		return s + TFModuleSource("&ref="+url.QueryEscape(ref))
+	}
+	return s + TFModuleSource("?ref="+url.QueryEscape(ref))
+}
+
+// WithoutRef removes the ref of a git source, keeping its other query arguments.
+func (s TFModuleSource) WithoutRef() TFModuleSource {
+	base, rawQuery, hasQuery := strings.Cut(string(s), "?")
+	if !hasQuery {
+		return s
+	}
+	var kept []string
+	for _, arg := range strings.Split(rawQuery, "&") {
+		if key, _, _ := strings.Cut(arg, "="); key != "ref" {
+			kept = append(kept, arg)
+		}
+	}
+	if len(kept) == 0 {
+		return TFModuleSource(base)
+	}
+	return TFModuleSource(base + "?" + strings.Join(kept, "&"))
+}
+
+// GitRemote returns the address of the repository a git source is cloned from, without the git:: forced getter,
+// the subdirectory and the query arguments. Shorthands such as github.com/org/repo are expanded to HTTPS URLs.
+func (s TFModuleSource) GitRemote() string {
+	remote, _, _ := strings.Cut(strings.TrimPrefix(string(s), "git::"), "?")
+	start := 0
+	if i := strings.Index(remote, "://"); i >= 0 {
+		start = i + len("://")
+	}
+	if i := strings.Index(remote[start:], "//"); i >= 0 {
+		remote = remote[:start+i]
+	}
+	for _, host := range []string{"github.com/", "bitbucket.org/"} {
+		if strings.HasPrefix(remote, host) {
+			return "https://" + strings.TrimSuffix(remote, ".git") + ".git"
+		}
+	}
+	return remote
+}
+
 // Version specification for a Terraform module, for example "5.16.0".
 //
 // May indicate version constraints, or be empty.
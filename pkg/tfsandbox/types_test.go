@@ -11,3 +11,52 @@ func Test_IsLocalPath(t *testing.T) {
 	assert.True(t, TFModuleSource("./local-module").IsLocalPath())
 	assert.False(t, TFModuleSource("hashicorp/consul/aws").IsLocalPath())
 }
+
+func Test_Normalized(t *testing.T) {
+	const vpc = "terraform-aws-modules/vpc/aws"
+	for source, expected := range map[TFModuleSource]TFModuleSource{
+		vpc:                            vpc,
+		"registry.terraform.io/" + vpc: vpc,
+		"registry.opentofu.org/" + vpc: vpc,
+		"https://registry.terraform.io/modules/" + vpc:             vpc,
+		"registry.terraform.io/" + vpc + "//modules/sub":           vpc + "//modules/sub",
+		"https://registry.terraform.io/modules/" + vpc + "/5.18.1": vpc,
+		"https://registry.terraform.io/modules/" + vpc + "/latest": vpc,
+		"registry.opentofu.org/" + vpc + "/5.18.1/":                vpc,
+		"registry.terraform.io/" + vpc + "/main":                   "registry.terraform.io/" + vpc + "/main",
+		"app.terraform.io/example-corp/k8s/azurerm":                "app.terraform.io/example-corp/k8s/azurerm",
+		"github.com/hashicorp/example":                             "github.com/hashicorp/example",
+		"./local-module":                                           "./local-module",
+	} {
+		assert.Equal(t, expected, source.Normalized(), "normalizing %s", source)
+	}
+}
+
+func Test_WithShallowClone(t *testing.T) {
+	for source, expected := range map[TFModuleSource]TFModuleSource{
+		"github.com/hashicorp/example":                    "github.com/hashicorp/example?depth=1",
+		"git::https://example.com/vpc.git?ref=v1.2.0":     "git::https://example.com/vpc.git?ref=v1.2.0&depth=1",
+		"git::https://example.com/net.git//modules/vpc":   "git::https://example.com/net.git//modules/vpc?depth=1",
+		"git@github.com:hashicorp/example.git?ref=main":   "git@github.com:hashicorp/example.git?ref=main&depth=1",
+		"git::https://example.com/vpc.git?depth=10":       "git::https://example.com/vpc.git?depth=10",
+		"git::https://example.com/vpc.git?ref=51d462976d": "git::https://example.com/vpc.git?ref=51d462976d",
+		"terraform-aws-modules/vpc/aws":                   "terraform-aws-modules/vpc/aws",
+		"https://example.com/vpc-module.zip":              "https://example.com/vpc-module.zip",
+		"./local-module":                                  "./local-module",
+	} {
+		assert.Equal(t, expected, source.WithShallowClone(), "shallow cloning %s", source)
+	}
+}
+
+func Test_GitRemote(t *testing.T) {
+	for source, expected := range map[TFModuleSource]string{
+		"github.com/hashicorp/example":                      "https://github.com/hashicorp/example.git",
+		"bitbucket.org/hashicorp/example.git":               "https://bitbucket.org/hashicorp/example.git",
+		"git::https://example.com/vpc.git?ref=v1.2.0":       "https://example.com/vpc.git",
+		"git::https://example.com/net.git//modules/vpc":     "https://example.com/net.git",
+		"git::ssh://git@example.com/net.git//modules/vpc":   "ssh://git@example.com/net.git",
+		"git@github.com:hashicorp/example.git//modules/vpc": "git@github.com:hashicorp/example.git",
+	} {
+		assert.Equal(t, expected, source.GitRemote(), "remote of %s", source)
+	}
+}
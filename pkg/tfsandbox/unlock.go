@@ -0,0 +1,30 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"fmt"
+)
+
+// ForceUnlock removes the lock with the given ID from the state of the module instance, as in
+// `terraform force-unlock`. It is meant for recovering from locks orphaned by a crashed run and only has an effect
+// with a backend that supports locking.
+func (t *ModuleRuntime) ForceUnlock(ctx context.Context, lockID string) error {
+	if err := t.tf.ForceUnlock(ctx, lockID); err != nil {
+		return fmt.Errorf("error running tofu force-unlock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceUnlock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub executor is a shell script")
+	}
+
+	ctx := context.Background()
+	stub, err := filepath.Abs(filepath.Join("testdata", "force_unlock", "stub.sh"))
+	require.NoError(t, err)
+
+	tf, err := NewRuntimeFromExecutable(ctx, DiscardLogger, Workdir{t.Name()}, nil, stub)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(tf.WorkingDir()) })
+
+	heldLock := filepath.Join(tf.WorkingDir(), "held.lock")
+	require.NoError(t, os.WriteFile(heldLock, []byte("abc-123"), 0600))
+
+	err = tf.ForceUnlock(ctx, "some-other-lock")
+	assert.ErrorContains(t, err, "does not match the existing lock")
+	assert.FileExists(t, heldLock)
+
+	require.NoError(t, tf.ForceUnlock(ctx, "abc-123"))
+	assert.NoFileExists(t, heldLock)
+}
@@ -0,0 +1,29 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tfsandbox
+
+import (
+	"context"
+	"fmt"
+)
+
+// Untaint clears the tainted flag of the resource at the given address, as in `terraform untaint`, so that the next
+// apply keeps the resource instead of replacing it.
+func (t *ModuleRuntime) Untaint(ctx context.Context, address ResourceAddress) error {
+	if err := t.tf.Untaint(ctx, string(address)); err != nil {
+		return fmt.Errorf("error running tofu untaint: %w", err)
+	}
+	return nil
+}
@@ -108,6 +108,11 @@ func workdirGetOrCreate(ctx context.Context, logger Logger, workdir Workdir) (st
 	return path, nil
 }
 
+// RemoveWorkdir deletes a working directory including the modules and providers cached in it, if it exists.
+func RemoveWorkdir(workdir Workdir) error {
+	return os.RemoveAll(workdirPath(workdir))
+}
+
 // Delete all transient files to avoid accidentally poisoning accuracy of TOFU execution with stale files.
 //
 // While not listed in an explicit way, the following important sub-paths with persist across `pulumi` executions as
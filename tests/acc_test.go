@@ -37,6 +37,8 @@ import (
 	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/hexops/autogold/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -164,7 +166,14 @@ func Test_RandMod_TypeScript(t *testing.T) {
 			"plaintext":                        `"9"`,
 		}}).Equal(t, randInt.Inputs)
 
-		autogold.Expect(map[string]interface{}{}).Equal(t, randInt.Outputs)
+		// the provider address depends on the registry of the executor, as recorded in the lock file of the module
+		myrandmod := mustFindDeploymentResourceByType(t, pt, "randmod:index:Module")
+		assert.Equal(t, map[string]interface{}{
+			"address":  "module.myrandmod.random_integer.priority",
+			"id":       "2",
+			"provider": lockedProviderAddress(t, myrandmod, "random"),
+			"type":     "random_integer",
+		}, randInt.Outputs)
 	})
 
 	t.Run("pulumi preview should be empty", func(t *testing.T) {
@@ -415,6 +424,170 @@ func Test_TwoInstances_TypeScript(t *testing.T) {
 	})
 }
 
+// Child resource views can be removed from the Pulumi state like any other resource. The module owns the Terraform
+// state of its resources, so the next update reports the view again without touching the resource.
+func TestStateDeleteChildResourceView(t *testing.T) {
+	t.Parallel()
+
+	localProviderBinPath := ensureCompiledProvider(t)
+	randMod, err := filepath.Abs(filepath.Join("testdata", "modules", randmod))
+	require.NoError(t, err)
+
+	randModProg := filepath.Join("testdata", "programs", "ts", "randmod-program")
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
+	pt := newPulumiTest(t, randModProg, localPath)
+	pt.CopyToTempDir(t)
+
+	pulumiPackageAdd(t, pt, localProviderBinPath, randMod, randmod)
+	pt.Up(t)
+
+	view := mustFindDeploymentResourceByType(t, pt, "randmod:tf:random_integer")
+	assert.Equal(t, "module.myrandmod.random_integer.priority", view.Outputs["address"])
+	assert.Equal(t, "2", view.Outputs["id"])
+
+	viewURN := strings.Replace(string(view.URN), "urn:pulumi:test::",
+		fmt.Sprintf("urn:pulumi:%s::", pt.CurrentStack().Name()), 1)
+	stdout, stderr, exitCode, err := pt.CurrentStack().Workspace().PulumiCommand().Run(
+		context.Background(),
+		pt.WorkingDir(),
+		nil, /* reader */
+		nil, /* additionalOutput */
+		nil, /* additionalErrorOutput */
+		nil, /* additionalEnv */
+		"state", "delete", viewURN, "--yes",
+	)
+	require.NoErrorf(t, err, "pulumi state delete failed\n%s\n%s", stdout, stderr)
+	require.Equal(t, 0, exitCode)
+
+	upResult := pt.Up(t)
+	t.Logf("%s", upResult.StdOut+upResult.StdErr)
+	restored := mustFindDeploymentResourceByType(t, pt, "randmod:tf:random_integer")
+	assert.Equal(t, view.URN, restored.URN)
+	assert.Equal(t, view.Outputs, restored.Outputs)
+}
+
+func TestPreviewJSONDetailsChildResourceChanges(t *testing.T) {
+	t.Parallel()
+
+	localProviderBinPath := ensureCompiledProvider(t)
+	randMod, err := filepath.Abs(filepath.Join("testdata", "modules", randmod))
+	require.NoError(t, err)
+
+	randModProg := filepath.Join("testdata", "programs", "ts", "randmod-program")
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
+	pt := newPulumiTest(t, randModProg, localPath)
+	pt.CopyToTempDir(t)
+
+	pulumiPackageAdd(t, pt, localProviderBinPath, randMod, randmod)
+	pt.Up(t)
+
+	// Changing the max of random_integer replaces it.
+	program := filepath.Join(pt.WorkingDir(), "index.ts")
+	source, err := os.ReadFile(program)
+	require.NoError(t, err)
+	source = []byte(strings.Replace(string(source), "maxlen: 10", "maxlen: 12", 1))
+	require.NoError(t, os.WriteFile(program, source, 0o600))
+
+	stdout, stderr, exitCode, err := pt.CurrentStack().Workspace().PulumiCommand().Run(
+		context.Background(),
+		pt.WorkingDir(),
+		nil, /* reader */
+		nil, /* additionalOutput */
+		nil, /* additionalErrorOutput */
+		[]string{"PULUMI_TERRAFORM_MODULE_CHILD_RESOURCE_DIFFS=true"},
+		"preview", "--json", "--stack", pt.CurrentStack().Name(),
+	)
+	require.NoErrorf(t, err, "pulumi preview --json failed\n%s\n%s", stdout, stderr)
+	require.Equal(t, 0, exitCode)
+
+	var preview struct {
+		Steps []struct {
+			Op             string                    `json:"op"`
+			URN            string                    `json:"urn"`
+			DiffReasons    []string                  `json:"diffReasons"`
+			ReplaceReasons []string                  `json:"replaceReasons"`
+			DetailedDiff   map[string]map[string]any `json:"detailedDiff"`
+		} `json:"steps"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &preview), stdout)
+
+	var ops []string
+	for _, step := range preview.Steps {
+		if !strings.HasSuffix(step.URN, "::module.myrandmod.random_integer.priority") {
+			continue
+		}
+		ops = append(ops, step.Op)
+		if step.Op != "replace" {
+			continue
+		}
+		// the attributes computed by the provider, such as result, change as well
+		assert.Contains(t, step.DiffReasons, "max")
+		assert.Equal(t, []string{"max"}, step.ReplaceReasons)
+		assert.Equal(t, "update-replace", step.DetailedDiff["max"]["kind"])
+	}
+	assert.Contains(t, ops, "replace", "expected the child resource to be replaced\n%s", stdout)
+}
+
+func TestStateBackupBeforeApply(t *testing.T) {
+	t.Parallel()
+
+	localProviderBinPath := ensureCompiledProvider(t)
+	randMod, err := filepath.Abs(filepath.Join("testdata", "modules", randmod))
+	require.NoError(t, err)
+
+	randModProg := filepath.Join("testdata", "programs", "ts", "randmod-program")
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
+	pt := newPulumiTest(t, randModProg, localPath)
+	pt.CopyToTempDir(t)
+
+	pulumiPackageAdd(t, pt, localProviderBinPath, randMod, randmod)
+	pt.Up(t)
+
+	program := filepath.Join(pt.WorkingDir(), "index.ts")
+	source, err := os.ReadFile(program)
+	require.NoError(t, err)
+	source = []byte(strings.Replace(string(source), "maxlen: 10", "maxlen: 12", 1))
+	require.NoError(t, os.WriteFile(program, source, 0o600))
+
+	backupDir := t.TempDir()
+	stdout, stderr, exitCode, err := pt.CurrentStack().Workspace().PulumiCommand().Run(
+		context.Background(),
+		pt.WorkingDir(),
+		nil, /* reader */
+		nil, /* additionalOutput */
+		nil, /* additionalErrorOutput */
+		[]string{"PULUMI_TERRAFORM_MODULE_STATE_BACKUP_DIR=" + backupDir},
+		"up", "--yes", "--skip-preview", "--stack", pt.CurrentStack().Name(),
+	)
+	require.NoErrorf(t, err, "pulumi up failed\n%s\n%s", stdout, stderr)
+	require.Equal(t, 0, exitCode)
+
+	backups, err := filepath.Glob(filepath.Join(backupDir, "*", pt.CurrentStack().Name(), "*-apply.tfstate"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1, "expected a backup of the module state taken before the apply")
+
+	// the backup holds the state from the first update, with the previous max of random_integer
+	var backup struct {
+		Resources []struct {
+			Type      string `json:"type"`
+			Instances []struct {
+				Attributes map[string]any `json:"attributes"`
+			} `json:"instances"`
+		} `json:"resources"`
+	}
+	contents, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(contents, &backup))
+	found := false
+	for _, r := range backup.Resources {
+		if r.Type == "random_integer" {
+			found = true
+			assert.Equal(t, float64(10), r.Instances[0].Attributes["max"])
+		}
+	}
+	assert.True(t, found, "expected random_integer in the backup\n%s", contents)
+}
+
 func TestAutomaticallySettingNameInputFromResourceName(t *testing.T) {
 	t.Parallel()
 	localProviderBinPath := ensureCompiledProvider(t)
@@ -549,6 +722,62 @@ func TestChangingLocalModuleSourceCodeResourcesCausesUpdate(t *testing.T) {
 	mustFindDeploymentResourceByType(t, integrationTest, "rand:tf:random_pet")
 }
 
+// Test that a child resource moved to another address by a moved block in the module is kept, with its view moving
+// to the new address.
+func TestMovedModuleResourcesAreKept(t *testing.T) {
+	localProviderBinPath := ensureCompiledProvider(t)
+	modulePath := filepath.Join("testdata", "modules", "moved_resources")
+	v1, err := filepath.Abs(filepath.Join(modulePath, "mod_v1"))
+	require.NoError(t, err, "failed to get absolute path for v1 module")
+	v2, err := filepath.Abs(filepath.Join(modulePath, "mod_v2"))
+	require.NoError(t, err, "failed to get absolute path for v2 module")
+
+	program := filepath.Join("testdata", "programs", "yaml", "moved_resources")
+
+	integrationTest := pulumitest.NewPulumiTest(t, program,
+		opttest.SkipInstall(),
+		opttest.LocalProviderPath(provider, filepath.Dir(localProviderBinPath)))
+
+	pulumiPackageAdd(t, integrationTest, localProviderBinPath, v1, "rand")
+
+	integrationTest.Up(t)
+	before := mustFindDeploymentResourceByType(t, integrationTest, "rand:tf:random_integer")
+	assert.True(t, strings.HasSuffix(string(before.URN), ".random_integer.priority"), before.URN)
+
+	// Now move random_integer.priority to random_integer.rank in the module
+	v1SourceCode, err := os.ReadFile(filepath.Join(v1, "main.tf"))
+	require.NoError(t, err, "failed to read v1 source code")
+	v2SourceCode, err := os.ReadFile(filepath.Join(v2, "main.tf"))
+	require.NoError(t, err, "failed to read v2 source code")
+
+	//nolint:gosec // G703: v1 is a test-controlled temp module directory, not attacker input
+	err = os.WriteFile(filepath.Join(v1, "main.tf"), v2SourceCode, 0600)
+	require.NoError(t, err, "failed to write v2 source code to v1 module")
+
+	t.Cleanup(func() {
+		// Restore the original source code after the test
+		//nolint:gosec // G703: v1 is a test-controlled temp module directory, not attacker input
+		err := os.WriteFile(filepath.Join(v1, "main.tf"), v1SourceCode, 0600)
+		require.NoError(t, err, "failed to restore v1 source code")
+	})
+
+	preview := integrationTest.Preview(t)
+	assert.Equal(t, 1, preview.ChangeSummary[apitype.OpType(deleteOp)],
+		"expected the view under the old address to be deleted")
+	assert.Equal(t, 1, preview.ChangeSummary[apitype.OpType(createOp)],
+		"expected a view under the new address to be created")
+
+	integrationTest.Up(t)
+	after := mustFindDeploymentResourceByType(t, integrationTest, "rand:tf:random_integer")
+	assert.True(t, strings.HasSuffix(string(after.URN), ".random_integer.rank"), after.URN)
+	assert.Equal(t, before.Outputs["id"], after.Outputs["id"], "expected the resource to be kept")
+
+	// The moved view is settled, so another update changes nothing
+	preview = integrationTest.Preview(t)
+	assert.Equal(t, 0, preview.ChangeSummary[apitype.OpType(deleteOp)])
+	assert.Equal(t, 0, preview.ChangeSummary[apitype.OpType(createOp)])
+}
+
 func TestGenerateTerraformAwsModulesSDKs(t *testing.T) {
 	t.Parallel()
 
@@ -702,7 +931,7 @@ func TestS3BucketModSecret(t *testing.T) {
 				"plaintext": `[{"apply_server_side_encryption_by_default":[{"kms_master_key_id":"","sse_algorithm":"AES256"}],"blocked_encryption_types":["SSE-C"],"bucket_key_enabled":false}]`,
 			}).Equal(t, encrConf.Inputs["rule"])
 
-			autogold.Expect(map[string]interface{}{}).Equal(t, encrConf.Outputs)
+			assert.Equal(t, "aws_s3_bucket_server_side_encryption_configuration", encrConf.Outputs["type"])
 		})
 	}
 }
@@ -1315,8 +1544,7 @@ func TestEndToEndUsingModuleWithDashes(t *testing.T) {
 	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
 	modulePath, err := filepath.Abs(filepath.Join("testdata", "modules", "dashed-module-fields"))
 	assert.NoError(t, err, "failed to get absolute path for dashed module")
-	// TODO[pulumi/pulumi-terraform-module#426] - test local modules with Go
-	for _, runtime := range []string{"yaml", "ts", "python", "dotnet"} {
+	for _, runtime := range []string{"yaml", "ts", "python", "dotnet", "go"} {
 		t.Run(runtime, func(t *testing.T) {
 			testProgram, err := filepath.Abs(filepath.Join("testdata", "programs", runtime, "dashed_module"))
 			require.NoError(t, err, "failed to get absolute path for dashed_module program")
@@ -1342,6 +1570,60 @@ func TestEndToEndUsingModuleWithDashes(t *testing.T) {
 	}
 }
 
+// complexOutputsModule is the module whose output types are checked by the schema inference tests of modprovider.
+var complexOutputsModule = filepath.Join("..", "pkg", "modprovider", "testdata", "modules", "complex-outputs")
+
+// Verify that the Go SDK exposes collections of objects output by a module as typed structs.
+func TestGoSDKReadsComplexOutputsAsStructs(t *testing.T) {
+	localProviderBinPath := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
+	modulePath, err := filepath.Abs(complexOutputsModule)
+	require.NoError(t, err)
+
+	testProgram := filepath.Join("testdata", "programs", "go", "complex_outputs")
+	it := newPulumiTest(t, testProgram, localPath, opttest.SkipInstall())
+	pulumiPackageAdd(t, it, localProviderBinPath, modulePath, "complexmod")
+	upResult := it.Up(t)
+
+	assert.Equal(t, []interface{}{"a", "b"}, upResult.Outputs["names"].Value)
+	firstID, ok := upResult.Outputs["firstId"]
+	require.True(t, ok, "expected output 'firstId' to be present")
+	assert.NotEmpty(t, firstID.Value)
+}
+
+// Verify that complex module outputs can be consumed from another project through a stack reference.
+func TestStackReferenceToComplexOutputs(t *testing.T) {
+	localProviderBinPath := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localProviderBinPath))
+	modulePath, err := filepath.Abs(complexOutputsModule)
+	require.NoError(t, err)
+
+	producerProgram := filepath.Join("testdata", "programs", "python", "complex_outputs_producer")
+	producer := newPulumiTest(t, producerProgram, localPath, opttest.SkipInstall())
+	pulumiPackageAdd(t, producer, localProviderBinPath, modulePath, "complexmod")
+	producerResult := producer.Up(t)
+
+	backendURL := producer.CurrentStack().Workspace().GetEnvVars()["PULUMI_BACKEND_URL"]
+	require.NotEmpty(t, backendURL)
+
+	consumerProgram := filepath.Join("testdata", "programs", "yaml", "complex_outputs_consumer")
+	consumer := newPulumiTest(t, consumerProgram, opttest.UseAmbientBackend(),
+		opttest.Env("PULUMI_BACKEND_URL", backendURL))
+	consumer.SetConfig(t, "producer",
+		fmt.Sprintf("organization/complex_outputs_producer/%s", producer.CurrentStack().Name()))
+	consumerResult := consumer.Up(t)
+
+	for _, name := range []string{"by_name", "items", "item_ids", "named_items", "item_names", "listener", "zones"} {
+		produced, ok := producerResult.Outputs[name]
+		require.True(t, ok, "expected the producer to export %q", name)
+		consumed, ok := consumerResult.Outputs[name]
+		require.True(t, ok, "expected the consumer to read %q", name)
+		assert.Equal(t, produced.Value, consumed.Value, "output %q", name)
+	}
+	assert.Equal(t, []interface{}{"http", float64(80)}, consumerResult.Outputs["listener"].Value)
+	assert.Equal(t, []interface{}{"a", "b"}, consumerResult.Outputs["item_names"].Value)
+}
+
 func TestDiffDetailTerraform(t *testing.T) {
 	w := newTestWriter(t)
 
@@ -1548,6 +1830,45 @@ func TestRefreshTofu(t *testing.T) {
 	checkRefresh(t, tofuExec)
 }
 
+// Switching the executor mid-stack must keep the module state usable: create with terraform, then update with tofu.
+func TestSwitchingExecutorFromTerraformToTofu(t *testing.T) {
+	tw := newTestWriter(t)
+	skipLocalRunsWithoutCreds(t) // using aws_s3_bucket to test
+	ctx := context.Background()
+
+	testProgram := filepath.Join("testdata", "programs", "ts", "refresher")
+	testMod, err := filepath.Abs(filepath.Join(".", "testdata", "modules", "bucketmod"))
+	require.NoError(t, err)
+
+	localBin := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localBin))
+
+	t.Setenv("PULUMI_TERRAFORM_MODULE_EXECUTOR", terraformExec)
+	it := newPulumiTest(t, testProgram, localPath)
+
+	pulumiPackageAdd(t, it, localBin, testMod, "bucketmod")
+	it.SetConfig(t, "prefix", generateTestResourcePrefix())
+
+	t.Logf("# pulumi up with terraform")
+	it.SetConfig(t, "tagvalue", "a")
+	it.Up(t, optup.ErrorProgressStreams(tw), optup.ProgressStreams(tw))
+
+	t.Logf("# pulumi up with tofu")
+	t.Setenv("PULUMI_TERRAFORM_MODULE_EXECUTOR", tofuExec)
+	it.SetConfig(t, "tagvalue", "b")
+	upResult := it.Up(t, optup.ErrorProgressStreams(tw), optup.ProgressStreams(tw))
+	require.Equal(t, 2, (*upResult.Summary.ResourceChanges)[updateOp], "expected the module and the bucket to update")
+
+	outMap, err := it.CurrentStack().Outputs(ctx)
+	require.NoError(t, err)
+	autogold.Expect(map[string]any{testTagKey: "b"}).Equal(t, outMap["tags"].Value)
+
+	t.Logf("# pulumi preview with tofu reports no changes")
+	previewResult := it.Preview(t, optpreview.Diff(), optpreview.ExpectNoChanges(),
+		optpreview.ErrorProgressStreams(tw), optpreview.ProgressStreams(tw))
+	t.Logf("%s", previewResult.StdOut)
+}
+
 // This variation of TestRefresh checks that normal pulumi preview and pulumi up detect and correct drift even when
 // refresh is not explicitly called or requested, to mimic Terraform default behavior on this.
 func checkRefreshImplicitly(t *testing.T, executor string) {
@@ -1653,6 +1974,125 @@ func TestRefreshImplicitlyTofu(t *testing.T) {
 	checkRefreshImplicitly(t, tofuExec)
 }
 
+// Verify that previews fail instead of displaying the drift when the provider is configured with failOnDrift.
+func TestFailOnDriftDuringPreview(t *testing.T) {
+	skipLocalRunsWithoutCreds(t) // using aws_s3_bucket to test
+	tw := newTestWriter(t)
+
+	testProgram := filepath.Join("testdata", "programs", "ts", "refresher")
+	testMod, err := filepath.Abs(filepath.Join(".", "testdata", "modules", "bucketmod"))
+	require.NoError(t, err)
+
+	localBin := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localBin))
+	it := newPulumiTest(t, testProgram, localPath, opttest.Env("PULUMI_TERRAFORM_MODULE_FAIL_ON_DRIFT", "true"))
+
+	pulumiPackageAdd(t, it, localBin, testMod, "bucketmod")
+	it.SetConfig(t, "prefix", generateTestResourcePrefix())
+
+	t.Logf("## pulumi up: create with tagvalue=a")
+	it.SetConfig(t, "tagvalue", "a")
+	it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+	stateA := it.ExportStack(t)
+
+	t.Logf("## pulumi up: update to set tagvalue=b")
+	it.SetConfig(t, "tagvalue", "b")
+	it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+
+	// Restoring the earlier state leaves the bucket tagged with b in the cloud, which is drift.
+	it.SetConfig(t, "tagvalue", "a")
+	it.ImportStack(t, stateA)
+
+	t.Logf("## pulumi preview: expect to fail on the drift")
+	_, err = it.CurrentStack().Preview(context.Background(),
+		optpreview.ProgressStreams(tw), optpreview.ErrorProgressStreams(tw))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "drift detected in module mybucketmod")
+}
+
+// Verify that tags added outside of the program are not drift when the aws provider ignores them with ignore_tags.
+func TestIgnoreTagsPreventsDrift(t *testing.T) {
+	skipLocalRunsWithoutCreds(t) // using aws_s3_bucket to test
+	tw := newTestWriter(t)
+	ctx := context.Background()
+
+	testProgram := filepath.Join("testdata", "programs", "ts", "ignore-tags")
+	testMod, err := filepath.Abs(filepath.Join(".", "testdata", "modules", "bucketmod"))
+	require.NoError(t, err)
+
+	localBin := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localBin))
+	it := newPulumiTest(t, testProgram, localPath, opttest.Env("PULUMI_TERRAFORM_MODULE_FAIL_ON_DRIFT", "true"))
+
+	pulumiPackageAdd(t, it, localBin, testMod, "bucketmod")
+	prefix := generateTestResourcePrefix()
+	it.SetConfig(t, "prefix", prefix)
+
+	t.Logf("## pulumi up: create the tagged bucket")
+	it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+
+	t.Logf("## tag the bucket outside of the program")
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-west-2"))
+	require.NoError(t, err)
+	_, err = s3.NewFromConfig(cfg).PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket: aws.String(prefix + "-tf-test-bucket"),
+		Tagging: &s3types.Tagging{
+			TagSet: []s3types.Tag{
+				{Key: aws.String(testTagKey), Value: aws.String("a")},
+				{Key: aws.String("ExternalTag"), Value: aws.String("external")},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Logf("## pulumi preview: expect no drift")
+	previewResult := it.Preview(t, optpreview.ProgressStreams(tw), optpreview.ErrorProgressStreams(tw))
+	// The stack, the provider, the module and the bucket.
+	autogold.Expect(map[apitype.OpType]int{apitype.OpType(sameOp): 4}).Equal(t, previewResult.ChangeSummary)
+
+	t.Logf("## pulumi refresh: expect no changes")
+	refreshResult := it.Refresh(t, optrefresh.ProgressStreams(tw), optrefresh.ErrorProgressStreams(tw))
+	autogold.Expect(&map[string]int{sameOp: 4}).Equal(t, refreshResult.Summary.ResourceChanges)
+}
+
+// Verify that refresh-only updates accept the drift into the state instead of reverting it.
+func TestRefreshOnlyUpAcceptsDrift(t *testing.T) {
+	skipLocalRunsWithoutCreds(t) // using aws_s3_bucket to test
+	tw := newTestWriter(t)
+
+	testProgram := filepath.Join("testdata", "programs", "ts", "refresher")
+	testMod, err := filepath.Abs(filepath.Join(".", "testdata", "modules", "bucketmod"))
+	require.NoError(t, err)
+
+	localBin := ensureCompiledProvider(t)
+	localPath := opttest.LocalProviderPath("terraform-module", filepath.Dir(localBin))
+	it := newPulumiTest(t, testProgram, localPath)
+
+	pulumiPackageAdd(t, it, localBin, testMod, "bucketmod")
+	it.SetConfig(t, "prefix", generateTestResourcePrefix())
+
+	const programTagValue, cloudTagValue = "a", "b"
+
+	t.Logf("## pulumi up: create with tagvalue=%s", programTagValue)
+	it.SetConfig(t, "tagvalue", programTagValue)
+	it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+	stateA := it.ExportStack(t)
+
+	t.Logf("## pulumi up: update to set tagvalue=%s", cloudTagValue)
+	it.SetConfig(t, "tagvalue", cloudTagValue)
+	it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+
+	// Restoring the earlier state leaves the bucket tagged with the later value in the cloud, which is drift.
+	it.SetConfig(t, "tagvalue", programTagValue)
+	it.ImportStack(t, stateA)
+
+	t.Logf("## pulumi up with refreshOnly: expect the drift to be accepted")
+	it.CurrentStack().Workspace().SetEnvVar("PULUMI_TERRAFORM_MODULE_REFRESH_ONLY", "true")
+	upResult := it.Up(t, optup.ProgressStreams(tw), optup.ErrorProgressStreams(tw))
+	assert.Equal(t, map[string]interface{}{testTagKey: cloudTagValue}, upResult.Outputs["tags"].Value,
+		"the state reflects the tags in the cloud rather than the program")
+}
+
 // Verify that pulumi refresh detects deleted resources.
 func checkRefreshDeleted(t *testing.T, executor string) {
 	skipLocalRunsWithoutCreds(t) // using aws_s3_bucket to test
@@ -1927,8 +2367,17 @@ func Test_Dependencies(t *testing.T) {
 	err = json.Unmarshal(deploy.Deployment, &deployment)
 	require.NoError(t, err)
 
+	moduleURN := urn.URN("urn:pulumi:test::ts-dep-tester::randmod:index:Module::myrandmod")
+
 	for _, r := range deployment.Resources {
 
+		// Child resource views of the module must be parented to the module resource.
+		if r.URN.Type() == "randmod:tf:random_integer" {
+			assert.Equal(t, moduleURN, r.Parent, "view %s must be parented to the module", r.URN)
+			//nolint:lll
+			autogold.Expect(urn.URN("urn:pulumi:test::ts-dep-tester::randmod:index:Module$randmod:tf:random_integer::module.myrandmod.random_integer.priority")).Equal(t, r.URN)
+		}
+
 		// The module resource myrandmod must depend on seed and extra resources.
 		if r.URN.Type() == "randmod:index:Module" && r.URN.Name() == "myrandmod" {
 			slices.Sort(r.Dependencies)
@@ -2146,6 +2595,17 @@ func mustFindDeploymentResourceByType(
 	return res
 }
 
+// lockedProviderAddress finds the address of a Terraform provider, such as registry.terraform.io/hashicorp/random for
+// random, in the lock file that a module instance records in its outputs.
+func lockedProviderAddress(t *testing.T, module apitype.ResourceV3, providerType string) string {
+	t.Helper()
+	lockFile, ok := module.Outputs["__lock"].(string)
+	require.Truef(t, ok, "expected %s to record its lock file", module.URN)
+	m := regexp.MustCompile(`provider "([^"]+/` + regexp.QuoteMeta(providerType) + `)"`).FindStringSubmatch(lockFile)
+	require.NotNilf(t, m, "provider %s not found in the lock file of %s:\n%s", providerType, module.URN, lockFile)
+	return m[1]
+}
+
 func fixupRandomizedStackURNs(stack *apitype.UntypedDeployment) {
 	re := regexp.MustCompile(`urn:pulumi:[^:]+[:][:]`)
 	stack.Deployment = re.ReplaceAll(stack.Deployment, []byte(`urn:pulumi:test::`))
@@ -0,0 +1,94 @@
+// Copyright 2016-2026, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// smokeTestSDK compiles a program generated by pulumi convert against the SDK generated for a module, catching code
+// generation issues before the program is run. Dependencies are expected to be installed already, as done by
+// pulumiConvert unless generateOnly is set. The toolchain of the language is required, the test is skipped otherwise.
+func smokeTestSDK(t *testing.T, language, programDir string) {
+	t.Helper()
+
+	var commands [][]string
+	switch language {
+	case "typescript":
+		commands = [][]string{{"npx", "tsc", "--noEmit", "--project", "."}}
+	case "python":
+		commands = [][]string{{"python3", "-m", "compileall", "-q", "."}}
+	case "go":
+		commands = [][]string{{"go", "mod", "tidy"}, {"go", "build", "./..."}}
+	case "dotnet":
+		commands = [][]string{{"dotnet", "build"}}
+	case "java":
+		commands = [][]string{{"mvn", "--batch-mode", "--quiet", "compile"}}
+	default:
+		t.Fatalf("no smoke test for SDKs in %s", language)
+	}
+
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			t.Skipf("%s is required to smoke test the %s SDK: %v", command[0], language, err)
+		}
+
+		t.Logf("%s", strings.Join(command, " "))
+		cmd := exec.Command(command[0], command[1:]...) //nolint:gosec
+		cmd.Dir = programDir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("failed to compile the %s program against the generated SDK: %v\n%s", language, err, out)
+		}
+	}
+}
+
+func TestSmokeTestTerraformAwsModulesVpcSDKs(t *testing.T) {
+	t.Parallel()
+
+	localProviderBinPath := ensureCompiledProvider(t)
+	pclDir := filepath.Join("testdata", "aws-vpc", "aws-vpc")
+
+	for _, language := range []string{"typescript", "python", "go", "dotnet", "java"} {
+		t.Run(language, func(t *testing.T) {
+			t.Parallel()
+
+			switch language {
+			case "python":
+				t.Skip("TODO[pulumi/pulumi-terraform-module#76] auto-installing global Python deps makes this fail")
+			case "dotnet":
+				t.Skip("TODO[pulumi/pulumi-terraform-module#77] the project is missing the SDK")
+			case "java":
+				t.Skip("pulumi convert prints instructions to make the Java program compile, which are not applied yet")
+			}
+
+			programDir := t.TempDir()
+			// --generate-only=false installs the dependencies of the program, including the generated SDK
+			pulumiConvert(t, localProviderBinPath, pclDir, programDir, language, false /* generateOnly */)
+
+			_, err := os.Stat(filepath.Join(programDir, "sdks", "vpc"))
+			require.NoError(t, err, "expected pulumi convert to generate the vpc SDK")
+
+			// The generated program creates a vpc.Module and exports its vpc_id output.
+			smokeTestSDK(t, language, programDir)
+		})
+	}
+}
@@ -0,0 +1,4 @@
+resource "random_integer" "priority" {
+  min = 1
+  max = 16
+}
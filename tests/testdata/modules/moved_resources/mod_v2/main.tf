@@ -0,0 +1,9 @@
+resource "random_integer" "rank" {
+  min = 1
+  max = 16
+}
+
+moved {
+  from = random_integer.priority
+  to   = random_integer.rank
+}
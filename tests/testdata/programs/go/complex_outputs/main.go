@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi-terraform-module/sdks/go/complexmod/complexmod"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func main() {
+	pulumi.Run(func(ctx *pulumi.Context) error {
+		m, err := complexmod.NewModule(ctx, "m", &complexmod.ModuleArgs{})
+		if err != nil {
+			return err
+		}
+
+		// named_items is a list of objects, which the Go SDK exposes as a slice of structs.
+		names := m.Named_items.ApplyT(func(items []complexmod.NamedItems) []string {
+			var names []string
+			for _, item := range items {
+				names = append(names, fmt.Sprint(item.Name))
+			}
+			return names
+		}).(pulumi.StringArrayOutput)
+
+		ctx.Export("names", names)
+		ctx.Export("firstId", m.Named_items.Index(pulumi.Int(0)).Id())
+		return nil
+	})
+}
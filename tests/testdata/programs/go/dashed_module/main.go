@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/pulumi/pulumi-terraform-module/sdks/go/dashed/dashed"
+	"github.com/pulumi/pulumi/sdk/v3/go/pulumi"
+)
+
+func main() {
+	pulumi.Run(func(ctx *pulumi.Context) error {
+		test, err := dashed.NewModule(ctx, "test", &dashed.ModuleArgs{
+			Dashed_input: pulumi.String("example"),
+		})
+		if err != nil {
+			return err
+		}
+
+		ctx.Export("result", test.Dashed_output)
+		return nil
+	})
+}
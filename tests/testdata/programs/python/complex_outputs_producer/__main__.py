@@ -0,0 +1,12 @@
+import pulumi
+import pulumi_complexmod as complexmod
+
+m = complexmod.Module("m")
+
+pulumi.export("by_name", m.by_name)
+pulumi.export("items", m.items)
+pulumi.export("item_ids", m.item_ids)
+pulumi.export("named_items", m.named_items)
+pulumi.export("item_names", m.item_names)
+pulumi.export("listener", m.listener)
+pulumi.export("zones", m.zones)
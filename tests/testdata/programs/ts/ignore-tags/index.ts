@@ -0,0 +1,22 @@
+import * as pulumi from '@pulumi/pulumi';
+import * as bucketmod from "@pulumi/bucketmod";
+
+const cfg = new pulumi.Config();
+const prefix = cfg.require("prefix");
+
+// Tags with the ExternalTag key are managed outside of the program.
+const provider = new bucketmod.Provider("ignoring-provider", {
+    aws: {
+        "region": "us-west-2",
+        "ignore_tags": {
+            "keys": ["ExternalTag"],
+        },
+    },
+});
+
+const m = new bucketmod.Module("mybucketmod", {
+    prefix: prefix,
+    tagvalue: "a",
+}, { provider: provider });
+
+export const tags = m.tags;